@@ -8,17 +8,28 @@ import (
 	"github.com/Yohe-Am/issue-1-REST/pkg/repositories/memory"
 	"github.com/Yohe-Am/issue-1-REST/pkg/repositories/postgres"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/auth"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/blob"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/comment"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/feed"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/mention"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/poll"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/reaction"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/tag"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/user"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/view"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/export"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/imaging"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/search"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/storage"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/unfurl"
 	"log"
 	"net/http"
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/microcosm-cc/bluemonday"
@@ -124,7 +135,7 @@ func main() {
 			dbRepos["Release"] = &releaseDBRepo
 			var releaseCacheRepo = memory.NewReleaseRepository(&releaseDBRepo)
 			cacheRepos["Release"] = &releaseCacheRepo
-			setup.ReleaseService = release.NewService(&releaseCacheRepo)
+			setup.ReleaseService = release.NewService(&releaseCacheRepo, &services)
 			services["Release"] = &setup.ReleaseService
 		}
 		{
@@ -143,12 +154,52 @@ func main() {
 			setup.CommentService = comment.NewService(&commentCacheRepo)
 			services["Comment"] = &setup.CommentService
 		}
+		{
+			var reactionDBRepo = postgres.NewReactionRepository(db, &dbRepos)
+			dbRepos["Reaction"] = &reactionDBRepo
+			var reactionCacheRepo = memory.NewReactionRepository(&reactionDBRepo)
+			cacheRepos["Reaction"] = &reactionCacheRepo
+			setup.ReactionService = reaction.NewService(&reactionCacheRepo, reaction.DefaultEmojis)
+			services["Reaction"] = &setup.ReactionService
+		}
+		{
+			var pollDBRepo = postgres.NewPollRepository(db, &dbRepos)
+			dbRepos["Poll"] = &pollDBRepo
+			var pollCacheRepo = memory.NewPollRepository(&pollDBRepo, &cacheRepos)
+			cacheRepos["Poll"] = &pollCacheRepo
+			setup.PollService = poll.NewService(&pollCacheRepo)
+			services["Poll"] = &setup.PollService
+		}
+		{
+			var viewDBRepo = postgres.NewViewRepository(db, &dbRepos)
+			dbRepos["View"] = &viewDBRepo
+			setup.ViewService = view.NewService(&viewDBRepo, 30*time.Minute, 500)
+			services["View"] = &setup.ViewService
+		}
+		{
+			var mentionDBRepo = postgres.NewMentionRepository(db, &dbRepos)
+			dbRepos["Mention"] = &mentionDBRepo
+			setup.MentionService = mention.NewService(&mentionDBRepo, &services)
+			services["Mention"] = &setup.MentionService
+		}
 		{
 			var searchDBRepo = postgres.NewSearchRepository(db, &dbRepos)
 			dbRepos["Search"] = &searchDBRepo
 			setup.SearchService = search.NewService(&searchDBRepo)
 			services["Search"] = &setup.SearchService
 		}
+		{
+			var tagDBRepo = postgres.NewTagRepository(db, &dbRepos)
+			dbRepos["Tag"] = &tagDBRepo
+			setup.TagService = tag.NewService(&tagDBRepo)
+			services["Tag"] = &setup.TagService
+		}
+		{
+			var unfurlDBRepo = postgres.NewUnfurlRepository(db, &dbRepos)
+			dbRepos["Unfurl"] = &unfurlDBRepo
+			setup.UnfurlService = unfurl.NewService(&unfurlDBRepo, unfurl.NewHTTPFetcher(5*time.Second, 512*1024), 24*time.Hour, 4, 256)
+			services["Unfurl"] = &setup.UnfurlService
+		}
 	}
 
 	setup.ImageServingRoute = "/images/"
@@ -158,6 +209,75 @@ func main() {
 
 	setup.HostAddress += ":" + setup.Port
 
+	setup.HTTPS = false
+
+	// usernames of those trusted to curate the tag taxonomy, separated by commas,
+	// nobody curates the taxonomy unless they're set
+	for _, curator := range strings.Split(os.Getenv("ISSUE1_TAG_CURATORS"), ",") {
+		if curator = strings.TrimSpace(curator); curator != "" {
+			setup.TagCurators = append(setup.TagCurators, curator)
+		}
+	}
+
+	if setup.HTTPS {
+		setup.HostAddress = "https://" + setup.HostAddress
+	} else {
+		setup.HostAddress = "http://" + setup.HostAddress
+	}
+
+	{
+		// set the endpoint to keep images in an S3 compatible bucket instead of the local disk,
+		// e.g. http://localhost:9000 for a local MinIO
+		const (
+			s3Endpoint  = ""
+			s3Region    = "us-east-1"
+			s3Bucket    = "issue1-images"
+			s3AccessKey = ""
+			s3SecretKey = ""
+		)
+		if s3Endpoint == "" {
+			setup.ImageStore = storage.NewLocalStore(setup.ImageStoragePath, setup.HostAddress+setup.ImageServingRoute)
+		} else {
+			var err error
+			setup.ImageStore, err = storage.NewS3Store(storage.S3Config{
+				Endpoint:  s3Endpoint,
+				Region:    s3Region,
+				Bucket:    s3Bucket,
+				AccessKey: s3AccessKey,
+				SecretKey: s3SecretKey,
+				PathStyle: true,
+				URLExpiry: time.Hour,
+			})
+			if err != nil {
+				setup.Logger.Fatalf("image store setup failed because: %s", err.Error())
+			}
+			// images are served by the bucket
+			setup.ImageStoragePath = ""
+		}
+	}
+
+	setup.ImagingService = imaging.NewService(setup.ImageStore, imaging.DefaultSizes, 2, 256, setup.Logger)
+	services["Imaging"] = &setup.ImagingService
+
+	{
+		var blobDBRepo = postgres.NewBlobRepository(db, &dbRepos)
+		dbRepos["Blob"] = &blobDBRepo
+		setup.BlobService = blob.NewService(&blobDBRepo, setup.ImageStore, setup.ImagingService)
+		services["Blob"] = &setup.BlobService
+	}
+
+	{
+		// exports are kept apart from the images, in a directory that isn't served,
+		// so that they're only sent to those that can view their channels
+		const exportStoragePath = "data/exports/"
+		if err := os.MkdirAll(exportStoragePath, 0700); err != nil {
+			setup.Logger.Fatalf("export store setup failed because: %s", err.Error())
+		}
+		exportStore := storage.NewLocalStore(exportStoragePath, "")
+		setup.ExportService = export.NewService(setup.ImageStore, exportStore, 1, 32, setup.Logger)
+		services["Export"] = &setup.ExportService
+	}
+
 	setup.StrictSanitizer = bluemonday.StrictPolicy()
 	setup.MarkupSanitizer = bluemonday.UGCPolicy()
 	setup.MarkupSanitizer.AllowAttrs("class").Matching(regexp.MustCompile("^language-[a-zA-Z0-9]+$")).OnElements("code")
@@ -179,28 +299,43 @@ func main() {
 	}
 
 	mux := rest.NewMux(&setup)
+	// periodically write the buffered views to the database
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := setup.ViewService.Flush(); err != nil {
+				setup.Logger.Printf("flushing of views failed because: %v", err)
+			}
+		}
+	}()
 	// command line ui
 	go func() {
 		scanner := bufio.NewScanner(os.Stdin)
 		for scanner.Scan() {
 			switch scanner.Text() {
 			case "k":
+				// the buffered views would be lost otherwise
+				if err := setup.ViewService.Flush(); err != nil {
+					setup.Logger.Printf("flushing of views failed because: %v", err)
+				}
 				log.Fatalln("shutting server down...")
+			case "gc":
+				// blobs are kept for a day after they're found unreferenced
+				names, err := setup.BlobService.CollectGarbage(24 * time.Hour)
+				if err != nil {
+					fmt.Printf("garbage collection failed because: %v\n", err)
+					continue
+				}
+				fmt.Printf("removed %d unreferenced blobs\n", len(names))
 			default:
 				fmt.Println("unknown command")
 			}
 		}
 	}()
 
-	setup.HTTPS = false
-
+	setup.Logger.Printf("server running on %s", setup.HostAddress)
 	if setup.HTTPS {
-		setup.HostAddress = "https://" + setup.HostAddress
-		setup.Logger.Printf("server running on %s", setup.HostAddress)
 		log.Fatal(http.ListenAndServeTLS(":"+setup.Port, "cmd/server/cert.pem", "cmd/server/key.pem", mux))
 	} else {
-		setup.HostAddress = "http://" + setup.HostAddress
-		setup.Logger.Printf("server running on %s", setup.HostAddress)
 		log.Fatal(http.ListenAndServe(":"+setup.Port, mux))
 	}
 
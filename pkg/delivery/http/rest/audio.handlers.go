@@ -0,0 +1,216 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/audio"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
+)
+
+// saveAudioPermanently is a helper function that moves the audio file saved to the
+// temporary file into the blob store under the given name
+func saveAudioPermanently(s *Setup, tmpFile *os.File, fileName, contentType string) error {
+	_, err := tmpFile.Seek(0, 0)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.BlobService.AddBlob(fileName, tmpFile, contentType)
+	return err
+}
+
+// getReleaseAudio returns a handler for GET /releases/{id}/audio requests that streams the
+// file of audio releases. Range requests are honored so that players can seek through it.
+// The file is only sent back for those who can fetch the release itself.
+func getReleaseAudio(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["id"]
+		username := r.Header.Get("authorized_username")
+
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("audio fetch attempt of non invalid release id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "releaseID",
+				ErrorMessage: fmt.Sprintf("invalid releaseID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			rel, err := s.ReleaseService.GetRelease(id)
+			if err == nil && (rel.Type != release.Audio || rel.Track == nil) {
+				err = release.ErrReleaseNotFound
+			}
+			if err == nil {
+				var visible bool
+				visible, err = canViewRelease(s, rel, username)
+				if err != nil {
+					s.Logger.Printf("fetching of release audio failed during auth because: %v", err)
+					response.Status = "error"
+					response.Message = "server error when fetching release audio"
+					writeResponseToWriter(response, w, http.StatusInternalServerError)
+					return
+				}
+				if !visible {
+					s.Logger.Printf("audio fetch attempt of unofficial release %d by non admin", id)
+					err = release.ErrReleaseNotFound
+				}
+			}
+			var file io.ReadCloser
+			if err == nil {
+				file, err = s.ImageStore.Get(rel.Content)
+			}
+			switch err {
+			case nil:
+				defer file.Close()
+				addCors(w)
+				if seeker, ok := file.(io.ReadSeeker); ok {
+					w.Header().Set("Content-Type", rel.Track.ContentType)
+					http.ServeContent(w, r, rel.Content, time.Time{}, seeker)
+					return
+				}
+				// stores that can't seek serve the file themselves, ranges included
+				u, err := s.ImageStore.URL(rel.Content)
+				if err != nil {
+					s.Logger.Printf("building of url of audio %s failed because: %v", rel.Content, err)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+				http.Redirect(w, r, u, http.StatusFound)
+				return
+			case release.ErrReleaseNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "releaseID",
+					ErrorMessage: fmt.Sprintf("audio release of releaseID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of release audio failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching release audio"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getChannelPodcast returns a handler for GET /channels/{channelUsername}/podcast requests
+// that describes the audio releases in the official catalog of the channel as a podcast
+// RSS feed. Releases the user isn't to see under their content filter are left out.
+func getChannelPodcast(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		username := r.Header.Get("authorized_username")
+
+		if !canViewChannel(s, channelUsername, username) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized podcast fetch attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
+
+		c, err := s.ChannelService.GetChannel(channelUsername)
+		switch err {
+		case nil:
+			link := fmt.Sprintf("%s/channels/%s", s.HostAddress, url.PathEscape(c.ChannelUsername))
+			podcast := &audio.Podcast{
+				Title:       c.Name,
+				Link:        link,
+				Description: c.Description,
+				Author:      c.Name,
+				Episodes:    make([]*audio.Episode, 0),
+			}
+			if podcast.Title == "" {
+				podcast.Title = c.ChannelUsername
+				podcast.Author = c.ChannelUsername
+			}
+			if c.PictureURL != "" {
+				podcast.ImageURL = imageURL(s, c.PictureURL)
+			}
+			filter := contentFilterOf(s, username)
+			for _, relID := range c.OfficialReleaseIDs {
+				rel, err := s.ReleaseService.GetRelease(int(relID))
+				if err != nil || rel.Type != release.Audio || rel.Track == nil ||
+					!filter.Allows(rel.Rating, rel.Warnings) {
+					continue
+				}
+				releaseLink := fmt.Sprintf("%s/releases/%d", s.HostAddress, rel.ID)
+				episode := &audio.Episode{
+					GUID:        releaseLink,
+					Title:       rel.Title,
+					Description: rel.Description,
+					Link:        releaseLink,
+					URL:         releaseLink + "/audio",
+					ContentType: rel.Track.ContentType,
+					Size:        rel.Track.Size,
+					Duration:    rel.Track.Duration,
+					Explicit:    rel.Rating == maturity.Mature || rel.Rating == maturity.Explicit,
+					PubDate:     rel.ReleaseDate,
+				}
+				if episode.Title == "" {
+					episode.Title = fmt.Sprintf("Release %d", rel.ID)
+				}
+				if episode.PubDate.IsZero() {
+					episode.PubDate = rel.CreationTime
+				}
+				if podcast.Category == "" {
+					podcast.Category = rel.GenreDefining
+				}
+				if podcast.Language == "" {
+					podcast.Language = rel.Language
+				}
+				podcast.Explicit = podcast.Explicit || episode.Explicit
+				podcast.Episodes = append(podcast.Episodes, episode)
+			}
+			var feed bytes.Buffer
+			if err := audio.WriteRSS(&feed, podcast); err != nil {
+				s.Logger.Printf("writing of podcast of channel %s failed because: %v", channelUsername, err)
+				response.Status = "error"
+				response.Message = "server error when fetching channel podcast"
+				statusCode = http.StatusInternalServerError
+				break
+			}
+			s.Logger.Printf("success fetching podcast of channel %s", channelUsername)
+			addCors(w)
+			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = feed.WriteTo(w)
+			return
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of channel podcast failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching channel podcast"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
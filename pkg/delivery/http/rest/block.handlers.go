@@ -0,0 +1,189 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+)
+
+// postBlockMode is a helper function that returns the mode under which the given user
+// is blocked on the channel of the post. An empty mode is returned if the user isn't blocked.
+func postBlockMode(s *Setup, postID uint, username string) channel.BlockMode {
+	p, err := s.PostService.GetPost(postID)
+	if err != nil {
+		return ""
+	}
+	b, err := s.ChannelService.GetBlockedUser(p.OriginChannel, username)
+	if err != nil {
+		return ""
+	}
+	return b.Mode
+}
+
+// getBlockedUsers returns a handler for GET /channels/{channelUsername}/blocked requests
+func getBlockedUsers(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized get blocked users attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		blocks, err := s.ChannelService.GetBlockedUsers(channelUsername)
+		switch err {
+		case nil:
+			response.Status = "success"
+			response.Data = blocks
+			s.Logger.Printf("success fetching blocked users of channel %s", channelUsername)
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of blocked users failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching blocked users of channel"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putBlockedUser returns a handler for PUT /channels/{channelUsername}/blocked/{username} requests
+func putBlockedUser(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		username := vars["username"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized put blocked user attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		b := new(channel.Block)
+		err := json.NewDecoder(r.Body).Decode(b)
+		if err != nil {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"mode":"block|mute",
+				"reason":"reason"}`,
+			}
+			s.Logger.Printf("bad put blocked user request")
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			b.Username = username
+			b.BlockedBy = r.Header.Get("authorized_username")
+			b.Reason = s.StrictSanitizer.Sanitize(b.Reason)
+			b, err = s.ChannelService.BlockUser(channelUsername, b)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *b
+				s.Logger.Printf("success blocking user %s on channel %s", username, channelUsername)
+			case channel.ErrInvalidBlockMode:
+				response.Data = jSendFailData{
+					ErrorReason:  "mode",
+					ErrorMessage: "mode must be either block or mute",
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrCantBlockAdmin:
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: "admins of the channel can't be blocked",
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrUserNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: fmt.Sprintf("user of %s not found", username),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("blocking of user failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when blocking user"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteBlockedUser returns a handler for DELETE /channels/{channelUsername}/blocked/{username} requests
+func deleteBlockedUser(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		username := vars["username"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized delete blocked user attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		err := s.ChannelService.UnblockUser(channelUsername, username)
+		switch err {
+		case nil:
+			response.Status = "success"
+			s.Logger.Printf("success unblocking user %s on channel %s", username, channelUsername)
+		case channel.ErrBlockNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "username",
+				ErrorMessage: fmt.Sprintf("user %s isn't blocked on the channel", username),
+			}
+			statusCode = http.StatusNotFound
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("unblocking of user failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when unblocking user"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
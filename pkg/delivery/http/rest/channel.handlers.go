@@ -5,14 +5,15 @@ import (
 	"fmt"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"io"
 
 	// "html"
-	"net/url"
 
 	"os"
 
 	"strconv"
 	"strings"
+	"time"
 
 	"net/http"
 )
@@ -26,6 +27,57 @@ func sanitizeChannel(c *channel.Channel, s *Setup) {
 	// c.Description = html.EscapeString(c.Description)
 }
 
+// isChannelAdmin is a helper function that checks if the given user is one of the admins of the channel
+func isChannelAdmin(s *Setup, channelUsername, username string) bool {
+	if username == "" {
+		return false
+	}
+	c, err := s.ChannelService.GetChannel(channelUsername)
+	if err != nil {
+		return false
+	}
+	for _, admin := range c.AdminUsernames {
+		if admin == username {
+			return true
+		}
+	}
+	return false
+}
+
+// canViewChannel is a helper function that checks if the given user can see the contents of the channel.
+// Channels that can't be found are reported as viewable so that handlers respond with their usual not found errors.
+func canViewChannel(s *Setup, channelUsername, username string) bool {
+	ok, err := s.ChannelService.CanView(channelUsername, username)
+	if err == channel.ErrChannelNotFound {
+		return true
+	}
+	return err == nil && ok
+}
+
+// canContributeToChannel is a helper function that checks if the given user can post into the channel.
+// Anyone can post into a public channel while private channels only accept posts from their admins
+// and contributors.
+func canContributeToChannel(s *Setup, channelUsername, username string) bool {
+	c, err := s.ChannelService.GetChannel(channelUsername)
+	if err != nil || !c.Private {
+		return true
+	}
+	if isChannelAdmin(s, channelUsername, username) {
+		return true
+	}
+	m, err := s.ChannelService.GetMember(channelUsername, username)
+	return err == nil && m.Tier == channel.TierContributor
+}
+
+// hideChannelContents is a helper function that strips the posts and releases off of a private channel
+func hideChannelContents(c *channel.Channel) {
+	c.PostIDs = nil
+	c.StickiedPostIDs = nil
+	c.Pins = nil
+	c.ReleaseIDs = nil
+	c.OfficialReleaseIDs = nil
+}
+
 // getChannel returns a handler for GET /channels/{channelUsername} requests
 func getChannel(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -53,8 +105,16 @@ func getChannel(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 
 				}
 			}
+			if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+				hideChannelContents(c)
+			}
 			if c.PictureURL != "" {
-				c.PictureURL = s.HostAddress + s.ImageServingRoute + url.PathEscape(c.PictureURL)
+				c.PictureVariants = imageVariants(s, c.PictureURL)
+				c.PictureURL = imageURL(s, c.PictureURL)
+			}
+			if c.BannerURL != "" {
+				c.BannerVariants = imageVariants(s, c.BannerURL)
+				c.BannerURL = imageURL(s, c.BannerURL)
 			}
 			response.Data = *c
 			s.Logger.Printf("success fetching channel %s", channelUsername)
@@ -349,11 +409,19 @@ func getChannels(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			} else {
 				response.Status = "success"
 				for _, c := range channels {
+					if !canViewChannel(s, c.ChannelUsername, r.Header.Get("authorized_username")) {
+						hideChannelContents(c)
+					}
 					c.AdminUsernames = nil
 					c.ReleaseIDs = nil
 					c.OwnerUsername = ""
 					if c.PictureURL != "" {
-						c.PictureURL = s.HostAddress + s.ImageServingRoute + url.PathEscape(c.PictureURL)
+						c.PictureVariants = imageVariants(s, c.PictureURL)
+						c.PictureURL = imageURL(s, c.PictureURL)
+					}
+					if c.BannerURL != "" {
+						c.BannerVariants = imageVariants(s, c.BannerURL)
+						c.BannerURL = imageURL(s, c.BannerURL)
 					}
 				}
 				response.Data = channels
@@ -831,7 +899,8 @@ func getCatalog(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// getOfficialCatalog returns a handler for GET /channels/{channelUsername}/official
+// getOfficialCatalog returns a handler for GET /channels/{channelUsername}/official?language=en requests.
+// The catalog can be browsed per language by giving one.
 func getOfficialCatalog(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
@@ -840,17 +909,47 @@ func getOfficialCatalog(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 		statusCode := http.StatusOK
 		vars := getParametersFromRequestAsMap(r)
 		channelUsername := vars["channelUsername"]
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized access attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
+		language := ""
+		if languageRaw := r.URL.Query().Get("language"); languageRaw != "" {
+			var ok bool
+			if language, ok = release.NormalizeLanguage(languageRaw); !ok {
+				s.Logger.Printf("bad get official catalog request, language")
+				response.Data = jSendFailData{
+					ErrorReason:  "language",
+					ErrorMessage: "bad request, language should be a language tag like 'en' or 'pt-BR'",
+				}
+				writeResponseToWriter(response, w, http.StatusBadRequest)
+				return
+			}
+		}
 		c, err := s.ChannelService.GetChannel(channelUsername)
 		switch err {
 		case nil:
 			response.Status = "success"
 			officialCatalog := c.OfficialReleaseIDs
 			releases := make([]interface{}, 0)
+			filter := contentFilterOf(s, r.Header.Get("authorized_username"))
 
 			for _, uID := range officialCatalog {
 				if temp, err := s.ReleaseService.GetRelease(int(uID)); err == nil {
+					if !filter.Allows(temp.Rating, temp.Warnings) {
+						continue
+					}
+					if language != "" && temp.Language != language {
+						continue
+					}
 					releases = append(releases, temp)
-				} else {
+				} else if language == "" {
 					releases = append(releases, int(uID))
 				}
 			}
@@ -1142,6 +1241,16 @@ func getReleaseFromOfficialCatalog(s *Setup) func(w http.ResponseWriter, r *http
 		statusCode := http.StatusOK
 		vars := getParametersFromRequestAsMap(r)
 		channelUsername := vars["channelUsername"]
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized access attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
 
 		ReleaseID, errC := strconv.Atoi(vars["catalogID"])
 		if errC != nil {
@@ -1212,6 +1321,10 @@ func putReleaseInCatalog(d *Setup) func(w http.ResponseWriter, r *http.Request)
 		response.Status = "fail"
 		statusCode := http.StatusOK
 
+		if !limitUploadSize(d, w, r, maxImageUploadSize) {
+			return
+		}
+
 		vars := getParametersFromRequestAsMap(r)
 		idRaw := vars["id"]
 		id, err := strconv.Atoi(idRaw)
@@ -1254,7 +1367,7 @@ func putReleaseInCatalog(d *Setup) func(w http.ResponseWriter, r *http.Request)
 							defer os.Remove(tmpFile.Name())
 							defer tmpFile.Close()
 							d.Logger.Printf(fmt.Sprintf("temp file saved: %s", tmpFile.Name()))
-							rel.Content = generateFileNameForStorage(fileName, "release")
+							rel.Content = fileName
 							rel.Type = release.Image
 						case errUnacceptedType:
 							response.Data = jSendFailData{
@@ -1306,7 +1419,7 @@ func putReleaseInCatalog(d *Setup) func(w http.ResponseWriter, r *http.Request)
 			}
 			if response.Data == nil {
 				// if JSON parsing doesn't fail
-				if rel.Content == "" && rel.Title == "" && rel.GenreDefining == "" && rel.Description == "" && len(rel.Genres) == 0 && len(rel.Authors) == 0 && rel.OwnerChannel == "" {
+				if rel.Content == "" && rel.Title == "" && rel.GenreDefining == "" && rel.Description == "" && len(rel.Genres) == 0 && rel.Credits == nil && rel.Language == "" && rel.TranslationOf == nil && rel.OwnerChannel == "" && rel.Format == "" {
 					response.Data = jSendFailData{
 						ErrorReason:  "request",
 						ErrorMessage: "bad request, data sent doesn't contain update able data",
@@ -1316,11 +1429,11 @@ func putReleaseInCatalog(d *Setup) func(w http.ResponseWriter, r *http.Request)
 				if response.Data == nil {
 					if response.Data == nil {
 						rel.ID = id
-						rel, err = d.ReleaseService.UpdateRelease(rel)
+						rel, err = d.ReleaseService.UpdateRelease(rel, r.Header.Get("authorized_username"))
 						switch err {
 						case nil:
 							if rel.Type == release.Image {
-								err := saveTempFilePermanentlyToPath(tmpFile, d.ImageStoragePath+rel.Content)
+								err := saveImagePermanently(d, tmpFile, rel.Content)
 								if err != nil {
 									d.Logger.Printf("updating of release failed because: %v", err)
 									response.Status = "error"
@@ -1332,7 +1445,7 @@ func putReleaseInCatalog(d *Setup) func(w http.ResponseWriter, r *http.Request)
 							if response.Message == "" {
 								d.Logger.Printf("success updating release %d", id)
 								response.Status = "success"
-								rel.Content = d.HostAddress + d.ImageServingRoute + url.PathEscape(rel.Content)
+								serveRelease(d, rel)
 								response.Data = *rel
 								// TODO delete old image if image updated
 							}
@@ -1350,6 +1463,50 @@ func putReleaseInCatalog(d *Setup) func(w http.ResponseWriter, r *http.Request)
 								ErrorMessage: fmt.Sprintf("release of id %d not found", id),
 							}
 							statusCode = http.StatusNotFound
+						case release.ErrInvalidFormat:
+							d.Logger.Printf("bad update release request, format")
+							response.Data = jSendFailData{
+								ErrorReason:  "format",
+								ErrorMessage: "format can only be 'html' or 'markdown' and only text releases have one",
+							}
+							statusCode = http.StatusBadRequest
+						case release.ErrInvalidCredit:
+							d.Logger.Printf("bad update release request, credits")
+							response.Data = jSendFailData{
+								ErrorReason:  "credits",
+								ErrorMessage: creditsErrorMessage,
+							}
+							statusCode = http.StatusBadRequest
+						case release.ErrCreditedUserNotFound:
+							response.Data = jSendFailData{
+								ErrorReason:  "credits",
+								ErrorMessage: "credited user not found",
+							}
+							statusCode = http.StatusNotFound
+						case release.ErrInvalidGenre:
+							response.Data = jSendFailData{
+								ErrorReason:  "genres",
+								ErrorMessage: genresErrorMessage,
+							}
+							statusCode = http.StatusBadRequest
+						case release.ErrInvalidLanguage:
+							response.Data = jSendFailData{
+								ErrorReason:  "language",
+								ErrorMessage: "language should be a language tag like 'en' or 'pt-BR' and translations need one different from that of the original",
+							}
+							statusCode = http.StatusBadRequest
+						case release.ErrInvalidTranslation:
+							response.Data = jSendFailData{
+								ErrorReason:  "translationOf",
+								ErrorMessage: "translations can only be of other existing releases and releases with translations can't be translations themselves",
+							}
+							statusCode = http.StatusBadRequest
+						case release.ErrTranslatorNotCredited:
+							response.Data = jSendFailData{
+								ErrorReason:  "credits",
+								ErrorMessage: "translations should credit their translator",
+							}
+							statusCode = http.StatusBadRequest
 						case release.ErrSomeReleaseDataNotPersisted:
 							fallthrough
 						default:
@@ -1373,6 +1530,10 @@ func postReleaseInCatalog(s *Setup) func(w http.ResponseWriter, r *http.Request)
 		response.Status = "fail"
 		statusCode := http.StatusCreated
 
+		if !limitUploadSize(s, w, r, maxImageUploadSize) {
+			return
+		}
+
 		newRelease := new(release.Release)
 		var tmpFile *os.File
 		{ // this block parses the JSON part of the request
@@ -1382,7 +1543,7 @@ func postReleaseInCatalog(s *Setup) func(w http.ResponseWriter, r *http.Request)
 				if err != nil {
 					response.Data = jSendFailData{
 						ErrorReason:  "request format",
-						ErrorMessage: "use multipart for for posting Image Releases. A part named 'JSON' with format\r\n{\n  \"ownerChannel\": \"ownerChannel\",\n  \"type\": \"image or text\",\n  \"content\": \"content if type is text\",\n  \"metadata\": {\n    \"title\": \"title\",\n    \"releaseDate\": \"unix timestamp\",\n    \"genreDefining\": \"genreDefining\",\n    \"description\": \"description\",\n    \"Other\": { \"authors\": [], \"genres\": [] }\n  }\n}\nfor Release data and a file called 'image' if release is of image type. We accept JPG/PNG formats.",
+						ErrorMessage: "use multipart for for posting Image Releases. A part named 'JSON' with format\r\n{\n  \"ownerChannel\": \"ownerChannel\",\n  \"translationOf\": { \"originalID\": \"id of the release translated, if a translation\" },\n  \"type\": \"image or text\",\n  \"content\": \"content if type is text\",\n  \"metadata\": {\n    \"title\": \"title\",\n    \"releaseDate\": \"unix timestamp\",\n    \"genreDefining\": \"genreDefining\",\n    \"description\": \"description\",\n    \"language\": \"language tag like en\",\n    \"credits\": [{ \"role\": \"writer\", \"username\": \"username or\", \"name\": \"name\" }],\n    \"Other\": { \"genres\": [] }\n  }\n}\nfor Release data and a file called 'image' if release is of image type. We accept JPG/PNG formats.",
 					}
 					statusCode = http.StatusBadRequest
 				}
@@ -1427,7 +1588,7 @@ func postReleaseInCatalog(s *Setup) func(w http.ResponseWriter, r *http.Request)
 							defer tmpFile.Close()
 							defer os.Remove(tmpFile.Name())
 							s.Logger.Printf(fmt.Sprintf("temp file saved: %s", tmpFile.Name()))
-							newRelease.Content = generateFileNameForStorage(fileName, "release")
+							newRelease.Content = fileName
 						case errUnacceptedType:
 							response.Data = jSendFailData{
 								ErrorMessage: "image",
@@ -1461,7 +1622,7 @@ func postReleaseInCatalog(s *Setup) func(w http.ResponseWriter, r *http.Request)
 					switch err {
 					case nil:
 						if newRelease.Type == release.Image {
-							err := saveTempFilePermanentlyToPath(tmpFile, s.ImageStoragePath+newRelease.Content)
+							err := saveImagePermanently(s, tmpFile, newRelease.Content)
 							if err != nil {
 								s.Logger.Printf("adding of release failed because: %v", err)
 								response.Status = "error"
@@ -1472,10 +1633,54 @@ func postReleaseInCatalog(s *Setup) func(w http.ResponseWriter, r *http.Request)
 						}
 						if response.Message == "" {
 							response.Status = "success"
-							newRelease.Content = s.HostAddress + s.ImageServingRoute + url.PathEscape(newRelease.Content)
+							serveRelease(s, newRelease)
 							response.Data = *newRelease
 							s.Logger.Printf("success adding release %d to channel %s", newRelease.ID, newRelease.OwnerChannel)
 						}
+					case release.ErrInvalidFormat:
+						s.Logger.Printf("bad add release request, format")
+						response.Data = jSendFailData{
+							ErrorReason:  "format",
+							ErrorMessage: "format can only be 'html' or 'markdown' and only text releases have one",
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrInvalidCredit:
+						s.Logger.Printf("bad add release request, credits")
+						response.Data = jSendFailData{
+							ErrorReason:  "credits",
+							ErrorMessage: creditsErrorMessage,
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrCreditedUserNotFound:
+						response.Data = jSendFailData{
+							ErrorReason:  "credits",
+							ErrorMessage: "credited user not found",
+						}
+						statusCode = http.StatusNotFound
+					case release.ErrInvalidGenre:
+						response.Data = jSendFailData{
+							ErrorReason:  "genres",
+							ErrorMessage: genresErrorMessage,
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrInvalidLanguage:
+						response.Data = jSendFailData{
+							ErrorReason:  "language",
+							ErrorMessage: "language should be a language tag like 'en' or 'pt-BR' and translations need one different from that of the original",
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrInvalidTranslation:
+						response.Data = jSendFailData{
+							ErrorReason:  "translationOf",
+							ErrorMessage: "translations can only be of other existing releases and releases with translations can't be translations themselves",
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrTranslatorNotCredited:
+						response.Data = jSendFailData{
+							ErrorReason:  "credits",
+							ErrorMessage: "translations should credit their translator",
+						}
+						statusCode = http.StatusBadRequest
 					case release.ErrSomeReleaseDataNotPersisted:
 						fallthrough
 					default:
@@ -1606,6 +1811,16 @@ func getChannelPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 
 		vars := getParametersFromRequestAsMap(r)
 		channelUsername := vars["channelUsername"]
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized access attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
 		c, err := s.ChannelService.GetChannel(channelUsername)
 		postID, errC := strconv.Atoi(vars["postID"])
 		if errC != nil {
@@ -1670,6 +1885,16 @@ func getChannelPosts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 		statusCode := http.StatusOK
 		vars := getParametersFromRequestAsMap(r)
 		channelUsername := vars["channelUsername"]
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized access attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
 
 		c, err := s.ChannelService.GetChannel(channelUsername)
 		switch err {
@@ -1677,9 +1902,13 @@ func getChannelPosts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			response.Status = "success"
 			postid := c.PostIDs
 			posts := make([]interface{}, 0)
+			filter := contentFilterOf(s, r.Header.Get("authorized_username"))
 
 			for _, pID := range postid {
 				if temp, err := s.PostService.GetPost(pID); err == nil {
+					if !filter.Allows(temp.Rating, temp.Warnings) {
+						continue
+					}
 
 					posts = append(posts, *temp)
 				} else {
@@ -1718,6 +1947,16 @@ func getStickiedPosts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 		statusCode := http.StatusOK
 		vars := getParametersFromRequestAsMap(r)
 		channelUsername := vars["channelUsername"]
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized access attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
 		c, err := s.ChannelService.GetChannel(channelUsername)
 
 		switch err {
@@ -1876,8 +2115,30 @@ func stickyPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			}
 			statusCode = http.StatusBadRequest
 
-		} else {
-			err := s.ChannelService.StickyPost(channelUsername, uint(stickyPost))
+		}
+
+		// the body is optional, pins without a position are placed after the rest
+		pin := struct {
+			Position  *int       `json:"position"`
+			ExpiresAt *time.Time `json:"expiresAt"`
+		}{}
+		if response.Data == nil {
+			if err := json.NewDecoder(r.Body).Decode(&pin); err != nil && err != io.EOF {
+				response.Data = jSendFailData{
+					ErrorReason: "request format",
+					ErrorMessage: `bad request, use format
+				{"position":0,"expiresAt":"RFC3339 time"}`,
+				}
+				statusCode = http.StatusBadRequest
+			}
+		}
+
+		if response.Data == nil {
+			p := &channel.Pin{PostID: uint(stickyPost), Position: -1, ExpiresAt: pin.ExpiresAt}
+			if pin.Position != nil {
+				p.Position = *pin.Position
+			}
+			err := s.ChannelService.StickyPost(channelUsername, p)
 			switch err {
 			case nil:
 				response.Status = "success"
@@ -1909,9 +2170,15 @@ func stickyPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				s.Logger.Printf(fmt.Sprintf("Stickying of post failed because: %s", err.Error()))
 				response.Data = jSendFailData{
 					ErrorReason:  "Stickied postID",
-					ErrorMessage: "stickied post full",
+					ErrorMessage: fmt.Sprintf("stickied post full, a channel can only sticky %d posts", channel.MaxStickiedPosts),
 				}
 				statusCode = http.StatusServiceUnavailable
+			case channel.ErrInvalidPinExpiry:
+				response.Data = jSendFailData{
+					ErrorReason:  "expiresAt",
+					ErrorMessage: "pin expiry has already passed",
+				}
+				statusCode = http.StatusBadRequest
 			default:
 				s.Logger.Printf(fmt.Sprintf("Stickying of post failed because: %s", err.Error()))
 				response.Data = jSendFailData{
@@ -1925,6 +2192,70 @@ func stickyPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// putStickiedPostsOrder returns a handler for PUT /channels/{channelUsername}/stickiedPosts requests
+// that reorder the stickied posts of the channel
+func putStickiedPostsOrder(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized reorder stickied posts attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		order := struct {
+			PostIDs []uint `json:"postIDs"`
+		}{}
+		err := json.NewDecoder(r.Body).Decode(&order)
+		if err != nil {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"postIDs":[1,2]}`,
+			}
+			s.Logger.Printf("bad reorder stickied posts request")
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			err = s.ChannelService.ReorderStickiedPosts(channelUsername, order.PostIDs)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = order.PostIDs
+				s.Logger.Printf("success reordering stickied posts of channel %s", channelUsername)
+			case channel.ErrInvalidPinOrder:
+				response.Data = jSendFailData{
+					ErrorReason:  "postIDs",
+					ErrorMessage: "order must list each of the stickied posts of the channel exactly once",
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("reordering of stickied posts failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when reordering stickied posts"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
 // getChannelPicture returns a handler for GET /channels/{channelUsername}/picture requests
 func getChannelPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -1940,7 +2271,7 @@ func getChannelPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 		switch err {
 		case nil:
 			response.Status = "success"
-			response.Data = s.HostAddress + s.ImageServingRoute + url.PathEscape(c.PictureURL)
+			response.Data = imageURL(s, c.PictureURL)
 			s.Logger.Printf("success fetching channel %s picture URL", channelUsername)
 		case channel.ErrChannelNotFound:
 			s.Logger.Printf("fetch picture URL attempt of non existing channel %s", channelUsername)
@@ -1995,6 +2326,9 @@ func putChannelPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 				}
 			}
 		}
+		if !limitUploadSize(s, w, r, maxImageUploadSize) {
+			return
+		}
 		var tmpFile *os.File
 		var fileName string
 		{ // this block extracts the image
@@ -2005,7 +2339,6 @@ func putChannelPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 				defer os.Remove(tmpFile.Name())
 				defer tmpFile.Close()
 				s.Logger.Printf("temp file saved: %s", tmpFile.Name())
-				fileName = generateFileNameForStorage(fileName, "channel")
 			case errUnacceptedType:
 				response.Data = jSendFailData{
 					ErrorMessage: "image",
@@ -2031,7 +2364,7 @@ func putChannelPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 			s.Logger.Printf(channelUsername)
 			switch err {
 			case nil:
-				err := saveTempFilePermanentlyToPath(tmpFile, s.ImageStoragePath+fileName)
+				err := saveImagePermanently(s, tmpFile, fileName)
 				if err != nil {
 					s.Logger.Printf("adding of picture failed  case nil because: %v", err)
 					response.Status = "error"
@@ -2041,7 +2374,7 @@ func putChannelPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 				} else {
 					s.Logger.Printf("success adding picture %s to channel %s", fileName, channelUsername)
 					response.Status = "success"
-					response.Data = s.HostAddress + s.ImageServingRoute + url.PathEscape(a)
+					response.Data = imageURL(s, a)
 				}
 			case channel.ErrChannelNotFound:
 				s.Logger.Printf("adding of channel picture failed because: %v", err)
@@ -2102,7 +2435,7 @@ func deleteChannelPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 			err = s.ChannelService.RemovePicture(channelUsername)
 			switch err {
 			case nil:
-				// TODO delete picture from fs
+				// the image is collected once nothing else refers to it
 				s.Logger.Printf("success removing piture from channel %s", channelUsername)
 				response.Status = "success"
 			case channel.ErrChannelNotFound:
@@ -2122,3 +2455,318 @@ func deleteChannelPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 		writeResponseToWriter(response, w, statusCode)
 	}
 }
+
+// getChannelStats returns a handler for GET /channels/{channelUsername}/stats?from=2020-01-01&to=2020-02-01&bucket=week&top=10 requests
+func getChannelStats(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized get channel stats attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		var from, to time.Time
+		bucket := channel.Bucket(r.URL.Query().Get("bucket"))
+		top := 10
+		{ // this block reads the query strings if any
+			parseTime := func(raw string) (time.Time, error) {
+				if t, err := time.Parse(time.RFC3339, raw); err == nil {
+					return t, nil
+				}
+				return time.Parse("2006-01-02", raw)
+			}
+			if fromRaw := r.URL.Query().Get("from"); fromRaw != "" {
+				from, err = parseTime(fromRaw)
+				if err != nil {
+					s.Logger.Printf("bad get channel stats request, from")
+					response.Data = jSendFailData{
+						ErrorReason:  "from",
+						ErrorMessage: "bad request, from must be a date (2006-01-02) or an RFC3339 timestamp",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if toRaw := r.URL.Query().Get("to"); toRaw != "" {
+				to, err = parseTime(toRaw)
+				if err != nil {
+					s.Logger.Printf("bad get channel stats request, to")
+					response.Data = jSendFailData{
+						ErrorReason:  "to",
+						ErrorMessage: "bad request, to must be a date (2006-01-02) or an RFC3339 timestamp",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if topRaw := r.URL.Query().Get("top"); topRaw != "" {
+				top, err = strconv.Atoi(topRaw)
+				if err != nil || top < 0 {
+					s.Logger.Printf("bad get channel stats request, top")
+					response.Data = jSendFailData{
+						ErrorReason:  "top",
+						ErrorMessage: "bad request, top can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil {
+			stats, err := s.ChannelService.GetStats(channelUsername, from, to, bucket, top)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *stats
+				s.Logger.Printf("success fetching stats of channel %s", channelUsername)
+			case channel.ErrInvalidBucket:
+				response.Data = jSendFailData{
+					ErrorReason:  "bucket",
+					ErrorMessage: fmt.Sprintf("bucket must be one of %s, %s or %s", channel.BucketDay, channel.BucketWeek, channel.BucketMonth),
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrInvalidStatsRange:
+				response.Data = jSendFailData{
+					ErrorReason:  "from",
+					ErrorMessage: "from must come before to and the range can't span too many buckets",
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of channel stats failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching stats of channel"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getChannelBanner returns a handler for GET /channels/{channelUsername}/banner requests
+func getChannelBanner(s *Setup) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		statusCode := http.StatusOK
+		response.Status = "fail"
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		c, err := s.ChannelService.GetChannel(channelUsername)
+		switch err {
+		case nil:
+			response.Status = "success"
+			if c.BannerURL != "" {
+				response.Data = imageURL(s, c.BannerURL)
+			}
+			s.Logger.Printf("success fetching channel %s banner URL", channelUsername)
+		case channel.ErrChannelNotFound:
+			s.Logger.Printf("fetch banner URL attempt of non existing channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of channelUsername %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of channel banner URL failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching channel banner URL"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putChannelBanner returns a handler for PUT /channels/{channelUsername}/banner requests
+func putChannelBanner(s *Setup) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		statusCode := http.StatusOK
+		response.Status = "fail"
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized put channel banner attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		if !limitUploadSize(s, w, r, maxImageUploadSize) {
+			return
+		}
+		var tmpFile *os.File
+		var fileName string
+		{ // this block extracts the image
+			tmpFile, fileName, err = saveImageFromRequest(r, "image")
+			switch err {
+			case nil:
+				s.Logger.Printf("image found on put channel banner request")
+				defer os.Remove(tmpFile.Name())
+				defer tmpFile.Close()
+			case errUnacceptedType:
+				response.Data = jSendFailData{
+					ErrorReason:  "image",
+					ErrorMessage: "only types image/jpeg & image/png are accepted",
+				}
+				statusCode = http.StatusBadRequest
+			case errReadingFromImage:
+				s.Logger.Printf("image not found on put banner request")
+				response.Data = jSendFailData{
+					ErrorReason:  "image",
+					ErrorMessage: "unable to read image file\nuse multipart-form for for posting channel banners. A form that contains the file under the key 'image', of image type JPG/PNG.",
+				}
+				statusCode = http.StatusBadRequest
+			default:
+				response.Status = "error"
+				response.Message = "server error when adding channel banner"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		// if queries are clean
+		if response.Data == nil && response.Status != "error" {
+			a, err := s.ChannelService.AddBanner(channelUsername, fileName)
+			switch err {
+			case nil:
+				err := saveImagePermanently(s, tmpFile, fileName)
+				if err != nil {
+					s.Logger.Printf("adding of banner failed because: %v", err)
+					response.Status = "error"
+					response.Message = "server error when setting channel banner"
+					statusCode = http.StatusInternalServerError
+					_ = s.ChannelService.RemoveBanner(channelUsername)
+				} else {
+					s.Logger.Printf("success adding banner %s to channel %s", fileName, channelUsername)
+					response.Status = "success"
+					response.Data = imageURL(s, a)
+				}
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of channelUsername %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("setting of banner of channel failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when setting channel banner"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteChannelBanner returns a handler for DELETE /channels/{channelUsername}/banner requests
+func deleteChannelBanner(s *Setup) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		statusCode := http.StatusOK
+		response.Status = "fail"
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized delete channel banner attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		err := s.ChannelService.RemoveBanner(channelUsername)
+		switch err {
+		case nil:
+			s.Logger.Printf("success removing banner from channel %s", channelUsername)
+			response.Status = "success"
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of channelUsername %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("deletion of channel banner failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when removing channel banner"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putChannelTheme returns a handler for PUT /channels/{channelUsername}/theme requests
+func putChannelTheme(s *Setup) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		statusCode := http.StatusOK
+		response.Status = "fail"
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized put channel theme attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		theme := new(channel.Theme)
+		err := json.NewDecoder(r.Body).Decode(theme)
+		if err != nil {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"accentColor":"#rrggbb",
+				"secondaryColor":"#rrggbb"}`,
+			}
+			s.Logger.Printf("bad put channel theme request")
+			statusCode = http.StatusBadRequest
+		}
+		if response.Data == nil {
+			err = s.ChannelService.SetTheme(channelUsername, theme)
+			switch err {
+			case nil:
+				s.Logger.Printf("success setting theme of channel %s", channelUsername)
+				response.Status = "success"
+				response.Data = *theme
+			case channel.ErrInvalidTheme:
+				response.Data = jSendFailData{
+					ErrorReason:  "theme",
+					ErrorMessage: "colors must be hex color codes of the format #rrggbb",
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of channelUsername %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("setting of channel theme failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when setting channel theme"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
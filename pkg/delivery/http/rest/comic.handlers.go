@@ -0,0 +1,370 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+)
+
+// releasePageFailData is a helper function that describes the failures of page operations
+// to clients. It reports false if the error is not one clients are responsible for.
+func releasePageFailData(err error, id int) (jSendFailData, int, bool) {
+	switch err {
+	case release.ErrReleaseNotFound:
+		return jSendFailData{
+			ErrorReason:  "releaseID",
+			ErrorMessage: fmt.Sprintf("release of releaseID %d not found", id),
+		}, http.StatusNotFound, true
+	case release.ErrNotComic:
+		return jSendFailData{
+			ErrorReason:  "type",
+			ErrorMessage: "only comic releases have pages",
+		}, http.StatusBadRequest, true
+	case release.ErrPageNotFound:
+		return jSendFailData{
+			ErrorReason:  "pageNumber",
+			ErrorMessage: "page not found",
+		}, http.StatusNotFound, true
+	case release.ErrTooManyPages:
+		return jSendFailData{
+			ErrorReason:  "pages",
+			ErrorMessage: fmt.Sprintf("comic releases can't have more than %d pages", release.MaxPages),
+		}, http.StatusBadRequest, true
+	case release.ErrInvalidPageOrder:
+		return jSendFailData{
+			ErrorReason:  "order",
+			ErrorMessage: "order must list every page number of the release exactly once",
+		}, http.StatusBadRequest, true
+	case release.ErrInvalidReleaseData:
+		return jSendFailData{
+			ErrorReason:  "pages",
+			ErrorMessage: "comic releases must keep at least a single page",
+		}, http.StatusBadRequest, true
+	}
+	return jSendFailData{}, 0, false
+}
+
+// parseReleasePageParams is a helper function that reads the release id and, if asked for,
+// the page number of the request.
+func parseReleasePageParams(vars map[string]string, withPage bool) (int, int, *jSendFailData) {
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return 0, 0, &jSendFailData{
+			ErrorReason:  "releaseID",
+			ErrorMessage: fmt.Sprintf("invalid releaseID %s", vars["id"]),
+		}
+	}
+	if !withPage {
+		return id, 0, nil
+	}
+	number, err := strconv.Atoi(vars["pageNumber"])
+	if err != nil {
+		return 0, 0, &jSendFailData{
+			ErrorReason:  "pageNumber",
+			ErrorMessage: fmt.Sprintf("invalid pageNumber %s", vars["pageNumber"]),
+		}
+	}
+	return id, number, nil
+}
+
+// postReleasePages returns a handler for POST /releases/{id}/pages requests appending the
+// images of the multipart field 'pages' to a comic release. A part named 'JSON' of format
+// {"altTexts": ["..."]} can hold the alt texts of the pages in order.
+func postReleasePages(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		id, _, failData := parseReleasePageParams(vars, false)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditRelease(s, id, r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized post release pages attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			if !limitUploadSize(s, w, r, maxReleaseUploadSize) {
+				return
+			}
+			tmpFiles, fileNames, err := saveImagesFromRequest(r, "pages")
+			switch err {
+			case nil:
+				defer removeTempFiles(tmpFiles)
+			case errUnacceptedType:
+				response.Data = jSendFailData{
+					ErrorReason:  "image-type",
+					ErrorMessage: "only types image/jpeg & image/png are accepted",
+				}
+				statusCode = http.StatusBadRequest
+			case errReadingFromImage:
+				response.Data = jSendFailData{
+					ErrorReason:  "pages",
+					ErrorMessage: "unable to read page files\nuse multipart-form with files called 'pages' of image type JPG/PNG\nand an optional part named 'JSON' of format {\"altTexts\": []}",
+				}
+				statusCode = http.StatusBadRequest
+			default:
+				s.Logger.Printf("adding of release pages failed during image parsing because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when adding release pages"
+				statusCode = http.StatusInternalServerError
+			}
+			if response.Data == nil && response.Message == "" {
+				requestData := struct {
+					AltTexts []string `json:"altTexts"`
+				}{}
+				if raw := r.PostFormValue("JSON"); raw != "" {
+					if err := json.Unmarshal([]byte(raw), &requestData); err != nil {
+						response.Data = jSendFailData{
+							ErrorReason:  "request format",
+							ErrorMessage: `bad request, use format {"altTexts": ["..."]}`,
+						}
+						statusCode = http.StatusBadRequest
+					}
+				}
+				if response.Data == nil {
+					pages := make([]*release.Page, len(fileNames))
+					for i, fileName := range fileNames {
+						pages[i] = &release.Page{Image: fileName}
+						if i < len(requestData.AltTexts) {
+							pages[i].AltText = s.StrictSanitizer.Sanitize(requestData.AltTexts[i])
+						}
+						if err = saveImagePermanently(s, tmpFiles[i], pages[i].Image); err != nil {
+							break
+						}
+					}
+					var rel *release.Release
+					if err == nil {
+						rel, err = s.ReleaseService.AddPages(id, pages)
+					}
+					if err == nil {
+						response.Status = "success"
+						serveRelease(s, rel)
+						response.Data = *rel
+						s.Logger.Printf("success adding %d pages to release %d", len(pages), id)
+					} else if data, code, ok := releasePageFailData(err, id); ok {
+						response.Data = data
+						statusCode = code
+					} else {
+						s.Logger.Printf("adding of release pages failed because: %v", err)
+						response.Status = "error"
+						response.Message = "server error when adding release pages"
+						statusCode = http.StatusInternalServerError
+					}
+				}
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putReleasePagesOrder returns a handler for PUT /releases/{id}/pages requests
+// rearranging the pages of a comic release.
+// Body is of format {"order": [3, 1, 2]} listing the current page numbers in their new order.
+func putReleasePagesOrder(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		id, _, failData := parseReleasePageParams(vars, false)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditRelease(s, id, r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized reorder release pages attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			requestData := struct {
+				Order []int `json:"order"`
+			}{}
+			if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+				response.Data = jSendFailData{
+					ErrorReason:  "request format",
+					ErrorMessage: `bad request, use format {"order": [3, 1, 2]}`,
+				}
+				statusCode = http.StatusBadRequest
+			}
+			if response.Data == nil {
+				rel, err := s.ReleaseService.ReorderPages(id, requestData.Order)
+				if err == nil {
+					response.Status = "success"
+					serveRelease(s, rel)
+					response.Data = *rel
+					s.Logger.Printf("success reordering pages of release %d", id)
+				} else if data, code, ok := releasePageFailData(err, id); ok {
+					response.Data = data
+					statusCode = code
+				} else {
+					s.Logger.Printf("reordering of release pages failed because: %v", err)
+					response.Status = "error"
+					response.Message = "server error when reordering release pages"
+					statusCode = http.StatusInternalServerError
+				}
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putReleasePage returns a handler for PUT /releases/{id}/pages/{pageNumber} requests
+// replacing the image, sent as a multipart file called 'image', and, or, the alt text,
+// sent as a part named 'JSON' or as the body of format {"altText": "..."}, of a page.
+func putReleasePage(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		id, number, failData := parseReleasePageParams(vars, true)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditRelease(s, id, r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized put release page attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			if !limitUploadSize(s, w, r, maxReleaseUploadSize) {
+				return
+			}
+			page := &release.Page{Number: number}
+			{ // this block parses the request
+				requestData := struct {
+					AltText string `json:"altText"`
+				}{}
+				err := json.Unmarshal([]byte(r.PostFormValue("JSON")), &requestData)
+				if err != nil && r.MultipartForm == nil {
+					err = json.NewDecoder(r.Body).Decode(&requestData)
+					if err != nil {
+						response.Data = jSendFailData{
+							ErrorReason:  "request format",
+							ErrorMessage: "use multipart-form with a file called 'image' of image type JPG/PNG\nand, or, a part named 'JSON' of format {\"altText\": \"...\"}",
+						}
+						statusCode = http.StatusBadRequest
+					}
+				}
+				page.AltText = s.StrictSanitizer.Sanitize(requestData.AltText)
+			}
+			var tmpFile *os.File
+			if response.Data == nil && r.MultipartForm != nil && len(r.MultipartForm.File["image"]) > 0 {
+				var fileName string
+				var err error
+				tmpFile, fileName, err = saveImageFromRequest(r, "image")
+				switch err {
+				case nil:
+					defer tmpFile.Close()
+					defer os.Remove(tmpFile.Name())
+					page.Image = fileName
+				case errUnacceptedType:
+					response.Data = jSendFailData{
+						ErrorReason:  "image-type",
+						ErrorMessage: "only types image/jpeg & image/png are accepted",
+					}
+					statusCode = http.StatusBadRequest
+				default:
+					response.Data = jSendFailData{
+						ErrorReason:  "image",
+						ErrorMessage: "unable to read image file",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if response.Data == nil {
+				var err error
+				if tmpFile != nil {
+					err = saveImagePermanently(s, tmpFile, page.Image)
+				}
+				var rel *release.Release
+				if err == nil {
+					rel, err = s.ReleaseService.UpdatePage(id, page)
+				}
+				if err == nil {
+					response.Status = "success"
+					serveRelease(s, rel)
+					response.Data = *rel
+					s.Logger.Printf("success updating page %d of release %d", number, id)
+				} else if data, code, ok := releasePageFailData(err, id); ok {
+					response.Data = data
+					statusCode = code
+				} else {
+					s.Logger.Printf("updating of release page failed because: %v", err)
+					response.Status = "error"
+					response.Message = "server error when updating release page"
+					statusCode = http.StatusInternalServerError
+				}
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteReleasePage returns a handler for DELETE /releases/{id}/pages/{pageNumber} requests
+func deleteReleasePage(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		id, number, failData := parseReleasePageParams(vars, true)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditRelease(s, id, r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized delete release page attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			rel, err := s.ReleaseService.DeletePage(id, number)
+			if err == nil {
+				response.Status = "success"
+				serveRelease(s, rel)
+				response.Data = *rel
+				s.Logger.Printf("success deleting page %d of release %d", number, id)
+			} else if data, code, ok := releasePageFailData(err, id); ok {
+				response.Data = data
+				statusCode = code
+			} else {
+				s.Logger.Printf("deletion of release page failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when deleting release page"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
@@ -3,7 +3,9 @@ package rest
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/comment"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/mention"
 
 	// "html"
 	"net/http"
@@ -93,6 +95,18 @@ func postComment(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 						w.WriteHeader(http.StatusUnauthorized)
 						return
 					}
+					if !canViewPost(s, uint(c.OriginPost), c.Commenter) {
+						s.Logger.Printf("post Comment request on private channel post")
+						addCors(w)
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
+					if postBlockMode(s, uint(c.OriginPost), c.Commenter) == channel.BlockModeBlock {
+						s.Logger.Printf("post Comment request by blocked user")
+						addCors(w)
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
 				}
 				sanitizeComment(c, s)
 				c.Commenter = r.Header.Get("authorized_username")
@@ -109,6 +123,7 @@ func postComment(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					switch err {
 					case nil:
 						response.Status = "success"
+						c.Mentions = setMentions(s, mention.SourceComment, c.ID, c.Content)
 						response.Data = *c
 						s.Logger.Printf("success adding comment %v", c)
 					case comment.ErrPostNotFound:
@@ -176,9 +191,17 @@ func getComment(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 
 		if response.Data == nil {
 			c, err := s.CommentService.GetComment(id)
+			if err == nil && !canViewPost(s, uint(c.OriginPost), r.Header.Get("authorized_username")) {
+				err = comment.ErrCommentNotFound
+			}
+			if err == nil && c.Commenter != r.Header.Get("authorized_username") &&
+				postBlockMode(s, uint(c.OriginPost), c.Commenter) == channel.BlockModeMute {
+				err = comment.ErrCommentNotFound
+			}
 			switch err {
 			case nil:
 				response.Status = "success"
+				c.Mentions = getMentions(s, mention.SourceComment, c.ID)
 				response.Data = *c
 				s.Logger.Printf("success fetching comment %d", id)
 			case comment.ErrCommentNotFound:
@@ -218,6 +241,13 @@ func getComments(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				ErrorMessage: fmt.Sprintf("invalid post id %s", postIDRaw),
 			}
 			statusCode = http.StatusBadRequest
+		} else if !canViewPost(s, uint(postID), r.Header.Get("authorized_username")) {
+			// comments of posts from private channels are only visible to members
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: "post not found",
+			}
+			statusCode = http.StatusNotFound
 		}
 		if response.Data == nil {
 			limit := 25
@@ -248,10 +278,13 @@ func getComments(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			}
 
 			if response.Data == nil {
-				c, err := s.CommentService.GetComments(postID, comment.SortByCreationTime, comment.SortDescending, limit, offset)
+				c, err := s.CommentService.GetComments(postID, comment.SortByCreationTime, comment.SortDescending, limit, offset, r.Header.Get("authorized_username"))
 				switch err {
 				case nil:
 					response.Status = "success"
+					for _, cmt := range c {
+						cmt.Mentions = getMentions(s, mention.SourceComment, cmt.ID)
+					}
 					response.Data = c
 					s.Logger.Printf("success fetching comments for post %d", postID)
 				case comment.ErrPostNotFound:
@@ -292,6 +325,14 @@ func getCommentReplies(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				ErrorMessage: fmt.Sprintf("invalid commentID %s", rootCommentIDRaw),
 			}
 			statusCode = http.StatusBadRequest
+		} else if root, err := s.CommentService.GetComment(commentID); err == nil &&
+			!canViewPost(s, uint(root.OriginPost), r.Header.Get("authorized_username")) {
+			// replies of posts from private channels are only visible to members
+			response.Data = jSendFailData{
+				ErrorReason:  "commentID",
+				ErrorMessage: "comment not found",
+			}
+			statusCode = http.StatusNotFound
 		}
 		if response.Data == nil {
 			limit := 25
@@ -321,10 +362,13 @@ func getCommentReplies(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 			if response.Data == nil {
-				c, err := s.CommentService.GetReplies(commentID, comment.SortByCreationTime, comment.SortDescending, limit, offset)
+				c, err := s.CommentService.GetReplies(commentID, comment.SortByCreationTime, comment.SortDescending, limit, offset, r.Header.Get("authorized_username"))
 				switch err {
 				case nil:
 					response.Status = "success"
+					for _, cmt := range c {
+						cmt.Mentions = getMentions(s, mention.SourceComment, cmt.ID)
+					}
 					response.Data = c
 					s.Logger.Printf("success fetching replies for post %d", commentID)
 				case comment.ErrCommentNotFound:
@@ -417,6 +461,7 @@ func patchComment(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					case nil:
 						s.Logger.Printf("success patch comment at id %d", id)
 						response.Status = "success"
+						c.Mentions = getMentions(s, mention.SourceComment, c.ID)
 						response.Data = *c
 					default:
 						s.Logger.Printf("patching of comment failed because: %v", err)
@@ -430,6 +475,7 @@ func patchComment(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					switch err {
 					case nil:
 						response.Status = "success"
+						c.Mentions = setMentions(s, mention.SourceComment, c.ID, c.Content)
 						response.Data = *c
 						s.Logger.Printf("success patching comment %v", c)
 					case comment.ErrCommentNotFound:
@@ -500,6 +546,9 @@ func deleteComment(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			statusCode = http.StatusInternalServerError
 		} else {
 			response.Status = "success"
+			if err := s.MentionService.DeleteMentions(mention.SourceComment, id); err != nil {
+				s.Logger.Printf("deletion of mentions of comment %d failed because: %v", id, err)
+			}
 			s.Logger.Printf("success deleting comment %d", id)
 		}
 		writeResponseToWriter(response, w, statusCode)
@@ -0,0 +1,364 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+)
+
+// canCrosspost is a helper function that checks if the given user can request crossposts of the post.
+// Posts of private channels can only be crossposted by the admins of those since crossposts
+// are listed to anyone that can view the channels they're crossposted to.
+func canCrosspost(s *Setup, postID uint, username string) bool {
+	if !canEditPost(s, postID, username) {
+		return false
+	}
+	p, err := s.PostService.GetPost(postID)
+	if err != nil {
+		return true
+	}
+	c, err := s.ChannelService.GetChannel(p.OriginChannel)
+	if err == channel.ErrChannelNotFound || (err == nil && !c.Private) {
+		return true
+	}
+	return isChannelAdmin(s, p.OriginChannel, username)
+}
+
+// getPostCrossposts returns a handler for GET /posts/{postID}/crossposts requests
+// Pending crossposts are only listed to those that can edit the post.
+func getPostCrossposts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("fetch crossposts attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			username := r.Header.Get("authorized_username")
+			var crossposts []*channel.Crosspost
+			if _, err = s.PostService.GetPost(uint(id)); err == nil {
+				if !canViewPost(s, uint(id), username) {
+					err = post.ErrPostNotFound
+				} else {
+					crossposts, err = s.ChannelService.GetPostCrossposts(uint(id))
+				}
+			}
+			switch err {
+			case nil:
+				if !canEditPost(s, uint(id), username) {
+					accepted := make([]*channel.Crosspost, 0, len(crossposts))
+					for _, x := range crossposts {
+						if x.Accepted {
+							x.RequestedBy = ""
+							accepted = append(accepted, x)
+						}
+					}
+					crossposts = accepted
+				}
+				response.Status = "success"
+				response.Data = crossposts
+				s.Logger.Printf("success fetching crossposts of post %d", id)
+			case post.ErrPostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("post of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of post crossposts failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching post crossposts"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postPostCrosspost returns a handler for POST /posts/{postID}/crossposts requests
+// The crosspost is listed in the channel once one of its admins accepts it, right
+// away if the requesting user is one. Posts of private channels can only be crossposted
+// by their admins.
+func postPostCrosspost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("crosspost attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		x := new(channel.Crosspost)
+		if response.Data == nil {
+			err = json.NewDecoder(r.Body).Decode(x)
+			if err != nil || x.ChannelUsername == "" {
+				response.Data = jSendFailData{
+					ErrorReason: "request format",
+					ErrorMessage: `bad request, use format
+				{"channelUsername":"channel to crosspost to"}`,
+				}
+				s.Logger.Printf("bad post crosspost request")
+				statusCode = http.StatusBadRequest
+			}
+		}
+
+		if response.Data == nil {
+			x.RequestedBy = r.Header.Get("authorized_username")
+			{ // this block secures the route
+				if !canCrosspost(s, uint(id), x.RequestedBy) {
+					s.Logger.Printf("unauthorized crosspost attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			var p *post.Post
+			p, err = s.PostService.GetPost(uint(id))
+			if err == nil {
+				x.PostID, x.OriginChannel = p.ID, p.OriginChannel
+				if p.Status != post.StatusPublished {
+					err = post.ErrPostNotFound
+				} else {
+					x, err = s.ChannelService.RequestCrosspost(x.ChannelUsername, x)
+				}
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *x
+				s.Logger.Printf("success crossposting post %d to channel %s", id, x.ChannelUsername)
+			case post.ErrPostNotFound, channel.ErrPostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("published post of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", x.ChannelUsername),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrCrosspostToOrigin:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: "post can't be crossposted to the channel it was posted in",
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrCrosspostAlreadyExists:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("post is already crossposted to %s", x.ChannelUsername),
+				}
+				statusCode = http.StatusConflict
+			default:
+				s.Logger.Printf("crossposting of post failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when crossposting post"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getChannelCrossposts returns a handler for GET /channels/{channelUsername}/crossposts?status=pending requests
+// status can be either pending or accepted, defaulting to pending.
+func getChannelCrossposts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized get crossposts attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		accepted := false
+		switch r.URL.Query().Get("status") {
+		case "", "pending":
+		case "accepted":
+			accepted = true
+		default:
+			response.Data = jSendFailData{
+				ErrorReason:  "status",
+				ErrorMessage: "bad request, status can be either pending or accepted",
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			crossposts, err := s.ChannelService.GetCrossposts(channelUsername, accepted)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = crossposts
+				s.Logger.Printf("success fetching crossposts of channel %s", channelUsername)
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of crossposts failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching crossposts of channel"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putChannelCrosspost returns a handler for PUT /channels/{channelUsername}/crossposts/{postID} requests
+// that accept pending crossposts
+func putChannelCrosspost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized accept crosspost attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("accept crosspost attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			err = s.ChannelService.AcceptCrosspost(channelUsername, uint(id))
+			switch err {
+			case nil:
+				response.Status = "success"
+				s.Logger.Printf("success accepting crosspost of post %d to channel %s", id, channelUsername)
+			case channel.ErrCrosspostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("crosspost of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("accepting of crosspost failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when accepting crosspost"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteChannelCrosspost returns a handler for DELETE /channels/{channelUsername}/crossposts/{postID} requests
+// Admins of the channel use it to reject or remove crossposts while those that can edit
+// the post use it to withdraw them.
+func deleteChannelCrosspost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("remove crosspost attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				username := r.Header.Get("authorized_username")
+				if !isChannelAdmin(s, channelUsername, username) && !canEditPost(s, uint(id), username) {
+					s.Logger.Printf("unauthorized remove crosspost attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			err = s.ChannelService.RemoveCrosspost(channelUsername, uint(id))
+			switch err {
+			case nil:
+				response.Status = "success"
+				s.Logger.Printf("success removing crosspost of post %d from channel %s", id, channelUsername)
+			case channel.ErrCrosspostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("crosspost of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("removing of crosspost failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when removing crosspost"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
@@ -0,0 +1,437 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+)
+
+// getChannelDrafts returns a handler for GET /channels/{channelUsername}/drafts?status=in-review&limit=25&offset=0 requests
+func getChannelDrafts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized get channel drafts attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		limit := 25
+		offset := 0
+		status := post.Status(r.URL.Query().Get("status"))
+		{ // this block reads the query strings if any
+			if limitPageRaw := r.URL.Query().Get("limit"); limitPageRaw != "" {
+				limit, err = strconv.Atoi(limitPageRaw)
+				if err != nil || limit < 0 {
+					s.Logger.Printf("bad get drafts request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					s.Logger.Printf("bad get drafts request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+		// if queries are clean
+		if response.Data == nil {
+			drafts, err := s.PostService.GetChannelDrafts(channelUsername, status, limit, offset)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = drafts
+				s.Logger.Printf("success fetching drafts of channel %s", channelUsername)
+			case post.ErrInvalidStatus:
+				s.Logger.Printf("bad get drafts request, status")
+				response.Data = jSendFailData{
+					ErrorReason:  "status",
+					ErrorMessage: fmt.Sprintf("bad request, status must be one of %s, %s or %s", post.StatusDraft, post.StatusInReview, post.StatusApproved),
+				}
+				statusCode = http.StatusBadRequest
+			default:
+				s.Logger.Printf("fetching of drafts failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching drafts"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getChannelDraft returns a handler for GET /channels/{channelUsername}/drafts/{postID} requests
+func getChannelDraft(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		postID, err := strconv.Atoi(vars["postID"])
+		if err != nil {
+			s.Logger.Printf("bad get draft request, postID")
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: "bad request, postID must be an integer",
+			}
+			statusCode = http.StatusBadRequest
+		}
+		if response.Data == nil {
+			p, err := s.PostService.GetPost(uint(postID))
+			if err == nil && (p.OriginChannel != channelUsername || p.Status == post.StatusPublished) {
+				err = post.ErrPostNotFound
+			}
+			switch err {
+			case nil:
+				{ // this block secures the route
+					username := r.Header.Get("authorized_username")
+					if p.PostedByUsername != username && !isChannelAdmin(s, channelUsername, username) {
+						s.Logger.Printf("unauthorized get draft attempt")
+						addCors(w)
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+				}
+				reviews, err := s.PostService.GetDraftReviews(p.ID)
+				if err != nil {
+					s.Logger.Printf("fetching of draft reviews failed because: %v", err)
+					response.Status = "error"
+					response.Message = "server error when fetching draft"
+					statusCode = http.StatusInternalServerError
+					break
+				}
+				response.Status = "success"
+				response.Data = struct {
+					post.Post
+					Reviews []*post.Review `json:"reviews"`
+				}{*p, reviews}
+				s.Logger.Printf("success fetching draft %d of channel %s", postID, channelUsername)
+			case post.ErrPostNotFound:
+				s.Logger.Printf("fetch attempt of non existing draft %d", postID)
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("draft of postID %d not found", postID),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of draft failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching draft"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postDraftSubmission returns a handler for POST /channels/{channelUsername}/drafts/{postID}/submit requests
+func postDraftSubmission(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		postID, err := strconv.Atoi(vars["postID"])
+		if err != nil {
+			s.Logger.Printf("bad draft submission request, postID")
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: "bad request, postID must be an integer",
+			}
+			statusCode = http.StatusBadRequest
+		}
+		if response.Data == nil {
+			p, err := s.PostService.GetPost(uint(postID))
+			if err == nil && p.OriginChannel != channelUsername {
+				err = post.ErrPostNotFound
+			}
+			if err == nil {
+				{ // this block secures the route
+					if p.PostedByUsername != r.Header.Get("authorized_username") {
+						s.Logger.Printf("unauthorized draft submission attempt")
+						addCors(w)
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+				}
+				p, err = s.PostService.SubmitDraft(p.ID)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *p
+				s.Logger.Printf("success submitting draft %d for review", postID)
+			case post.ErrPostNotFound:
+				s.Logger.Printf("submission attempt of non existing draft %d", postID)
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("draft of postID %d not found", postID),
+				}
+				statusCode = http.StatusNotFound
+			case post.ErrInvalidStatusTransition:
+				s.Logger.Printf("submission of draft failed because: %v", err)
+				response.Data = jSendFailData{
+					ErrorReason:  "status",
+					ErrorMessage: "only posts in draft stage can be submitted for review",
+				}
+				statusCode = http.StatusConflict
+			default:
+				s.Logger.Printf("submission of draft failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when submitting draft"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getDraftReviews returns a handler for GET /channels/{channelUsername}/drafts/{postID}/reviews requests
+func getDraftReviews(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		postID, err := strconv.Atoi(vars["postID"])
+		if err != nil {
+			s.Logger.Printf("bad get draft reviews request, postID")
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: "bad request, postID must be an integer",
+			}
+			statusCode = http.StatusBadRequest
+		}
+		if response.Data == nil {
+			p, err := s.PostService.GetPost(uint(postID))
+			if err == nil && p.OriginChannel != channelUsername {
+				err = post.ErrPostNotFound
+			}
+			if err == nil {
+				{ // this block secures the route
+					username := r.Header.Get("authorized_username")
+					if p.PostedByUsername != username && !isChannelAdmin(s, channelUsername, username) {
+						s.Logger.Printf("unauthorized get draft reviews attempt")
+						addCors(w)
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+				}
+			}
+			var reviews []*post.Review
+			if err == nil {
+				reviews, err = s.PostService.GetDraftReviews(p.ID)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = reviews
+				s.Logger.Printf("success fetching reviews of draft %d", postID)
+			case post.ErrPostNotFound:
+				s.Logger.Printf("fetch reviews attempt of non existing draft %d", postID)
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("draft of postID %d not found", postID),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of draft reviews failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching draft reviews"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postDraftReview returns a handler for POST /channels/{channelUsername}/drafts/{postID}/reviews requests
+func postDraftReview(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized post draft review attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		postID, err := strconv.Atoi(vars["postID"])
+		if err != nil {
+			s.Logger.Printf("bad post draft review request, postID")
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: "bad request, postID must be an integer",
+			}
+			statusCode = http.StatusBadRequest
+		}
+		review := new(post.Review)
+		if response.Data == nil {
+			{ // checks if requests uses forms or JSON and parses then
+				review.Content = r.FormValue("content")
+				if review.Content != "" {
+					review.Verdict = post.Verdict(r.FormValue("verdict"))
+				} else {
+					err := json.NewDecoder(r.Body).Decode(review)
+					if err != nil {
+						response.Data = jSendFailData{
+							ErrorReason: "request format",
+							ErrorMessage: `bad request, use format
+				{"content":"remarks on the draft",
+				"verdict":"approve|reject, optional"}`,
+						}
+						s.Logger.Printf("bad post draft review request")
+						statusCode = http.StatusBadRequest
+					}
+				}
+			}
+		}
+		if response.Data == nil {
+			review.Reviewer = r.Header.Get("authorized_username")
+			review.Content = s.StrictSanitizer.Sanitize(review.Content)
+			if review.Content == "" && review.Verdict == post.VerdictNone {
+				response.Data = jSendFailData{
+					ErrorReason:  "content",
+					ErrorMessage: "a review needs either content or a verdict",
+				}
+				statusCode = http.StatusBadRequest
+			}
+		}
+		if response.Data == nil {
+			p, err := s.PostService.GetPost(uint(postID))
+			if err == nil && p.OriginChannel != channelUsername {
+				err = post.ErrPostNotFound
+			}
+			if err == nil {
+				review, err = s.PostService.ReviewDraft(uint(postID), review)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *review
+				s.Logger.Printf("success reviewing draft %d by %s", postID, review.Reviewer)
+			case post.ErrPostNotFound, post.ErrPostNotDraft:
+				s.Logger.Printf("review attempt of non existing draft %d", postID)
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("draft of postID %d not found", postID),
+				}
+				statusCode = http.StatusNotFound
+			case post.ErrInvalidStatusTransition:
+				s.Logger.Printf("reviewing of draft failed because: %v", err)
+				response.Data = jSendFailData{
+					ErrorReason:  "verdict",
+					ErrorMessage: "only drafts in review can be approved and only drafts in review or approved can be rejected",
+				}
+				statusCode = http.StatusConflict
+			default:
+				s.Logger.Printf("reviewing of draft failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when reviewing draft"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postDraftPublication returns a handler for POST /channels/{channelUsername}/drafts/{postID}/publish requests.
+// Only admins of the channel can publish drafts, their writers included.
+func postDraftPublication(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		postID, err := strconv.Atoi(vars["postID"])
+		if err != nil {
+			s.Logger.Printf("bad draft publication request, postID")
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: "bad request, postID must be an integer",
+			}
+			statusCode = http.StatusBadRequest
+		}
+		if response.Data == nil {
+			p, err := s.PostService.GetPost(uint(postID))
+			if err == nil && p.OriginChannel != channelUsername {
+				err = post.ErrPostNotFound
+			}
+			if err == nil {
+				{ // this block secures the route
+					username := r.Header.Get("authorized_username")
+					if !isChannelAdmin(s, channelUsername, username) {
+						s.Logger.Printf("unauthorized draft publication attempt")
+						addCors(w)
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+				}
+				p, err = s.PostService.PublishPost(p.ID)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *p
+				s.Logger.Printf("success publishing draft %d on channel %s", postID, channelUsername)
+			case post.ErrPostNotFound:
+				s.Logger.Printf("publication attempt of non existing draft %d", postID)
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("draft of postID %d not found", postID),
+				}
+				statusCode = http.StatusNotFound
+			case post.ErrInvalidStatusTransition:
+				s.Logger.Printf("publication of draft failed because: %v", err)
+				response.Data = jSendFailData{
+					ErrorReason:  "status",
+					ErrorMessage: "only approved drafts can be published",
+				}
+				statusCode = http.StatusConflict
+			default:
+				s.Logger.Printf("publication of draft failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when publishing draft"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
@@ -0,0 +1,196 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/epub"
+)
+
+// postChannelEPUB returns a handler for POST /channels/{channelUsername}/epub requests.
+// It splits the uploaded book into text releases, one per chapter, in the catalog of the
+// channel with the metadata of the book. Either all the chapters are added or none are.
+func postChannelEPUB(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusCreated
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized post channel epub attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		if !limitUploadSize(s, w, r, maxEPUBUploadSize) {
+			return
+		}
+
+		var book *epub.Book
+		{ // this block reads the book from the request
+			file, header, err := r.FormFile("epub")
+			if err != nil {
+				response.Data = jSendFailData{
+					ErrorReason:  "epub",
+					ErrorMessage: "unable to read epub file\nuse multipart-form with a file called 'epub' and an optional part named 'JSON' of format\n{\n  \"rating\": \"rating\",\n  \"warnings\": []\n}\nfor the maturity rating of the chapters.",
+				}
+				statusCode = http.StatusBadRequest
+			} else {
+				defer file.Close()
+				book, err = epub.Parse(file, header.Size)
+				switch err {
+				case nil:
+					if len(book.Chapters) == 0 {
+						response.Data = jSendFailData{
+							ErrorReason:  "epub",
+							ErrorMessage: "book has no chapters",
+						}
+						statusCode = http.StatusBadRequest
+					}
+				case epub.ErrInvalidBook:
+					response.Data = jSendFailData{
+						ErrorReason:  "epub",
+						ErrorMessage: "file isn't a readable epub",
+					}
+					statusCode = http.StatusBadRequest
+				case epub.ErrTooLarge:
+					response.Data = jSendFailData{
+						ErrorReason:  "epub",
+						ErrorMessage: fmt.Sprintf("books can have up to %d chapters of up to %d bytes each and %d bytes in all", epub.MaxChapters, epub.MaxDocumentSize, epub.MaxBookSize),
+					}
+					statusCode = http.StatusRequestEntityTooLarge
+				default:
+					s.Logger.Printf("parsing of epub failed because: %v", err)
+					response.Status = "error"
+					response.Message = "server error when importing epub"
+					statusCode = http.StatusInternalServerError
+				}
+			}
+		}
+
+		var meta release.Metadata
+		if response.Data == nil && response.Message == "" {
+			if raw := r.PostFormValue("JSON"); raw != "" {
+				err := json.Unmarshal([]byte(raw), &meta)
+				if err != nil {
+					response.Data = jSendFailData{
+						ErrorReason:  "request format",
+						ErrorMessage: "bad request, the 'JSON' part should be of format\n{\n  \"rating\": \"rating\",\n  \"warnings\": [],\n  \"language\": \"language tag like en, that of the book by default\",\n  \"credits\": [{ \"role\": \"writer\", \"username\": \"username or\", \"name\": \"name\" }]\n}",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil && response.Message == "" {
+			releases := make([]*release.Release, 0, len(book.Chapters))
+			bookTitle := s.StrictSanitizer.Sanitize(book.Title)
+			genreDefining := ""
+			if len(book.Subjects) > 0 {
+				genreDefining = book.Subjects[0]
+			}
+			language := meta.Language
+			if language == "" {
+				// books with malformed language tags are imported without one
+				language, _ = release.NormalizeLanguage(book.Language)
+			}
+			credits := meta.Credits
+			if credits == nil {
+				// the authors of the book are credited by name unless credits are given
+				for _, author := range book.Authors {
+					credits = append(credits, &release.Credit{Role: release.RoleWriter, Name: author})
+				}
+			}
+			var err error
+			for _, chapter := range book.Chapters {
+				rel := &release.Release{
+					OwnerChannel: channelUsername,
+					Type:         release.Text,
+					Format:       release.FormatHTML,
+					Content:      s.MarkupSanitizer.Sanitize(chapter.HTML),
+					Metadata: release.Metadata{
+						Title:         s.StrictSanitizer.Sanitize(chapter.Title),
+						ReleaseDate:   book.Date,
+						GenreDefining: genreDefining,
+						Description:   s.StrictSanitizer.Sanitize(book.Description),
+						Rating:        meta.Rating,
+						Warnings:      meta.Warnings,
+						Language:      language,
+						Credits:       credits,
+						Other: release.Other{
+							Genres:  book.Subjects,
+							Book:    bookTitle,
+							Chapter: len(releases) + 1,
+						},
+					},
+				}
+				if rel.Content == "" {
+					// nothing of the chapter survived sanitization
+					continue
+				}
+				rel, err = s.ReleaseService.AddRelease(rel)
+				if err != nil {
+					break
+				}
+				releases = append(releases, rel)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				for _, rel := range releases {
+					serveRelease(s, rel)
+				}
+				response.Data = releases
+				s.Logger.Printf("success importing %d chapters of epub to channel %s", len(releases), channelUsername)
+			default:
+				for _, rel := range releases {
+					_ = s.ReleaseService.DeleteRelease(rel.ID)
+				}
+				switch err {
+				case release.ErrInvalidRating:
+					response.Data = jSendFailData{
+						ErrorReason:  "rating",
+						ErrorMessage: "rating isn't recognized",
+					}
+					statusCode = http.StatusBadRequest
+				case release.ErrInvalidCredit:
+					response.Data = jSendFailData{
+						ErrorReason:  "credits",
+						ErrorMessage: creditsErrorMessage,
+					}
+					statusCode = http.StatusBadRequest
+				case release.ErrCreditedUserNotFound:
+					response.Data = jSendFailData{
+						ErrorReason:  "credits",
+						ErrorMessage: "credited user not found",
+					}
+					statusCode = http.StatusNotFound
+				case release.ErrInvalidGenre:
+					response.Data = jSendFailData{
+						ErrorReason:  "genres",
+						ErrorMessage: genresErrorMessage,
+					}
+					statusCode = http.StatusBadRequest
+				case release.ErrInvalidLanguage:
+					response.Data = jSendFailData{
+						ErrorReason:  "language",
+						ErrorMessage: "language should be a language tag like 'en' or 'pt-BR'",
+					}
+					statusCode = http.StatusBadRequest
+				default:
+					s.Logger.Printf("importing of epub failed because: %v", err)
+					response.Status = "error"
+					response.Message = "server error when importing epub"
+					statusCode = http.StatusInternalServerError
+				}
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
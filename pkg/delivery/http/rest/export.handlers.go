@@ -0,0 +1,287 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/export"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
+)
+
+// catalogBook is a helper function that gathers the official catalog of the channel, in
+// order, into a book. Releases the user isn't to see under their content filter are left out.
+// Those credited as writers or artists are the authors of the book.
+func catalogBook(s *Setup, c *channel.Channel, username string) *export.Book {
+	book := &export.Book{
+		Title:       c.Name,
+		Description: c.Description,
+		Cover:       c.PictureURL,
+		Chapters:    make([]*export.Chapter, 0, len(c.OfficialReleaseIDs)),
+	}
+	if book.Title == "" {
+		book.Title = c.ChannelUsername
+	}
+	seenAuthors := make(map[string]bool)
+	seenGenres := make(map[string]bool)
+	filter := contentFilterOf(s, username)
+	for _, id := range c.OfficialReleaseIDs {
+		rel, err := s.ReleaseService.GetRelease(int(id))
+		if err != nil || !filter.Allows(rel.Rating, rel.Warnings) {
+			continue
+		}
+		chapter := &export.Chapter{Title: rel.Title}
+		if chapter.Title == "" {
+			chapter.Title = fmt.Sprintf("Release %d", rel.ID)
+		}
+		switch rel.Type {
+		case release.Text:
+			chapter.HTML = renderReleaseText(s, rel)
+		case release.Image:
+			chapter.Images = []string{rel.Content}
+		case release.Comic:
+			for _, page := range rel.Pages {
+				chapter.Images = append(chapter.Images, page.Image)
+			}
+		}
+		book.Chapters = append(book.Chapters, chapter)
+		for _, credit := range rel.Credits {
+			if credit.Role != release.RoleWriter && credit.Role != release.RoleArtist {
+				continue
+			}
+			author := credit.Name
+			if credit.Username != "" {
+				author = credit.Username
+			}
+			if !seenAuthors[author] {
+				seenAuthors[author] = true
+				book.Authors = append(book.Authors, author)
+			}
+		}
+		for _, genre := range append([]string{rel.GenreDefining}, rel.Genres...) {
+			if genre != "" && !seenGenres[genre] {
+				seenGenres[genre] = true
+				book.Genres = append(book.Genres, genre)
+			}
+		}
+		if book.Description == "" {
+			book.Description = rel.Description
+		}
+	}
+	return book
+}
+
+// filterVariant is a helper function that tells apart the catalogs gathered under different content
+// filters so that their exports don't replace each other
+func filterVariant(filter maturity.Filter) string {
+	warnings := append([]string{}, filter.HiddenWarnings...)
+	sort.Strings(warnings)
+	return string(filter.MaxRating) + ":" + strings.Join(warnings, ",")
+}
+
+// serveExportJob is a helper function that adds the url the file of done jobs can be downloaded from
+func serveExportJob(s *Setup, job *export.Job) {
+	if job.Status == export.StatusDone {
+		job.URL = fmt.Sprintf("%s/channels/%s/exports/%s/file", s.HostAddress, url.PathEscape(job.Owner), job.ID)
+	}
+}
+
+// postChannelExport returns a handler for POST /channels/{channelUsername}/exports requests.
+// The export is written in the background, the returned job is to be polled until it's done.
+// Exports of a catalog that hasn't changed since are returned right away.
+func postChannelExport(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		username := r.Header.Get("authorized_username")
+
+		if !canViewChannel(s, channelUsername, username) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized export attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
+
+		requestData := struct {
+			Format export.Format `json:"format"`
+		}{}
+		err := json.NewDecoder(r.Body).Decode(&requestData)
+		if err != nil || !requestData.Format.Valid() {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"format":"epub, cbz or pdf"}`,
+			}
+			s.Logger.Printf("bad post channel export request")
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			var job *export.Job
+			c, err := s.ChannelService.GetChannel(channelUsername)
+			if err == nil {
+				variant := filterVariant(contentFilterOf(s, username))
+				job, err = s.ExportService.Export(c.ChannelUsername, variant, requestData.Format, catalogBook(s, c, username))
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				if job.Status != export.StatusDone {
+					statusCode = http.StatusAccepted
+				}
+				serveExportJob(s, job)
+				response.Data = *job
+				s.Logger.Printf("success exporting official catalog of channel %s to %s", channelUsername, job.Format)
+			case export.ErrEmptyBook:
+				response.Data = jSendFailData{
+					ErrorReason:  "format",
+					ErrorMessage: "official catalog has no releases that can be exported to the format, epub holds text releases while cbz and pdf hold image and comic releases",
+				}
+				statusCode = http.StatusUnprocessableEntity
+			case export.ErrQueueFull:
+				s.Logger.Printf("export of channel %s rejected as the queue is full", channelUsername)
+				response.Status = "error"
+				response.Message = "server busy, try again later"
+				statusCode = http.StatusServiceUnavailable
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("exporting of official catalog of channel failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when exporting official catalog of channel"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getChannelExport returns a handler for GET /channels/{channelUsername}/exports/{jobID} requests
+func getChannelExport(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		jobID := vars["jobID"]
+
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized export fetch attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
+
+		job, err := s.ExportService.GetJob(jobID)
+		if err == nil && job.Owner != channelUsername {
+			err = export.ErrJobNotFound
+		}
+		switch err {
+		case nil:
+			response.Status = "success"
+			serveExportJob(s, job)
+			response.Data = *job
+			s.Logger.Printf("success fetching export %s of channel %s", jobID, channelUsername)
+		case export.ErrJobNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "jobID",
+				ErrorMessage: fmt.Sprintf("export of id %s not found", jobID),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of export failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching export"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getChannelExportFile returns a handler for GET /channels/{channelUsername}/exports/{jobID}/file
+// requests that sends the file of done exports
+func getChannelExportFile(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		jobID := vars["jobID"]
+
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized export download attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
+
+		job, err := s.ExportService.GetJob(jobID)
+		if err == nil && job.Owner != channelUsername {
+			err = export.ErrJobNotFound
+		}
+		var file io.ReadCloser
+		if err == nil {
+			file, err = s.ExportService.Open(jobID)
+		}
+		switch err {
+		case nil:
+			defer file.Close()
+			addCors(w)
+			w.Header().Set("Content-Type", job.Format.ContentType())
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, channelUsername, job.Format))
+			if job.Size > 0 {
+				w.Header().Set("Content-Length", fmt.Sprint(job.Size))
+			}
+			w.WriteHeader(http.StatusOK)
+			if _, err := io.Copy(w, file); err != nil {
+				s.Logger.Printf("sending of export %s failed because: %v", jobID, err)
+			}
+			return
+		case export.ErrJobNotDone:
+			response.Data = jSendFailData{
+				ErrorReason:  "jobID",
+				ErrorMessage: "export isn't done yet",
+			}
+			statusCode = http.StatusConflict
+		case export.ErrJobNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "jobID",
+				ErrorMessage: fmt.Sprintf("export of id %s not found", jobID),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of export file failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching export file"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
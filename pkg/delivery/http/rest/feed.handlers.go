@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/feed"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
 	"net/http"
 	"strconv"
 	"strings"
@@ -129,7 +130,7 @@ func getFeedPosts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 		}
 		// if queries are clean
 		if response.Data == nil {
-			posts, err := s.FeedService.GetPosts(&f, sort, limit, offset)
+			posts, err := s.FeedService.GetPosts(&f, sort, limit, offset, contentFilterOf(s, r.Header.Get("authorized_username")))
 			switch err {
 			case nil:
 				response.Status = "success"
@@ -139,7 +140,14 @@ func getFeedPosts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					truePosts := make([]interface{}, 0)
 					for _, pID := range posts {
 						if temp, err := s.PostService.GetPost(uint(pID.ID)); err == nil {
-							truePosts = append(truePosts, temp)
+							if pID.Pinned {
+								truePosts = append(truePosts, struct {
+									*post.Post
+									Pinned bool `json:"pinned"`
+								}{temp, true})
+							} else {
+								truePosts = append(truePosts, temp)
+							}
 						} else {
 							truePosts = append(truePosts, pID)
 						}
@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/imaging"
+)
+
+// saveImagePermanently is a helper function that stores the uploaded image under its content
+// addressed name and queues the generation of its variants if it hasn't been stored before.
+// The image is still served if it couldn't be queued, only without the smaller sizes.
+// Stored images are never removed here as others might refer to them, the blob service
+// collects the ones nothing refers to.
+func saveImagePermanently(s *Setup, tmpFile *os.File, fileName string) error {
+	_, err := tmpFile.Seek(0, 0)
+	if err != nil {
+		return err
+	}
+	sniff := make([]byte, 512)
+	n, _ := tmpFile.ReadAt(sniff, 0)
+	_, isNew, err := s.BlobService.AddBlob(fileName, tmpFile, http.DetectContentType(sniff[:n]))
+	if err != nil {
+		return err
+	}
+	if isNew {
+		if err = s.ImagingService.Process(fileName); err != nil {
+			s.Logger.Printf("queueing of image %s for processing failed because: %v", fileName, err)
+		}
+	}
+	return nil
+}
+
+// imageURL is a helper function that returns the address clients can fetch the stored
+// image of the given name from
+func imageURL(s *Setup, fileName string) string {
+	u, err := s.ImageStore.URL(fileName)
+	if err != nil {
+		s.Logger.Printf("building of url of image %s failed because: %v", fileName, err)
+	}
+	return u
+}
+
+// imageVariants is a helper function that returns the variants generated so far for the
+// given stored image with their URLs set
+func imageVariants(s *Setup, fileName string) []*imaging.Variant {
+	if fileName == "" {
+		return nil
+	}
+	variants := s.ImagingService.Variants(fileName)
+	for _, v := range variants {
+		v.URL = imageURL(s, v.File)
+	}
+	return variants
+}
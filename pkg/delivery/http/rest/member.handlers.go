@@ -0,0 +1,446 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+)
+
+// putChannelPrivacy returns a handler for PUT /channels/{channelUsername}/privacy requests
+func putChannelPrivacy(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized put channel privacy attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		var requestData struct {
+			Private *bool `json:"private"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&requestData)
+		if err != nil || requestData.Private == nil {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"private":true|false}`,
+			}
+			s.Logger.Printf("bad put channel privacy request")
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			err = s.ChannelService.SetPrivacy(channelUsername, *requestData.Private)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = requestData
+				s.Logger.Printf("success setting privacy of channel %s to %v", channelUsername, *requestData.Private)
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("setting privacy of channel failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when setting privacy of channel"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getChannelMembers returns a handler for GET /channels/{channelUsername}/members requests
+func getChannelMembers(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized get channel members attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		members, err := s.ChannelService.GetMembers(channelUsername)
+		switch err {
+		case nil:
+			response.Status = "success"
+			response.Data = members
+			s.Logger.Printf("success fetching members of channel %s", channelUsername)
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of members of channel failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching members of channel"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putChannelMember returns a handler for PUT /channels/{channelUsername}/members/{username} requests
+func putChannelMember(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		username := vars["username"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized put channel member attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		member := new(channel.Member)
+		err := json.NewDecoder(r.Body).Decode(member)
+		if err != nil {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"tier":"member|contributor"}`,
+			}
+			s.Logger.Printf("bad put channel member request")
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			member.Username = username
+			err = s.ChannelService.AddMember(channelUsername, member)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *member
+				s.Logger.Printf("success adding member %s to channel %s", username, channelUsername)
+			case channel.ErrInvalidTier:
+				response.Data = jSendFailData{
+					ErrorReason:  "tier",
+					ErrorMessage: fmt.Sprintf("tier must be either %s or %s", channel.TierMember, channel.TierContributor),
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrUserNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: fmt.Sprintf("user of %s not found", username),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("adding of member to channel failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when adding member to channel"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteChannelMember returns a handler for DELETE /channels/{channelUsername}/members/{username} requests
+func deleteChannelMember(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		username := vars["username"]
+
+		{ // this block secures the route, members can leave on their own
+			authUsername := r.Header.Get("authorized_username")
+			if username != authUsername && !isChannelAdmin(s, channelUsername, authUsername) {
+				s.Logger.Printf("unauthorized delete channel member attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		err := s.ChannelService.RemoveMember(channelUsername, username)
+		switch err {
+		case nil:
+			response.Status = "success"
+			s.Logger.Printf("success removing member %s from channel %s", username, channelUsername)
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		case channel.ErrMemberNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "username",
+				ErrorMessage: fmt.Sprintf("member of %s not found", username),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("removing of member from channel failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when removing member from channel"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postJoinRequest returns a handler for POST /channels/{channelUsername}/joinRequests requests
+func postJoinRequest(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		request := new(channel.JoinRequest)
+		if r.ContentLength != 0 {
+			err := json.NewDecoder(r.Body).Decode(request)
+			if err != nil {
+				response.Data = jSendFailData{
+					ErrorReason: "request format",
+					ErrorMessage: `bad request, use format
+				{"message":"message to the admins, optional"}`,
+				}
+				s.Logger.Printf("bad post join request request")
+				statusCode = http.StatusBadRequest
+			}
+		}
+
+		if response.Data == nil {
+			request.Username = r.Header.Get("authorized_username")
+			request.Message = s.StrictSanitizer.Sanitize(request.Message)
+			jr, err := s.ChannelService.RequestToJoin(channelUsername, request)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *jr
+				s.Logger.Printf("success adding join request of %s to channel %s", jr.Username, channelUsername)
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrAlreadyMember:
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: "user is already a member of the channel",
+				}
+				statusCode = http.StatusConflict
+			case channel.ErrJoinRequestAlreadyExists:
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: "user has already requested to join the channel",
+				}
+				statusCode = http.StatusConflict
+			default:
+				s.Logger.Printf("adding of join request failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when adding join request"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getJoinRequests returns a handler for GET /channels/{channelUsername}/joinRequests requests
+func getJoinRequests(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized get join requests attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		requests, err := s.ChannelService.GetJoinRequests(channelUsername)
+		switch err {
+		case nil:
+			response.Status = "success"
+			response.Data = requests
+			s.Logger.Printf("success fetching join requests of channel %s", channelUsername)
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of join requests failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching join requests"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putJoinRequest returns a handler for PUT /channels/{channelUsername}/joinRequests/{username} requests.
+// It approves the join request making the user a member of the given tier.
+func putJoinRequest(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		username := vars["username"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized approve join request attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		member := new(channel.Member)
+		if r.ContentLength != 0 {
+			err := json.NewDecoder(r.Body).Decode(member)
+			if err != nil {
+				response.Data = jSendFailData{
+					ErrorReason: "request format",
+					ErrorMessage: `bad request, use format
+				{"tier":"member|contributor, optional"}`,
+				}
+				s.Logger.Printf("bad approve join request request")
+				statusCode = http.StatusBadRequest
+			}
+		}
+
+		if response.Data == nil {
+			err := s.ChannelService.ApproveJoinRequest(channelUsername, username, member.Tier)
+			switch err {
+			case nil:
+				response.Status = "success"
+				s.Logger.Printf("success approving join request of %s to channel %s", username, channelUsername)
+			case channel.ErrInvalidTier:
+				response.Data = jSendFailData{
+					ErrorReason:  "tier",
+					ErrorMessage: fmt.Sprintf("tier must be either %s or %s", channel.TierMember, channel.TierContributor),
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrJoinRequestNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: fmt.Sprintf("join request of %s not found", username),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("approving of join request failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when approving join request"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteJoinRequest returns a handler for DELETE /channels/{channelUsername}/joinRequests/{username} requests.
+// Admins use it to reject requests while users use it to withdraw their own.
+func deleteJoinRequest(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		username := vars["username"]
+
+		{ // this block secures the route
+			authUsername := r.Header.Get("authorized_username")
+			if username != authUsername && !isChannelAdmin(s, channelUsername, authUsername) {
+				s.Logger.Printf("unauthorized delete join request attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		err := s.ChannelService.RejectJoinRequest(channelUsername, username)
+		switch err {
+		case nil:
+			response.Status = "success"
+			s.Logger.Printf("success deleting join request of %s to channel %s", username, channelUsername)
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		case channel.ErrJoinRequestNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "username",
+				ErrorMessage: fmt.Sprintf("join request of %s not found", username),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("deleting of join request failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when deleting join request"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/comment"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/mention"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+)
+
+// setMentions is a helper function that records the mentions written in the given
+// text and returns them. Failures are only logged as the source has already been saved.
+func setMentions(s *Setup, sourceType mention.SourceType, sourceID int, text string) []*mention.Mention {
+	mentions, err := s.MentionService.SetMentions(sourceType, sourceID, text)
+	if err != nil {
+		s.Logger.Printf("recording of mentions of %s %d failed because: %v", sourceType, sourceID, err)
+		return nil
+	}
+	return mentions
+}
+
+// getMentions is a helper function that returns the mentions recorded for the given source.
+// Failures are only logged as they shouldn't keep the source from being served.
+func getMentions(s *Setup, sourceType mention.SourceType, sourceID int) []*mention.Mention {
+	mentions, err := s.MentionService.GetMentions(sourceType, sourceID)
+	if err != nil {
+		s.Logger.Printf("fetching of mentions of %s %d failed because: %v", sourceType, sourceID, err)
+		return nil
+	}
+	return mentions
+}
+
+// getUserMentions returns a handler for GET /users/{username}/mentions?limit=25&offset=0 requests
+// listing the posts and comments the user was mentioned in, newest first
+func getUserMentions(s *Setup) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		statusCode := http.StatusOK
+		response.Status = "fail"
+
+		vars := getParametersFromRequestAsMap(r)
+		username := vars["username"]
+
+		{ // this block secures the route
+			if username != r.Header.Get("authorized_username") {
+				s.Logger.Printf("unauthorized get user mentions request")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		limit := 25
+		offset := 0
+		{ // this block reads the query strings if any
+			if limitPageRaw := r.URL.Query().Get("limit"); limitPageRaw != "" {
+				limit, err = strconv.Atoi(limitPageRaw)
+				if err != nil || limit < 0 {
+					s.Logger.Printf("bad get user mentions request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					s.Logger.Printf("bad get user mentions request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil {
+			mentions, err := s.MentionService.GetMentionsOf(mention.KindUser, username, username, limit, offset)
+			switch err {
+			case nil:
+				type mentionedIn struct {
+					*mention.Mention
+					Post    *post.Post       `json:"post,omitempty"`
+					Comment *comment.Comment `json:"comment,omitempty"`
+				}
+				results := make([]mentionedIn, 0, len(mentions))
+				for _, m := range mentions {
+					result := mentionedIn{Mention: m}
+					postID := m.SourceID
+					if m.SourceType == mention.SourceComment {
+						c, err := s.CommentService.GetComment(m.SourceID)
+						if err != nil {
+							// the comment has since been removed
+							continue
+						}
+						result.Comment = c
+						postID = c.OriginPost
+					}
+					p, err := s.PostService.GetPost(uint(postID))
+					if err != nil {
+						continue
+					}
+					if m.SourceType == mention.SourcePost {
+						result.Post = p
+					}
+					results = append(results, result)
+				}
+				response.Status = "success"
+				response.Data = results
+				s.Logger.Printf("success fetching mentions of user %s", username)
+			default:
+				s.Logger.Printf("fetching of user mentions failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching user mentions"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
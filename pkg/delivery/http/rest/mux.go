@@ -2,13 +2,23 @@ package rest
 
 import (
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/auth"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/blob"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/comment"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/feed"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/mention"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/poll"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/reaction"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/tag"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/user"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/view"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/export"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/imaging"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/search"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/storage"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/unfurl"
 	"log"
 	"net/http"
 	"time"
@@ -40,17 +50,31 @@ type Dependencies struct {
 	ReleaseService  release.Service
 	PostService     post.Service
 	CommentService  comment.Service
+	ReactionService reaction.Service
+	PollService     poll.Service
+	ViewService     view.Service
+	MentionService  mention.Service
 	SearchService   search.Service
+	TagService      tag.Service
+	UnfurlService   unfurl.Service
+	ImagingService  imaging.Service
+	ImageStore      storage.Store
+	BlobService     blob.Service
+	ExportService   export.Service
 	AuthService     auth.Service
 	Logger          *log.Logger
 }
 
-// Config contains the different settings used to set up the handlers
+// Config contains the different settings used to set up the handlers.
+// ImageStoragePath is only set when the ImageStore keeps its blobs on the
+// local disk, in which case they're served under ImageServingRoute.
+// TagCurators lists the usernames of those trusted to curate the tag taxonomy.
 type Config struct {
 	ImageServingRoute, ImageStoragePath, HostAddress, Port string
 	TokenAccessLifetime, TokenRefreshLifetime              time.Duration
 	TokenSigningSecret                                     []byte
 	HTTPS                                                  bool
+	TagCurators                                            []string
 }
 
 // NewMux returns a new multiplexer with all the used setup.
@@ -104,8 +128,11 @@ func NewMux(s *Setup) *httprouter.Router {
 	})
 	mainRouter.NotFound = CheckForAuthMiddleware(s)(secureRouter)
 
-	fs := http.FileServer(http.Dir(s.ImageStoragePath))
-	rootRouter.Handler("GET", s.ImageServingRoute+"*filepath", http.StripPrefix(s.ImageServingRoute, fs))
+	// images are only served from here when they're kept on the local disk
+	if s.ImageStoragePath != "" {
+		fs := http.FileServer(http.Dir(s.ImageStoragePath))
+		rootRouter.Handler("GET", s.ImageServingRoute+"*filepath", http.StripPrefix(s.ImageServingRoute, fs))
+	}
 
 	// attach routes
 	attachAuthRoutesToRouters(mainRouter, secureRouter, s)
@@ -115,8 +142,17 @@ func NewMux(s *Setup) *httprouter.Router {
 	attachCommentRoutesToRouters(mainRouter, secureRouter, s)
 	attachChannelRoutesToRouters(mainRouter, secureRouter, s)
 	attachPostRoutesToRouters(mainRouter, secureRouter, s)
+	attachDraftRoutesToRouters(secureRouter, s)
+	attachMemberRoutesToRouters(secureRouter, s)
+	attachBlockRoutesToRouters(secureRouter, s)
+	attachReactionRoutesToRouters(mainRouter, secureRouter, s)
+	attachRevisionRoutesToRouters(secureRouter, s)
+	attachCrosspostRoutesToRouters(mainRouter, secureRouter, s)
+	attachPollRoutesToRouters(secureRouter, s)
+	attachTagRoutesToRouters(mainRouter, secureRouter, s)
 
 	mainRouter.HandlerFunc("GET", "/search", getSearch(s))
+	mainRouter.HandlerFunc("GET", "/search/tags", getTagSuggestions(s))
 
 	return rootRouter
 }
@@ -141,6 +177,10 @@ func attachUserRoutesToRouters(mainRouter, secureRouter *httprouter.Router, setu
 	secureRouter.HandlerFunc("GET", "/users/:username/picture", getUserPicture(setup))
 	secureRouter.HandlerFunc("PUT", "/users/:username/picture", putUserPicture(setup))
 	secureRouter.HandlerFunc("DELETE", "/users/:username/picture", deleteUserPicture(setup))
+	secureRouter.HandlerFunc("GET", "/users/:username/content-filter", getUserContentFilter(setup))
+	secureRouter.HandlerFunc("PUT", "/users/:username/content-filter", putUserContentFilter(setup))
+	secureRouter.HandlerFunc("GET", "/users/:username/mentions", getUserMentions(setup))
+	mainRouter.HandlerFunc("GET", "/users/:username/credits", getUserCredits(setup))
 }
 
 func attachReleaseRoutesToRouters(mainRouter, secureRouter *httprouter.Router, setup *Setup) {
@@ -149,6 +189,15 @@ func attachReleaseRoutesToRouters(mainRouter, secureRouter *httprouter.Router, s
 	secureRouter.HandlerFunc("POST", "/releases", postRelease(setup))
 	secureRouter.HandlerFunc("PATCH", "/releases/:id", patchRelease(setup))
 	secureRouter.HandlerFunc("DELETE", "/releases/:id", deleteRelease(setup))
+	secureRouter.HandlerFunc("POST", "/releases/:id/pages", postReleasePages(setup))
+	secureRouter.HandlerFunc("PUT", "/releases/:id/pages", putReleasePagesOrder(setup))
+	secureRouter.HandlerFunc("PUT", "/releases/:id/pages/:pageNumber", putReleasePage(setup))
+	secureRouter.HandlerFunc("DELETE", "/releases/:id/pages/:pageNumber", deleteReleasePage(setup))
+	mainRouter.HandlerFunc("GET", "/releases/:id/audio", getReleaseAudio(setup))
+	mainRouter.HandlerFunc("HEAD", "/releases/:id/audio", getReleaseAudio(setup))
+	secureRouter.HandlerFunc("DELETE", "/releases/:id/translation", deleteReleaseTranslation(setup))
+	secureRouter.HandlerFunc("PUT", "/releases/:id/translation/permission", putReleaseTranslationPermission(setup))
+	secureRouter.HandlerFunc("DELETE", "/releases/:id/translation/permission", deleteReleaseTranslationPermission(setup))
 }
 
 func attachFeedRoutesToRouters(secureRouter *httprouter.Router, setup *Setup) {
@@ -196,11 +245,27 @@ func attachChannelRoutesToRouters(mainRouter, secureRouter *httprouter.Router, s
 	secureRouter.HandlerFunc("POST", "/channels/:channelUsername/catalogs}", postReleaseInCatalog(setup))
 	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/official/:releaseID", putReleaseInOfficialCatalog(setup))
 	mainRouter.HandlerFunc("GET", "/channels/:channelUsername/stickiedPosts", getStickiedPosts(setup))
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/stickiedPosts", putStickiedPostsOrder(setup))
 	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/stickiedPosts/:postID", stickyPost(setup))
 	secureRouter.HandlerFunc("DELETE", "/channels/:channelUsername/stickiedPosts/:stickiedPostID", deleteStickiedPost(setup))
 	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/picture", putChannelPicture(setup))
 	mainRouter.HandlerFunc("GET", "/channels/:channelUsername/picture", getChannelPicture(setup))
 	secureRouter.HandlerFunc("DELETE", "/channels/:channelUsername/picture", deleteChannelPicture(setup))
+	secureRouter.HandlerFunc("GET", "/channels/:channelUsername/stats", getChannelStats(setup))
+	mainRouter.HandlerFunc("GET", "/channels/:channelUsername/banner", getChannelBanner(setup))
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/banner", putChannelBanner(setup))
+	secureRouter.HandlerFunc("DELETE", "/channels/:channelUsername/banner", deleteChannelBanner(setup))
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/theme", putChannelTheme(setup))
+	mainRouter.HandlerFunc("GET", "/channels/:channelUsername/pages", getChannelPages(setup))
+	mainRouter.HandlerFunc("GET", "/channels/:channelUsername/pages/:slug", getChannelPage(setup))
+	secureRouter.HandlerFunc("POST", "/channels/:channelUsername/pages", postChannelPage(setup))
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/pages/:slug", putChannelPage(setup))
+	secureRouter.HandlerFunc("DELETE", "/channels/:channelUsername/pages/:slug", deleteChannelPage(setup))
+	secureRouter.HandlerFunc("POST", "/channels/:channelUsername/epub", postChannelEPUB(setup))
+	mainRouter.HandlerFunc("POST", "/channels/:channelUsername/exports", postChannelExport(setup))
+	mainRouter.HandlerFunc("GET", "/channels/:channelUsername/exports/:jobID", getChannelExport(setup))
+	mainRouter.HandlerFunc("GET", "/channels/:channelUsername/exports/:jobID/file", getChannelExportFile(setup))
+	mainRouter.HandlerFunc("GET", "/channels/:channelUsername/podcast", getChannelPodcast(setup))
 
 }
 func attachPostRoutesToRouters(mainRouter, secureRouter *httprouter.Router, setup *Setup) {
@@ -213,10 +278,93 @@ func attachPostRoutesToRouters(mainRouter, secureRouter *httprouter.Router, setu
 	mainRouter.HandlerFunc("GET", "/posts/:postID/releases", getPostReleases(setup))
 	//mainRouter.HandlerFunc("GET","/posts/:postID/comments", getPostComments(setup))
 	mainRouter.HandlerFunc("GET", "/posts/:postID/stars", getPostStars(setup))
+	mainRouter.HandlerFunc("GET", "/leaderboard", getLeaderboard(setup))
 	mainRouter.HandlerFunc("GET", "/posts/:postID/stars/:username", getPostStar(setup))
 	secureRouter.HandlerFunc("PUT", "/posts/:postID/stars", putPostStar(setup))
 }
 
+func attachDraftRoutesToRouters(secureRouter *httprouter.Router, setup *Setup) {
+	secureRouter.HandlerFunc("GET", "/channels/:channelUsername/drafts", getChannelDrafts(setup))
+	secureRouter.HandlerFunc("GET", "/channels/:channelUsername/drafts/:postID", getChannelDraft(setup))
+	secureRouter.HandlerFunc("POST", "/channels/:channelUsername/drafts/:postID/submit", postDraftSubmission(setup))
+	secureRouter.HandlerFunc("GET", "/channels/:channelUsername/drafts/:postID/reviews", getDraftReviews(setup))
+	secureRouter.HandlerFunc("POST", "/channels/:channelUsername/drafts/:postID/reviews", postDraftReview(setup))
+	secureRouter.HandlerFunc("POST", "/channels/:channelUsername/drafts/:postID/publish", postDraftPublication(setup))
+}
+
+func attachMemberRoutesToRouters(secureRouter *httprouter.Router, setup *Setup) {
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/privacy", putChannelPrivacy(setup))
+	secureRouter.HandlerFunc("GET", "/channels/:channelUsername/members", getChannelMembers(setup))
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/members/:username", putChannelMember(setup))
+	secureRouter.HandlerFunc("DELETE", "/channels/:channelUsername/members/:username", deleteChannelMember(setup))
+	secureRouter.HandlerFunc("POST", "/channels/:channelUsername/joinRequests", postJoinRequest(setup))
+	secureRouter.HandlerFunc("GET", "/channels/:channelUsername/joinRequests", getJoinRequests(setup))
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/joinRequests/:username", putJoinRequest(setup))
+	secureRouter.HandlerFunc("DELETE", "/channels/:channelUsername/joinRequests/:username", deleteJoinRequest(setup))
+}
+
+func attachBlockRoutesToRouters(secureRouter *httprouter.Router, setup *Setup) {
+	secureRouter.HandlerFunc("GET", "/channels/:channelUsername/blocked", getBlockedUsers(setup))
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/blocked/:username", putBlockedUser(setup))
+	secureRouter.HandlerFunc("DELETE", "/channels/:channelUsername/blocked/:username", deleteBlockedUser(setup))
+}
+
+func attachReactionRoutesToRouters(mainRouter, secureRouter *httprouter.Router, setup *Setup) {
+	mainRouter.HandlerFunc("GET", "/reactions", getReactionEmojis(setup))
+
+	mainRouter.HandlerFunc("GET", "/posts/:postID/reactions", getReactions(setup))
+	mainRouter.HandlerFunc("GET", "/posts/:postID/reactions/users", getReactors(setup))
+	secureRouter.HandlerFunc("POST", "/posts/:postID/reactions", postReaction(setup))
+
+	mainRouter.HandlerFunc("GET", "/posts/:postID/comments/:commentID/reactions", getReactions(setup))
+	mainRouter.HandlerFunc("GET", "/posts/:postID/comments/:commentID/reactions/users", getReactors(setup))
+	secureRouter.HandlerFunc("POST", "/posts/:postID/comments/:commentID/reactions", postReaction(setup))
+
+	mainRouter.HandlerFunc("GET", "/releases/:id/reactions", getReactions(setup))
+	mainRouter.HandlerFunc("GET", "/releases/:id/reactions/users", getReactors(setup))
+	secureRouter.HandlerFunc("POST", "/releases/:id/reactions", postReaction(setup))
+}
+
+func attachRevisionRoutesToRouters(secureRouter *httprouter.Router, setup *Setup) {
+	secureRouter.HandlerFunc("GET", "/posts/:postID/revisions", getPostRevisions(setup))
+	secureRouter.HandlerFunc("GET", "/posts/:postID/revisions/:revisionID", getPostRevision(setup))
+	secureRouter.HandlerFunc("GET", "/posts/:postID/revisions/:revisionID/diff", getPostRevisionDiff(setup))
+	secureRouter.HandlerFunc("POST", "/posts/:postID/revisions/:revisionID/restore", postPostRevisionRestore(setup))
+
+	secureRouter.HandlerFunc("GET", "/releases/:id/revisions", getReleaseRevisions(setup))
+	secureRouter.HandlerFunc("GET", "/releases/:id/revisions/:revisionID", getReleaseRevision(setup))
+	secureRouter.HandlerFunc("GET", "/releases/:id/revisions/:revisionID/diff", getReleaseRevisionDiff(setup))
+	secureRouter.HandlerFunc("POST", "/releases/:id/revisions/:revisionID/restore", postReleaseRevisionRestore(setup))
+}
+
+func attachCrosspostRoutesToRouters(mainRouter, secureRouter *httprouter.Router, setup *Setup) {
+	mainRouter.HandlerFunc("GET", "/posts/:postID/crossposts", getPostCrossposts(setup))
+	secureRouter.HandlerFunc("POST", "/posts/:postID/crossposts", postPostCrosspost(setup))
+	secureRouter.HandlerFunc("GET", "/channels/:channelUsername/crossposts", getChannelCrossposts(setup))
+	secureRouter.HandlerFunc("PUT", "/channels/:channelUsername/crossposts/:postID", putChannelCrosspost(setup))
+	secureRouter.HandlerFunc("DELETE", "/channels/:channelUsername/crossposts/:postID", deleteChannelCrosspost(setup))
+}
+
+func attachPollRoutesToRouters(secureRouter *httprouter.Router, setup *Setup) {
+	secureRouter.HandlerFunc("GET", "/posts/:postID/polls", getPostPolls(setup))
+	secureRouter.HandlerFunc("POST", "/posts/:postID/polls", postPostPoll(setup))
+	secureRouter.HandlerFunc("GET", "/polls/:pollID", getPoll(setup))
+	secureRouter.HandlerFunc("DELETE", "/polls/:pollID", deletePoll(setup))
+	secureRouter.HandlerFunc("POST", "/polls/:pollID/votes", postPollVote(setup))
+	secureRouter.HandlerFunc("GET", "/polls/:pollID/results", getPollResults(setup))
+}
+
+func attachTagRoutesToRouters(mainRouter, secureRouter *httprouter.Router, setup *Setup) {
+	mainRouter.HandlerFunc("GET", "/tags", getTags(setup))
+	mainRouter.HandlerFunc("GET", "/tags/:name", getTag(setup))
+	secureRouter.HandlerFunc("PATCH", "/tags/:name", patchTag(setup))
+	secureRouter.HandlerFunc("DELETE", "/tags/:name/parent", deleteTagParent(setup))
+	secureRouter.HandlerFunc("PUT", "/tags/:name/aliases/:alias", putTagAlias(setup))
+	secureRouter.HandlerFunc("DELETE", "/tags/:name/aliases/:alias", deleteTagAlias(setup))
+	mainRouter.HandlerFunc("GET", "/tags/:name/releases", getTagReleases(setup))
+	mainRouter.HandlerFunc("GET", "/tags/:name/channels", getTagChannels(setup))
+}
+
 // Old gorilla trappings, just comment out
 
 /*
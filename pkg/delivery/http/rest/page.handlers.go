@@ -0,0 +1,316 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+	"gopkg.in/russross/blackfriday.v2"
+)
+
+// renderPage is a helper function that renders the markdown content of the page into sanitized HTML
+func renderPage(p *channel.Page, s *Setup) {
+	p.Title = s.StrictSanitizer.Sanitize(p.Title)
+	p.HTML = string(s.MarkupSanitizer.SanitizeBytes(
+		blackfriday.Run(
+			[]byte(p.Content),
+			blackfriday.WithExtensions(blackfriday.CommonExtensions),
+		),
+	))
+}
+
+// getChannelPages returns a handler for GET /channels/{channelUsername}/pages requests
+func getChannelPages(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized access attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
+
+		pages, err := s.ChannelService.GetPages(channelUsername)
+		switch err {
+		case nil:
+			response.Status = "success"
+			for _, p := range pages {
+				renderPage(p, s)
+			}
+			response.Data = pages
+			s.Logger.Printf("success fetching pages of channel %s", channelUsername)
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of pages of channel failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching pages of channel"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getChannelPage returns a handler for GET /channels/{channelUsername}/pages/{slug} requests
+func getChannelPage(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		slug := vars["slug"]
+		if !canViewChannel(s, channelUsername, r.Header.Get("authorized_username")) {
+			// this block hides the contents of private channels from non members
+			s.Logger.Printf("unauthorized access attempt of private channel %s", channelUsername)
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: "channel is private",
+			}
+			writeResponseToWriter(response, w, http.StatusForbidden)
+			return
+		}
+
+		p, err := s.ChannelService.GetPage(channelUsername, slug)
+		switch err {
+		case nil:
+			response.Status = "success"
+			renderPage(p, s)
+			response.Data = *p
+			s.Logger.Printf("success fetching page %s of channel %s", slug, channelUsername)
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		case channel.ErrPageNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "slug",
+				ErrorMessage: fmt.Sprintf("page of slug %s not found", slug),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of page of channel failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching page of channel"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postChannelPage returns a handler for POST /channels/{channelUsername}/pages requests
+func postChannelPage(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized post channel page attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		p := new(channel.Page)
+		err := json.NewDecoder(r.Body).Decode(p)
+		if err != nil {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"slug":"lowercase-words-joined-by-dashes",
+				"title":"title",
+				"content":"markdown content",
+				"position":0}`,
+			}
+			s.Logger.Printf("bad post channel page request")
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			p, err = s.ChannelService.AddPage(channelUsername, p)
+			switch err {
+			case nil:
+				response.Status = "success"
+				renderPage(p, s)
+				response.Data = *p
+				s.Logger.Printf("success adding page %s to channel %s", p.Slug, channelUsername)
+			case channel.ErrInvalidPage:
+				response.Data = jSendFailData{
+					ErrorReason:  "slug",
+					ErrorMessage: "title is required and slug must be made of lowercase words joined by dashes, up to 32 chars",
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrTooManyPages:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: "channel has reached the maximum number of pages",
+				}
+				statusCode = http.StatusConflict
+			case channel.ErrPageAlreadyExists:
+				response.Data = jSendFailData{
+					ErrorReason:  "slug",
+					ErrorMessage: "channel already has a page under the given slug",
+				}
+				statusCode = http.StatusConflict
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("adding of page to channel failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when adding page to channel"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putChannelPage returns a handler for PUT /channels/{channelUsername}/pages/{slug} requests.
+// Fields missing from the request keep their current values.
+func putChannelPage(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		slug := vars["slug"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized put channel page attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		p, err := s.ChannelService.GetPage(channelUsername, slug)
+		if err == nil {
+			err = json.NewDecoder(r.Body).Decode(p)
+			if err != nil {
+				response.Data = jSendFailData{
+					ErrorReason: "request format",
+					ErrorMessage: `bad request, use format
+				{"slug":"lowercase-words-joined-by-dashes",
+				"title":"title",
+				"content":"markdown content",
+				"position":0}`,
+				}
+				s.Logger.Printf("bad put channel page request")
+				statusCode = http.StatusBadRequest
+			} else {
+				p, err = s.ChannelService.UpdatePage(channelUsername, slug, p)
+			}
+		}
+		if response.Data == nil {
+			switch err {
+			case nil:
+				response.Status = "success"
+				renderPage(p, s)
+				response.Data = *p
+				s.Logger.Printf("success updating page %s of channel %s", slug, channelUsername)
+			case channel.ErrInvalidPage:
+				response.Data = jSendFailData{
+					ErrorReason:  "slug",
+					ErrorMessage: "title is required and slug must be made of lowercase words joined by dashes, up to 32 chars",
+				}
+				statusCode = http.StatusBadRequest
+			case channel.ErrPageAlreadyExists:
+				response.Data = jSendFailData{
+					ErrorReason:  "slug",
+					ErrorMessage: "channel already has a page under the given slug",
+				}
+				statusCode = http.StatusConflict
+			case channel.ErrPageNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "slug",
+					ErrorMessage: fmt.Sprintf("page of slug %s not found", slug),
+				}
+				statusCode = http.StatusNotFound
+			case channel.ErrChannelNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "channelUsername",
+					ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("updating of page of channel failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when updating page of channel"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteChannelPage returns a handler for DELETE /channels/{channelUsername}/pages/{slug} requests
+func deleteChannelPage(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+		vars := getParametersFromRequestAsMap(r)
+		channelUsername := vars["channelUsername"]
+		slug := vars["slug"]
+
+		{ // this block secures the route
+			if !isChannelAdmin(s, channelUsername, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized delete channel page attempt")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		err := s.ChannelService.DeletePage(channelUsername, slug)
+		switch err {
+		case nil:
+			response.Status = "success"
+			s.Logger.Printf("success deleting page %s of channel %s", slug, channelUsername)
+		case channel.ErrPageNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "slug",
+				ErrorMessage: fmt.Sprintf("page of slug %s not found", slug),
+			}
+			statusCode = http.StatusNotFound
+		case channel.ErrChannelNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "channelUsername",
+				ErrorMessage: fmt.Sprintf("channel of %s not found", channelUsername),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("deleting of page of channel failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when deleting page of channel"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
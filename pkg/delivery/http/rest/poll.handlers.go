@@ -0,0 +1,380 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/poll"
+)
+
+// parsePollID is just a helper function that reads the poll id of the request
+func parsePollID(vars map[string]string) (uint, *jSendFailData) {
+	id, err := strconv.Atoi(vars["pollID"])
+	if err != nil || id < 0 {
+		return 0, &jSendFailData{
+			ErrorReason:  "pollID",
+			ErrorMessage: fmt.Sprintf("invalid pollID %s", vars["pollID"]),
+		}
+	}
+	return uint(id), nil
+}
+
+// getPostPolls returns a handler for GET /posts/{postID}/polls requests
+func getPostPolls(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("fetch polls attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			var polls []*poll.Poll
+			if !canViewPost(s, uint(id), r.Header.Get("authorized_username")) {
+				err = poll.ErrPostNotFound
+			} else {
+				polls, err = s.PollService.GetPostPolls(uint(id))
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = polls
+				s.Logger.Printf("success fetching polls of post %d", id)
+			case poll.ErrPostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("post of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of post polls failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching post polls"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postPostPoll returns a handler for POST /posts/{postID}/polls requests
+// Only those that can edit the post can attach polls to it.
+func postPostPoll(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("add poll attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		p := new(poll.Poll)
+		if response.Data == nil {
+			err = json.NewDecoder(r.Body).Decode(p)
+			if err != nil {
+				response.Data = jSendFailData{
+					ErrorReason: "request format",
+					ErrorMessage: `bad request, use format
+				{"question":"question","options":[{"text":"option"}],
+				"multipleChoice":false,"hideResults":false,"closesAt":"RFC3339 time"}`,
+				}
+				s.Logger.Printf("bad add poll request")
+				statusCode = http.StatusBadRequest
+			}
+		}
+
+		if response.Data == nil {
+			p.CreatedBy = r.Header.Get("authorized_username")
+			{ // this block secures the route
+				if !canEditPost(s, uint(id), p.CreatedBy) {
+					s.Logger.Printf("unauthorized add poll attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			p.PostID = uint(id)
+			p.Question = s.StrictSanitizer.Sanitize(p.Question)
+			for _, o := range p.Options {
+				if o != nil {
+					o.Text = s.StrictSanitizer.Sanitize(o.Text)
+				}
+			}
+			p, err = s.PollService.AddPoll(p)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *p
+				s.Logger.Printf("success adding poll %d to post %d", p.ID, id)
+			case poll.ErrInvalidPoll:
+				response.Data = jSendFailData{
+					ErrorReason: "options",
+					ErrorMessage: fmt.Sprintf("poll must have a question and between 2 and %d distinct options",
+						poll.MaxOptions),
+				}
+				statusCode = http.StatusBadRequest
+			case poll.ErrInvalidCloseTime:
+				response.Data = jSendFailData{
+					ErrorReason:  "closesAt",
+					ErrorMessage: "poll close time has already passed",
+				}
+				statusCode = http.StatusBadRequest
+			case poll.ErrPostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("post of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("adding of poll failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when adding poll"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getPoll returns a handler for GET /polls/{pollID} requests
+func getPoll(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		id, failData := parsePollID(vars)
+		if failData != nil {
+			s.Logger.Printf("fetch attempt of invalid poll id %s", vars["pollID"])
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			p, err := s.PollService.GetPoll(id)
+			if err == nil && !canViewPost(s, p.PostID, r.Header.Get("authorized_username")) {
+				err = poll.ErrPollNotFound
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *p
+				s.Logger.Printf("success fetching poll %d", id)
+			case poll.ErrPollNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "pollID",
+					ErrorMessage: fmt.Sprintf("poll of pollID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of poll failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching poll"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deletePoll returns a handler for DELETE /polls/{pollID} requests
+// Only those that can edit the post the poll is attached to can remove it.
+func deletePoll(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		id, failData := parsePollID(vars)
+		if failData != nil {
+			s.Logger.Printf("delete attempt of invalid poll id %s", vars["pollID"])
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			p, err := s.PollService.GetPoll(id)
+			if err == nil {
+				{ // this block secures the route
+					if !canEditPost(s, p.PostID, r.Header.Get("authorized_username")) {
+						s.Logger.Printf("unauthorized delete poll attempt")
+						addCors(w)
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+				}
+				err = s.PollService.DeletePoll(id)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				s.Logger.Printf("success deleting poll %d", id)
+			case poll.ErrPollNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "pollID",
+					ErrorMessage: fmt.Sprintf("poll of pollID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("deletion of poll failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when deleting poll"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postPollVote returns a handler for POST /polls/{pollID}/votes requests
+func postPollVote(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		id, failData := parsePollID(vars)
+		if failData != nil {
+			s.Logger.Printf("vote attempt on invalid poll id %s", vars["pollID"])
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		v := new(poll.Vote)
+		if response.Data == nil {
+			err := json.NewDecoder(r.Body).Decode(v)
+			if err != nil {
+				response.Data = jSendFailData{
+					ErrorReason: "request format",
+					ErrorMessage: `bad request, use format
+				{"optionIDs":[1]}`,
+				}
+				s.Logger.Printf("bad vote request")
+				statusCode = http.StatusBadRequest
+			}
+		}
+
+		if response.Data == nil {
+			v.PollID = id
+			v.Username = r.Header.Get("authorized_username")
+			var err error
+			var p *poll.Poll
+			if p, err = s.PollService.GetPoll(id); err == nil {
+				if !canViewPost(s, p.PostID, v.Username) {
+					err = poll.ErrPollNotFound
+				} else {
+					v, err = s.PollService.CastVote(v)
+				}
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *v
+				s.Logger.Printf("success voting on poll %d", id)
+			case poll.ErrPollNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "pollID",
+					ErrorMessage: fmt.Sprintf("poll of pollID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			case poll.ErrInvalidVote:
+				response.Data = jSendFailData{
+					ErrorReason:  "optionIDs",
+					ErrorMessage: "vote must pick distinct options of the poll, only one if it isn't multiple choice",
+				}
+				statusCode = http.StatusBadRequest
+			case poll.ErrPollClosed:
+				response.Data = jSendFailData{
+					ErrorReason:  "pollID",
+					ErrorMessage: "voting on poll has closed",
+				}
+				statusCode = http.StatusForbidden
+			case poll.ErrAlreadyVoted:
+				response.Data = jSendFailData{
+					ErrorReason:  "pollID",
+					ErrorMessage: "user has already voted on poll",
+				}
+				statusCode = http.StatusConflict
+			default:
+				s.Logger.Printf("voting on poll failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when voting on poll"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getPollResults returns a handler for GET /polls/{pollID}/results requests
+// If the author chose to hide results, only they get to see the tally before voting closes.
+func getPollResults(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		id, failData := parsePollID(vars)
+		if failData != nil {
+			s.Logger.Printf("fetch results attempt of invalid poll id %s", vars["pollID"])
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			username := r.Header.Get("authorized_username")
+			var results *poll.Results
+			p, err := s.PollService.GetPoll(id)
+			if err == nil {
+				if !canViewPost(s, p.PostID, username) {
+					err = poll.ErrPollNotFound
+				} else {
+					results, err = s.PollService.GetResults(id, username)
+				}
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *results
+				s.Logger.Printf("success fetching results of poll %d", id)
+			case poll.ErrPollNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "pollID",
+					ErrorMessage: fmt.Sprintf("poll of pollID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of poll results failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching poll results"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
@@ -2,7 +2,11 @@ package rest
 
 import (
 	"fmt"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/mention"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/view"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/unfurl"
 	"gopkg.in/russross/blackfriday.v2"
 
 	// "html"
@@ -32,6 +36,47 @@ func sanitizePost(p *post.Post, s *Setup) {
 	p.Description = html.EscapeString(p.Description) */
 }
 
+// sanitizePreview is a helper function that strips markup from the third party
+// metadata of link previews
+func sanitizePreview(p *unfurl.Preview, s *Setup) {
+	p.Title = s.StrictSanitizer.Sanitize(p.Title)
+	p.Description = s.StrictSanitizer.Sanitize(p.Description)
+	p.SiteName = s.StrictSanitizer.Sanitize(p.SiteName)
+}
+
+// canViewPost is a helper function that checks if the given user can see the post of the given id.
+// Posts that can't be fetched are reported as hidden, it's left to handlers to respond with their usual errors.
+// Drafts are only visible to their writers and the admins of their channel.
+func canViewPost(s *Setup, id uint, username string) bool {
+	p, err := s.PostService.GetPost(id)
+	if err != nil {
+		return false
+	}
+	if p.Status != post.StatusPublished && p.PostedByUsername != username && !isChannelAdmin(s, p.OriginChannel, username) {
+		return false
+	}
+	if canViewChannel(s, p.OriginChannel, username) {
+		return true
+	}
+	// posts are also visible through the channels they're crossposted to
+	crossposts, err := s.ChannelService.GetPostCrossposts(id)
+	if err != nil {
+		return false
+	}
+	for _, x := range crossposts {
+		if x.Accepted && canViewChannel(s, x.ChannelUsername, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// postNeedsReview is a helper function that reports whether edits of the given user to the
+// post have to go through the review of the admins of its channel.
+func postNeedsReview(s *Setup, p *post.Post, username string) bool {
+	return !isChannelAdmin(s, p.OriginChannel, username)
+}
+
 // GET: /posts/:id ...getpost(id)
 // getPost returns a handler for GET /posts/{id} requests
 func getPost(d *Setup) func(w http.ResponseWriter, r *http.Request) {
@@ -56,9 +101,32 @@ func getPost(d *Setup) func(w http.ResponseWriter, r *http.Request) {
 			id := uint(id)
 			d.Logger.Printf("trying to fetch Post %d", id)
 			rel, err := d.PostService.GetPost(id)
+			if err == nil && !canViewChannel(d, rel.OriginChannel, r.Header.Get("authorized_username")) {
+				err = post.ErrPostNotFound
+			}
+			if err == nil && rel.Status != post.StatusPublished {
+				// drafts are only visible to their writers and the admins of their channel
+				username := r.Header.Get("authorized_username")
+				if rel.PostedByUsername != username && !isChannelAdmin(d, rel.OriginChannel, username) {
+					err = post.ErrPostNotFound
+				}
+			}
 			switch err {
 			case nil:
 				response.Status = "success"
+				if summary, err := d.PostService.GetPostStarSummary(id, r.Header.Get("authorized_username")); err == nil {
+					rel.Stars = *summary
+				}
+				rel.Views = countView(d, view.TargetPost, int(id), r, rel.Status == post.StatusPublished)
+				rel.Mentions = getMentions(d, mention.SourcePost, int(id))
+				if previews, err := d.UnfurlService.GetPreviews(rel.Description); err == nil {
+					for _, p := range previews {
+						sanitizePreview(p, d)
+					}
+					rel.Previews = previews
+				} else {
+					d.Logger.Printf("fetching of previews of post %d failed because: %v", id, err)
+				}
 				response.Data = *rel
 				d.Logger.Printf("success fetching post %d", id)
 			case post.ErrPostNotFound:
@@ -95,6 +163,7 @@ func postPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				newPost.Title = r.FormValue("title")
 				newPost.Description = r.FormValue("description")
 				newPost.OriginChannel = r.FormValue("channelName")
+				newPost.Status = post.Status(r.FormValue("status"))
 
 			} else {
 				err := json.NewDecoder(r.Body).Decode(newPost)
@@ -105,7 +174,8 @@ func postPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				{"postedByUsername":"username len 5-22 chars",
 				"originChannel":"channel",
 				"title":"title",
-				"description":"description"
+				"description":"description",
+				"status":"draft|published, optional"
 				}`,
 					}
 					s.Logger.Printf("bad update post request")
@@ -174,15 +244,37 @@ func postPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					}*/
 
 			}
+			if response.Data == nil && !canContributeToChannel(s, newPost.OriginChannel, newPost.PostedByUsername) {
+				// this block keeps users other than contributors from posting into private channels
+				s.Logger.Printf("unauthorized post attempt into private channel %s", newPost.OriginChannel)
+				response.Data = jSendFailData{
+					ErrorReason:  "originChannel",
+					ErrorMessage: "channel is private",
+				}
+				writeResponseToWriter(response, w, http.StatusForbidden)
+				return
+			}
 			if response.Data == nil {
 				sanitizePost(newPost, s)
+				// posts by writers who aren't admins of the channel go through review
+				if !isChannelAdmin(s, newPost.OriginChannel, newPost.PostedByUsername) {
+					newPost.Status = post.StatusDraft
+				}
 				s.Logger.Printf("trying to add post %s %s %s %s", newPost.PostedByUsername, newPost.Title, newPost.OriginChannel, newPost.Description)
 				pos, err := s.PostService.AddPost(newPost)
 				switch err {
 				case nil:
 					response.Status = "success"
+					pos.Mentions = setMentions(s, mention.SourcePost, int(pos.ID), pos.Description)
 					response.Data = *pos
 					s.Logger.Printf("success adding post %s %s %s %s", pos.PostedByUsername, pos.Title, pos.OriginChannel, pos.Description)
+				case post.ErrInvalidStatus:
+					s.Logger.Printf("adding of post failed because: %v", err)
+					response.Data = jSendFailData{
+						ErrorReason:  "status",
+						ErrorMessage: fmt.Sprintf("status must be either %s or %s", post.StatusDraft, post.StatusPublished),
+					}
+					statusCode = http.StatusBadRequest
 				default:
 					s.Logger.Printf("adding of post failed because: %v", err)
 					response.Status = "error"
@@ -222,9 +314,9 @@ func putPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			statusCode = http.StatusBadRequest
 		} else {
 			id := uint(id)
+			x, err := s.PostService.GetPost(id)
 			{ // this block blocks user updating of post if the poster didn't accessing the route
 
-				x, err := s.PostService.GetPost(id)
 				if err == nil {
 					if x.PostedByUsername != r.Header.Get("authorized_username") {
 						s.Logger.Printf("unauthorized update post attempt")
@@ -273,7 +365,9 @@ func putPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			{"poster":"username len 5-22 chars",
 			"originChannel":"channel",
 			"title":"title",
-			"description":"description"
+			"description":"description",
+			"rating":"maturity rating, optional",
+			"warnings":["content warning"]
 			}`,
 				}
 				s.Logger.Printf("bad update post request")
@@ -282,7 +376,16 @@ func putPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			if response.Data == nil {
 				// if JSON parsing doesn't fail
 
-				if newPost.PostedByUsername == "" && newPost.OriginChannel == "" && newPost.Title == "" && newPost.Description == "" && len(newPost.ContentsID) == 0 {
+				if (newPost.PostedByUsername != "" && newPost.PostedByUsername != x.PostedByUsername) ||
+					(newPost.OriginChannel != "" && newPost.OriginChannel != x.OriginChannel) {
+					// moving posts between channels or writers would skip the checks they were posted with
+					response.Data = jSendFailData{
+						ErrorReason:  "request",
+						ErrorMessage: "the channel and writer of a post can't be changed",
+					}
+					statusCode = http.StatusBadRequest
+				} else if newPost.PostedByUsername == "" && newPost.OriginChannel == "" && newPost.Title == "" && newPost.Description == "" && len(newPost.ContentsID) == 0 &&
+					newPost.Rating == "" && newPost.Warnings == nil {
 					response.Data = jSendFailData{
 						ErrorReason:  "request",
 						ErrorMessage: "request doesn't contain updatable data",
@@ -290,11 +393,13 @@ func putPost(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					statusCode = http.StatusBadRequest
 				} else {
 					sanitizePost(newPost, s)
-					pos, erron := s.PostService.UpdatePost(newPost, id)
+					username := r.Header.Get("authorized_username")
+					pos, erron := s.PostService.UpdatePost(newPost, id, username, postNeedsReview(s, x, username))
 					switch erron {
 					case nil:
 						s.Logger.Printf("success put post %s %s %s %s %s", idRaw, pos.PostedByUsername, pos.OriginChannel, pos.Title, pos.Description)
 						response.Status = "success"
+						pos.Mentions = setMentions(s, mention.SourcePost, int(pos.ID), pos.Description)
 						response.Data = *pos
 
 					case post.ErrPostNotFound:
@@ -386,6 +491,9 @@ func deletePost(d *Setup) func(w http.ResponseWriter, r *http.Request) {
 			switch err {
 			case nil:
 				response.Status = "success"
+				if err := d.MentionService.DeleteMentions(mention.SourcePost, int(id)); err != nil {
+					d.Logger.Printf("deletion of mentions of post %d failed because: %v", id, err)
+				}
 				d.Logger.Printf("success deleting Post %d", id)
 			case post.ErrPostNotFound:
 				d.Logger.Printf("deletion of Post failed because: %v", err)
@@ -432,6 +540,9 @@ func getPostReleases(d *Setup) func(w http.ResponseWriter, r *http.Request) {
 			id := uint(id)
 			d.Logger.Printf("trying to fetch Post %d", id)
 			pos, err := d.PostService.GetPost(id)
+			if err == nil && !canViewPost(d, id, r.Header.Get("authorized_username")) {
+				err = post.ErrPostNotFound
+			}
 			switch err {
 			case nil:
 				response.Status = "success"
@@ -487,12 +598,21 @@ func getPostComments(d *Setup) func(w http.ResponseWriter, r *http.Request) {
 			id := uint(id)
 			d.Logger.Printf("trying to fetch Post %d", id)
 			pos, err := d.PostService.GetPost(id)
+			if err == nil && !canViewPost(d, id, r.Header.Get("authorized_username")) {
+				err = post.ErrPostNotFound
+			}
 			switch err {
 			case nil:
 				response.Status = "success"
 				pComments := make([]interface{}, 0)
+				viewer := r.Header.Get("authorized_username")
 				for _, cID := range pos.CommentsID {
 					if temp, err := d.CommentService.GetComment(cID); err == nil {
+						if temp.Commenter != viewer {
+							if b, err := d.ChannelService.GetBlockedUser(pos.OriginChannel, temp.Commenter); err == nil && b.Mode == channel.BlockModeMute {
+								continue
+							}
+						}
 						pComments = append(pComments, temp)
 					} else {
 						pComments = append(pComments, cID)
@@ -586,7 +706,7 @@ func getPosts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 		}
 		// if queries are clean
 		if response.Data == nil {
-			posts, err := s.PostService.SearchPost(pattern, sortBy, sortOrder, limit, offset)
+			posts, err := s.PostService.SearchPost(pattern, sortBy, sortOrder, limit, offset, contentFilterOf(s, r.Header.Get("authorized_username")))
 			if err != nil {
 				s.Logger.Printf("fetching of post failed because: %v", err)
 				response.Status = "error"
@@ -602,7 +722,7 @@ func getPosts(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-//GET: /posts/:id/stars
+//GET: /posts/:id/stars?limit=25&offset=0
 // getPostStars returns a handler for GET: /posts/:id/stars requests
 func getPostStars(d *Setup) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -622,25 +742,47 @@ func getPostStars(d *Setup) func(w http.ResponseWriter, r *http.Request) {
 			statusCode = http.StatusBadRequest
 		}
 
+		limit := 25
+		offset := 0
+		{ // this block reads the query strings if any
+			if limitPageRaw := r.URL.Query().Get("limit"); limitPageRaw != "" {
+				limit, err = strconv.Atoi(limitPageRaw)
+				if err != nil || limit < 0 {
+					d.Logger.Printf("bad get post stars request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					d.Logger.Printf("bad get post stars request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
 		if response.Data == nil {
 			id := uint(id)
-			d.Logger.Printf("trying to fetch Post %d", id)
-			pos, err := d.PostService.GetPost(id)
+			d.Logger.Printf("trying to fetch stars of Post %d", id)
+			var stars []*post.Star
+			if !canViewPost(d, id, r.Header.Get("authorized_username")) {
+				err = post.ErrPostNotFound
+			} else {
+				stars, err = d.PostService.GetPostStars(id, limit, offset)
+			}
 			switch err {
 			case nil:
-
 				response.Status = "success"
-				pStars := make([]interface{}, 0)
-				for username := range pos.Stars {
-					if temp, err := d.PostService.GetPostStar(id, username); err == nil {
-						pStars = append(pStars, temp)
-					} else {
-						pStars = append(pStars, username)
-					}
-				}
-				response.Data = pStars
+				response.Data = stars
 				d.Logger.Printf("success fetching stars of post %d", id)
-
 			case post.ErrPostNotFound:
 				d.Logger.Printf("fetching of post failed because: %v", post.ErrPostNotFound)
 				response.Data = jSendFailData{
@@ -649,11 +791,72 @@ func getPostStars(d *Setup) func(w http.ResponseWriter, r *http.Request) {
 				}
 				statusCode = http.StatusNotFound
 			default:
-				d.Logger.Printf("fetching of post failed because: %v", err)
+				d.Logger.Printf("fetching of post stars failed because: %v", err)
 				response.Status = "error"
-				response.Message = "server error when fetching post"
+				response.Message = "server error when fetching post stars"
 				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+//GET: /leaderboard?window=week&limit=25&offset=0
+// getLeaderboard returns a handler for GET: /leaderboard requests listing the top rated posts
+// of the past week, month or of all time
+func getLeaderboard(d *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
 
+		window := post.LeaderboardWindow(r.URL.Query().Get("window"))
+		limit := 25
+		offset := 0
+		{ // this block reads the query strings if any
+			if limitPageRaw := r.URL.Query().Get("limit"); limitPageRaw != "" {
+				limit, err = strconv.Atoi(limitPageRaw)
+				if err != nil || limit < 0 {
+					d.Logger.Printf("bad get leaderboard request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					d.Logger.Printf("bad get leaderboard request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil {
+			posts, err := d.PostService.GetTopRatedPosts(window, limit, offset, contentFilterOf(d, r.Header.Get("authorized_username")))
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = posts
+				d.Logger.Printf("success fetching %s leaderboard", window)
+			case post.ErrInvalidLeaderboardWindow:
+				response.Data = jSendFailData{
+					ErrorReason:  "window",
+					ErrorMessage: "window must be one of week, month or all",
+				}
+				statusCode = http.StatusBadRequest
+			default:
+				d.Logger.Printf("fetching of leaderboard failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching leaderboard"
+				statusCode = http.StatusInternalServerError
 			}
 		}
 		writeResponseToWriter(response, w, statusCode)
@@ -686,6 +889,9 @@ func getPostStar(d *Setup) func(w http.ResponseWriter, r *http.Request) {
 			id := uint(id)
 			d.Logger.Printf("trying to fetch Star of Post %d and username %s", id, username)
 			st, err := d.PostService.GetPostStar(id, username)
+			if err == nil && !canViewPost(d, id, r.Header.Get("authorized_username")) {
+				err = post.ErrPostNotFound
+			}
 			switch err {
 			case nil:
 				response.Status = "success"
@@ -761,6 +967,18 @@ func putPostStar(s *Setup) func(http.ResponseWriter, *http.Request) {
 						w.WriteHeader(http.StatusUnauthorized)
 						return
 					}
+					if !canViewPost(s, id, username) {
+						s.Logger.Printf("post Star request on private channel post")
+						addCors(w)
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
+					if postBlockMode(s, id, username) == channel.BlockModeBlock {
+						s.Logger.Printf("post Star request by blocked user")
+						addCors(w)
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
 				}
 				if st.NumOfStars == 0 {
 					errs := s.PostService.DeletePostStar(id, username)
@@ -0,0 +1,294 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/reaction"
+)
+
+// reactionTargetFromRequest is a helper function that reads the entity the reactions
+// request is made on from the route parameters
+func reactionTargetFromRequest(vars map[string]string) (reaction.TargetType, int, string, error) {
+	var targetType reaction.TargetType
+	var key string
+	if _, found := vars["commentID"]; found {
+		targetType, key = reaction.TargetComment, "commentID"
+	} else if _, found := vars["postID"]; found {
+		targetType, key = reaction.TargetPost, "postID"
+	} else {
+		targetType, key = reaction.TargetRelease, "id"
+	}
+	id, err := strconv.Atoi(vars[key])
+	return targetType, id, key, err
+}
+
+// reactionTargetChannel is a helper function that returns the channel the target of
+// reactions belongs to. An empty string is returned if the target isn't found.
+func reactionTargetChannel(s *Setup, targetType reaction.TargetType, targetID int) string {
+	postID := targetID
+	switch targetType {
+	case reaction.TargetRelease:
+		rel, err := s.ReleaseService.GetRelease(targetID)
+		if err != nil {
+			return ""
+		}
+		return rel.OwnerChannel
+	case reaction.TargetComment:
+		c, err := s.CommentService.GetComment(targetID)
+		if err != nil {
+			return ""
+		}
+		postID = c.OriginPost
+	}
+	p, err := s.PostService.GetPost(uint(postID))
+	if err != nil {
+		return ""
+	}
+	return p.OriginChannel
+}
+
+// canViewReactionTarget is a helper function that checks if the given user can see the target of reactions.
+func canViewReactionTarget(s *Setup, targetType reaction.TargetType, targetID int, username string) bool {
+	switch targetType {
+	case reaction.TargetPost:
+		return canViewPost(s, uint(targetID), username)
+	case reaction.TargetComment:
+		c, err := s.CommentService.GetComment(targetID)
+		return err != nil || canViewPost(s, uint(c.OriginPost), username)
+	}
+	return canViewChannel(s, reactionTargetChannel(s, targetType, targetID), username)
+}
+
+// getReactionEmojis returns a handler for GET /reactions requests
+func getReactionEmojis(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "success"
+		response.Data = s.ReactionService.GetEmojis()
+		writeResponseToWriter(response, w, http.StatusOK)
+	}
+}
+
+// getReactions returns a handler for GET /posts/{postID}/reactions requests
+// it also handles /posts/{postID}/comments/{commentID}/reactions and /releases/{id}/reactions requests
+func getReactions(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		targetType, targetID, key, err := reactionTargetFromRequest(vars)
+		if err != nil {
+			s.Logger.Printf("fetch reactions attempt of invalid %s %s", targetType, vars[key])
+			response.Data = jSendFailData{
+				ErrorReason:  key,
+				ErrorMessage: fmt.Sprintf("invalid %s %s", key, vars[key]),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			username := r.Header.Get("authorized_username")
+			var counts []*reaction.Count
+			if !canViewReactionTarget(s, targetType, targetID, username) {
+				err = reaction.ErrTargetNotFound
+			} else {
+				counts, err = s.ReactionService.GetCounts(targetType, targetID, username)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = counts
+				s.Logger.Printf("success fetching reactions of %s %d", targetType, targetID)
+			case reaction.ErrTargetNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  key,
+					ErrorMessage: fmt.Sprintf("%s of %s %d not found", targetType, key, targetID),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of reactions failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching reactions"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getReactors returns a handler for GET /posts/{postID}/reactions/users?emoji=👍&limit=25&offset=0 requests
+// it also handles the same route under comments and releases
+func getReactors(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		targetType, targetID, key, err := reactionTargetFromRequest(vars)
+		if err != nil {
+			s.Logger.Printf("fetch reactors attempt of invalid %s %s", targetType, vars[key])
+			response.Data = jSendFailData{
+				ErrorReason:  key,
+				ErrorMessage: fmt.Sprintf("invalid %s %s", key, vars[key]),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		emoji := r.URL.Query().Get("emoji")
+		limit := 25
+		offset := 0
+		{ // this block reads the query strings if any
+			if limitPageRaw := r.URL.Query().Get("limit"); limitPageRaw != "" {
+				limit, err = strconv.Atoi(limitPageRaw)
+				if err != nil || limit < 0 {
+					s.Logger.Printf("bad get reactors request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					s.Logger.Printf("bad get reactors request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil {
+			var reactions []*reaction.Reaction
+			if !canViewReactionTarget(s, targetType, targetID, r.Header.Get("authorized_username")) {
+				err = reaction.ErrTargetNotFound
+			} else {
+				reactions, err = s.ReactionService.GetReactors(targetType, targetID, emoji, limit, offset)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = reactions
+				s.Logger.Printf("success fetching reactors of %s %d", targetType, targetID)
+			case reaction.ErrInvalidEmoji:
+				response.Data = jSendFailData{
+					ErrorReason:  "emoji",
+					ErrorMessage: "emoji not accepted, see /reactions for the accepted set",
+				}
+				statusCode = http.StatusBadRequest
+			case reaction.ErrTargetNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  key,
+					ErrorMessage: fmt.Sprintf("%s of %s %d not found", targetType, key, targetID),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of reactors failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching reactors"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postReaction returns a handler for POST /posts/{postID}/reactions requests that toggle
+// the reaction of the requesting user. It also handles the same route under comments and releases
+func postReaction(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		targetType, targetID, key, err := reactionTargetFromRequest(vars)
+		if err != nil {
+			s.Logger.Printf("react attempt on invalid %s %s", targetType, vars[key])
+			response.Data = jSendFailData{
+				ErrorReason:  key,
+				ErrorMessage: fmt.Sprintf("invalid %s %s", key, vars[key]),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		rc := &reaction.Reaction{TargetType: targetType, TargetID: targetID}
+		if response.Data == nil {
+			err = json.NewDecoder(r.Body).Decode(rc)
+			if err != nil || rc.Emoji == "" {
+				response.Data = jSendFailData{
+					ErrorReason: "request format",
+					ErrorMessage: `bad request, use format
+				{"emoji":"one of the emojis listed at /reactions"}`,
+				}
+				s.Logger.Printf("bad post reaction request")
+				statusCode = http.StatusBadRequest
+			}
+		}
+
+		if response.Data == nil {
+			rc.TargetType, rc.TargetID = targetType, targetID
+			rc.Username = r.Header.Get("authorized_username")
+			{ // this block secures the route
+				channelUsername := reactionTargetChannel(s, targetType, targetID)
+				if !canViewReactionTarget(s, targetType, targetID, rc.Username) {
+					s.Logger.Printf("post reaction request on private channel content")
+					addCors(w)
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				if b, err := s.ChannelService.GetBlockedUser(channelUsername, rc.Username); err == nil && b.Mode == channel.BlockModeBlock {
+					s.Logger.Printf("post reaction request by blocked user")
+					addCors(w)
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+			}
+			reacted, err := s.ReactionService.ToggleReaction(rc)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = struct {
+					Emoji   string `json:"emoji"`
+					Reacted bool   `json:"reacted"`
+				}{rc.Emoji, reacted}
+				s.Logger.Printf("success toggling reaction %s of %s on %s %d", rc.Emoji, rc.Username, targetType, targetID)
+			case reaction.ErrInvalidEmoji:
+				response.Data = jSendFailData{
+					ErrorReason:  "emoji",
+					ErrorMessage: "emoji not accepted, see /reactions for the accepted set",
+				}
+				statusCode = http.StatusBadRequest
+			case reaction.ErrTargetNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  key,
+					ErrorMessage: fmt.Sprintf("%s of %s %d not found", targetType, key, targetID),
+				}
+				statusCode = http.StatusNotFound
+			case reaction.ErrUserNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: "user not found",
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("toggling of reaction failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when toggling reaction"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
@@ -3,15 +3,110 @@ package rest
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/audio"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/tag"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/view"
 	"net/http"
-	"net/url"
 	"os"
 	"strconv"
 	"strings"
+
+	"gopkg.in/russross/blackfriday.v2"
 )
 
+// serveRelease is a helper function that prepares releases to be sent to clients. It turns
+// the image names of image and comic releases into urls they can be fetched from, along with
+// the variants generated for them, renders the content of text releases into sanitized HTML
+// and points audio releases to the address their file is streamed from.
+// Pages are copied as they might be shared with the cached release.
+func serveRelease(s *Setup, rel *release.Release) {
+	switch rel.Type {
+	case release.Image, release.Comic:
+		rel.Variants = imageVariants(s, rel.Content)
+		rel.Content = imageURL(s, rel.Content)
+	case release.Text:
+		rel.HTML = renderReleaseText(s, rel)
+	case release.Audio:
+		rel.Content = fmt.Sprintf("%s/releases/%d/audio", s.HostAddress, rel.ID)
+	}
+	if rel.Pages != nil {
+		pages := make([]*release.Page, len(rel.Pages))
+		for i, p := range rel.Pages {
+			page := *p
+			page.Variants = imageVariants(s, page.Image)
+			page.Image = imageURL(s, page.Image)
+			pages[i] = &page
+		}
+		rel.Pages = pages
+	}
+}
+
+// creditsErrorMessage describes what credits should look like
+const creditsErrorMessage = "credits should have a role of 'writer', 'artist', 'colorist', 'translator' or 'editor' and either the username of the credited user or the name of someone off issue#1"
+
+// genresErrorMessage describes what genres should look like
+var genresErrorMessage = fmt.Sprintf("genres, the defining one included, can't be longer than %d characters", tag.MaxNameLength)
+
+// canViewRelease is a helper function that checks if the given user can see the release. Official
+// releases of channels the user can view are visible to them while the rest are only visible to the
+// admins of their channels.
+func canViewRelease(s *Setup, rel *release.Release, username string) (bool, error) {
+	c, err := s.ChannelService.GetChannel(rel.OwnerChannel)
+	switch err {
+	case nil:
+	case channel.ErrChannelNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+	for _, relID := range c.OfficialReleaseIDs {
+		if uint(rel.ID) == relID && canViewChannel(s, c.ChannelUsername, username) {
+			return true, nil
+		}
+	}
+	return isChannelAdmin(s, c.ChannelUsername, username), nil
+}
+
+// releaseLanguages is a helper function that lists the languages the work of the release is
+// available in to the given user, leaving out the releases the viewer can't see. Nothing is listed
+// for works available in a single language.
+func releaseLanguages(s *Setup, rel *release.Release, username string) []*release.Variant {
+	variants, err := s.ReleaseService.GetLanguages(rel.ID)
+	if err != nil {
+		s.Logger.Printf("fetching of languages of release %d failed because: %v", rel.ID, err)
+		return nil
+	}
+	visible := make([]*release.Variant, 0, len(variants))
+	for _, v := range variants {
+		if v.ReleaseID != rel.ID {
+			variant, err := s.ReleaseService.GetRelease(v.ReleaseID)
+			if err != nil {
+				continue
+			}
+			if ok, err := canViewRelease(s, variant, username); err != nil || !ok {
+				continue
+			}
+		}
+		visible = append(visible, v)
+	}
+	if len(visible) < 2 {
+		return nil
+	}
+	return visible
+}
+
+// renderReleaseText is a helper function that renders the content of text releases,
+// written in their format, into sanitized HTML
+func renderReleaseText(s *Setup, rel *release.Release) string {
+	content := []byte(rel.Content)
+	if rel.Format == release.FormatMarkdown {
+		content = blackfriday.Run(content, blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	}
+	return string(s.MarkupSanitizer.SanitizeBytes(content))
+}
+
 // postRelease returns a handler for POST /releases requests
 func postRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -19,8 +114,13 @@ func postRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 		response.Status = "fail"
 		statusCode := http.StatusCreated
 
+		if !limitUploadSize(s, w, r, maxReleaseUploadSize) {
+			return
+		}
+
 		newRelease := new(release.Release)
 		var tmpFile *os.File
+		var tmpPages []*os.File
 		{ // this block parses the JSON part of the request
 			err := json.Unmarshal([]byte(r.PostFormValue("JSON")), newRelease)
 			if err != nil {
@@ -28,7 +128,7 @@ func postRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				if err != nil {
 					response.Data = jSendFailData{
 						ErrorReason:  "request format",
-						ErrorMessage: "use multipart for for posting Image Releases. A part named 'JSON' with format\r\n{\n  \"ownerChannel\": \"ownerChannel\",\n  \"type\": \"image or text\",\n  \"content\": \"content if type is text\",\n  \"metadata\": {\n    \"title\": \"title\",\n    \"releaseDate\": \"unix timestamp\",\n    \"genreDefining\": \"genreDefining\",\n    \"description\": \"description\",\n    \"Other\": { \"authors\": [], \"genres\": [] }\n  }\n}\nfor Release data and a file called 'image' if release is of image type. We accept JPG/PNG formats.",
+						ErrorMessage: "use multipart for for posting Image Releases. A part named 'JSON' with format\r\n{\n  \"ownerChannel\": \"ownerChannel\",\n  \"translationOf\": { \"originalID\": \"id of the release translated, if a translation\" },\n  \"type\": \"image, comic, text or audio\",\n  \"content\": \"content if type is text\",\n  \"format\": \"html or markdown if type is text\",\n  \"metadata\": {\n    \"title\": \"title\",\n    \"releaseDate\": \"unix timestamp\",\n    \"genreDefining\": \"genreDefining\",\n    \"description\": \"description\",\n    \"language\": \"language tag like en\",\n    \"credits\": [{ \"role\": \"writer\", \"username\": \"username or\", \"name\": \"name\" }],\n    \"Other\": { \"genres\": [] }\n  }\n}\nfor Release data and a file called 'image' if release is of image type or files called 'pages', in order, if it's of comic type or a file called 'audio' if it's of audio type. We accept JPG/PNG images and MP3/OGG/OPUS audio.",
 					}
 					statusCode = http.StatusBadRequest
 				}
@@ -91,7 +191,7 @@ func postRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 							defer tmpFile.Close()
 							defer os.Remove(tmpFile.Name())
 							s.Logger.Printf(fmt.Sprintf("temp file saved: %s", tmpFile.Name()))
-							newRelease.Content = generateFileNameForStorage(fileName, "release")
+							newRelease.Content = fileName
 						case errUnacceptedType:
 							response.Data = jSendFailData{
 								ErrorMessage: "image-type",
@@ -110,6 +210,71 @@ func postRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 							response.Message = "server error when adding release"
 
 						}
+					case release.Comic:
+						tmpFiles, fileNames, err := saveImagesFromRequest(r, "pages")
+						switch err {
+						case nil:
+							tmpPages = tmpFiles
+							defer removeTempFiles(tmpPages)
+							// alt texts can be sent in order along the JSON part
+							altTexts := newRelease.Pages
+							newRelease.Pages = make([]*release.Page, len(fileNames))
+							for i, fileName := range fileNames {
+								newRelease.Pages[i] = &release.Page{Image: fileName}
+								if i < len(altTexts) && altTexts[i] != nil {
+									newRelease.Pages[i].AltText = s.StrictSanitizer.Sanitize(altTexts[i].AltText)
+								}
+							}
+						case errUnacceptedType:
+							response.Data = jSendFailData{
+								ErrorReason:  "image-type",
+								ErrorMessage: "only types image/jpeg & image/png are accepted",
+							}
+							statusCode = http.StatusBadRequest
+						case errReadingFromImage:
+							response.Data = jSendFailData{
+								ErrorReason:  "pages",
+								ErrorMessage: "unable to read page files\nuse multipart-form for for posting Comic Releases. A part named 'JSON' for Release data \nand files called 'pages' of image type JPG/PNG.",
+							}
+							statusCode = http.StatusBadRequest
+						default:
+							s.Logger.Printf("adding of release failed during page parsing because: %v", err)
+							response.Status = "error"
+							response.Message = "server error when adding release"
+						}
+					case release.Audio:
+						var fileName string
+						var info *audio.Info
+						var err error
+						tmpFile, fileName, info, err = saveAudioFromRequest(r, "audio")
+						switch err {
+						case nil:
+							defer tmpFile.Close()
+							defer os.Remove(tmpFile.Name())
+							s.Logger.Printf(fmt.Sprintf("temp file saved: %s", tmpFile.Name()))
+							newRelease.Content = fileName
+							newRelease.Track = &release.Track{
+								ContentType: info.Format.ContentType(),
+								Size:        info.Size,
+								Duration:    info.Duration,
+							}
+						case errUnacceptedAudioType:
+							response.Data = jSendFailData{
+								ErrorReason:  "audio-type",
+								ErrorMessage: "only MP3, Ogg Vorbis & Opus audio is accepted",
+							}
+							statusCode = http.StatusBadRequest
+						case errReadingFromAudio:
+							response.Data = jSendFailData{
+								ErrorReason:  "audio",
+								ErrorMessage: "unable to read audio file\nuse multipart-form for for posting Audio Releases. A part named 'JSON' for Release data \nand a file called 'audio' of format MP3/OGG/OPUS.",
+							}
+							statusCode = http.StatusBadRequest
+						default:
+							s.Logger.Printf("adding of release failed during audio parsing because: %v", err)
+							response.Status = "error"
+							response.Message = "server error when adding release"
+						}
 					case release.Text:
 						if newRelease.Content == "" {
 							response.Data = jSendFailData{
@@ -121,7 +286,7 @@ func postRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					default:
 						statusCode = http.StatusBadRequest
 						response.Data = jSendFailData{
-							ErrorMessage: "type can only be 'text' or 'image'",
+							ErrorMessage: "type can only be 'text', 'image', 'comic' or 'audio'",
 							ErrorReason:  "type",
 						}
 					}
@@ -131,7 +296,17 @@ func postRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					switch err {
 					case nil:
 						if newRelease.Type == release.Image {
-							err := saveTempFilePermanentlyToPath(tmpFile, s.ImageStoragePath+newRelease.Content)
+							err := saveImagePermanently(s, tmpFile, newRelease.Content)
+							if err != nil {
+								s.Logger.Printf("adding of release failed because: %v", err)
+								response.Status = "error"
+								response.Message = "server error when adding release"
+								statusCode = http.StatusInternalServerError
+								_ = s.ReleaseService.DeleteRelease(newRelease.ID)
+							}
+						}
+						if newRelease.Type == release.Audio {
+							err := saveAudioPermanently(s, tmpFile, newRelease.Content, newRelease.Track.ContentType)
 							if err != nil {
 								s.Logger.Printf("adding of release failed because: %v", err)
 								response.Status = "error"
@@ -140,14 +315,72 @@ func postRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 								_ = s.ReleaseService.DeleteRelease(newRelease.ID)
 							}
 						}
+						if newRelease.Type == release.Comic {
+							for i, page := range newRelease.Pages {
+								if i >= len(tmpPages) {
+									break
+								}
+								err := saveImagePermanently(s, tmpPages[i], page.Image)
+								if err != nil {
+									s.Logger.Printf("adding of release failed because: %v", err)
+									response.Status = "error"
+									response.Message = "server error when adding release"
+									statusCode = http.StatusInternalServerError
+									_ = s.ReleaseService.DeleteRelease(newRelease.ID)
+									break
+								}
+							}
+						}
 						if response.Message == "" {
 							response.Status = "success"
-							if newRelease.Type == release.Image {
-								newRelease.Content = s.HostAddress + s.ImageServingRoute + url.PathEscape(newRelease.Content)
-							}
+							serveRelease(s, newRelease)
 							response.Data = *newRelease
 							s.Logger.Printf("success adding release %d to channel %s", newRelease.ID, newRelease.OwnerChannel)
 						}
+					case release.ErrInvalidFormat:
+						s.Logger.Printf("bad add release request, format")
+						response.Data = jSendFailData{
+							ErrorReason:  "format",
+							ErrorMessage: "format can only be 'html' or 'markdown' and only text releases have one",
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrInvalidCredit:
+						s.Logger.Printf("bad add release request, credits")
+						response.Data = jSendFailData{
+							ErrorReason:  "credits",
+							ErrorMessage: creditsErrorMessage,
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrCreditedUserNotFound:
+						response.Data = jSendFailData{
+							ErrorReason:  "credits",
+							ErrorMessage: "credited user not found",
+						}
+						statusCode = http.StatusNotFound
+					case release.ErrInvalidGenre:
+						response.Data = jSendFailData{
+							ErrorReason:  "genres",
+							ErrorMessage: genresErrorMessage,
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrInvalidLanguage:
+						response.Data = jSendFailData{
+							ErrorReason:  "language",
+							ErrorMessage: "language should be a language tag like 'en' or 'pt-BR' and translations need one different from that of the original",
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrInvalidTranslation:
+						response.Data = jSendFailData{
+							ErrorReason:  "translationOf",
+							ErrorMessage: "translations can only be of other existing releases and releases with translations can't be translations themselves",
+						}
+						statusCode = http.StatusBadRequest
+					case release.ErrTranslatorNotCredited:
+						response.Data = jSendFailData{
+							ErrorReason:  "credits",
+							ErrorMessage: "translations should credit their translator",
+						}
+						statusCode = http.StatusBadRequest
 					case release.ErrSomeReleaseDataNotPersisted:
 						fallthrough
 					default:
@@ -190,9 +423,7 @@ func getRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			rel, err := s.ReleaseService.GetRelease(id)
 			switch err {
 			case nil:
-				if rel.Type == release.Image {
-					rel.Content = s.HostAddress + s.ImageServingRoute + url.PathEscape(rel.Content)
-				}
+				serveRelease(s, rel)
 				// TODO secure route
 				{ // this block sanitizes the returned User if it's not the user herself accessing the route
 					c, err := s.ChannelService.GetChannel(rel.OwnerChannel)
@@ -205,8 +436,14 @@ func getRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 								isOfficial = true
 							}
 						}
+						if isOfficial && !canViewChannel(s, rel.OwnerChannel, r.Header.Get("authorized_username")) {
+							// official releases of private channels are only visible to members
+							isOfficial = false
+						}
 						if isOfficial { // return the release if official
 							response.Status = "success"
+							rel.Views = countView(s, view.TargetRelease, id, r, true)
+							rel.Languages = releaseLanguages(s, rel, r.Header.Get("authorized_username"))
 							response.Data = *rel
 							s.Logger.Printf("success fetching release %d from an offical catalog", id)
 							break
@@ -221,9 +458,8 @@ func getRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 						}
 						if isAdmin {
 							response.Status = "success"
-							if rel.Type == release.Image {
-								rel.Content = s.HostAddress + s.ImageServingRoute + url.PathEscape(rel.Content)
-							}
+							rel.Views = countView(s, view.TargetRelease, id, r, false)
+							rel.Languages = releaseLanguages(s, rel, r.Header.Get("authorized_username"))
 							response.Data = *rel
 							s.Logger.Printf("success fetching release %d from an offical catalog", id)
 							break
@@ -330,7 +566,7 @@ func getReleases(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 		}
 		// if queries are clean
 		if response.Data == nil {
-			releases, err := s.ReleaseService.SearchRelease(pattern, sortBy, sortOrder, limit, offset)
+			releases, err := s.ReleaseService.SearchRelease(pattern, sortBy, sortOrder, limit, offset, contentFilterOf(s, r.Header.Get("authorized_username")))
 			if err != nil {
 				s.Logger.Printf("fetching of releases failed because: %v", err)
 				response.Status = "error"
@@ -339,9 +575,7 @@ func getReleases(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 			} else {
 				response.Status = "success"
 				for _, rel := range releases {
-					if rel.Type == release.Image {
-						rel.Content = s.HostAddress + s.ImageServingRoute + url.PathEscape(rel.Content)
-					}
+					serveRelease(s, rel)
 				}
 				response.Data = releases
 				s.Logger.Printf("success fetching releases")
@@ -390,6 +624,9 @@ func patchRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 						w.WriteHeader(http.StatusUnauthorized)
 						return
 					}
+					if !limitUploadSize(s, w, r, maxReleaseUploadSize) {
+						return
+					}
 					rel := new(release.Release)
 					var tmpFile *os.File
 					{ // this block parses the JSON part of the request
@@ -400,7 +637,7 @@ func patchRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 								// TODO send back format
 								response.Data = jSendFailData{
 									ErrorReason:  "message",
-									ErrorMessage: "use multipart for for posting Image Releases. A part named 'JSON' for Release data \r\nand a file called 'image' if release is of image type JPG/PNG.",
+									ErrorMessage: "use multipart for for posting Image Releases. A part named 'JSON' for Release data \r\nand a file called 'image' if release is of image type JPG/PNG or a file called 'audio', along a type of 'audio', if it's of audio type MP3/OGG/OPUS.",
 								}
 								statusCode = http.StatusBadRequest
 							}
@@ -408,6 +645,36 @@ func patchRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 							{ // this block extracts the image file if necessary
 								switch rel.Type {
 								case release.Text:
+								case release.Audio:
+									var fileName string
+									var info *audio.Info
+									var err error
+									tmpFile, fileName, info, err = saveAudioFromRequest(r, "audio")
+									switch err {
+									case nil:
+										defer os.Remove(tmpFile.Name())
+										defer tmpFile.Close()
+										s.Logger.Printf(fmt.Sprintf("temp file saved: %s", tmpFile.Name()))
+										rel.Content = fileName
+										rel.Track = &release.Track{
+											ContentType: info.Format.ContentType(),
+											Size:        info.Size,
+											Duration:    info.Duration,
+										}
+									case errUnacceptedAudioType:
+										response.Data = jSendFailData{
+											ErrorReason:  "audio-type",
+											ErrorMessage: "only MP3, Ogg Vorbis & Opus audio is accepted",
+										}
+										statusCode = http.StatusBadRequest
+									case errReadingFromAudio:
+										// the file of audio releases is optional on updates
+										s.Logger.Printf("audio not found on put request")
+									default:
+										response.Status = "error"
+										response.Message = "server error when updating release"
+										statusCode = http.StatusInternalServerError
+									}
 								case release.Image:
 									fallthrough
 								default:
@@ -420,7 +687,7 @@ func patchRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 										defer os.Remove(tmpFile.Name())
 										defer tmpFile.Close()
 										s.Logger.Printf(fmt.Sprintf("temp file saved: %s", tmpFile.Name()))
-										rel.Content = generateFileNameForStorage(fileName, "release")
+										rel.Content = fileName
 										rel.Type = release.Image
 									case errUnacceptedType:
 										response.Data = jSendFailData{
@@ -449,17 +716,15 @@ func patchRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					// if JSON parsing doesn't fail
 					if response.Data == nil {
 						if rel.Content == "" && rel.Title == "" && rel.GenreDefining == "" &&
-							rel.Description == "" && len(rel.Genres) == 0 && len(rel.Authors) == 0 &&
-							rel.OwnerChannel == "" {
+							rel.Description == "" && len(rel.Genres) == 0 && rel.Credits == nil && rel.Language == "" && rel.TranslationOf == nil &&
+							rel.OwnerChannel == "" && rel.Format == "" {
 							//no patchable data found
 							rel, err = s.ReleaseService.GetRelease(id)
 							switch err {
 							case nil:
 								s.Logger.Printf("success patch release at id %d: no new data found on request", id)
 								response.Status = "success"
-								if rel.Type == release.Image {
-									rel.Content = s.HostAddress + s.ImageServingRoute + url.PathEscape(rel.Content)
-								}
+								serveRelease(s, rel)
 								response.Data = *rel
 							default:
 								s.Logger.Printf("update of user failed because: %v", err)
@@ -470,11 +735,11 @@ func patchRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 						}
 						if response.Data == nil {
 							rel.ID = id
-							rel, err = s.ReleaseService.UpdateRelease(rel)
+							rel, err = s.ReleaseService.UpdateRelease(rel, r.Header.Get("authorized_username"))
 							switch err {
 							case nil:
 								if rel.Type == release.Image {
-									err := saveTempFilePermanentlyToPath(tmpFile, s.ImageStoragePath+rel.Content)
+									err := saveImagePermanently(s, tmpFile, rel.Content)
 									if err != nil {
 										s.Logger.Printf("updating of release failed because: %v", err)
 										response.Status = "error"
@@ -483,12 +748,19 @@ func patchRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 										_ = s.ReleaseService.DeleteRelease(rel.ID)
 									}
 								}
+								if rel.Type == release.Audio && tmpFile != nil {
+									err := saveAudioPermanently(s, tmpFile, rel.Content, rel.Track.ContentType)
+									if err != nil {
+										s.Logger.Printf("updating of release failed because: %v", err)
+										response.Status = "error"
+										response.Message = "server error when updating release"
+										statusCode = http.StatusInternalServerError
+									}
+								}
 								if response.Message == "" {
 									s.Logger.Printf("success updating release %d", id)
 									response.Status = "success"
-									if rel.Type == release.Image {
-										rel.Content = s.HostAddress + s.ImageServingRoute + url.PathEscape(rel.Content)
-									}
+									serveRelease(s, rel)
 									response.Data = *rel
 									// TODO delete old image if image updated
 								}
@@ -499,6 +771,50 @@ func patchRelease(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 									ErrorMessage: "release type cannot be changed",
 								}
 								statusCode = http.StatusNotFound
+							case release.ErrInvalidFormat:
+								s.Logger.Printf("bad update release request, format")
+								response.Data = jSendFailData{
+									ErrorReason:  "format",
+									ErrorMessage: "format can only be 'html' or 'markdown' and only text releases have one",
+								}
+								statusCode = http.StatusBadRequest
+							case release.ErrInvalidCredit:
+								s.Logger.Printf("bad update release request, credits")
+								response.Data = jSendFailData{
+									ErrorReason:  "credits",
+									ErrorMessage: creditsErrorMessage,
+								}
+								statusCode = http.StatusBadRequest
+							case release.ErrCreditedUserNotFound:
+								response.Data = jSendFailData{
+									ErrorReason:  "credits",
+									ErrorMessage: "credited user not found",
+								}
+								statusCode = http.StatusNotFound
+							case release.ErrInvalidGenre:
+								response.Data = jSendFailData{
+									ErrorReason:  "genres",
+									ErrorMessage: genresErrorMessage,
+								}
+								statusCode = http.StatusBadRequest
+							case release.ErrInvalidLanguage:
+								response.Data = jSendFailData{
+									ErrorReason:  "language",
+									ErrorMessage: "language should be a language tag like 'en' or 'pt-BR' and translations need one different from that of the original",
+								}
+								statusCode = http.StatusBadRequest
+							case release.ErrInvalidTranslation:
+								response.Data = jSendFailData{
+									ErrorReason:  "translationOf",
+									ErrorMessage: "translations can only be of other existing releases and releases with translations can't be translations themselves",
+								}
+								statusCode = http.StatusBadRequest
+							case release.ErrTranslatorNotCredited:
+								response.Data = jSendFailData{
+									ErrorReason:  "credits",
+									ErrorMessage: "translations should credit their translator",
+								}
+								statusCode = http.StatusBadRequest
 							case release.ErrSomeReleaseDataNotPersisted:
 								fallthrough
 							default:
@@ -0,0 +1,547 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+)
+
+// canEditPost is a helper function that reports whether the given user is the poster
+// of the post under the given id or an admin of the channel it's posted in
+func canEditPost(s *Setup, id uint, username string) bool {
+	p, err := s.PostService.GetPost(id)
+	if err != nil {
+		return false
+	}
+	return p.PostedByUsername == username || isChannelAdmin(s, p.OriginChannel, username)
+}
+
+// canEditRelease is a helper function that reports whether the given user is an admin of
+// the channel owning the release under the given id
+func canEditRelease(s *Setup, id int, username string) bool {
+	rel, err := s.ReleaseService.GetRelease(id)
+	if err != nil {
+		return false
+	}
+	return isChannelAdmin(s, rel.OwnerChannel, username)
+}
+
+// parseRevisionIDs is a helper function that reads the revision ids of the request. The
+// revision diffed against defaults to the latest one when it's not found on the query string.
+func parseRevisionIDs(r *http.Request, vars map[string]string) (int, int, *jSendFailData) {
+	revisionID, err := strconv.Atoi(vars["revisionID"])
+	if err != nil {
+		return 0, 0, &jSendFailData{
+			ErrorReason:  "revisionID",
+			ErrorMessage: fmt.Sprintf("invalid revisionID %s", vars["revisionID"]),
+		}
+	}
+	to := -1
+	if toRaw := r.URL.Query().Get("to"); toRaw != "" {
+		to, err = strconv.Atoi(toRaw)
+		if err != nil {
+			return 0, 0, &jSendFailData{
+				ErrorReason:  "to",
+				ErrorMessage: fmt.Sprintf("invalid revision id %s", toRaw),
+			}
+		}
+	}
+	return revisionID, to, nil
+}
+
+// getPostRevisions returns a handler for GET /posts/{postID}/revisions requests
+func getPostRevisions(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("fetch revisions attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditPost(s, uint(id), r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized get post revisions attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			revisions, err := s.PostService.GetRevisions(uint(id))
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = revisions
+				s.Logger.Printf("success fetching revisions of post %d", id)
+			case post.ErrPostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("post of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of post revisions failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching post revisions"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getPostRevision returns a handler for GET /posts/{postID}/revisions/{revisionID} requests
+func getPostRevision(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("fetch revision attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+		revisionID, _, failData := parseRevisionIDs(r, vars)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditPost(s, uint(id), r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized get post revision attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			revision, err := s.PostService.GetRevision(uint(id), revisionID)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *revision
+				s.Logger.Printf("success fetching revision %d of post %d", revisionID, id)
+			case post.ErrRevisionNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "revisionID",
+					ErrorMessage: fmt.Sprintf("revision of revisionID %d not found", revisionID),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of post revision failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching post revision"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getPostRevisionDiff returns a handler for GET /posts/{postID}/revisions/{revisionID}/diff?to=revisionID requests
+func getPostRevisionDiff(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("diff revisions attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+		from, to, failData := parseRevisionIDs(r, vars)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditPost(s, uint(id), r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized get post revision diff attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			var d *post.RevisionDiff
+			if to == -1 {
+				var revisions []*post.Revision
+				revisions, err = s.PostService.GetRevisions(uint(id))
+				if err == nil && len(revisions) == 0 {
+					err = post.ErrRevisionNotFound
+				}
+				if err == nil {
+					to = revisions[len(revisions)-1].ID
+				}
+			}
+			if err == nil {
+				d, err = s.PostService.DiffRevisions(uint(id), from, to)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *d
+				s.Logger.Printf("success diffing revisions %d and %d of post %d", from, to, id)
+			case post.ErrRevisionNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "revisionID",
+					ErrorMessage: "revision not found",
+				}
+				statusCode = http.StatusNotFound
+			case post.ErrPostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("post of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("diffing of post revisions failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when diffing post revisions"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postPostRevisionRestore returns a handler for POST /posts/{postID}/revisions/{revisionID}/restore requests
+func postPostRevisionRestore(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["postID"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("restore revision attempt of invalid post id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "postID",
+				ErrorMessage: fmt.Sprintf("invalid postID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+		revisionID, _, failData := parseRevisionIDs(r, vars)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			username := r.Header.Get("authorized_username")
+			{ // this block secures the route
+				if !canEditPost(s, uint(id), username) {
+					s.Logger.Printf("unauthorized restore post revision attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			needsReview := true
+			if p, err := s.PostService.GetPost(uint(id)); err == nil {
+				needsReview = postNeedsReview(s, p, username)
+			}
+			p, err := s.PostService.RestoreRevision(uint(id), revisionID, username, needsReview)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *p
+				s.Logger.Printf("success restoring revision %d of post %d", revisionID, id)
+			case post.ErrRevisionNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "revisionID",
+					ErrorMessage: fmt.Sprintf("revision of revisionID %d not found", revisionID),
+				}
+				statusCode = http.StatusNotFound
+			case post.ErrPostNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "postID",
+					ErrorMessage: fmt.Sprintf("post of postID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("restoring of post revision failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when restoring post revision"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getReleaseRevisions returns a handler for GET /releases/{id}/revisions requests
+func getReleaseRevisions(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["id"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("fetch revisions attempt of invalid release id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "releaseID",
+				ErrorMessage: fmt.Sprintf("invalid releaseID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditRelease(s, id, r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized get release revisions attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			revisions, err := s.ReleaseService.GetRevisions(id)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = revisions
+				s.Logger.Printf("success fetching revisions of release %d", id)
+			case release.ErrReleaseNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "releaseID",
+					ErrorMessage: fmt.Sprintf("release of releaseID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of release revisions failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching release revisions"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getReleaseRevision returns a handler for GET /releases/{id}/revisions/{revisionID} requests
+func getReleaseRevision(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["id"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("fetch revision attempt of invalid release id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "releaseID",
+				ErrorMessage: fmt.Sprintf("invalid releaseID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+		revisionID, _, failData := parseRevisionIDs(r, vars)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditRelease(s, id, r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized get release revision attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			revision, err := s.ReleaseService.GetRevision(id, revisionID)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *revision
+				s.Logger.Printf("success fetching revision %d of release %d", revisionID, id)
+			case release.ErrRevisionNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "revisionID",
+					ErrorMessage: fmt.Sprintf("revision of revisionID %d not found", revisionID),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of release revision failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching release revision"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getReleaseRevisionDiff returns a handler for GET /releases/{id}/revisions/{revisionID}/diff?to=revisionID requests
+func getReleaseRevisionDiff(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["id"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("diff revisions attempt of invalid release id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "releaseID",
+				ErrorMessage: fmt.Sprintf("invalid releaseID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+		from, to, failData := parseRevisionIDs(r, vars)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			{ // this block secures the route
+				if !canEditRelease(s, id, r.Header.Get("authorized_username")) {
+					s.Logger.Printf("unauthorized get release revision diff attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			var d *release.RevisionDiff
+			if to == -1 {
+				var revisions []*release.Revision
+				revisions, err = s.ReleaseService.GetRevisions(id)
+				if err == nil && len(revisions) == 0 {
+					err = release.ErrRevisionNotFound
+				}
+				if err == nil {
+					to = revisions[len(revisions)-1].ID
+				}
+			}
+			if err == nil {
+				d, err = s.ReleaseService.DiffRevisions(id, from, to)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *d
+				s.Logger.Printf("success diffing revisions %d and %d of release %d", from, to, id)
+			case release.ErrRevisionNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "revisionID",
+					ErrorMessage: "revision not found",
+				}
+				statusCode = http.StatusNotFound
+			case release.ErrReleaseNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "releaseID",
+					ErrorMessage: fmt.Sprintf("release of releaseID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("diffing of release revisions failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when diffing release revisions"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// postReleaseRevisionRestore returns a handler for POST /releases/{id}/revisions/{revisionID}/restore requests
+func postReleaseRevisionRestore(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["id"]
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("restore revision attempt of invalid release id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "releaseID",
+				ErrorMessage: fmt.Sprintf("invalid releaseID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+		revisionID, _, failData := parseRevisionIDs(r, vars)
+		if failData != nil {
+			response.Data = *failData
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			username := r.Header.Get("authorized_username")
+			{ // this block secures the route
+				if !canEditRelease(s, id, username) {
+					s.Logger.Printf("unauthorized restore release revision attempt")
+					addCors(w)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+			rel, err := s.ReleaseService.RestoreRevision(id, revisionID, username)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *rel
+				s.Logger.Printf("success restoring revision %d of release %d", revisionID, id)
+			case release.ErrRevisionNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "revisionID",
+					ErrorMessage: fmt.Sprintf("revision of revisionID %d not found", revisionID),
+				}
+				statusCode = http.StatusNotFound
+			case release.ErrReleaseNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "releaseID",
+					ErrorMessage: fmt.Sprintf("release of releaseID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("restoring of release revision failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when restoring release revision"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
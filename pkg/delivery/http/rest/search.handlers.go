@@ -7,7 +7,6 @@ import (
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/user"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/search"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
 )
@@ -92,9 +91,10 @@ func getSearch(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				Users    interface{} `json:"users"`
 			}
 			order := string(sortOrder)
+			filter := contentFilterOf(s, r.Header.Get("authorized_username"))
 			successCounter := 0
 			{
-				posts, err := s.PostService.SearchPost(pattern, "", post.SortOrder(order), limit, offset)
+				posts, err := s.PostService.SearchPost(pattern, "", post.SortOrder(order), limit, offset, filter)
 				if err != nil {
 					s.Logger.Printf("searching of posts failed because: %v", err)
 					responseData.Posts = jSendResponse{
@@ -121,8 +121,10 @@ func getSearch(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					for _, u := range users {
 						u.Email = ""
 						u.BookmarkedPosts = nil
+						u.ContentFilter = nil
 						if u.PictureURL != "" {
-							u.PictureURL = s.HostAddress + s.ImageServingRoute + url.PathEscape(u.PictureURL)
+							u.PictureVariants = imageVariants(s, u.PictureURL)
+							u.PictureURL = imageURL(s, u.PictureURL)
 						}
 					}
 					responseData.Users = users
@@ -131,7 +133,7 @@ func getSearch(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 			{
-				releases, err := s.ReleaseService.SearchRelease(pattern, "", release.SortOrder(order), limit, offset)
+				releases, err := s.ReleaseService.SearchRelease(pattern, "", release.SortOrder(order), limit, offset, filter)
 				if err != nil {
 					s.Logger.Printf("searching of releases failed because: %v", err)
 					responseData.Releases = jSendResponse{
@@ -141,9 +143,7 @@ func getSearch(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					}
 				} else {
 					for _, rel := range releases {
-						if rel.Type == release.Image {
-							rel.Content = s.HostAddress + s.ImageServingRoute + url.PathEscape(rel.Content)
-						}
+						serveRelease(s, rel)
 					}
 					responseData.Releases = releases
 					s.Logger.Printf("success searching releases")
@@ -161,11 +161,19 @@ func getSearch(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					}
 				} else {
 					for _, c := range channels {
+						if !canViewChannel(s, c.ChannelUsername, r.Header.Get("authorized_username")) {
+							hideChannelContents(c)
+						}
 						c.AdminUsernames = nil
 						c.ReleaseIDs = nil
 						c.OwnerUsername = ""
 						if c.PictureURL != "" {
-							c.PictureURL = s.HostAddress + s.ImageServingRoute + url.PathEscape(c.PictureURL)
+							c.PictureVariants = imageVariants(s, c.PictureURL)
+							c.PictureURL = imageURL(s, c.PictureURL)
+						}
+						if c.BannerURL != "" {
+							c.BannerVariants = imageVariants(s, c.BannerURL)
+							c.BannerURL = imageURL(s, c.BannerURL)
 						}
 					}
 					responseData.Channels = channels
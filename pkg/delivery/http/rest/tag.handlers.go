@@ -0,0 +1,523 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/tag"
+)
+
+// isTagCurator is a helper function that checks if the given user is one of those
+// trusted to curate the tag taxonomy
+func isTagCurator(s *Setup, username string) bool {
+	if username == "" {
+		return false
+	}
+	for _, curator := range s.TagCurators {
+		if curator == username {
+			return true
+		}
+	}
+	return false
+}
+
+// getTags returns a handler for GET /tags requests that lists the tags of the taxonomy
+// along the number of releases and channels filed under each
+func getTags(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		limit := 25
+		offset := 0
+		sortBy := tag.SortByName
+		{ // this block reads the query strings if any
+			if limitRaw := r.URL.Query().Get("limit"); limitRaw != "" {
+				limit, err = strconv.Atoi(limitRaw)
+				if err != nil || limit < 0 {
+					s.Logger.Printf("bad get tags request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					s.Logger.Printf("bad get tags request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			switch r.URL.Query().Get("sort") {
+			case "releases":
+				sortBy = tag.SortByReleaseCount
+			case "channels":
+				sortBy = tag.SortByChannelCount
+			}
+		}
+
+		if response.Data == nil {
+			tags, err := s.TagService.GetTags(sortBy, limit, offset)
+			if err == nil {
+				response.Status = "success"
+				response.Data = tags
+				s.Logger.Printf("success fetching tags")
+			} else {
+				s.Logger.Printf("fetching of tags failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching tags"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getTag returns a handler for GET /tags/{name} requests. Aliases lead to the tag they're filed under.
+func getTag(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		name := vars["name"]
+
+		t, err := s.TagService.GetTag(name)
+		switch err {
+		case nil:
+			response.Status = "success"
+			response.Data = *t
+			s.Logger.Printf("success fetching tag %s", name)
+		case tag.ErrTagNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "name",
+				ErrorMessage: fmt.Sprintf("tag of name %s not found", name),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of tag failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching tag"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getTagReleases returns a handler for GET /tags/{name}/releases requests that lists the
+// official releases filed under the tag, or any genre filed under it, the latest first.
+// Releases the user isn't to see under their content filter are left out.
+func getTagReleases(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		name := vars["name"]
+
+		limit := 25
+		offset := 0
+		{ // this block reads the query strings if any
+			if limitRaw := r.URL.Query().Get("limit"); limitRaw != "" {
+				limit, err = strconv.Atoi(limitRaw)
+				if err != nil || limit < 0 {
+					s.Logger.Printf("bad get tag releases request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					s.Logger.Printf("bad get tag releases request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil {
+			ids, err := s.TagService.GetReleases(name, limit, offset)
+			switch err {
+			case nil:
+				filter := contentFilterOf(s, r.Header.Get("authorized_username"))
+				releases := make([]*release.Release, 0, len(ids))
+				for _, id := range ids {
+					rel, err := s.ReleaseService.GetRelease(id)
+					if err != nil || !filter.Allows(rel.Rating, rel.Warnings) {
+						continue
+					}
+					serveRelease(s, rel)
+					releases = append(releases, rel)
+				}
+				response.Status = "success"
+				response.Data = releases
+				s.Logger.Printf("success fetching releases of tag %s", name)
+			case tag.ErrTagNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "name",
+					ErrorMessage: fmt.Sprintf("tag of name %s not found", name),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of tag releases failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching tag releases"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getTagChannels returns a handler for GET /tags/{name}/channels requests that lists the
+// channels with official releases filed under the tag, or any genre filed under it,
+// along the number of those
+func getTagChannels(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		name := vars["name"]
+
+		limit := 25
+		offset := 0
+		{ // this block reads the query strings if any
+			if limitRaw := r.URL.Query().Get("limit"); limitRaw != "" {
+				limit, err = strconv.Atoi(limitRaw)
+				if err != nil || limit < 0 {
+					s.Logger.Printf("bad get tag channels request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					s.Logger.Printf("bad get tag channels request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil {
+			channels, err := s.TagService.GetChannels(name, limit, offset)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = channels
+				s.Logger.Printf("success fetching channels of tag %s", name)
+			case tag.ErrTagNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "name",
+					ErrorMessage: fmt.Sprintf("tag of name %s not found", name),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of tag channels failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching tag channels"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// getTagSuggestions returns a handler for GET /search/tags requests that suggests tags
+// for the pattern, be it the start of a genre or the text of a release
+func getTagSuggestions(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		limit := 10
+		pattern := r.URL.Query().Get("pattern")
+		{ // this block reads the query strings if any
+			if pattern == "" {
+				s.Logger.Printf("bad tag suggestion request, pattern")
+				response.Data = jSendFailData{
+					ErrorReason:  "pattern",
+					ErrorMessage: "bad request, pattern can't be empty",
+				}
+				statusCode = http.StatusBadRequest
+			}
+			if limitRaw := r.URL.Query().Get("limit"); limitRaw != "" {
+				limit, err = strconv.Atoi(limitRaw)
+				if err != nil || limit < 0 {
+					s.Logger.Printf("bad tag suggestion request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil {
+			suggestions, err := s.TagService.Suggest(pattern, limit)
+			if err == nil {
+				response.Status = "success"
+				response.Data = suggestions
+				s.Logger.Printf("success suggesting tags for %s", pattern)
+			} else {
+				s.Logger.Printf("suggesting of tags failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when suggesting tags"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// patchTag returns a handler for PATCH /tags/{name} requests that lets tag curators
+// describe tags and file them under parent genres
+func patchTag(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		name := vars["name"]
+
+		{ // this block secures the route
+			if !isTagCurator(s, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized patch tag request")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		t := new(tag.Tag)
+		err := json.NewDecoder(r.Body).Decode(t)
+		if err != nil || (t.Description == "" && t.Parent == "") {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"description":"description",
+				"parent":"name of parent genre"}`,
+			}
+			s.Logger.Printf("bad patch tag request")
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			t.Name = name
+			t.Description = s.StrictSanitizer.Sanitize(t.Description)
+			t, err = s.TagService.UpdateTag(t)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *t
+				s.Logger.Printf("success updating tag %s", name)
+			case tag.ErrInvalidTag:
+				response.Data = jSendFailData{
+					ErrorReason:  "description",
+					ErrorMessage: fmt.Sprintf("description can't be longer than %d characters", tag.MaxDescriptionLength),
+				}
+				statusCode = http.StatusBadRequest
+			case tag.ErrInvalidParent:
+				response.Data = jSendFailData{
+					ErrorReason:  "parent",
+					ErrorMessage: "parent should be an existing tag that isn't the tag itself or filed under it",
+				}
+				statusCode = http.StatusBadRequest
+			case tag.ErrTagNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "name",
+					ErrorMessage: fmt.Sprintf("tag of name %s not found", name),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("updating of tag failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when updating tag"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteTagParent returns a handler for DELETE /tags/{name}/parent requests that lets tag
+// curators take tags out of their parent genres
+func deleteTagParent(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		name := vars["name"]
+
+		{ // this block secures the route
+			if !isTagCurator(s, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized delete tag parent request")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		t, err := s.TagService.RemoveParent(name)
+		switch err {
+		case nil:
+			response.Status = "success"
+			response.Data = *t
+			s.Logger.Printf("success removing parent of tag %s", name)
+		case tag.ErrTagNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "name",
+				ErrorMessage: fmt.Sprintf("tag of name %s not found", name),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("removal of tag parent failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when removing tag parent"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putTagAlias returns a handler for PUT /tags/{name}/aliases/{alias} requests that lets tag
+// curators file aliases under tags. Tags used as aliases are merged into the tag and the
+// releases filed under them are refiled along.
+func putTagAlias(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		name := vars["name"]
+		alias := vars["alias"]
+
+		{ // this block secures the route
+			if !isTagCurator(s, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized put tag alias request")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		t, err := s.TagService.AddAlias(name, alias)
+		if err == nil {
+			// releases given the aliases before they were ones are refiled under the tag
+			err = s.ReleaseService.ReplaceGenres(t.Aliases, t.Name)
+		}
+		switch err {
+		case nil:
+			response.Status = "success"
+			response.Data = *t
+			s.Logger.Printf("success filing alias %s under tag %s", alias, name)
+		case tag.ErrInvalidTag:
+			response.Data = jSendFailData{
+				ErrorReason:  "alias",
+				ErrorMessage: fmt.Sprintf("alias should have letters or digits and can't be longer than %d characters", tag.MaxNameLength),
+			}
+			statusCode = http.StatusBadRequest
+		case tag.ErrAliasTaken:
+			response.Data = jSendFailData{
+				ErrorReason:  "alias",
+				ErrorMessage: fmt.Sprintf("alias %s is filed under another tag", alias),
+			}
+			statusCode = http.StatusConflict
+		case tag.ErrTagNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "name",
+				ErrorMessage: fmt.Sprintf("tag of name %s not found", name),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("filing of tag alias failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when filing tag alias"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteTagAlias returns a handler for DELETE /tags/{name}/aliases/{alias} requests that lets
+// tag curators remove aliases from tags
+func deleteTagAlias(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		name := vars["name"]
+		alias := vars["alias"]
+
+		{ // this block secures the route
+			if !isTagCurator(s, r.Header.Get("authorized_username")) {
+				s.Logger.Printf("unauthorized delete tag alias request")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		t, err := s.TagService.RemoveAlias(name, alias)
+		switch err {
+		case nil:
+			response.Status = "success"
+			response.Data = *t
+			s.Logger.Printf("success removing alias %s of tag %s", alias, name)
+		case tag.ErrAliasNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "alias",
+				ErrorMessage: fmt.Sprintf("alias %s of tag %s not found", alias, name),
+			}
+			statusCode = http.StatusNotFound
+		case tag.ErrTagNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "name",
+				ErrorMessage: fmt.Sprintf("tag of name %s not found", name),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("removal of tag alias failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when removing tag alias"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
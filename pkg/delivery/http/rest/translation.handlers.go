@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+)
+
+// putReleaseTranslationPermission returns a handler for PUT /releases/{id}/translation/permission
+// requests that lets the admins of the channel of the original permit a translation
+func putReleaseTranslationPermission(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return setReleaseTranslationPermission(s, true)
+}
+
+// deleteReleaseTranslationPermission returns a handler for DELETE /releases/{id}/translation/permission
+// requests that lets the admins of the channel of the original take back the permission of a translation
+func deleteReleaseTranslationPermission(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return setReleaseTranslationPermission(s, false)
+}
+
+// setReleaseTranslationPermission is a helper function that returns the handler for the
+// permission routes of translations
+func setReleaseTranslationPermission(s *Setup, permitted bool) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["id"]
+
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("translation permission attempt on invalid release id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "releaseID",
+				ErrorMessage: fmt.Sprintf("invalid releaseID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			rel, err := s.ReleaseService.GetRelease(id)
+			if err == nil && rel.TranslationOf == nil {
+				err = release.ErrNotTranslation
+			}
+			var original *release.Release
+			if err == nil {
+				original, err = s.ReleaseService.GetRelease(rel.TranslationOf.OriginalID)
+				if err == release.ErrReleaseNotFound {
+					// the original was removed since
+					err = release.ErrNotTranslation
+				}
+			}
+			if err == nil {
+				{ // this block secures the route
+					if !isChannelAdmin(s, original.OwnerChannel, r.Header.Get("authorized_username")) {
+						s.Logger.Printf("unauthorized translation permission request")
+						addCors(w)
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+				}
+				rel, err = s.ReleaseService.SetTranslationPermission(id, permitted)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				serveRelease(s, rel)
+				response.Data = *rel
+				s.Logger.Printf("success setting permission of translation %d to %v", id, permitted)
+			case release.ErrNotTranslation:
+				response.Data = jSendFailData{
+					ErrorReason:  "releaseID",
+					ErrorMessage: fmt.Sprintf("release of releaseID %d isn't a translation", id),
+				}
+				statusCode = http.StatusNotFound
+			case release.ErrReleaseNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "releaseID",
+					ErrorMessage: fmt.Sprintf("release of releaseID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("setting of translation permission failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when setting translation permission"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// deleteReleaseTranslation returns a handler for DELETE /releases/{id}/translation requests
+// that unlinks a translation from the release it's translated from
+func deleteReleaseTranslation(s *Setup) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		response.Status = "fail"
+		statusCode := http.StatusOK
+
+		vars := getParametersFromRequestAsMap(r)
+		idRaw := vars["id"]
+
+		id, err := strconv.Atoi(idRaw)
+		if err != nil {
+			s.Logger.Printf("translation unlink attempt on invalid release id %s", idRaw)
+			response.Data = jSendFailData{
+				ErrorReason:  "releaseID",
+				ErrorMessage: fmt.Sprintf("invalid releaseID %s", idRaw),
+			}
+			statusCode = http.StatusBadRequest
+		}
+
+		if response.Data == nil {
+			rel, err := s.ReleaseService.GetRelease(id)
+			if err == nil {
+				{ // this block secures the route
+					if !isChannelAdmin(s, rel.OwnerChannel, r.Header.Get("authorized_username")) {
+						s.Logger.Printf("unauthorized translation unlink request")
+						addCors(w)
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+				}
+				rel, err = s.ReleaseService.RemoveTranslation(id)
+			}
+			switch err {
+			case nil:
+				response.Status = "success"
+				serveRelease(s, rel)
+				response.Data = *rel
+				s.Logger.Printf("success unlinking translation %d", id)
+			case release.ErrNotTranslation:
+				response.Data = jSendFailData{
+					ErrorReason:  "releaseID",
+					ErrorMessage: fmt.Sprintf("release of releaseID %d isn't a translation", id),
+				}
+				statusCode = http.StatusNotFound
+			case release.ErrReleaseNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "releaseID",
+					ErrorMessage: fmt.Sprintf("release of releaseID %d not found", id),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("unlinking of translation failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when unlinking translation"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
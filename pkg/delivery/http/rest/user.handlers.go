@@ -4,13 +4,14 @@ import (
 	//"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/user"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 
 	//"io"
 
 	// "html"
 	"net/http"
-	"net/url"
 	"os"
 	"regexp"
 	"strconv"
@@ -34,6 +35,19 @@ func sanitizeUser(u *user.User, s *Setup) {
 
 }
 
+// contentFilterOf is a helper function that returns the maturity filter listings requested by
+// the given user are to honor. Unauthenticated requests and users that can't be found get the defaults.
+func contentFilterOf(s *Setup, username string) maturity.Filter {
+	if username == "" {
+		return maturity.DefaultFilter
+	}
+	u, err := s.UserService.GetUser(username)
+	if err != nil || u.ContentFilter == nil {
+		return maturity.DefaultFilter
+	}
+	return *u.ContentFilter
+}
+
 var emailRX = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
 
 var usernameRX = regexp.MustCompile("^[a-zA-Z]?(?:[_]?[a-zA-Z0-9])*$")
@@ -182,10 +196,12 @@ func getUser(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 					s.Logger.Printf("user %s fetched user %s", r.Header.Get("authorized_username"), u.Username)
 					u.Email = ""
 					u.BookmarkedPosts = nil
+					u.ContentFilter = nil
 				}
 			}
 			if u.PictureURL != "" {
-				u.PictureURL = s.HostAddress + s.ImageServingRoute + url.PathEscape(u.PictureURL)
+				u.PictureVariants = imageVariants(s, u.PictureURL)
+				u.PictureURL = imageURL(s, u.PictureURL)
 			}
 			response.Data = *u
 			s.Logger.Printf("success fetching user %s", username)
@@ -284,8 +300,10 @@ func getUsers(s *Setup) func(w http.ResponseWriter, r *http.Request) {
 				for _, u := range users {
 					u.Email = ""
 					u.BookmarkedPosts = nil
+					u.ContentFilter = nil
 					if u.PictureURL != "" {
-						u.PictureURL = s.HostAddress + s.ImageServingRoute + url.PathEscape(u.PictureURL)
+						u.PictureVariants = imageVariants(s, u.PictureURL)
+						u.PictureURL = imageURL(s, u.PictureURL)
 					}
 				}
 				response.Data = users
@@ -737,7 +755,7 @@ func getUserPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 		switch err {
 		case nil:
 			response.Status = "success"
-			response.Data = s.HostAddress + s.ImageServingRoute + url.PathEscape(u.PictureURL)
+			response.Data = imageURL(s, u.PictureURL)
 			s.Logger.Printf("success fetching user %s picture URL", username)
 		case user.ErrUserNotFound:
 			s.Logger.Printf("fetch picture URL attempt of non existing user %s", username)
@@ -783,6 +801,9 @@ func putUserPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 				return
 			}
 		}
+		if !limitUploadSize(s, w, r, maxImageUploadSize) {
+			return
+		}
 		var tmpFile *os.File
 		var fileName string
 		{ // this block extracts the image
@@ -793,7 +814,6 @@ func putUserPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 				defer os.Remove(tmpFile.Name())
 				defer tmpFile.Close()
 				s.Logger.Printf("temp file saved: %s", tmpFile.Name())
-				fileName = generateFileNameForStorage(fileName, "user")
 			case errUnacceptedType:
 				response.Data = jSendFailData{
 					ErrorMessage: "image",
@@ -818,7 +838,7 @@ func putUserPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 			err := s.UserService.AddPicture(username, fileName)
 			switch err {
 			case nil:
-				err := saveTempFilePermanentlyToPath(tmpFile, s.ImageStoragePath+fileName)
+				err := saveImagePermanently(s, tmpFile, fileName)
 				if err != nil {
 					s.Logger.Printf("adding of release failed because: %v", err)
 					response.Status = "error"
@@ -828,7 +848,7 @@ func putUserPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 				} else {
 					s.Logger.Printf("success adding picture %s to user %s", fileName, username)
 					response.Status = "success"
-					response.Data = s.HostAddress + s.ImageServingRoute + url.PathEscape(fileName)
+					response.Data = imageURL(s, fileName)
 				}
 			case user.ErrUserNotFound:
 				s.Logger.Printf("adding of user picture failed because: %v", err)
@@ -872,7 +892,7 @@ func deleteUserPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 			err = s.UserService.RemovePicture(username)
 			switch err {
 			case nil:
-				// TODO delete picture from fs
+				// the image is collected once nothing else refers to it
 				s.Logger.Printf("success removing piture from user %s", username)
 				response.Status = "success"
 			case user.ErrUserNotFound:
@@ -892,3 +912,204 @@ func deleteUserPicture(s *Setup) func(http.ResponseWriter, *http.Request) {
 		writeResponseToWriter(response, w, statusCode)
 	}
 }
+
+// getUserContentFilter returns a handler for GET /users/{username}/content-filter requests
+func getUserContentFilter(s *Setup) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		statusCode := http.StatusOK
+		response.Status = "fail"
+
+		vars := getParametersFromRequestAsMap(r)
+		username := vars["username"]
+
+		{ // this block secures the route
+			if username != r.Header.Get("authorized_username") {
+				s.Logger.Printf("unauthorized get content filter request")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		u, err := s.UserService.GetUser(username)
+		switch err {
+		case nil:
+			response.Status = "success"
+			if u.ContentFilter != nil {
+				response.Data = *u.ContentFilter
+			} else {
+				response.Data = maturity.DefaultFilter
+			}
+			s.Logger.Printf("success fetching content filter of user %s", username)
+		case user.ErrUserNotFound:
+			response.Data = jSendFailData{
+				ErrorReason:  "username",
+				ErrorMessage: fmt.Sprintf("user of username %s not found", username),
+			}
+			statusCode = http.StatusNotFound
+		default:
+			s.Logger.Printf("fetching of content filter failed because: %v", err)
+			response.Status = "error"
+			response.Message = "server error when fetching content filter"
+			statusCode = http.StatusInternalServerError
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// putUserContentFilter returns a handler for PUT /users/{username}/content-filter requests
+func putUserContentFilter(s *Setup) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response jSendResponse
+		statusCode := http.StatusOK
+		response.Status = "fail"
+
+		vars := getParametersFromRequestAsMap(r)
+		username := vars["username"]
+
+		{ // this block secures the route
+			if username != r.Header.Get("authorized_username") {
+				s.Logger.Printf("unauthorized put content filter request")
+				addCors(w)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		filter := new(maturity.Filter)
+		err := json.NewDecoder(r.Body).Decode(filter)
+		if err != nil {
+			response.Data = jSendFailData{
+				ErrorReason: "request format",
+				ErrorMessage: `bad request, use format
+				{"maxRating":"general|teen|mature|explicit",
+				"hiddenWarnings":["warnings to hide"]}`,
+			}
+			s.Logger.Printf("bad put content filter request")
+			statusCode = http.StatusBadRequest
+		}
+		// if queries are clean
+		if response.Data == nil {
+			filter, err = s.UserService.SetContentFilter(username, filter)
+			switch err {
+			case nil:
+				response.Status = "success"
+				response.Data = *filter
+				s.Logger.Printf("success setting content filter of user %s", username)
+			case user.ErrInvalidContentFilter:
+				response.Data = jSendFailData{
+					ErrorReason:  "maxRating",
+					ErrorMessage: "maxRating can be either general, teen, mature or explicit",
+				}
+				statusCode = http.StatusBadRequest
+			case user.ErrUserNotFound:
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: fmt.Sprintf("user of username %s not found", username),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("setting of content filter failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when setting content filter"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
+
+// creditedRelease is a release along the roles a user is credited with in it
+type creditedRelease struct {
+	Roles   []release.Role  `json:"roles"`
+	Release release.Release `json:"release"`
+}
+
+// getUserCredits returns a handler for GET /users/{username}/credits?limit=25&offset=0 requests
+// that lists the works credited to the user, the latest first. Releases the requester can't
+// see or isn't to see under their content filter are left out.
+func getUserCredits(s *Setup) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var response jSendResponse
+		statusCode := http.StatusOK
+		response.Status = "fail"
+
+		vars := getParametersFromRequestAsMap(r)
+		username := vars["username"]
+		viewer := r.Header.Get("authorized_username")
+
+		limit := 25
+		offset := 0
+		{ // this block reads the query strings if any
+			if limitRaw := r.URL.Query().Get("limit"); limitRaw != "" {
+				limit, err = strconv.Atoi(limitRaw)
+				if err != nil || limit < 0 {
+					s.Logger.Printf("bad get user credits request, limit")
+					response.Data = jSendFailData{
+						ErrorReason:  "limit",
+						ErrorMessage: "bad request, limit can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+			if offsetRaw := r.URL.Query().Get("offset"); offsetRaw != "" {
+				offset, err = strconv.Atoi(offsetRaw)
+				if err != nil || offset < 0 {
+					s.Logger.Printf("bad get user credits request, offset")
+					response.Data = jSendFailData{
+						ErrorReason:  "offset",
+						ErrorMessage: "bad request, offset can't be negative",
+					}
+					statusCode = http.StatusBadRequest
+				}
+			}
+		}
+
+		if response.Data == nil {
+			var works []*release.Work
+			u, err := s.UserService.GetUser(username)
+			if err == nil {
+				works, err = s.ReleaseService.GetCreditsOf(u.Username)
+			}
+			switch err {
+			case nil:
+				filter := contentFilterOf(s, viewer)
+				credited := make([]creditedRelease, 0)
+				for _, work := range works {
+					rel, err := s.ReleaseService.GetRelease(work.ReleaseID)
+					if err != nil || !filter.Allows(rel.Rating, rel.Warnings) {
+						continue
+					}
+					if visible, err := canViewRelease(s, rel, viewer); err != nil || !visible {
+						continue
+					}
+					if offset > 0 {
+						offset--
+						continue
+					}
+					if len(credited) == limit {
+						break
+					}
+					serveRelease(s, rel)
+					credited = append(credited, creditedRelease{Roles: work.Roles, Release: *rel})
+				}
+				response.Status = "success"
+				response.Data = credited
+				s.Logger.Printf("success fetching credits of user %s", username)
+			case user.ErrUserNotFound:
+				s.Logger.Printf("credits fetch attempt of non existing user %s", username)
+				response.Data = jSendFailData{
+					ErrorReason:  "username",
+					ErrorMessage: fmt.Sprintf("user of username %s not found", username),
+				}
+				statusCode = http.StatusNotFound
+			default:
+				s.Logger.Printf("fetching of user credits failed because: %v", err)
+				response.Status = "error"
+				response.Message = "server error when fetching user credits"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+		writeResponseToWriter(response, w, statusCode)
+	}
+}
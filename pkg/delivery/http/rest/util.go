@@ -12,8 +12,10 @@ import (
 
 	// "time"
 
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/audio"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/blob"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/imaging"
 	"github.com/julienschmidt/httprouter"
-	uuid "github.com/satori/go.uuid"
 
 	// mrand "math/rand"
 	"os"
@@ -64,70 +66,192 @@ func addCors(w http.ResponseWriter) {
 var errUnacceptedType = fmt.Errorf("file mime type not accepted")
 var errReadingFromImage = fmt.Errorf("err reading image file from request")
 
+// saveImageFromRequest saves the image of the multipart field of the given name, without
+// its metadata, to a temporary file and returns it along with the content addressed name
+// it's to be stored under.
 func saveImageFromRequest(r *http.Request, fileName string) (*os.File, string, error) {
-	file, header, err := r.FormFile(fileName)
+	file, _, err := r.FormFile(fileName)
 	if err != nil {
 		return nil, "", errReadingFromImage
 	}
 	defer file.Close()
-	err = checkIfFileIsAcceptedType(file)
+	return saveImageToTempFile(file)
+}
+
+// saveImageToTempFile is a helper function that checks the type of the uploaded image
+// and copies it to a temporary file, leaving out the metadata as it might give away the
+// location of the uploader, while hashing it for its content addressed name.
+func saveImageToTempFile(file multipart.File) (*os.File, string, error) {
+	contentType, err := checkIfFileIsAcceptedType(file)
 	if err != nil {
 		return nil, "", err
 	}
-	newFile, err := ioutil.TempFile("", "tempIMG*.jpg")
+	newFile, err := ioutil.TempFile("", "tempIMG*")
 	if err != nil {
 		return nil, "", err
 	}
-	_, err = io.Copy(newFile, file)
+	hash := blob.NewHash()
+	err = imaging.StripMetadata(io.MultiWriter(newFile, hash), file)
 	if err != nil {
+		newFile.Close()
+		os.Remove(newFile.Name())
+		if err == imaging.ErrMalformedImage {
+			return nil, "", errReadingFromImage
+		}
 		return nil, "", err
 	}
-	return newFile, header.Filename, nil
+	return newFile, blob.Name(hash.Sum(nil), imageExtensions[contentType]), nil
+}
+
+// saveImagesFromRequest saves every image of the multipart field of the given name to
+// temporary files, in the order they were sent, along with their content addressed names.
+// The temporary files are removed if any of the images is faulty.
+func saveImagesFromRequest(r *http.Request, fieldName string) ([]*os.File, []string, error) {
+	if r.MultipartForm == nil {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, nil, errReadingFromImage
+		}
+	}
+	headers := r.MultipartForm.File[fieldName]
+	if len(headers) == 0 {
+		return nil, nil, errReadingFromImage
+	}
+	tmpFiles := make([]*os.File, 0, len(headers))
+	fileNames := make([]string, 0, len(headers))
+	var err error
+	for _, header := range headers {
+		var file multipart.File
+		file, err = header.Open()
+		if err != nil {
+			err = errReadingFromImage
+			break
+		}
+		var newFile *os.File
+		var name string
+		newFile, name, err = saveImageToTempFile(file)
+		if err == nil {
+			tmpFiles = append(tmpFiles, newFile)
+			fileNames = append(fileNames, name)
+		}
+		file.Close()
+		if err != nil {
+			break
+		}
+	}
+	if err != nil {
+		removeTempFiles(tmpFiles)
+		return nil, nil, err
+	}
+	return tmpFiles, fileNames, nil
 }
 
-func checkIfFileIsAcceptedType(file multipart.File) error { // this block checks if image is of accepted types
-	acceptedTypes := map[string]struct{}{
-		"image/jpeg": {},
-		"image/png":  {},
+// removeTempFiles closes and removes the given temporary files
+func removeTempFiles(tmpFiles []*os.File) {
+	for _, tmpFile := range tmpFiles {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
 	}
+}
+
+// imageExtensions maps the accepted image types to the extensions they're stored with
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+}
+
+func checkIfFileIsAcceptedType(file multipart.File) (string, error) { // this block checks if image is of accepted types
 	tempBuffer := make([]byte, 512)
 	_, err := file.ReadAt(tempBuffer, 0)
 	if err != nil {
-		return errReadingFromImage
+		return "", errReadingFromImage
 	}
 	contentType := http.DetectContentType(tempBuffer)
-	if _, ok := acceptedTypes[contentType]; !ok {
-		return errUnacceptedType
+	if _, ok := imageExtensions[contentType]; !ok {
+		return "", errUnacceptedType
 	}
-	return err
+	return contentType, nil
 }
 
-func saveTempFilePermanentlyToPath(tmpFile *os.File, path string) error {
-	newFile, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer newFile.Close()
+// maxReleaseUploadSize is the largest request body accepted when adding or updating releases,
+// enough for long audio releases and comics of many pages
+const maxReleaseUploadSize = 512 << 20
 
-	_, err = tmpFile.Seek(0, 0)
-	if err != nil {
-		return err
+// maxImageUploadSize is the largest request body accepted when uploading a single image,
+// enough for the largest images that are decoded
+const maxImageUploadSize = 32 << 20
+
+// maxEPUBUploadSize is the largest request body accepted when uploading a book, leaving room
+// for the images and fonts books carry on top of the text that's read from them
+const maxEPUBUploadSize = 64 << 20
+
+// limitUploadSize is a helper function that refuses requests larger than the given size,
+// writing the response itself, and keeps those that don't tell their size from reading
+// any further. It reports whether the request can still be handled.
+func limitUploadSize(s *Setup, w http.ResponseWriter, r *http.Request, maxSize int64) bool {
+	if r.ContentLength > maxSize {
+		s.Logger.Printf("refused upload of %d bytes", r.ContentLength)
+		var response jSendResponse
+		response.Status = "fail"
+		response.Data = jSendFailData{
+			ErrorReason:  "size",
+			ErrorMessage: fmt.Sprintf("uploads can be up to %d bytes", maxSize),
+		}
+		writeResponseToWriter(response, w, http.StatusRequestEntityTooLarge)
+		return false
 	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+	return true
+}
+
+var errUnacceptedAudioType = fmt.Errorf("audio format not accepted")
+var errReadingFromAudio = fmt.Errorf("err reading audio file from request")
 
-	_, err = io.Copy(newFile, tmpFile)
+// checkIfFileIsAcceptedAudioType checks if the uploaded file is mp3, ogg vorbis or opus
+// audio going by its magic bytes
+func checkIfFileIsAcceptedAudioType(file multipart.File) (audio.Format, error) {
+	tempBuffer := make([]byte, audio.HeaderSize)
+	n, err := file.ReadAt(tempBuffer, 0)
+	if err != nil && err != io.EOF {
+		return "", errReadingFromAudio
+	}
+	format, err := audio.Detect(tempBuffer[:n])
 	if err != nil {
-		return err
+		return "", errUnacceptedAudioType
 	}
-
-	return nil
+	return format, nil
 }
 
-func generateFileNameForStorage(fileName, prefix string) string {
-
-	v4uuid, _ := uuid.NewV4()
-	return prefix + "." + v4uuid.String() + "." + fileName
-	// entropy, _ := generateRandomString(20)
-	// return prefix + "." + entropy + "." + fileName
+// saveAudioFromRequest saves the audio file of the multipart field of the given name to a
+// temporary file and returns it along with the content addressed name it's to be stored
+// under and a description of its format, size and duration.
+func saveAudioFromRequest(r *http.Request, fileName string) (*os.File, string, *audio.Info, error) {
+	file, _, err := r.FormFile(fileName)
+	if err != nil {
+		return nil, "", nil, errReadingFromAudio
+	}
+	defer file.Close()
+	if _, err = checkIfFileIsAcceptedAudioType(file); err != nil {
+		return nil, "", nil, err
+	}
+	newFile, err := ioutil.TempFile("", "tempAudio*")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	hash := blob.NewHash()
+	size, err := io.Copy(io.MultiWriter(newFile, hash), file)
+	var info *audio.Info
+	if err == nil {
+		info, err = audio.Probe(newFile, size)
+		if err == audio.ErrMalformedAudio || err == audio.ErrUnsupportedFormat {
+			err = errReadingFromAudio
+		}
+	}
+	if err != nil {
+		newFile.Close()
+		os.Remove(newFile.Name())
+		return nil, "", nil, err
+	}
+	return newFile, blob.Name(hash.Sum(nil), string(info.Format)), info, nil
 }
 
 /* // GenerateRandomBytes returns securely generated random bytes.
@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/view"
+)
+
+// viewerOf is a helper function that identifies the reader of the request for view
+// deduplication. Anonymous readers are fingerprinted by their address and user agent.
+func viewerOf(r *http.Request) string {
+	if username := r.Header.Get("authorized_username"); username != "" {
+		return "user:" + username
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	sum := sha256.Sum256([]byte(host + "|" + r.UserAgent()))
+	return "anon:" + hex.EncodeToString(sum[:16])
+}
+
+// countView is a helper function that records a view of the target by the reader of the
+// request, if record is set, and returns the number of views the target received.
+// Failures are only logged as they shouldn't keep the target from being served.
+func countView(s *Setup, targetType view.TargetType, targetID int, r *http.Request, record bool) uint {
+	if record {
+		if _, err := s.ViewService.RecordView(&view.View{TargetType: targetType, TargetID: targetID, Viewer: viewerOf(r)}); err != nil {
+			s.Logger.Printf("recording of view of %s %d failed because: %v", targetType, targetID, err)
+		}
+	}
+	count, err := s.ViewService.GetCount(targetType, targetID)
+	if err != nil {
+		s.Logger.Printf("fetching of view count of %s %d failed because: %v", targetType, targetID, err)
+	}
+	return count
+}
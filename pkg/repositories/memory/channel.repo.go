@@ -2,6 +2,7 @@ package memory
 
 import (
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+	"time"
 )
 
 //ChannelRepository...
@@ -168,8 +169,20 @@ func (repo *ChannelRepository) DeleteReleaseFromOfficialCatalog(channelUsername
 }
 
 // StickyPost calls the DB repo StickyPost function.
-func (repo *ChannelRepository) StickyPost(channelUsername string, postID uint) error {
-	err := (*repo.secondaryRepo).StickyPost(channelUsername, postID)
+func (repo *ChannelRepository) StickyPost(channelUsername string, pin *channel.Pin) error {
+	err := (*repo.secondaryRepo).StickyPost(channelUsername, pin)
+	if err == nil {
+		err = repo.cacheChannel(channelUsername)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// ReorderStickiedPosts calls the DB repo ReorderStickiedPosts function.
+func (repo *ChannelRepository) ReorderStickiedPosts(channelUsername string, postIDs []uint) error {
+	err := (*repo.secondaryRepo).ReorderStickiedPosts(channelUsername, postIDs)
 	if err == nil {
 		err = repo.cacheChannel(channelUsername)
 		if err != nil {
@@ -214,3 +227,187 @@ func (repo *ChannelRepository) RemovePicture(channelUsername string) error {
 	}
 	return err
 }
+
+// SetPrivacy calls the same method on the wrapped repo with a lil caching in between.
+func (repo *ChannelRepository) SetPrivacy(channelUsername string, private bool) error {
+	err := (*repo.secondaryRepo).SetPrivacy(channelUsername, private)
+	if err == nil {
+		err = repo.cacheChannel(channelUsername)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// GetMembers calls the DB repo GetMembers function.
+func (repo *ChannelRepository) GetMembers(channelUsername string) ([]*channel.Member, error) {
+	return (*repo.secondaryRepo).GetMembers(channelUsername)
+}
+
+// GetMember calls the DB repo GetMember function.
+func (repo *ChannelRepository) GetMember(channelUsername string, username string) (*channel.Member, error) {
+	return (*repo.secondaryRepo).GetMember(channelUsername, username)
+}
+
+// AddMember calls the DB repo AddMember function.
+func (repo *ChannelRepository) AddMember(channelUsername string, member *channel.Member) error {
+	return (*repo.secondaryRepo).AddMember(channelUsername, member)
+}
+
+// RemoveMember calls the DB repo RemoveMember function.
+func (repo *ChannelRepository) RemoveMember(channelUsername string, username string) error {
+	return (*repo.secondaryRepo).RemoveMember(channelUsername, username)
+}
+
+// GetJoinRequests calls the DB repo GetJoinRequests function.
+func (repo *ChannelRepository) GetJoinRequests(channelUsername string) ([]*channel.JoinRequest, error) {
+	return (*repo.secondaryRepo).GetJoinRequests(channelUsername)
+}
+
+// AddJoinRequest calls the DB repo AddJoinRequest function.
+func (repo *ChannelRepository) AddJoinRequest(channelUsername string, request *channel.JoinRequest) (*channel.JoinRequest, error) {
+	return (*repo.secondaryRepo).AddJoinRequest(channelUsername, request)
+}
+
+// DeleteJoinRequest calls the DB repo DeleteJoinRequest function.
+func (repo *ChannelRepository) DeleteJoinRequest(channelUsername string, username string) error {
+	return (*repo.secondaryRepo).DeleteJoinRequest(channelUsername, username)
+}
+
+// GetStats calls the DB repo GetStats function.
+func (repo *ChannelRepository) GetStats(channelUsername string, from, to time.Time, bucket channel.Bucket, topLimit int) (*channel.Stats, error) {
+	return (*repo.secondaryRepo).GetStats(channelUsername, from, to, bucket, topLimit)
+}
+
+// AddBanner calls the same method on the wrapped repo with a lil caching in between.
+func (repo *ChannelRepository) AddBanner(channelUsername, name string) (string, error) {
+	a, err := (*repo.secondaryRepo).AddBanner(channelUsername, name)
+	if err == nil {
+		err = repo.cacheChannel(channelUsername)
+		if err != nil {
+			return "", err
+		}
+	}
+	return a, err
+}
+
+// RemoveBanner calls the same method on the wrapped repo with a lil caching in between.
+func (repo *ChannelRepository) RemoveBanner(channelUsername string) error {
+	err := (*repo.secondaryRepo).RemoveBanner(channelUsername)
+	if err == nil {
+		err = repo.cacheChannel(channelUsername)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// SetTheme calls the same method on the wrapped repo with a lil caching in between.
+func (repo *ChannelRepository) SetTheme(channelUsername string, theme *channel.Theme) error {
+	err := (*repo.secondaryRepo).SetTheme(channelUsername, theme)
+	if err == nil {
+		err = repo.cacheChannel(channelUsername)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// GetPages calls the DB repo GetPages function.
+func (repo *ChannelRepository) GetPages(channelUsername string) ([]*channel.Page, error) {
+	return (*repo.secondaryRepo).GetPages(channelUsername)
+}
+
+// GetPage calls the DB repo GetPage function.
+func (repo *ChannelRepository) GetPage(channelUsername string, slug string) (*channel.Page, error) {
+	return (*repo.secondaryRepo).GetPage(channelUsername, slug)
+}
+
+// AddPage calls the DB repo AddPage function.
+func (repo *ChannelRepository) AddPage(channelUsername string, page *channel.Page) (*channel.Page, error) {
+	return (*repo.secondaryRepo).AddPage(channelUsername, page)
+}
+
+// UpdatePage calls the DB repo UpdatePage function.
+func (repo *ChannelRepository) UpdatePage(channelUsername string, slug string, page *channel.Page) (*channel.Page, error) {
+	return (*repo.secondaryRepo).UpdatePage(channelUsername, slug, page)
+}
+
+// DeletePage calls the DB repo DeletePage function.
+func (repo *ChannelRepository) DeletePage(channelUsername string, slug string) error {
+	return (*repo.secondaryRepo).DeletePage(channelUsername, slug)
+}
+
+// GetBlockedUsers calls the DB repo GetBlockedUsers function.
+func (repo *ChannelRepository) GetBlockedUsers(channelUsername string) ([]*channel.Block, error) {
+	return (*repo.secondaryRepo).GetBlockedUsers(channelUsername)
+}
+
+// GetBlockedUser calls the DB repo GetBlockedUser function.
+func (repo *ChannelRepository) GetBlockedUser(channelUsername string, username string) (*channel.Block, error) {
+	return (*repo.secondaryRepo).GetBlockedUser(channelUsername, username)
+}
+
+// BlockUser calls the DB repo BlockUser function.
+func (repo *ChannelRepository) BlockUser(channelUsername string, block *channel.Block) (*channel.Block, error) {
+	return (*repo.secondaryRepo).BlockUser(channelUsername, block)
+}
+
+// UnblockUser calls the DB repo UnblockUser function.
+func (repo *ChannelRepository) UnblockUser(channelUsername string, username string) error {
+	return (*repo.secondaryRepo).UnblockUser(channelUsername, username)
+}
+
+// GetCrossposts calls the DB repo GetCrossposts function.
+func (repo *ChannelRepository) GetCrossposts(channelUsername string, accepted bool) ([]*channel.Crosspost, error) {
+	return (*repo.secondaryRepo).GetCrossposts(channelUsername, accepted)
+}
+
+// GetPostCrossposts calls the DB repo GetPostCrossposts function.
+func (repo *ChannelRepository) GetPostCrossposts(postID uint) ([]*channel.Crosspost, error) {
+	return (*repo.secondaryRepo).GetPostCrossposts(postID)
+}
+
+// GetCrosspost calls the DB repo GetCrosspost function.
+func (repo *ChannelRepository) GetCrosspost(channelUsername string, postID uint) (*channel.Crosspost, error) {
+	return (*repo.secondaryRepo).GetCrosspost(channelUsername, postID)
+}
+
+// AddCrosspost calls the DB repo AddCrosspost function.
+func (repo *ChannelRepository) AddCrosspost(channelUsername string, crosspost *channel.Crosspost) (*channel.Crosspost, error) {
+	x, err := (*repo.secondaryRepo).AddCrosspost(channelUsername, crosspost)
+	if err == nil && x.Accepted {
+		err = repo.cacheChannel(channelUsername)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return x, err
+}
+
+// AcceptCrosspost calls the DB repo AcceptCrosspost function.
+func (repo *ChannelRepository) AcceptCrosspost(channelUsername string, postID uint) error {
+	err := (*repo.secondaryRepo).AcceptCrosspost(channelUsername, postID)
+	if err == nil {
+		err = repo.cacheChannel(channelUsername)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// DeleteCrosspost calls the DB repo DeleteCrosspost function.
+func (repo *ChannelRepository) DeleteCrosspost(channelUsername string, postID uint) error {
+	err := (*repo.secondaryRepo).DeleteCrosspost(channelUsername, postID)
+	if err == nil {
+		err = repo.cacheChannel(channelUsername)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
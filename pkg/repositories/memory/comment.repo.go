@@ -43,8 +43,8 @@ func (repo *commentRepository) GetComment(id int) (*comment.Comment, error) {
 }
 
 // GetComments calls the same method on the wrapped repo with a little caching in between.
-func (repo *commentRepository) GetComments(postID int, by string, order string, limit, offset int) ([]*comment.Comment, error) {
-	result, err := (*repo.secondaryRepo).GetComments(postID, by, order, limit, offset)
+func (repo *commentRepository) GetComments(postID int, by string, order string, limit, offset int, viewer string) ([]*comment.Comment, error) {
+	result, err := (*repo.secondaryRepo).GetComments(postID, by, order, limit, offset, viewer)
 	if err == nil {
 		for _, c := range result {
 			repo.cache[c.ID] = *c
@@ -54,8 +54,8 @@ func (repo *commentRepository) GetComments(postID int, by string, order string,
 }
 
 // GetReplies calls the same method on the wrapped repo with a little caching in between.
-func (repo *commentRepository) GetReplies(commentID int, by string, order string, limit, offset int) ([]*comment.Comment, error) {
-	result, err := (*repo.secondaryRepo).GetReplies(commentID, by, order, limit, offset)
+func (repo *commentRepository) GetReplies(commentID int, by string, order string, limit, offset int, viewer string) ([]*comment.Comment, error) {
+	result, err := (*repo.secondaryRepo).GetReplies(commentID, by, order, limit, offset, viewer)
 	if err == nil {
 		for _, c := range result {
 			repo.cache[c.ID] = *c
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/feed"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 //feedRepository ...
@@ -59,8 +60,14 @@ func (repo *feedRepository) cacheFeed(username string) error {
 //GetPosts directly calls the same method on the secondary repos it wraps to
 // retrieve a list of posts collected from the channels the given feed has
 // subscribed to sorted according to the given method.
-func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, offset int) ([]*feed.Post, error) {
-	return (*repo.secondaryRepo).GetPosts(f, sort, limit, offset)
+func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, offset int, filter maturity.Filter, exclude []int) ([]*feed.Post, error) {
+	return (*repo.secondaryRepo).GetPosts(f, sort, limit, offset, filter, exclude)
+}
+
+// GetPinnedPosts directly calls the same method on the secondary repos it wraps to
+// retrieve the posts pinned in the channels the given feed has subscribed to.
+func (repo *feedRepository) GetPinnedPosts(f *feed.Feed, filter maturity.Filter) ([]*feed.Post, error) {
+	return (*repo.secondaryRepo).GetPinnedPosts(f, filter)
 }
 
 // UpdateFeed directly calls the same method on the secondary repos it wraps to
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/poll"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+)
+
+type pollRepository struct {
+	cache         map[uint]poll.Poll
+	secondaryRepo *poll.Repository
+	allRepos      *map[string]interface{}
+}
+
+// NewPollRepository returns a new in memory cache implementation of poll.Repository.
+// The database implementation of poll.Repository must be passed as the first argument
+// since to simplify logic, cache repos wrap the database repos.
+// A map of all the other cache based implementations of the Repository interfaces
+// must be passed as a second argument as the cached posts polls are attached to
+// need to be refreshed when polls are added or removed.
+func NewPollRepository(secondaryRepo *poll.Repository, allRepos *map[string]interface{}) poll.Repository {
+	return &pollRepository{make(map[uint]poll.Poll), secondaryRepo, allRepos}
+}
+
+// refreshPost is just a helper function that recaches the post under the given id
+// so that its PollsID stay up to date
+func (repo *pollRepository) refreshPost(postID uint) {
+	if r, ok := (*repo.allRepos)["Post"].(*post.Repository); ok {
+		if postRepo, ok := (*r).(*postRepository); ok {
+			if _, found := postRepo.cache[postID]; found {
+				_ = postRepo.cachePost(postID)
+			}
+		}
+	}
+}
+
+// GetPoll gets the poll from the cache, if found, or from the wrapped repository.
+func (repo *pollRepository) GetPoll(id uint) (*poll.Poll, error) {
+	if _, ok := repo.cache[id]; ok == false {
+		p, err := (*repo.secondaryRepo).GetPoll(id)
+		if err != nil {
+			return nil, err
+		}
+		repo.cache[id] = *p
+	}
+	p := repo.cache[id]
+	return &p, nil
+}
+
+// GetPostPolls calls the same method on the wrapped repo.
+func (repo *pollRepository) GetPostPolls(postID uint) ([]*poll.Poll, error) {
+	return (*repo.secondaryRepo).GetPostPolls(postID)
+}
+
+// AddPoll calls the same method on the wrapped repo refreshing the post it's attached to.
+func (repo *pollRepository) AddPoll(p *poll.Poll) (*poll.Poll, error) {
+	p, err := (*repo.secondaryRepo).AddPoll(p)
+	if err == nil {
+		repo.cache[p.ID] = *p
+		repo.refreshPost(p.PostID)
+	}
+	return p, err
+}
+
+// DeletePoll calls the same method on the wrapped repo refreshing the post it was attached to.
+func (repo *pollRepository) DeletePoll(id uint) error {
+	p, err := repo.GetPoll(id)
+	if err != nil {
+		return err
+	}
+	err = (*repo.secondaryRepo).DeletePoll(id)
+	if err == nil {
+		delete(repo.cache, id)
+		repo.refreshPost(p.PostID)
+	}
+	return err
+}
+
+// GetVote calls the same method on the wrapped repo.
+func (repo *pollRepository) GetVote(pollID uint, username string) (*poll.Vote, error) {
+	return (*repo.secondaryRepo).GetVote(pollID, username)
+}
+
+// AddVote calls the same method on the wrapped repo.
+func (repo *pollRepository) AddVote(v *poll.Vote) (*poll.Vote, error) {
+	return (*repo.secondaryRepo).AddVote(v)
+}
+
+// GetTally calls the same method on the wrapped repo.
+func (repo *pollRepository) GetTally(pollID uint) (*poll.Results, error) {
+	return (*repo.secondaryRepo).GetTally(pollID)
+}
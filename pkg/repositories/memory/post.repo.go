@@ -1,7 +1,10 @@
 package memory
 
 import (
+	"time"
+
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 //postRepository ...
@@ -76,8 +79,8 @@ func (repo *postRepository) UpdatePost(pos *post.Post, id uint) (*post.Post, err
 }
 
 // SearchPost gets all Posts under specfications
-func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order post.SortOrder, limit int, offset int) ([]*post.Post, error) {
-	pos, err := (*repo.secondaryRepo).SearchPost(pattern, by, order, limit, offset)
+func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order post.SortOrder, limit int, offset int, filter maturity.Filter) ([]*post.Post, error) {
+	pos, err := (*repo.secondaryRepo).SearchPost(pattern, by, order, limit, offset, filter)
 	if err == nil {
 		for _, p := range pos {
 			repo.cache[p.ID] = *p
@@ -100,6 +103,22 @@ func (repo *postRepository) GetPostStar(id uint, username string) (*post.Star, e
 	return s, nil
 }
 
+// GetPostStars calls the same method on the wrapped repo.
+func (repo *postRepository) GetPostStars(id uint, limit, offset int) ([]*post.Star, error) {
+	return (*repo.secondaryRepo).GetPostStars(id, limit, offset)
+}
+
+// GetTopRatedPosts calls the same method on the wrapped repo caching the results.
+func (repo *postRepository) GetTopRatedPosts(since time.Time, limit, offset int, filter maturity.Filter) ([]*post.Post, error) {
+	pos, err := (*repo.secondaryRepo).GetTopRatedPosts(since, limit, offset, filter)
+	if err == nil {
+		for _, p := range pos {
+			repo.cache[p.ID] = *p
+		}
+	}
+	return pos, err
+}
+
 //DeletePostStar deletes the star stored under given postid and username
 func (repo *postRepository) DeletePostStar(id uint, username string) error {
 	_, found := repo.cache[id]
@@ -147,3 +166,42 @@ func (repo *postRepository) UpdatePostStar(id uint, star *post.Star) (*post.Star
 	}
 	return s, nil
 }
+
+// UpdatePostStatus moves the post under the given id to the given stage of the editorial pipeline
+func (repo *postRepository) UpdatePostStatus(id uint, status post.Status) (*post.Post, error) {
+	p, err := (*repo.secondaryRepo).UpdatePostStatus(id, status)
+	if err == nil {
+		repo.cache[p.ID] = *p
+	}
+	return p, err
+}
+
+// AddDraftReview persists the given review on the draft under the given id
+func (repo *postRepository) AddDraftReview(id uint, review *post.Review) (*post.Review, error) {
+	return (*repo.secondaryRepo).AddDraftReview(id, review)
+}
+
+// GetDraftReviews gets the reviews left on the draft under the given id
+func (repo *postRepository) GetDraftReviews(id uint) ([]*post.Review, error) {
+	return (*repo.secondaryRepo).GetDraftReviews(id)
+}
+
+// GetChannelDrafts gets the unpublished posts of the given channel
+func (repo *postRepository) GetChannelDrafts(channelUsername string, status post.Status, limit, offset int) ([]*post.Post, error) {
+	return (*repo.secondaryRepo).GetChannelDrafts(channelUsername, status, limit, offset)
+}
+
+// AddRevision calls the same method on the wrapped repo.
+func (repo *postRepository) AddRevision(id uint, revision *post.Revision) (*post.Revision, error) {
+	return (*repo.secondaryRepo).AddRevision(id, revision)
+}
+
+// GetRevisions calls the same method on the wrapped repo.
+func (repo *postRepository) GetRevisions(id uint) ([]*post.Revision, error) {
+	return (*repo.secondaryRepo).GetRevisions(id)
+}
+
+// GetRevision calls the same method on the wrapped repo.
+func (repo *postRepository) GetRevision(id uint, revisionID int) (*post.Revision, error) {
+	return (*repo.secondaryRepo).GetRevision(id, revisionID)
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/reaction"
+)
+
+type reactionRepository struct {
+	cache         map[string][]*reaction.Count
+	secondaryRepo *reaction.Repository
+}
+
+// NewReactionRepository returns a struct that implements the reaction.Repository using
+// a cached based implementation.
+// A database implementation of the same interface needs to be passed so that it can be
+// consulted when the caches aren't enough.
+func NewReactionRepository(secondaryRepo *reaction.Repository) reaction.Repository {
+	return &reactionRepository{make(map[string][]*reaction.Count), secondaryRepo}
+}
+
+// reactionCacheKey is just a helper function that returns the key the counts of the target are cached under
+func reactionCacheKey(targetType reaction.TargetType, targetID int) string {
+	return fmt.Sprintf("%s:%d", targetType, targetID)
+}
+
+// GetReaction calls the same method on the wrapped repo.
+func (repo *reactionRepository) GetReaction(targetType reaction.TargetType, targetID int, username, emoji string) (*reaction.Reaction, error) {
+	return (*repo.secondaryRepo).GetReaction(targetType, targetID, username, emoji)
+}
+
+// AddReaction calls the same method on the wrapped repo invalidating the cached counts of the target.
+func (repo *reactionRepository) AddReaction(r *reaction.Reaction) (*reaction.Reaction, error) {
+	r, err := (*repo.secondaryRepo).AddReaction(r)
+	if err == nil {
+		delete(repo.cache, reactionCacheKey(r.TargetType, r.TargetID))
+	}
+	return r, err
+}
+
+// DeleteReaction calls the same method on the wrapped repo invalidating the cached counts of the target.
+func (repo *reactionRepository) DeleteReaction(targetType reaction.TargetType, targetID int, username, emoji string) error {
+	err := (*repo.secondaryRepo).DeleteReaction(targetType, targetID, username, emoji)
+	if err == nil {
+		delete(repo.cache, reactionCacheKey(targetType, targetID))
+	}
+	return err
+}
+
+// GetCounts returns the counts of the target from the cache, if found, or from the wrapped repository.
+// Only counts requested without a viewer are cached since the rest depend on who's asking.
+func (repo *reactionRepository) GetCounts(targetType reaction.TargetType, targetID int, viewer string) ([]*reaction.Count, error) {
+	if viewer != "" {
+		return (*repo.secondaryRepo).GetCounts(targetType, targetID, viewer)
+	}
+	key := reactionCacheKey(targetType, targetID)
+	if counts, ok := repo.cache[key]; ok {
+		result := make([]*reaction.Count, 0, len(counts))
+		for _, c := range counts {
+			temp := *c
+			result = append(result, &temp)
+		}
+		return result, nil
+	}
+	counts, err := (*repo.secondaryRepo).GetCounts(targetType, targetID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	cached := make([]*reaction.Count, 0, len(counts))
+	for _, c := range counts {
+		temp := *c
+		cached = append(cached, &temp)
+	}
+	repo.cache[key] = cached
+	return counts, nil
+}
+
+// GetReactors calls the same method on the wrapped repo.
+func (repo *reactionRepository) GetReactors(targetType reaction.TargetType, targetID int, emoji string, limit, offset int) ([]*reaction.Reaction, error) {
+	return (*repo.secondaryRepo).GetReactors(targetType, targetID, emoji, limit, offset)
+}
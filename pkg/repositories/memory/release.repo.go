@@ -2,6 +2,7 @@ package memory
 
 import (
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 //releaseRepository ...
@@ -33,8 +34,8 @@ func (repo *releaseRepository) GetRelease(id int) (*release.Release, error) {
 }
 
 // SearchRelease calls the same method on the wrapped repo with a little caching in between.
-func (repo *releaseRepository) SearchRelease(pattern string, by release.SortBy, order release.SortOrder, limit int, offset int) ([]*release.Release, error) {
-	result, err := (*repo.secondaryRepo).SearchRelease(pattern, by, order, limit, offset)
+func (repo *releaseRepository) SearchRelease(pattern string, by release.SortBy, order release.SortOrder, limit int, offset int, filter maturity.Filter) ([]*release.Release, error) {
+	result, err := (*repo.secondaryRepo).SearchRelease(pattern, by, order, limit, offset, filter)
 	if err == nil {
 		for _, r := range result {
 			rTemp := *r
@@ -47,10 +48,15 @@ func (repo *releaseRepository) SearchRelease(pattern string, by release.SortBy,
 // DeleteRelease calls the same method on the wrapped repo while also cleaning the cache
 // when appropriate.
 func (repo *releaseRepository) DeleteRelease(id int) error {
+	// translations of the release are unlinked along with it
+	translations, _ := (*repo.secondaryRepo).GetTranslations(id)
 	err := (*repo.secondaryRepo).DeleteRelease(id)
 	if err == nil {
 		// If deletion is successful, it also tries to delete the user from its cache.
 		delete(repo.cache, id)
+		for _, t := range translations {
+			delete(repo.cache, t.ReleaseID)
+		}
 	}
 	return err
 }
@@ -72,3 +78,98 @@ func (repo *releaseRepository) UpdateRelease(rel *release.Release) (*release.Rel
 	}
 	return r, err
 }
+
+// AddRevision calls the same method on the wrapped repo.
+func (repo *releaseRepository) AddRevision(id int, revision *release.Revision) (*release.Revision, error) {
+	return (*repo.secondaryRepo).AddRevision(id, revision)
+}
+
+// GetRevisions calls the same method on the wrapped repo.
+func (repo *releaseRepository) GetRevisions(id int) ([]*release.Revision, error) {
+	return (*repo.secondaryRepo).GetRevisions(id)
+}
+
+// GetRevision calls the same method on the wrapped repo.
+func (repo *releaseRepository) GetRevision(id int, revisionID int) (*release.Revision, error) {
+	return (*repo.secondaryRepo).GetRevision(id, revisionID)
+}
+
+// recache is just a helper function that refetches the release under the given id
+// from the wrapped repo after its pages or translation link change
+func (repo *releaseRepository) recache(id int) {
+	delete(repo.cache, id)
+	_, _ = repo.GetRelease(id)
+}
+
+// AddPages calls the same method on the wrapped repo refreshing the cached release.
+func (repo *releaseRepository) AddPages(id int, pages []*release.Page) error {
+	err := (*repo.secondaryRepo).AddPages(id, pages)
+	if err == nil {
+		repo.recache(id)
+	}
+	return err
+}
+
+// UpdatePage calls the same method on the wrapped repo refreshing the cached release.
+func (repo *releaseRepository) UpdatePage(id int, page *release.Page) error {
+	err := (*repo.secondaryRepo).UpdatePage(id, page)
+	if err == nil {
+		repo.recache(id)
+	}
+	return err
+}
+
+// ReorderPages calls the same method on the wrapped repo refreshing the cached release.
+func (repo *releaseRepository) ReorderPages(id int, numbers []int) error {
+	err := (*repo.secondaryRepo).ReorderPages(id, numbers)
+	if err == nil {
+		repo.recache(id)
+	}
+	return err
+}
+
+// DeletePage calls the same method on the wrapped repo refreshing the cached release.
+func (repo *releaseRepository) DeletePage(id int, number int) error {
+	err := (*repo.secondaryRepo).DeletePage(id, number)
+	if err == nil {
+		repo.recache(id)
+	}
+	return err
+}
+
+// GetCreditsOf calls the same method on the wrapped repo.
+func (repo *releaseRepository) GetCreditsOf(username string) ([]*release.Work, error) {
+	return (*repo.secondaryRepo).GetCreditsOf(username)
+}
+
+// GetTranslations calls the same method on the wrapped repo.
+func (repo *releaseRepository) GetTranslations(originalID int) ([]*release.Variant, error) {
+	return (*repo.secondaryRepo).GetTranslations(originalID)
+}
+
+// SetTranslationPermission calls the same method on the wrapped repo refreshing the cached release.
+func (repo *releaseRepository) SetTranslationPermission(id int, permitted bool) error {
+	err := (*repo.secondaryRepo).SetTranslationPermission(id, permitted)
+	if err == nil {
+		repo.recache(id)
+	}
+	return err
+}
+
+// DeleteTranslation calls the same method on the wrapped repo refreshing the cached release.
+func (repo *releaseRepository) DeleteTranslation(id int) error {
+	err := (*repo.secondaryRepo).DeleteTranslation(id)
+	if err == nil {
+		repo.recache(id)
+	}
+	return err
+}
+
+// ReplaceGenres calls the same method on the wrapped repo refreshing the changed releases.
+func (repo *releaseRepository) ReplaceGenres(from []string, to string) ([]int, error) {
+	ids, err := (*repo.secondaryRepo).ReplaceGenres(from, to)
+	for _, id := range ids {
+		repo.recache(id)
+	}
+	return ids, err
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/user"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 // userRepository ...
@@ -156,3 +157,15 @@ func (repo *userRepository) RemovePicture(username string) error {
 	}
 	return err
 }
+
+// SetContentFilter calls the same method on the wrapped repo with a lil caching in between.
+func (repo *userRepository) SetContentFilter(username string, filter *maturity.Filter) error {
+	err := (*repo.secondaryRepo).SetContentFilter(username, filter)
+	if err == nil {
+		err = repo.cacheUser(username)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/blob"
+)
+
+// blobRepository ...
+type blobRepository repository
+
+// NewBlobRepository returns a struct that implements the blob.Repository using
+// a postgres database
+func NewBlobRepository(DB *sql.DB, allRepos *map[string]interface{}) blob.Repository {
+	return &blobRepository{DB, allRepos}
+}
+
+// AddBlob records the given blob. Recording an existing blob clears the time it
+// was found unreferenced so it's not collected before it's counted again.
+func (repo *blobRepository) AddBlob(b *blob.Blob) (*blob.Blob, error) {
+	err := repo.db.QueryRow(`INSERT INTO "issue#1".blobs (name, size)
+							VALUES ($1, $2)
+							ON CONFLICT (name) DO UPDATE
+							SET unreferenced_since = NULL,
+							    size = GREATEST("issue#1".blobs.size, EXCLUDED.size)
+							RETURNING name, size, ref_count, creation_time, unreferenced_since`, b.Name, b.Size).
+		Scan(&b.Name, &b.Size, &b.RefCount, &b.CreationTime, &b.UnreferencedSince)
+	if err != nil {
+		return nil, fmt.Errorf("insertion of blob failed because of: %v", err)
+	}
+	return b, nil
+}
+
+// GetBlob gets the blob of the given name
+func (repo *blobRepository) GetBlob(name string) (*blob.Blob, error) {
+	var b = new(blob.Blob)
+	err := repo.db.QueryRow(`SELECT name, size, ref_count, creation_time, unreferenced_since
+							FROM "issue#1".blobs
+							WHERE name = $1`, name).Scan(&b.Name, &b.Size, &b.RefCount, &b.CreationTime, &b.UnreferencedSince)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, blob.ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("querying for blob failed because of: %v", err)
+	}
+	return b, nil
+}
+
+// CountReferences counts the user pictures, channel pictures and banners, image releases,
+// comic pages and audio releases referring to every blob
+func (repo *blobRepository) CountReferences() error {
+	_, err := repo.db.Exec(`WITH refs AS (
+								SELECT image_name AS name FROM "issue#1".user_avatars
+								UNION ALL
+								SELECT image_name FROM "issue#1".channel_pictures
+								UNION ALL
+								SELECT image_name FROM "issue#1".channel_banners
+								UNION ALL
+								SELECT image_name FROM "issue#1".releases_image_based
+								UNION ALL
+								SELECT image_name FROM "issue#1".release_pages
+								UNION ALL
+								SELECT audio_name FROM "issue#1".releases_audio_based
+							), counts AS (
+								SELECT blobs.name, COUNT(refs.name) AS ref_count
+								FROM "issue#1".blobs
+								LEFT JOIN refs ON refs.name = blobs.name
+								GROUP BY blobs.name
+							)
+							UPDATE "issue#1".blobs
+							SET ref_count = counts.ref_count,
+							    unreferenced_since = CASE
+							        WHEN counts.ref_count > 0 THEN NULL
+							        ELSE COALESCE(blobs.unreferenced_since, CURRENT_TIMESTAMP)
+							    END
+							FROM counts
+							WHERE counts.name = blobs.name`)
+	if err != nil {
+		return fmt.Errorf("counting of blob references failed because of: %v", err)
+	}
+	return nil
+}
+
+// DeleteUnreferenced removes the blobs found unreferenced before the given time
+func (repo *blobRepository) DeleteUnreferenced(before time.Time) ([]string, error) {
+	rows, err := repo.db.Query(`DELETE FROM "issue#1".blobs
+								WHERE ref_count = 0 AND unreferenced_since < $1
+								RETURNING name`, before)
+	if err != nil {
+		return nil, fmt.Errorf("deletion of unreferenced blobs failed because of: %v", err)
+	}
+	defer rows.Close()
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
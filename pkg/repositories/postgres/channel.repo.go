@@ -26,8 +26,8 @@ func NewChannelRepository(DB *sql.DB, allRepos *map[string]interface{}) channel.
 func (repo *channelRepository) AddChannel(c *channel.Channel) (*channel.Channel, error) {
 	var err error
 
-	_, err = repo.db.Exec(`INSERT INTO "issue#1".channels (username, name, description)
-							VALUES ($1, $2, $3)`, c.ChannelUsername, c.Name, c.Description)
+	_, err = repo.db.Exec(`INSERT INTO "issue#1".channels (username, name, description, private)
+							VALUES ($1, $2, $3, $4)`, c.ChannelUsername, c.Name, c.Description, c.Private)
 
 	if err != nil {
 		return nil, fmt.Errorf("insertion of channel failed because of: %s", err.Error())
@@ -50,9 +50,9 @@ func (repo *channelRepository) GetChannel(channelUsername string) (*channel.Chan
 	var c = new(channel.Channel)
 
 	var creationTimeString string
-	err = repo.db.QueryRow(`SELECT name, COALESCE(description, ''), creation_time
+	err = repo.db.QueryRow(`SELECT name, COALESCE(description, ''), private, COALESCE(accent_color, ''), COALESCE(secondary_color, ''), creation_time
 							FROM "issue#1".channels
-							WHERE username = $1`, channelUsername).Scan(&c.Name, &c.Description, &creationTimeString)
+							WHERE username = $1`, channelUsername).Scan(&c.Name, &c.Description, &c.Private, &c.Theme.AccentColor, &c.Theme.SecondaryColor, &creationTimeString)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -75,7 +75,7 @@ func (repo *channelRepository) GetChannel(channelUsername string) (*channel.Chan
 	if err != nil {
 		return nil, fmt.Errorf("unable to get owner because of: %s", err.Error())
 	}
-	stickiedPosts, err := repo.GetStickiedPost(channelUsername)
+	pins, err := repo.GetPins(channelUsername)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get bookmarked posts because of: %s", err.Error())
 	}
@@ -95,13 +95,22 @@ func (repo *channelRepository) GetChannel(channelUsername string) (*channel.Chan
 	if err != nil {
 		return nil, fmt.Errorf("unable to get Picture because of: %s", err.Error())
 	}
+	bannerURL, err := repo.GetBanner(channelUsername)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get Banner because of: %s", err.Error())
+	}
 	c.AdminUsernames = admins
 	c.OwnerUsername = owner
-	c.StickiedPostIDs = stickiedPosts
+	c.Pins = pins
+	c.StickiedPostIDs = make([]uint, 0, len(pins))
+	for _, pin := range pins {
+		c.StickiedPostIDs = append(c.StickiedPostIDs, pin.PostID)
+	}
 	c.PostIDs = posts
 	c.ReleaseIDs = unOfficialReleases
 	c.OfficialReleaseIDs = officialReleases
 	c.PictureURL = pictureURL
+	c.BannerURL = bannerURL
 	c.ChannelUsername = channelUsername
 	return c, nil
 }
@@ -219,7 +228,11 @@ func (repo *channelRepository) GetPosts(username string) ([]uint, error) {
 
 	rows, err := repo.db.Query(`SELECT id
                 FROM "issue#1".posts
-                WHERE channel_from = $1`, username)
+                WHERE status = 'published' AND (channel_from = $1 OR id IN (
+                    SELECT post_id
+                    FROM "issue#1".post_crossposts
+                    WHERE channel_username = $1 AND accepted
+                ))`, username)
 	if err != nil {
 		return nil, fmt.Errorf("querying for posts failed because of: %v", err)
 	}
@@ -240,38 +253,55 @@ func (repo *channelRepository) GetPosts(username string) ([]uint, error) {
 	return PostList, nil
 }
 
-// GetUnOfficialRelease is just a helper function that gets the Stickied Post List
-func (repo *channelRepository) GetStickiedPost(username string) ([]uint, error) {
-	var stickied []uint
-
-	var Post uint
-
-	rows, err := repo.db.Query(`SELECT
-  post_id
-FROM
-  "issue#1".channel_stickies
-INNER JOIN "issue#1".posts ON channel_stickies.post_id =posts.id WHERE posts.channel_from=$1
- `, username)
+// GetPins is just a helper function that gets the unexpired pins of the channel in order
+func (repo *channelRepository) GetPins(channelUsername string) ([]*channel.Pin, error) {
+	pins := make([]*channel.Pin, 0)
+	rows, err := repo.db.Query(`SELECT post_id, position, expires_at, creation_time
+								FROM "issue#1".channel_stickies
+								WHERE channel_username = $1
+								  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+								ORDER BY position, creation_time`, channelUsername)
 	if err != nil {
-		return nil, fmt.Errorf("querying for posts failed because of: %v", err)
+		return nil, fmt.Errorf("querying for pins failed because of: %v", err)
 	}
 	defer rows.Close()
-
-	i := 0
 	for rows.Next() {
-		err := rows.Scan(&Post)
+		pin := new(channel.Pin)
+		var expiresAt pq.NullTime
+		err := rows.Scan(&pin.PostID, &pin.Position, &expiresAt, &pin.CreationTime)
 		if err != nil {
 			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
 		}
-		stickied = append(stickied, Post)
-		i++
+		if expiresAt.Valid {
+			pin.ExpiresAt = &expiresAt.Time
+		}
+		pins = append(pins, pin)
 	}
 	err = rows.Err()
 	if err != nil {
 		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
 	}
+	return pins, nil
+}
 
-	return stickied, nil
+// renumberPins is just a helper function that clears out the expired pins of the channel
+// and closes the gaps in the positions of the rest
+func (repo *channelRepository) renumberPins(channelUsername string) error {
+	_, err := repo.db.Exec(`DELETE FROM "issue#1".channel_stickies
+							WHERE channel_username = $1 AND expires_at <= CURRENT_TIMESTAMP`, channelUsername)
+	if err != nil {
+		return fmt.Errorf("deletion of expired pins failed because of: %v", err)
+	}
+	_, err = repo.db.Exec(`UPDATE "issue#1".channel_stickies
+							SET position = O.position - 1
+							FROM (SELECT post_id, ROW_NUMBER() OVER (ORDER BY position, creation_time)
+								  FROM "issue#1".channel_stickies
+								  WHERE channel_username = $1) AS O (post_id, position)
+							WHERE channel_stickies.channel_username = $1 AND channel_stickies.post_id = O.post_id`, channelUsername)
+	if err != nil {
+		return fmt.Errorf("renumbering of pins failed because of: %v", err)
+	}
+	return nil
 }
 
 // GetOwner is just a helper function that gets the Owner
@@ -315,13 +345,13 @@ func (repo *channelRepository) SearchChannels(pattern string, sortBy channel.Sor
 	var rows *sql.Rows
 	var query string
 	if pattern == "" {
-		query = fmt.Sprintf(`(SELECT username,name, COALESCE(description, ''),creation_time 
+		query = fmt.Sprintf(`(SELECT username,name, COALESCE(description, ''),private,COALESCE(accent_color, ''),COALESCE(secondary_color, ''),creation_time 
 												FROM "issue#1".channels) 
 												ORDER BY %s %s NULLS LAST
 												LIMIT $1 OFFSET $2`, sortBy, sortOrder)
 		rows, err = repo.db.Query(query, limit, offset)
 	} else {
-		query = `SELECT username,name, COALESCE(description, ''),creation_time 
+		query = `SELECT username,name, COALESCE(description, ''),private,COALESCE(accent_color, ''),COALESCE(secondary_color, ''),creation_time 
 			from channels
 			where username ilike '%' || $1 || '%'  OR name  ilike '%' || $1|| '%'
 			LIMIT $2 OFFSET $3`
@@ -334,7 +364,7 @@ func (repo *channelRepository) SearchChannels(pattern string, sortBy channel.Sor
 	var creationTimeString string
 	for rows.Next() {
 		c := channel.Channel{}
-		err := rows.Scan(&c.ChannelUsername, &c.Name, &c.Description, &creationTimeString)
+		err := rows.Scan(&c.ChannelUsername, &c.Name, &c.Description, &c.Private, &c.Theme.AccentColor, &c.Theme.SecondaryColor, &creationTimeString)
 		if err != nil {
 			return nil, fmt.Errorf("scanning from rows failed because: %s", err.Error())
 		}
@@ -356,7 +386,7 @@ func (repo *channelRepository) SearchChannels(pattern string, sortBy channel.Sor
 		if err != nil {
 			return nil, fmt.Errorf("unable to get owner because of: %s", err.Error())
 		}
-		stickiedPosts, err := repo.GetStickiedPost(c.ChannelUsername)
+		pins, err := repo.GetPins(c.ChannelUsername)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get stickied posts because of: %s", err.Error())
 		}
@@ -376,13 +406,22 @@ func (repo *channelRepository) SearchChannels(pattern string, sortBy channel.Sor
 		if err != nil {
 			return nil, fmt.Errorf("unable to get picture because of: %s", err.Error())
 		}
+		bannerURL, err := repo.GetBanner(c.ChannelUsername)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get banner because of: %s", err.Error())
+		}
 		c.AdminUsernames = admins
 		c.OwnerUsername = owner
-		c.StickiedPostIDs = stickiedPosts
+		c.Pins = pins
+		c.StickiedPostIDs = make([]uint, 0, len(pins))
+		for _, pin := range pins {
+			c.StickiedPostIDs = append(c.StickiedPostIDs, pin.PostID)
+		}
 		c.PostIDs = posts
 		c.ReleaseIDs = unOfficialReleases
 		c.OfficialReleaseIDs = officialReleases
 		c.PictureURL = pictureURL
+		c.BannerURL = bannerURL
 		channels = append(channels, &c)
 	}
 	err = rows.Err()
@@ -518,40 +557,72 @@ func (repo *channelRepository) DeleteReleaseFromOfficialCatalog(channelUsername
 	return nil
 }
 
-// StickyPost stickies a post on channel channelUsername
-func (repo *channelRepository) StickyPost(channelUsername string, postID uint) error {
-	a, err := repo.GetStickiedPost(channelUsername)
+// StickyPost stickies a post on channel channelUsername at the position of the given pin
+// moving the pins at and after it down
+func (repo *channelRepository) StickyPost(channelUsername string, pin *channel.Pin) error {
+	err := repo.renumberPins(channelUsername)
 	if err != nil {
-		return fmt.Errorf("getting stickied posts failed because of: %s", err.Error())
+		return err
 	}
-	if len(a) == 2 {
-		return channel.ErrStickiedPostFull
-	} else {
-		_, err := repo.db.Exec(`INSERT INTO channel_stickies (post_id)
-							VALUES ($1)
-							ON CONFLICT DO NOTHING`, postID)
+	_, err = repo.db.Exec(`UPDATE "issue#1".channel_stickies
+							SET position = position + 1
+							WHERE channel_username = $1 AND position >= $2`, channelUsername, pin.Position)
+	if err != nil {
+		return fmt.Errorf("moving of pins failed because of: %v", err)
+	}
+	var expiresAt pq.NullTime
+	if pin.ExpiresAt != nil {
+		expiresAt = pq.NullTime{Time: *pin.ExpiresAt, Valid: true}
+	}
+	_, err = repo.db.Exec(`INSERT INTO "issue#1".channel_stickies (channel_username, post_id, position, expires_at)
+							VALUES ($1, $2, $3, $4)`, channelUsername, pin.PostID, pin.Position, expiresAt)
+	if err != nil {
 		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
-		if err != nil {
-			if pgErr, isPGErr := err.(pq.Error); !isPGErr {
-				if pgErr.Code != foreignKeyViolationErrorCode {
-					return channel.ErrPostNotFound
+		const uniqueKeyViolationErrorCode = pq.ErrorCode("23505")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr {
+			switch pgErr.Code {
+			case foreignKeyViolationErrorCode:
+				if pgErr.Constraint == "channel_stickies_channel_username_fkey" {
+					return channel.ErrChannelNotFound
 				}
-				return fmt.Errorf("inserting into channel_stickies failed because of: %s", err.Error())
+				return channel.ErrPostNotFound
+			case uniqueKeyViolationErrorCode:
+				return channel.ErrPostAlreadyStickied
 			}
 		}
+		return fmt.Errorf("inserting into channel_stickies failed because of: %s", err.Error())
+	}
+	return nil
+}
+
+// ReorderStickiedPosts sets the positions of the stickied posts of channel channelUsername
+// to follow the given order
+func (repo *channelRepository) ReorderStickiedPosts(channelUsername string, postIDs []uint) error {
+	ids := make(pq.Int64Array, 0, len(postIDs))
+	for _, id := range postIDs {
+		ids = append(ids, int64(id))
+	}
+	_, err := repo.db.Exec(`UPDATE "issue#1".channel_stickies
+							SET position = O.position - 1
+							FROM unnest($2::integer[]) WITH ORDINALITY AS O (post_id, position)
+							WHERE channel_stickies.channel_username = $1 AND channel_stickies.post_id = O.post_id`, channelUsername, ids)
+	if err != nil {
+		return fmt.Errorf("reordering of pins failed because of: %v", err)
 	}
 	return nil
 }
 
 // DeleteStickiedPost deletes a stickied post from channel channelUsername
 func (repo *channelRepository) DeleteStickiedPost(channelUsername string, stickiedPostID uint) error {
-	//TODO
-	_, err := repo.db.Exec(`DELETE FROM "issue#1".channel_stickies
-							WHERE post_id = $1`, stickiedPostID)
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".channel_stickies
+							WHERE channel_username = $1 AND post_id = $2`, channelUsername, stickiedPostID)
 	if err != nil {
 		return fmt.Errorf("deletion of tuple from channel_stickie because of: %s", err.Error())
 	}
-	return nil
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return channel.ErrStickiedPostNotFound
+	}
+	return repo.renumberPins(channelUsername)
 }
 
 // AddPicture persists the given name as the image_name for the user under the given username
@@ -608,3 +679,692 @@ func (repo *channelRepository) GetPicture(ChannelUsername string) (string, error
 
 	return pictureURL, nil
 }
+
+// SetPrivacy sets the private flag of the channel channelUsername
+func (repo *channelRepository) SetPrivacy(channelUsername string, private bool) error {
+	_, err := repo.db.Exec(`UPDATE "issue#1".channels
+							SET private = $1
+							WHERE username = $2`, private, channelUsername)
+	if err != nil {
+		return fmt.Errorf("updating privacy of channel failed because of: %v", err)
+	}
+	return nil
+}
+
+// GetMembers gets a list of Members of channel channelUsername
+func (repo *channelRepository) GetMembers(channelUsername string) ([]*channel.Member, error) {
+	members := make([]*channel.Member, 0)
+	rows, err := repo.db.Query(`SELECT username, tier, join_time
+                FROM "issue#1".channel_members
+                WHERE channel_username = $1
+                ORDER BY join_time`, channelUsername)
+	if err != nil {
+		return nil, fmt.Errorf("querying for members failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		m := new(channel.Member)
+		var tier string
+		err := rows.Scan(&m.Username, &tier, &m.JoinTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		m.Tier = channel.Tier(tier)
+		members = append(members, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return members, nil
+}
+
+// GetMember gets the membership of User username in the channel channelUsername
+func (repo *channelRepository) GetMember(channelUsername string, username string) (*channel.Member, error) {
+	m := channel.Member{Username: username}
+	var tier string
+	err := repo.db.QueryRow(`SELECT tier, join_time
+							FROM "issue#1".channel_members
+							WHERE channel_username = $1 AND username = $2`, channelUsername, username).Scan(&tier, &m.JoinTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, channel.ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("querying for member failed because of: %v", err)
+	}
+	m.Tier = channel.Tier(tier)
+	return &m, nil
+}
+
+// AddMember adds a User to the channel channelUsername as a member, updating the tier
+// if the user is already a member
+func (repo *channelRepository) AddMember(channelUsername string, member *channel.Member) error {
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".channel_members (channel_username, username, tier)
+							VALUES ($1, $2, $3)
+							ON CONFLICT (channel_username, username) DO UPDATE SET tier = EXCLUDED.tier`,
+		channelUsername, member.Username, string(member.Tier))
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			return channel.ErrUserNotFound
+		}
+		return fmt.Errorf("inserting into members failed because of: %v", err)
+	}
+	return nil
+}
+
+// RemoveMember removes User username from the members of channel channelUsername
+func (repo *channelRepository) RemoveMember(channelUsername string, username string) error {
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".channel_members
+							WHERE channel_username = $1 AND username = $2`, channelUsername, username)
+	if err != nil {
+		return fmt.Errorf("deletion of tuple from channel_members failed because of: %v", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("deletion of tuple from channel_members failed because of: %v", err)
+	}
+	if n == 0 {
+		return channel.ErrMemberNotFound
+	}
+	return nil
+}
+
+// GetJoinRequests gets the pending join requests of channel channelUsername
+func (repo *channelRepository) GetJoinRequests(channelUsername string) ([]*channel.JoinRequest, error) {
+	requests := make([]*channel.JoinRequest, 0)
+	rows, err := repo.db.Query(`SELECT username, COALESCE(message, ''), request_time
+                FROM "issue#1".channel_join_requests
+                WHERE channel_username = $1
+                ORDER BY request_time`, channelUsername)
+	if err != nil {
+		return nil, fmt.Errorf("querying for join requests failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		jr := new(channel.JoinRequest)
+		err := rows.Scan(&jr.Username, &jr.Message, &jr.RequestTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		requests = append(requests, jr)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return requests, nil
+}
+
+// AddJoinRequest persists a request by a User to join the channel channelUsername
+func (repo *channelRepository) AddJoinRequest(channelUsername string, request *channel.JoinRequest) (*channel.JoinRequest, error) {
+	err := repo.db.QueryRow(`INSERT INTO "issue#1".channel_join_requests (channel_username, username, message)
+							VALUES ($1, $2, $3)
+							RETURNING request_time`, channelUsername, request.Username, request.Message).Scan(&request.RequestTime)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		const uniqueKeyViolationErrorCode = pq.ErrorCode("23505")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr {
+			switch pgErr.Code {
+			case foreignKeyViolationErrorCode:
+				return nil, channel.ErrUserNotFound
+			case uniqueKeyViolationErrorCode:
+				return nil, channel.ErrJoinRequestAlreadyExists
+			}
+		}
+		return nil, fmt.Errorf("inserting into join requests failed because of: %v", err)
+	}
+	return request, nil
+}
+
+// DeleteJoinRequest removes the join request of User username from channel channelUsername
+func (repo *channelRepository) DeleteJoinRequest(channelUsername string, username string) error {
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".channel_join_requests
+							WHERE channel_username = $1 AND username = $2`, channelUsername, username)
+	if err != nil {
+		return fmt.Errorf("deletion of tuple from channel_join_requests failed because of: %v", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("deletion of tuple from channel_join_requests failed because of: %v", err)
+	}
+	if n == 0 {
+		return channel.ErrJoinRequestNotFound
+	}
+	return nil
+}
+
+// postStatsQuery aggregates the stars, comments and views received by the published posts of channel $1
+// between $2 and $3. Posts without activity are kept if they were created during the period.
+const postStatsQuery = `
+	SELECT id, title, star_count, comment_count, view_count, creation_time
+	FROM (
+	         SELECT p.id,
+	                p.title,
+	                p.creation_time,
+	                COALESCE(s.star_count, 0)    AS star_count,
+	                COALESCE(c.comment_count, 0) AS comment_count,
+	                COALESCE(v.view_count, 0)    AS view_count
+	         FROM "issue#1".posts p
+	                  LEFT JOIN
+	              (
+	                  SELECT post_id, SUM(star_count)
+	                  FROM "issue#1".post_stars
+	                  WHERE star_time BETWEEN $2 AND $3
+	                  GROUP BY post_id
+	              ) AS s (post_id, star_count) ON s.post_id = p.id
+	                  LEFT JOIN
+	              (
+	                  SELECT post_from, COUNT(*)
+	                  FROM "issue#1".comments
+	                  WHERE creation_time BETWEEN $2 AND $3
+	                  GROUP BY post_from
+	              ) AS c (post_id, comment_count) ON c.post_id = p.id
+	                  LEFT JOIN
+	              (
+	                  SELECT target_id, SUM(count)
+	                  FROM "issue#1".views
+	                  WHERE target_type = 'post'
+	                    AND bucket BETWEEN $2 AND $3
+	                  GROUP BY target_id
+	              ) AS v (post_id, view_count) ON v.post_id = p.id
+	         WHERE p.channel_from = $1
+	           AND p.status = 'published'
+	     ) AS ps
+	WHERE star_count > 0
+	   OR comment_count > 0
+	   OR view_count > 0
+	   OR creation_time BETWEEN $2 AND $3`
+
+// GetStats computes the analytics of channel channelUsername between from and to.
+func (repo *channelRepository) GetStats(channelUsername string, from, to time.Time, bucket channel.Bucket, topLimit int) (*channel.Stats, error) {
+	var err error
+	stats := &channel.Stats{
+		ChannelUsername: channelUsername,
+		From:            from,
+		To:              to,
+		Bucket:          bucket,
+	}
+
+	err = repo.db.QueryRow(`SELECT COUNT(*)
+							FROM "issue#1".feed_subscriptions
+							WHERE channel_username = $1 AND subscription_time <= $2`, channelUsername, to).Scan(&stats.SubscriberCount)
+	if err != nil {
+		return nil, fmt.Errorf("counting of subscribers failed because of: %v", err)
+	}
+
+	stats.Subscribers, err = repo.queryStatsPoints(`
+		SELECT b.bucket, COUNT(fs.channel_username)
+		FROM generate_series(date_trunc($2::text, $3::timestamptz), $4::timestamptz, ('1 ' || $2::text)::interval) AS b (bucket)
+		         LEFT JOIN "issue#1".feed_subscriptions fs
+		                   ON fs.channel_username = $1
+		                       AND fs.subscription_time < b.bucket + ('1 ' || $2::text)::interval
+		GROUP BY b.bucket
+		ORDER BY b.bucket`, channelUsername, string(bucket), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying for subscribers over time failed because of: %v", err)
+	}
+
+	stats.ActiveReaders, err = repo.queryStatsPoints(`
+		SELECT b.bucket, COUNT(DISTINCT a.username)
+		FROM generate_series(date_trunc($2::text, $3::timestamptz), $4::timestamptz, ('1 ' || $2::text)::interval) AS b (bucket)
+		         LEFT JOIN
+		     (
+		         SELECT c.commented_by, c.creation_time
+		         FROM "issue#1".comments c
+		                  JOIN "issue#1".posts p ON p.id = c.post_from
+		         WHERE p.channel_from = $1
+		           AND c.creation_time BETWEEN $3 AND $4
+		         UNION ALL
+		         SELECT s.username, s.star_time
+		         FROM "issue#1".post_stars s
+		                  JOIN "issue#1".posts p ON p.id = s.post_id
+		         WHERE p.channel_from = $1
+		           AND s.star_time BETWEEN $3 AND $4
+		     ) AS a (username, activity_time) ON date_trunc($2::text, a.activity_time) = b.bucket
+		GROUP BY b.bucket
+		ORDER BY b.bucket`, channelUsername, string(bucket), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying for active readers failed because of: %v", err)
+	}
+
+	stats.Views, err = repo.queryStatsPoints(`
+		SELECT b.bucket, COALESCE(SUM(v.count), 0)
+		FROM generate_series(date_trunc($2::text, $3::timestamptz), $4::timestamptz, ('1 ' || $2::text)::interval) AS b (bucket)
+		         LEFT JOIN
+		     (
+		         SELECT v.count, v.bucket
+		         FROM "issue#1".views v
+		                  JOIN "issue#1".posts p ON v.target_type = 'post' AND p.id = v.target_id
+		         WHERE p.channel_from = $1
+		           AND v.bucket BETWEEN $3 AND $4
+		         UNION ALL
+		         SELECT v.count, v.bucket
+		         FROM "issue#1".views v
+		                  JOIN "issue#1".releases r ON v.target_type = 'release' AND r.id = v.target_id
+		         WHERE r.owner_channel = $1
+		           AND v.bucket BETWEEN $3 AND $4
+		     ) AS v (count, bucket) ON date_trunc($2::text, v.bucket) = b.bucket
+		GROUP BY b.bucket
+		ORDER BY b.bucket`, channelUsername, string(bucket), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying for views over time failed because of: %v", err)
+	}
+
+	stats.Posts, err = repo.queryPostStats(fmt.Sprintf(`%s
+		ORDER BY creation_time DESC`, postStatsQuery), channelUsername, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying for post stats failed because of: %v", err)
+	}
+
+	stats.TopPosts, err = repo.queryPostStats(fmt.Sprintf(`%s
+		ORDER BY star_count + comment_count DESC, creation_time DESC
+		LIMIT $4`, postStatsQuery), channelUsername, from, to, topLimit)
+	if err != nil {
+		return nil, fmt.Errorf("querying for top posts failed because of: %v", err)
+	}
+
+	stats.Releases = make([]*channel.ReleaseStats, 0)
+	rows, err := repo.db.Query(fmt.Sprintf(`
+		SELECT r.id,
+		       COALESCE(SUM(ps.star_count), 0),
+		       COALESCE(SUM(ps.comment_count), 0),
+		       COALESCE((SELECT SUM(v.count)
+		                 FROM "issue#1".views v
+		                 WHERE v.target_type = 'release'
+		                   AND v.target_id = r.id
+		                   AND v.bucket BETWEEN $2 AND $3), 0)
+		FROM "issue#1".releases r
+		         JOIN "issue#1".post_contents pc ON pc.release_id = r.id
+		         JOIN (%s) AS ps ON ps.id = pc.post_id
+		WHERE r.owner_channel = $1
+		GROUP BY r.id
+		ORDER BY r.id`, postStatsQuery), channelUsername, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying for release stats failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rs := new(channel.ReleaseStats)
+		err := rows.Scan(&rs.ReleaseID, &rs.StarCount, &rs.CommentCount, &rs.ViewCount)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		stats.Releases = append(stats.Releases, rs)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+
+	return stats, nil
+}
+
+// queryStatsPoints is just a helper function that scans (time, count) rows
+func (repo *channelRepository) queryStatsPoints(query string, args ...interface{}) ([]*channel.StatsPoint, error) {
+	points := make([]*channel.StatsPoint, 0)
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		sp := new(channel.StatsPoint)
+		err := rows.Scan(&sp.Time, &sp.Count)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		points = append(points, sp)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return points, nil
+}
+
+// queryPostStats is just a helper function that scans rows of postStatsQuery
+func (repo *channelRepository) queryPostStats(query string, args ...interface{}) ([]*channel.PostStats, error) {
+	posts := make([]*channel.PostStats, 0)
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var creationTime time.Time
+	for rows.Next() {
+		ps := new(channel.PostStats)
+		err := rows.Scan(&ps.PostID, &ps.Title, &ps.StarCount, &ps.CommentCount, &ps.ViewCount, &creationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		posts = append(posts, ps)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return posts, nil
+}
+
+// AddBanner persists the given name as the banner image of the channel channelUsername
+func (repo *channelRepository) AddBanner(channelUsername string, name string) (string, error) {
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".channel_banners (channelname, image_name)
+								VALUES ($1, $2)
+								ON CONFLICT(channelname) DO UPDATE
+								SET image_name = EXCLUDED.image_name`, channelUsername, name)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			return "", channel.ErrChannelNotFound
+		}
+		return "", fmt.Errorf("inserting into channel_banners failed because of: %v", err)
+	}
+	return name, nil
+}
+
+// RemoveBanner removes the channel's tuple entry from the channel_banners table.
+func (repo *channelRepository) RemoveBanner(channelUsername string) error {
+	_, err := repo.db.Exec(`DELETE FROM "issue#1".channel_banners
+							WHERE channelname = $1`, channelUsername)
+	if err != nil {
+		return fmt.Errorf("deletion of tuple from channel_banners failed because of: %v", err)
+	}
+	return nil
+}
+
+// GetBanner gets the channel's tuple entry from the channel_banners table.
+func (repo *channelRepository) GetBanner(channelUsername string) (string, error) {
+	var bannerURL string
+	err := repo.db.QueryRow(`SELECT image_name
+                FROM "issue#1".channel_banners
+                WHERE channelname = $1`, channelUsername).Scan(&bannerURL)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("querying for banners failed because of: %v", err)
+	}
+	return bannerURL, nil
+}
+
+// SetTheme sets the accent colors of the channel channelUsername
+func (repo *channelRepository) SetTheme(channelUsername string, theme *channel.Theme) error {
+	_, err := repo.db.Exec(`UPDATE "issue#1".channels
+							SET accent_color = NULLIF($1, ''), secondary_color = NULLIF($2, '')
+							WHERE username = $3`, theme.AccentColor, theme.SecondaryColor, channelUsername)
+	if err != nil {
+		return fmt.Errorf("updating theme of channel failed because of: %v", err)
+	}
+	return nil
+}
+
+// GetPages gets the custom pages of channel channelUsername
+func (repo *channelRepository) GetPages(channelUsername string) ([]*channel.Page, error) {
+	pages := make([]*channel.Page, 0)
+	rows, err := repo.db.Query(`SELECT slug, title, content, position, creation_time, update_time
+                FROM "issue#1".channel_pages
+                WHERE channel_username = $1
+                ORDER BY position, creation_time`, channelUsername)
+	if err != nil {
+		return nil, fmt.Errorf("querying for pages failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p := new(channel.Page)
+		err := rows.Scan(&p.Slug, &p.Title, &p.Content, &p.Position, &p.CreationTime, &p.UpdateTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		pages = append(pages, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return pages, nil
+}
+
+// GetPage gets the custom page of channel channelUsername under the given slug
+func (repo *channelRepository) GetPage(channelUsername string, slug string) (*channel.Page, error) {
+	p := channel.Page{Slug: slug}
+	err := repo.db.QueryRow(`SELECT title, content, position, creation_time, update_time
+							FROM "issue#1".channel_pages
+							WHERE channel_username = $1 AND slug = $2`, channelUsername, slug).Scan(&p.Title, &p.Content, &p.Position, &p.CreationTime, &p.UpdateTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, channel.ErrPageNotFound
+		}
+		return nil, fmt.Errorf("querying for page failed because of: %v", err)
+	}
+	return &p, nil
+}
+
+// AddPage persists a custom page for channel channelUsername
+func (repo *channelRepository) AddPage(channelUsername string, page *channel.Page) (*channel.Page, error) {
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".channel_pages (channel_username, slug, title, content, position)
+							VALUES ($1, $2, $3, $4, $5)`, channelUsername, page.Slug, page.Title, page.Content, page.Position)
+	if err != nil {
+		const uniqueKeyViolationErrorCode = pq.ErrorCode("23505")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == uniqueKeyViolationErrorCode {
+			return nil, channel.ErrPageAlreadyExists
+		}
+		return nil, fmt.Errorf("insertion of page failed because of: %v", err)
+	}
+	return repo.GetPage(channelUsername, page.Slug)
+}
+
+// UpdatePage updates the custom page of channel channelUsername under the given slug
+// based on the passed in channel.Page struct.
+func (repo *channelRepository) UpdatePage(channelUsername string, slug string, page *channel.Page) (*channel.Page, error) {
+	result, err := repo.db.Exec(`UPDATE "issue#1".channel_pages
+							SET slug = $1,
+							    title = $2,
+							    content = $3,
+							    position = $4,
+							    update_time = CURRENT_TIMESTAMP
+							WHERE channel_username = $5 AND slug = $6`,
+		page.Slug, page.Title, page.Content, page.Position, channelUsername, slug)
+	if err != nil {
+		const uniqueKeyViolationErrorCode = pq.ErrorCode("23505")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == uniqueKeyViolationErrorCode {
+			return nil, channel.ErrPageAlreadyExists
+		}
+		return nil, fmt.Errorf("updating of page failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, channel.ErrPageNotFound
+	}
+	return repo.GetPage(channelUsername, page.Slug)
+}
+
+// DeletePage removes the custom page of channel channelUsername under the given slug
+func (repo *channelRepository) DeletePage(channelUsername string, slug string) error {
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".channel_pages
+							WHERE channel_username = $1 AND slug = $2`, channelUsername, slug)
+	if err != nil {
+		return fmt.Errorf("deletion of tuple from channel_pages failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return channel.ErrPageNotFound
+	}
+	return nil
+}
+
+// GetBlockedUsers gets the users blocked or muted on channel channelUsername
+func (repo *channelRepository) GetBlockedUsers(channelUsername string) ([]*channel.Block, error) {
+	blocks := make([]*channel.Block, 0)
+	rows, err := repo.db.Query(`SELECT username, mode, COALESCE(reason, ''), COALESCE(blocked_by, ''), creation_time
+                FROM "issue#1".channel_blocks
+                WHERE channel_username = $1
+                ORDER BY creation_time DESC`, channelUsername)
+	if err != nil {
+		return nil, fmt.Errorf("querying for blocked users failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		b := new(channel.Block)
+		var mode string
+		err := rows.Scan(&b.Username, &mode, &b.Reason, &b.BlockedBy, &b.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		b.Mode = channel.BlockMode(mode)
+		blocks = append(blocks, b)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return blocks, nil
+}
+
+// GetBlockedUser gets the block of User username on channel channelUsername
+func (repo *channelRepository) GetBlockedUser(channelUsername string, username string) (*channel.Block, error) {
+	b := channel.Block{Username: username}
+	var mode string
+	err := repo.db.QueryRow(`SELECT mode, COALESCE(reason, ''), COALESCE(blocked_by, ''), creation_time
+							FROM "issue#1".channel_blocks
+							WHERE channel_username = $1 AND username = $2`, channelUsername, username).Scan(&mode, &b.Reason, &b.BlockedBy, &b.CreationTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, channel.ErrBlockNotFound
+		}
+		return nil, fmt.Errorf("querying for blocked user failed because of: %v", err)
+	}
+	b.Mode = channel.BlockMode(mode)
+	return &b, nil
+}
+
+// BlockUser blocks or mutes User username on channel channelUsername replacing any previous block
+func (repo *channelRepository) BlockUser(channelUsername string, block *channel.Block) (*channel.Block, error) {
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".channel_blocks (channel_username, username, mode, reason, blocked_by)
+							VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+							ON CONFLICT (channel_username, username) DO UPDATE
+							SET mode = EXCLUDED.mode, reason = EXCLUDED.reason, blocked_by = EXCLUDED.blocked_by`,
+		channelUsername, block.Username, string(block.Mode), block.Reason, block.BlockedBy)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			return nil, channel.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("inserting into channel_blocks failed because of: %v", err)
+	}
+	return repo.GetBlockedUser(channelUsername, block.Username)
+}
+
+// UnblockUser removes the block of User username on channel channelUsername
+func (repo *channelRepository) UnblockUser(channelUsername string, username string) error {
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".channel_blocks
+							WHERE channel_username = $1 AND username = $2`, channelUsername, username)
+	if err != nil {
+		return fmt.Errorf("deletion of tuple from channel_blocks failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return channel.ErrBlockNotFound
+	}
+	return nil
+}
+
+// crosspostColumns are the columns selected by the crosspost queries, the posts table needs to be joined as p
+const crosspostColumns = `x.post_id, x.channel_username, COALESCE(p.channel_from, ''), COALESCE(x.requested_by, ''), x.accepted, x.creation_time`
+
+// queryCrossposts is just a helper function that runs the given crosspost query
+func (repo *channelRepository) queryCrossposts(query string, args ...interface{}) ([]*channel.Crosspost, error) {
+	crossposts := make([]*channel.Crosspost, 0)
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying for crossposts failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		x := new(channel.Crosspost)
+		err := rows.Scan(&x.PostID, &x.ChannelUsername, &x.OriginChannel, &x.RequestedBy, &x.Accepted, &x.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		crossposts = append(crossposts, x)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return crossposts, nil
+}
+
+// GetCrossposts gets the accepted or pending crossposts of channel channelUsername, most recent first
+func (repo *channelRepository) GetCrossposts(channelUsername string, accepted bool) ([]*channel.Crosspost, error) {
+	return repo.queryCrossposts(`SELECT `+crosspostColumns+`
+                FROM "issue#1".post_crossposts x
+                INNER JOIN "issue#1".posts p ON p.id = x.post_id
+                WHERE x.channel_username = $1 AND x.accepted = $2
+                ORDER BY x.creation_time DESC`, channelUsername, accepted)
+}
+
+// GetPostCrossposts gets the crossposts of the Post postID into all channels
+func (repo *channelRepository) GetPostCrossposts(postID uint) ([]*channel.Crosspost, error) {
+	return repo.queryCrossposts(`SELECT `+crosspostColumns+`
+                FROM "issue#1".post_crossposts x
+                INNER JOIN "issue#1".posts p ON p.id = x.post_id
+                WHERE x.post_id = $1
+                ORDER BY x.creation_time`, postID)
+}
+
+// GetCrosspost gets the crosspost of Post postID into channel channelUsername
+func (repo *channelRepository) GetCrosspost(channelUsername string, postID uint) (*channel.Crosspost, error) {
+	x := new(channel.Crosspost)
+	err := repo.db.QueryRow(`SELECT `+crosspostColumns+`
+                FROM "issue#1".post_crossposts x
+                INNER JOIN "issue#1".posts p ON p.id = x.post_id
+                WHERE x.channel_username = $1 AND x.post_id = $2`, channelUsername, postID).Scan(&x.PostID, &x.ChannelUsername, &x.OriginChannel, &x.RequestedBy, &x.Accepted, &x.CreationTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, channel.ErrCrosspostNotFound
+		}
+		return nil, fmt.Errorf("querying for crosspost failed because of: %v", err)
+	}
+	return x, nil
+}
+
+// AddCrosspost persists the crosspost of a Post into channel channelUsername
+func (repo *channelRepository) AddCrosspost(channelUsername string, crosspost *channel.Crosspost) (*channel.Crosspost, error) {
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".post_crossposts (post_id, channel_username, requested_by, accepted)
+							VALUES ($1, $2, NULLIF($3, ''), $4)`,
+		crosspost.PostID, channelUsername, crosspost.RequestedBy, crosspost.Accepted)
+	if err != nil {
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr {
+			switch pgErr.Code {
+			case pq.ErrorCode("23505"):
+				return nil, channel.ErrCrosspostAlreadyExists
+			case pq.ErrorCode("23503"):
+				return nil, channel.ErrPostNotFound
+			}
+		}
+		return nil, fmt.Errorf("inserting into post_crossposts failed because of: %v", err)
+	}
+	return repo.GetCrosspost(channelUsername, crosspost.PostID)
+}
+
+// AcceptCrosspost marks the crosspost of Post postID into channel channelUsername as accepted
+func (repo *channelRepository) AcceptCrosspost(channelUsername string, postID uint) error {
+	result, err := repo.db.Exec(`UPDATE "issue#1".post_crossposts
+							SET accepted = true
+							WHERE channel_username = $1 AND post_id = $2`, channelUsername, postID)
+	if err != nil {
+		return fmt.Errorf("updating of post_crossposts failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return channel.ErrCrosspostNotFound
+	}
+	return nil
+}
+
+// DeleteCrosspost removes the crosspost of Post postID into channel channelUsername
+func (repo *channelRepository) DeleteCrosspost(channelUsername string, postID uint) error {
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".post_crossposts
+							WHERE channel_username = $1 AND post_id = $2`, channelUsername, postID)
+	if err != nil {
+		return fmt.Errorf("deletion of tuple from post_crossposts failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return channel.ErrCrosspostNotFound
+	}
+	return nil
+}
@@ -59,7 +59,7 @@ func (repo commentRepository) GetComment(id int) (*comment.Comment, error) {
 
 // GetComments returns all comments in the database that match the given post
 // id.
-func (repo commentRepository) GetComments(postID int, by string, order string, limit, offset int) ([]*comment.Comment, error) {
+func (repo commentRepository) GetComments(postID int, by string, order string, limit, offset int, viewer string) ([]*comment.Comment, error) {
 	{ // block checks if post exits
 		var found bool
 		err := repo.db.QueryRow(`
@@ -80,9 +80,16 @@ func (repo commentRepository) GetComments(postID int, by string, order string, l
 	query := fmt.Sprintf(`SELECT id,commented_by,content,reply_to,creation_time
 			FROM comments
 			WHERE post_from = $1
+			  AND (commented_by = $4 OR NOT EXISTS (
+			      SELECT 1
+			      FROM channel_blocks b
+			               JOIN posts p ON p.channel_from = b.channel_username
+			      WHERE p.id = comments.post_from
+			        AND b.username = comments.commented_by
+			        AND b.mode = 'mute'))
 			ORDER BY %s %s
 			LIMIT $2 OFFSET $3`, by, order)
-	rows, err = repo.db.Query(query, postID, limit, offset)
+	rows, err = repo.db.Query(query, postID, limit, offset, viewer)
 	if err != nil {
 		return nil, fmt.Errorf("querying for comments failed because of: %v", err)
 	}
@@ -107,7 +114,7 @@ func (repo commentRepository) GetComments(postID int, by string, order string, l
 
 // GetComments returns all comments in the database that match the given reply_to
 // id.
-func (repo commentRepository) GetReplies(commentID int, by string, order string, limit, offset int) ([]*comment.Comment, error) {
+func (repo commentRepository) GetReplies(commentID int, by string, order string, limit, offset int, viewer string) ([]*comment.Comment, error) {
 	{ // block checks if root comment exits
 		var found bool
 		err := repo.db.QueryRow(`
@@ -128,9 +135,16 @@ func (repo commentRepository) GetReplies(commentID int, by string, order string,
 	query := fmt.Sprintf(`SELECT id,commented_by,content,post_from,creation_time
 			FROM comments
 			WHERE reply_to = $1
+			  AND (commented_by = $4 OR NOT EXISTS (
+			      SELECT 1
+			      FROM channel_blocks b
+			               JOIN posts p ON p.channel_from = b.channel_username
+			      WHERE p.id = comments.post_from
+			        AND b.username = comments.commented_by
+			        AND b.mode = 'mute'))
 			ORDER BY %s %s
 			LIMIT $2 OFFSET $3`, by, order)
-	rows, err = repo.db.Query(query, commentID, limit, offset)
+	rows, err = repo.db.Query(query, commentID, limit, offset, viewer)
 	if err != nil {
 		return nil, fmt.Errorf("querying for comments failed because of: %v", err)
 	}
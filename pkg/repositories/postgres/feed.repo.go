@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/feed"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 	"github.com/lib/pq"
 )
 
@@ -112,9 +113,16 @@ func (repo *feedRepository) GetChannels(f *feed.Feed, sortBy string, sortOrder s
 
 // GetPosts returns a list of posts collected from the channels
 // the given feed has subscribed to sorted according to the given
-// method.
-func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, offset int) ([]*feed.Post, error) {
+// method. Posts crossposted to the channels are included and posts
+// reachable through more than one of them are only listed once.
+// Posts that don't pass the given filter and the ones under the excluded ids are left out.
+func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, offset int, filter maturity.Filter, exclude []int) ([]*feed.Post, error) {
 	var err error
+	allowedRatings, hiddenWarnings := maturityFilterArgs(filter)
+	excluded := make([]int64, 0, len(exclude))
+	for _, id := range exclude {
+		excluded = append(excluded, int64(id))
+	}
 
 	var rows *sql.Rows
 	switch sort {
@@ -122,30 +130,72 @@ func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, off
 	case feed.SortNew:
 		rows, err = repo.db.Query(`
 		SELECT id
-		FROM	(SELECT id, creation_time
+		FROM	(SELECT DISTINCT id, creation_time
 				      FROM (
 				               SELECT channel_username
 				               FROM feed_subscriptions
 				               WHERE feed_id = $1
 				           ) AS C (channel_from)
 				               NATURAL JOIN
-				           posts
+				           (
+				               SELECT id, creation_time, status, rating, warnings, channel_from
+				               FROM posts
+				               UNION ALL
+				               SELECT posts.id, posts.creation_time, posts.status, posts.rating, posts.warnings, channel_username
+				               FROM posts
+				                        INNER JOIN post_crossposts ON post_crossposts.post_id = posts.id
+				               WHERE accepted
+				           ) AS CP (id, creation_time, status, rating, warnings, channel_from)
+				      WHERE status = 'published'
+				        AND rating = ANY ($4) AND NOT warnings && $5
+				        AND NOT id = ANY ($6)
+				        AND (channel_from NOT IN (SELECT username FROM channels WHERE private)
+				          OR channel_from IN (
+				              SELECT channel_username
+				              FROM channel_members
+				              WHERE username = (SELECT owner_username FROM feeds WHERE id = $1)
+				              UNION
+				              SELECT channel_username
+				              FROM channel_admins
+				              WHERE username = (SELECT owner_username FROM feeds WHERE id = $1)
+				          ))
 				     ) AS P
-		ORDER BY creation_time DESC NULLS LAST LIMIT $2 OFFSET $3`, f.ID, limit, offset)
+		ORDER BY creation_time DESC NULLS LAST LIMIT $2 OFFSET $3`, f.ID, limit, offset, allowedRatings, hiddenWarnings, pq.Array(excluded))
 	case feed.SortHot:
 		rows, err = repo.db.Query(`
 		SELECT post_id
-		FROM(SELECT LP.post_id, comment_count
+		FROM(SELECT LP.post_id, COALESCE(comment_count, 0) + COALESCE(reaction_count, 0) + COALESCE(view_count, 0) / 10.0 AS activity
 			FROM(
 			    SELECT *
-				FROM (SELECT id, creation_time
+				FROM (SELECT DISTINCT id, creation_time
 				      FROM (
 				               SELECT channel_username
 				               FROM feed_subscriptions
 				               WHERE feed_id = $1
 				           ) AS C (channel_from)
 				               NATURAL JOIN
-				           posts
+				           (
+				               SELECT id, creation_time, status, rating, warnings, channel_from
+				               FROM posts
+				               UNION ALL
+				               SELECT posts.id, posts.creation_time, posts.status, posts.rating, posts.warnings, channel_username
+				               FROM posts
+				                        INNER JOIN post_crossposts ON post_crossposts.post_id = posts.id
+				               WHERE accepted
+				           ) AS CP (id, creation_time, status, rating, warnings, channel_from)
+				      WHERE status = 'published'
+				        AND rating = ANY ($4) AND NOT warnings && $5
+				        AND NOT id = ANY ($6)
+				        AND (channel_from NOT IN (SELECT username FROM channels WHERE private)
+				          OR channel_from IN (
+				              SELECT channel_username
+				              FROM channel_members
+				              WHERE username = (SELECT owner_username FROM feeds WHERE id = $1)
+				              UNION
+				              SELECT channel_username
+				              FROM channel_admins
+				              WHERE username = (SELECT owner_username FROM feeds WHERE id = $1)
+				          ))
 				     ) AS P
 				ORDER BY creation_time DESC NULLS LAST
 				) AS LP (post_id)
@@ -154,9 +204,21 @@ func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, off
 				 FROM comments
 				 GROUP BY post_from
 				) AS PS (post_id, comment_count) ON LP.post_id = PS.post_id
+				LEFT JOIN
+				(SELECT target_id, COUNT(*)
+				 FROM reactions
+				 WHERE target_type = 'post'
+				 GROUP BY target_id
+				) AS PR (post_id, reaction_count) ON LP.post_id = PR.post_id
+				LEFT JOIN
+				(SELECT target_id, SUM(count)
+				 FROM views
+				 WHERE target_type = 'post' AND bucket > CURRENT_TIMESTAMP - INTERVAL '1 day'
+				 GROUP BY target_id
+				) AS PV (post_id, view_count) ON LP.post_id = PV.post_id
 			ORDER BY creation_time DESC
-		) AS F ORDER BY comment_count DESC NULLS LAST 
-		LIMIT $2 OFFSET $3`, f.ID, limit, offset)
+		) AS F ORDER BY activity DESC NULLS LAST 
+		LIMIT $2 OFFSET $3`, f.ID, limit, offset, allowedRatings, hiddenWarnings, pq.Array(excluded))
 	case feed.NotSet:
 		fallthrough
 	case feed.SortTop:
@@ -167,14 +229,35 @@ func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, off
 		FROM(SELECT Lp.post_id, total_star_count
 			FROM(
 			    SELECT *
-				FROM (SELECT id, creation_time
+				FROM (SELECT DISTINCT id, creation_time
 				      FROM (
 				               SELECT channel_username
 				               FROM feed_subscriptions
 				               WHERE feed_id = $1
 				           ) AS C (channel_from)
 				               NATURAL JOIN
-				           posts
+				           (
+				               SELECT id, creation_time, status, rating, warnings, channel_from
+				               FROM posts
+				               UNION ALL
+				               SELECT posts.id, posts.creation_time, posts.status, posts.rating, posts.warnings, channel_username
+				               FROM posts
+				                        INNER JOIN post_crossposts ON post_crossposts.post_id = posts.id
+				               WHERE accepted
+				           ) AS CP (id, creation_time, status, rating, warnings, channel_from)
+				      WHERE status = 'published'
+				        AND rating = ANY ($4) AND NOT warnings && $5
+				        AND NOT id = ANY ($6)
+				        AND (channel_from NOT IN (SELECT username FROM channels WHERE private)
+				          OR channel_from IN (
+				              SELECT channel_username
+				              FROM channel_members
+				              WHERE username = (SELECT owner_username FROM feeds WHERE id = $1)
+				              UNION
+				              SELECT channel_username
+				              FROM channel_admins
+				              WHERE username = (SELECT owner_username FROM feeds WHERE id = $1)
+				          ))
 				     ) AS P
 				ORDER BY creation_time DESC NULLS LAST
 				) AS LP (post_id)
@@ -185,7 +268,7 @@ func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, off
 				) AS PS (post_id, total_star_count) ON LP.post_id = PS.post_id
 			ORDER BY creation_time DESC
 		) AS F ORDER BY total_star_count DESC NULLS LAST 
-		LIMIT $2 OFFSET $3`, f.ID, limit, offset)
+		LIMIT $2 OFFSET $3`, f.ID, limit, offset, allowedRatings, hiddenWarnings, pq.Array(excluded))
 	}
 	if err != nil {
 		return nil, fmt.Errorf("querying for feed_subscriptions failed because of: %s", err.Error())
@@ -210,6 +293,61 @@ func (repo *feedRepository) GetPosts(f *feed.Feed, sort feed.Sorting, limit, off
 	return posts, nil
 }
 
+// GetPinnedPosts gets the unexpired pinned posts of the channels the given feed has subscribed to
+// ordered by channel and then by their position. Posts pinned in more than one of the channels
+// are only listed once, where they're first pinned.
+func (repo *feedRepository) GetPinnedPosts(f *feed.Feed, filter maturity.Filter) ([]*feed.Post, error) {
+	allowedRatings, hiddenWarnings := maturityFilterArgs(filter)
+	rows, err := repo.db.Query(`
+		SELECT id
+		FROM (SELECT DISTINCT ON (posts.id) posts.id,
+		                                    feed_subscriptions.subscription_time,
+		                                    channel_stickies.channel_username,
+		                                    channel_stickies.position
+		      FROM channel_stickies
+		               INNER JOIN posts ON channel_stickies.post_id = posts.id
+		               INNER JOIN feed_subscriptions ON feed_subscriptions.channel_username = channel_stickies.channel_username
+		      WHERE feed_subscriptions.feed_id = $1
+		        AND (channel_stickies.expires_at IS NULL OR channel_stickies.expires_at > CURRENT_TIMESTAMP)
+		        AND posts.status = 'published'
+		        AND posts.rating = ANY ($2) AND NOT posts.warnings && $3
+		        AND (channel_stickies.channel_username NOT IN (SELECT username FROM channels WHERE private)
+		          OR channel_stickies.channel_username IN (
+		              SELECT channel_username
+		              FROM channel_members
+		              WHERE username = (SELECT owner_username FROM feeds WHERE id = $1)
+		              UNION
+		              SELECT channel_username
+		              FROM channel_admins
+		              WHERE username = (SELECT owner_username FROM feeds WHERE id = $1)
+		          ))
+		      ORDER BY posts.id, feed_subscriptions.subscription_time, channel_stickies.channel_username, channel_stickies.position
+		     ) AS P (id, subscription_time, channel_username, position)
+		ORDER BY subscription_time, channel_username, position`,
+		f.ID, allowedRatings, hiddenWarnings)
+	if err != nil {
+		return nil, fmt.Errorf("querying for pinned posts failed because of: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var id int
+	posts := make([]*feed.Post, 0)
+
+	for rows.Next() {
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %s", err.Error())
+		}
+		posts = append(posts, &feed.Post{ID: id})
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %s", err.Error())
+	}
+
+	return posts, nil
+}
+
 // UpdateFeed updates the feed entity under the given id based on the passed in feed.Feed struct.
 func (repo *feedRepository) UpdateFeed(id uint, f *feed.Feed) error {
 	var sorting string
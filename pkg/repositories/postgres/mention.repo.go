@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/mention"
+	"github.com/lib/pq"
+)
+
+// mentionRepository ...
+type mentionRepository repository
+
+// NewMentionRepository returns a struct that implements the mention.Repository using
+// a postgres database
+func NewMentionRepository(DB *sql.DB, allRepos *map[string]interface{}) mention.Repository {
+	return &mentionRepository{DB, allRepos}
+}
+
+// SetMentions replaces the mentions of the given source in a single statement keeping
+// the creation time of the ones that were already recorded
+func (repo *mentionRepository) SetMentions(sourceType mention.SourceType, sourceID int, mentions []*mention.Mention) error {
+	kinds := make([]string, 0, len(mentions))
+	targets := make([]string, 0, len(mentions))
+	texts := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		kinds = append(kinds, string(m.Kind))
+		targets = append(targets, m.Target)
+		texts = append(texts, m.Text)
+	}
+	_, err := repo.db.Exec(`WITH M AS (
+							    SELECT * FROM unnest($3::text[], $4::text[], $5::text[]) AS M (kind, target, text)
+							), D AS (
+							    DELETE FROM "issue#1".mentions
+							    WHERE source_type = $1 AND source_id = $2
+							      AND (kind, target) NOT IN (SELECT kind, target FROM M)
+							)
+							INSERT INTO "issue#1".mentions (source_type, source_id, kind, target, text)
+							SELECT $1, $2, kind, target, text FROM M
+							ON CONFLICT (source_type, source_id, kind, target) DO NOTHING`,
+		string(sourceType), sourceID, pq.Array(kinds), pq.Array(targets), pq.Array(texts))
+	if err != nil {
+		return fmt.Errorf("insertion of mentions failed because of: %v", err)
+	}
+	return nil
+}
+
+// GetMentions gets the mentions recorded for the given source
+func (repo *mentionRepository) GetMentions(sourceType mention.SourceType, sourceID int) ([]*mention.Mention, error) {
+	return repo.queryMentions(`SELECT source_type, source_id, kind, target, text, creation_time
+							FROM "issue#1".mentions
+							WHERE source_type = $1 AND source_id = $2
+							ORDER BY creation_time, text`, string(sourceType), sourceID)
+}
+
+// GetMentionsOf gets the mentions of the given user or channel, newest first, in the published posts
+// the viewer can see and the comments on those. Comments of users muted in the channel of the
+// post are left out unless the viewer wrote them.
+func (repo *mentionRepository) GetMentionsOf(kind mention.Kind, target, viewer string, limit, offset int) ([]*mention.Mention, error) {
+	return repo.queryMentions(`WITH V AS (
+							    SELECT username FROM "issue#1".channels WHERE NOT private
+							    UNION
+							    SELECT channel_username FROM "issue#1".channel_members WHERE username = $3
+							    UNION
+							    SELECT channel_username FROM "issue#1".channel_admins WHERE username = $3
+							)
+							SELECT M.source_type, M.source_id, M.kind, M.target, M.text, M.creation_time
+							FROM "issue#1".mentions AS M
+							         LEFT JOIN "issue#1".comments AS C
+							                   ON M.source_type = 'comment' AND C.id = M.source_id
+							         INNER JOIN "issue#1".posts AS P
+							                    ON P.id = CASE WHEN M.source_type = 'comment' THEN C.post_from ELSE M.source_id END
+							WHERE M.kind = $1 AND M.target = $2
+							  AND P.status = 'published'
+							  AND (P.channel_from IN (SELECT username FROM V)
+							    OR P.id IN (
+							        SELECT post_id
+							        FROM "issue#1".post_crossposts
+							        WHERE accepted AND channel_username IN (SELECT username FROM V)
+							    ))
+							  AND (M.source_type <> 'comment' OR C.commented_by = $3 OR NOT EXISTS(
+							        SELECT 1
+							        FROM "issue#1".channel_blocks
+							        WHERE channel_username = P.channel_from
+							          AND username = C.commented_by
+							          AND mode = 'mute'
+							    ))
+							ORDER BY M.creation_time DESC
+							LIMIT $4 OFFSET $5`, string(kind), target, viewer, limit, offset)
+}
+
+// DeleteMentions removes the mentions recorded for the given source
+func (repo *mentionRepository) DeleteMentions(sourceType mention.SourceType, sourceID int) error {
+	_, err := repo.db.Exec(`DELETE FROM "issue#1".mentions
+							WHERE source_type = $1 AND source_id = $2`, string(sourceType), sourceID)
+	if err != nil {
+		return fmt.Errorf("deletion of mentions failed because of: %v", err)
+	}
+	return nil
+}
+
+// queryMentions is just a helper function that scans rows of mentions
+func (repo *mentionRepository) queryMentions(query string, args ...interface{}) ([]*mention.Mention, error) {
+	mentions := make([]*mention.Mention, 0)
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying for mentions failed because of: %v", err)
+	}
+	defer rows.Close()
+	var sourceType, kind string
+	for rows.Next() {
+		m := new(mention.Mention)
+		err := rows.Scan(&sourceType, &m.SourceID, &kind, &m.Target, &m.Text, &m.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		m.SourceType = mention.SourceType(sourceType)
+		m.Kind = mention.Kind(kind)
+		mentions = append(mentions, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return mentions, nil
+}
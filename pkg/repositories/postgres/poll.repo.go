@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/poll"
+	"github.com/lib/pq"
+)
+
+// pollRepository ...
+type pollRepository repository
+
+// NewPollRepository returns a struct that implements the poll.Repository using
+// a postgres database
+func NewPollRepository(DB *sql.DB, allRepos *map[string]interface{}) poll.Repository {
+	return &pollRepository{DB, allRepos}
+}
+
+// GetPoll gets the Poll stored under the given id along with its options.
+func (repo *pollRepository) GetPoll(id uint) (*poll.Poll, error) {
+	p := &poll.Poll{ID: id}
+	var closesAt pq.NullTime
+	err := repo.db.QueryRow(`SELECT post_id, COALESCE(created_by, ''), question, multiple_choice, hide_results, closes_at, creation_time
+							FROM "issue#1".polls
+							WHERE id = $1`, id).Scan(&p.PostID, &p.CreatedBy, &p.Question, &p.MultipleChoice, &p.HideResults, &closesAt, &p.CreationTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, poll.ErrPollNotFound
+		}
+		return nil, fmt.Errorf("querying for poll failed because of: %v", err)
+	}
+	if closesAt.Valid {
+		p.ClosesAt = &closesAt.Time
+	}
+	if p.Options, err = repo.getOptions(id); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// getOptions is just a helper function that gets the options of the poll in the order they were given
+func (repo *pollRepository) getOptions(pollID uint) ([]*poll.Option, error) {
+	options := make([]*poll.Option, 0)
+	rows, err := repo.db.Query(`SELECT id, text
+								FROM "issue#1".poll_options
+								WHERE poll_id = $1
+								ORDER BY position`, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("querying for poll options failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		o := new(poll.Option)
+		err := rows.Scan(&o.ID, &o.Text)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		options = append(options, o)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return options, nil
+}
+
+// GetPostPolls gets the polls attached to the post under the given id, oldest first.
+func (repo *pollRepository) GetPostPolls(postID uint) ([]*poll.Poll, error) {
+	var found bool
+	err := repo.db.QueryRow(`SELECT EXISTS(SELECT * FROM "issue#1".posts WHERE id = $1)`, postID).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check if post exists because of: %v", err)
+	}
+	if !found {
+		return nil, poll.ErrPostNotFound
+	}
+	ids := make([]uint, 0)
+	rows, err := repo.db.Query(`SELECT id
+								FROM "issue#1".polls
+								WHERE post_id = $1
+								ORDER BY creation_time, id`, postID)
+	if err != nil {
+		return nil, fmt.Errorf("querying for post polls failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uint
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	polls := make([]*poll.Poll, 0, len(ids))
+	for _, id := range ids {
+		p, err := repo.GetPoll(id)
+		if err != nil {
+			return nil, err
+		}
+		polls = append(polls, p)
+	}
+	return polls, nil
+}
+
+// AddPoll persists the given poll along with its options in a single statement.
+func (repo *pollRepository) AddPoll(p *poll.Poll) (*poll.Poll, error) {
+	texts := make([]string, 0, len(p.Options))
+	for _, o := range p.Options {
+		texts = append(texts, o.Text)
+	}
+	var closesAt pq.NullTime
+	if p.ClosesAt != nil {
+		closesAt = pq.NullTime{Time: *p.ClosesAt, Valid: true}
+	}
+	var id uint
+	err := repo.db.QueryRow(`WITH new_poll AS (
+									INSERT INTO "issue#1".polls (post_id, created_by, question, multiple_choice, hide_results, closes_at)
+									VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6)
+									RETURNING id
+								), new_options AS (
+									INSERT INTO "issue#1".poll_options (poll_id, position, text)
+									SELECT new_poll.id, o.position, o.text
+									FROM new_poll, unnest($7::text[]) WITH ORDINALITY AS o (text, position)
+								)
+								SELECT id FROM new_poll`,
+		p.PostID, p.CreatedBy, p.Question, p.MultipleChoice, p.HideResults, closesAt, pq.Array(texts)).Scan(&id)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			if pgErr.Constraint == "polls_created_by_fkey" {
+				return nil, poll.ErrUserNotFound
+			}
+			return nil, poll.ErrPostNotFound
+		}
+		return nil, fmt.Errorf("insertion of poll failed because of: %v", err)
+	}
+	return repo.GetPoll(id)
+}
+
+// DeletePoll removes the poll under the given id. Its options and votes are removed along with it.
+func (repo *pollRepository) DeletePoll(id uint) error {
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".polls WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deletion of poll failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return poll.ErrPollNotFound
+	}
+	return nil
+}
+
+// GetVote gets the vote the given user cast on the poll.
+func (repo *pollRepository) GetVote(pollID uint, username string) (*poll.Vote, error) {
+	v := &poll.Vote{PollID: pollID, Username: username}
+	var optionIDs pq.Int64Array
+	err := repo.db.QueryRow(`SELECT option_ids, creation_time
+							FROM "issue#1".poll_votes
+							WHERE poll_id = $1 AND username = $2`, pollID, username).Scan(&optionIDs, &v.CreationTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, poll.ErrVoteNotFound
+		}
+		return nil, fmt.Errorf("querying for vote failed because of: %v", err)
+	}
+	v.OptionIDs = make([]uint, 0, len(optionIDs))
+	for _, id := range optionIDs {
+		v.OptionIDs = append(v.OptionIDs, uint(id))
+	}
+	return v, nil
+}
+
+// AddVote persists the given vote. Users can only have one vote per poll.
+func (repo *pollRepository) AddVote(v *poll.Vote) (*poll.Vote, error) {
+	optionIDs := make(pq.Int64Array, 0, len(v.OptionIDs))
+	for _, id := range v.OptionIDs {
+		optionIDs = append(optionIDs, int64(id))
+	}
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".poll_votes (poll_id, username, option_ids)
+							VALUES ($1, $2, $3)`, v.PollID, v.Username, optionIDs)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		const uniqueKeyViolationErrorCode = pq.ErrorCode("23505")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr {
+			switch {
+			case pgErr.Code == uniqueKeyViolationErrorCode:
+				return nil, poll.ErrAlreadyVoted
+			case pgErr.Code == foreignKeyViolationErrorCode && pgErr.Constraint == "poll_votes_username_fkey":
+				return nil, poll.ErrUserNotFound
+			case pgErr.Code == foreignKeyViolationErrorCode:
+				return nil, poll.ErrPollNotFound
+			}
+		}
+		return nil, fmt.Errorf("insertion of vote failed because of: %v", err)
+	}
+	return repo.GetVote(v.PollID, v.Username)
+}
+
+// GetTally counts the votes each option of the poll received along with the number of users that voted.
+func (repo *pollRepository) GetTally(pollID uint) (*poll.Results, error) {
+	results := &poll.Results{PollID: pollID, Options: make([]*poll.OptionTally, 0)}
+	err := repo.db.QueryRow(`SELECT COUNT(*)
+							FROM "issue#1".poll_votes
+							WHERE poll_id = $1`, pollID).Scan(&results.TotalVoters)
+	if err != nil {
+		return nil, fmt.Errorf("querying for poll voters failed because of: %v", err)
+	}
+	rows, err := repo.db.Query(`SELECT poll_options.id, poll_options.text, COUNT(poll_votes.username)
+								FROM "issue#1".poll_options
+								LEFT JOIN "issue#1".poll_votes
+								ON poll_votes.poll_id = poll_options.poll_id AND poll_options.id = ANY (poll_votes.option_ids)
+								WHERE poll_options.poll_id = $1
+								GROUP BY poll_options.id, poll_options.text, poll_options.position
+								ORDER BY poll_options.position`, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("querying for poll tally failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t := new(poll.OptionTally)
+		err := rows.Scan(&t.OptionID, &t.Text, &t.Votes)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		results.Options = append(results.Options, t)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	if len(results.Options) == 0 {
+		return nil, poll.ErrPollNotFound
+	}
+	return results, nil
+}
@@ -3,7 +3,11 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"time"
+
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/post"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
+	"github.com/lib/pq"
 )
 
 type postRepository repository
@@ -20,9 +24,9 @@ func (repo *postRepository) GetPost(id uint) (*post.Post, error) {
 	var p = new(post.Post)
 
 	err = repo.db.QueryRow(`
-								SELECT COALESCE(posted_by, ''), COALESCE(channel_from, ''), COALESCE(title, ''), COALESCE(description, ''),creation_time
+								SELECT COALESCE(posted_by, ''), COALESCE(channel_from, ''), COALESCE(title, ''), COALESCE(description, ''), status, rating, warnings, creation_time
 								FROM "issue#1".posts
-								WHERE posts.id = $1`, id).Scan(&p.PostedByUsername, &p.OriginChannel, &p.Title, &p.Description, &p.CreationTime)
+								WHERE posts.id = $1`, id).Scan(&p.PostedByUsername, &p.OriginChannel, &p.Title, &p.Description, &p.Status, &p.Rating, pq.Array(&p.Warnings), &p.CreationTime)
 	if err != nil {
 		//checkErr(err)
 		return nil, post.ErrPostNotFound
@@ -31,7 +35,7 @@ func (repo *postRepository) GetPost(id uint) (*post.Post, error) {
 	if errr != nil {
 		return nil, errr
 	}
-	StarList, e := repo.getStars(id)
+	StarSummary, e := repo.getStarSummary(id)
 	if e != nil {
 		return nil, e
 	}
@@ -40,10 +44,16 @@ func (repo *postRepository) GetPost(id uint) (*post.Post, error) {
 		return nil, fmt.Errorf("Comments Not found because of: %v", d)
 	}
 
+	PollList, err := repo.getPolls(id)
+	if err != nil {
+		return nil, err
+	}
+
 	p.ID = id
 	p.ContentsID = ContentList
+	p.PollsID = PollList
 	p.CommentsID = CommentList
-	p.Stars = StarList
+	p.Stars = StarSummary
 
 	return p, nil
 
@@ -78,6 +88,32 @@ func (repo *postRepository) getContents(id uint) ([]uint, error) {
 	return ContentList, nil
 }
 
+// getPolls is just a helper function that gets the ids of the polls attached to the post
+func (repo *postRepository) getPolls(id uint) ([]uint, error) {
+	var PollList = []uint{}
+	rows, err := repo.db.Query(`SELECT id
+								FROM "issue#1".polls
+								WHERE post_id = $1
+								ORDER BY creation_time, id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying for post polls failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pollID uint
+		err := rows.Scan(&pollID)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		PollList = append(PollList, pollID)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return PollList, nil
+}
+
 func (repo *postRepository) getComments(id uint) ([]int, error) {
 
 	var CommentList = []int{}
@@ -106,34 +142,43 @@ func (repo *postRepository) getComments(id uint) ([]int, error) {
 	return CommentList, nil
 }
 
-func (repo *postRepository) getStars(id uint) (map[string]uint, error) {
-	// TODO test this method
-	var StarList = make(map[string]uint, 0)
+// getStarSummary is just a helper function that aggregates the stars given to the post
+func (repo *postRepository) getStarSummary(id uint) (post.StarSummary, error) {
+	var summary post.StarSummary
 	var (
-		username  string
 		starCount uint
+		count     uint
 	)
 
-	rows, err := repo.db.Query(`SELECT username, star_count
-								FROM "issue#1".post_stars 
-								WHERE post_id = $1`, id)
+	rows, err := repo.db.Query(`SELECT star_count, COUNT(*)
+								FROM "issue#1".post_stars
+								WHERE post_id = $1
+								GROUP BY star_count`, id)
 	if err != nil {
-		return nil, fmt.Errorf("querying for star list failed because of: %v", err)
+		return summary, fmt.Errorf("querying for star summary failed because of: %v", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		err := rows.Scan(&username, &starCount)
+		err := rows.Scan(&starCount, &count)
 		if err != nil {
-			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+			return summary, fmt.Errorf("scanning from rows failed because: %v", err)
 		}
-		StarList[username] = starCount
+		if starCount < 1 || starCount > 5 {
+			continue
+		}
+		summary.Histogram[starCount-1] = count
+		summary.Count += count
+		summary.Total += starCount * count
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+		return summary, fmt.Errorf("scanning from rows faulty because: %v", err)
 	}
-	return StarList, nil
+	if summary.Count > 0 {
+		summary.Average = float64(summary.Total) / float64(summary.Count)
+	}
+	return summary, nil
 }
 
 // DeletePost Deletes the Post stored under the given id.
@@ -149,10 +194,10 @@ func (repo *postRepository) DeletePost(id uint) error {
 // AddPost Adds the Post stored under its id from given post struct.
 func (repo *postRepository) AddPost(p *post.Post) (*post.Post, error) {
 
-	query := `INSERT INTO "issue#1".posts (posted_by,channel_from, title,description) 
-				VALUES ($1,$2,$3,$4)
+	query := `INSERT INTO "issue#1".posts (posted_by,channel_from, title,description, status) 
+				VALUES ($1,$2,$3,$4,$5)
 				RETURNING id`
-	errs := repo.db.QueryRow(query, p.PostedByUsername, p.OriginChannel, p.Title, p.Description).Scan(&p.ID)
+	errs := repo.db.QueryRow(query, p.PostedByUsername, p.OriginChannel, p.Title, p.Description, p.Status).Scan(&p.ID)
 	if errs != nil {
 		//checkErr(errs)
 		return nil, post.ErrSomePostDataNotPersisted
@@ -199,7 +244,21 @@ func (repo *postRepository) UpdatePost(pos *post.Post, id uint) (*post.Post, err
 			errs = append(errs, err)
 		}
 	}
-	const maxNoOfPossibleErr = 5
+	if pos.Rating != "" {
+		err := repo.execUpdateStatementOnColumnIntoPost("rating", string(pos.Rating), id)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if pos.Warnings != nil {
+		_, err := repo.db.Exec(`UPDATE posts
+								SET warnings = $1
+								WHERE id = $2`, pq.Array(pos.Warnings), id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("updating failed of warnings column because of: %v", err))
+		}
+	}
+	const maxNoOfPossibleErr = 7
 	if len(errs) == maxNoOfPossibleErr {
 		return nil, fmt.Errorf("was unable to update any data because of %v", errs)
 	}
@@ -253,18 +312,23 @@ func (repo *postRepository) execUpdateStatementOnColumnIntoStars(value map[strin
 }
 
 // SearchPost gets all Posts under specfications
-func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order post.SortOrder, limit int, offset int) ([]*post.Post, error) {
+// Posts that don't pass the given filter are left out.
+func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order post.SortOrder, limit int, offset int, filter maturity.Filter) ([]*post.Post, error) {
+	allowedRatings, hiddenWarnings := maturityFilterArgs(filter)
 	var posts = make([]*post.Post, 0)
 	var err error
 	var rows *sql.Rows
 	var query string
 	if pattern == "" {
 		query = fmt.Sprintf(`
-		SELECT id, COALESCE(posted_by, ''), COALESCE(channel_from, ''), COALESCE(title, ''), COALESCE(description, ''),creation_time
+		SELECT id, COALESCE(posted_by, ''), COALESCE(channel_from, ''), COALESCE(title, ''), COALESCE(description, ''), status, rating, warnings, creation_time
 		FROM "issue#1".posts
+		WHERE status = 'published'
+		  AND channel_from NOT IN (SELECT username FROM "issue#1".channels WHERE private)
+		  AND rating = ANY ($3) AND NOT warnings && $4
 		ORDER BY %s %s NULLS LAST
 		LIMIT $1 OFFSET $2`, by, order)
-		rows, err = repo.db.Query(query, limit, offset)
+		rows, err = repo.db.Query(query, limit, offset, allowedRatings, hiddenWarnings)
 	} else {
 		query = `
 			  SELECT id,
@@ -272,6 +336,9 @@ func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order pos
 			   channel_from,
 			   title,
 			   COALESCE(description, ''),
+			   status,
+			   rating,
+			   warnings,
 			   creation_time
 		FROM (
 				 SELECT ts_rank(vector, query) as rank, *
@@ -284,13 +351,16 @@ func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order pos
 						  NATURAL JOIN
 					  posts
 			 ) as "r*"
+		WHERE status = 'published'
+		  AND channel_from NOT IN (SELECT username FROM "issue#1".channels WHERE private)
+		  AND rating = ANY ($4) AND NOT warnings && $5
 		ORDER BY rank DESC`
 		if by != "" {
 			query = fmt.Sprintf(`%s, %s %s NULLS LAST`, query, by, order)
 		}
 		query = fmt.Sprintf(`%s 
 		  LIMIT $2 OFFSET $3`, query)
-		rows, err = repo.db.Query(query, pattern, limit, offset)
+		rows, err = repo.db.Query(query, pattern, limit, offset, allowedRatings, hiddenWarnings)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("querying for posts failed because of: %v", err)
@@ -298,7 +368,7 @@ func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order pos
 	defer rows.Close()
 	for rows.Next() {
 		p := post.Post{}
-		err := rows.Scan(&p.ID, &p.PostedByUsername, &p.OriginChannel, &p.Title, &p.Description, &p.CreationTime)
+		err := rows.Scan(&p.ID, &p.PostedByUsername, &p.OriginChannel, &p.Title, &p.Description, &p.Status, &p.Rating, pq.Array(&p.Warnings), &p.CreationTime)
 		if err != nil {
 			return nil, post.ErrPostNotFound
 		}
@@ -306,7 +376,7 @@ func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order pos
 		if errr != nil {
 			return nil, err
 		}
-		StarList, e := repo.getStars(p.ID)
+		StarSummary, e := repo.getStarSummary(p.ID)
 		if e != nil {
 			return nil, e
 		}
@@ -314,10 +384,15 @@ func (repo *postRepository) SearchPost(pattern string, by post.SortBy, order pos
 		if d != nil {
 			return nil, fmt.Errorf("Comments Not found because of: %v", d)
 		}
+		PollList, err := repo.getPolls(p.ID)
+		if err != nil {
+			return nil, err
+		}
 
 		p.ContentsID = ContentList
+		p.PollsID = PollList
 		p.CommentsID = CommentList
-		p.Stars = StarList
+		p.Stars = StarSummary
 
 		posts = append(posts, &p)
 	}
@@ -344,6 +419,86 @@ func (repo *postRepository) GetPostStar(id uint, username string) (*post.Star, e
 
 }
 
+// GetPostStars gets a page of the stars given to the post under the given id, most recent first.
+func (repo *postRepository) GetPostStars(id uint, limit, offset int) ([]*post.Star, error) {
+	{ // block checks if post exits
+		var found bool
+		err := repo.db.QueryRow(`SELECT EXISTS(SELECT * FROM "issue#1".posts WHERE id = $1)`, id).Scan(&found)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check if post exists because of: %v", err)
+		}
+		if !found {
+			return nil, post.ErrPostNotFound
+		}
+	}
+	stars := make([]*post.Star, 0)
+	rows, err := repo.db.Query(`SELECT username, star_count
+								FROM "issue#1".post_stars
+								WHERE post_id = $1
+								ORDER BY star_time DESC, username
+								LIMIT $2 OFFSET $3`, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying for stars failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		st := new(post.Star)
+		err := rows.Scan(&st.Username, &st.NumOfStars)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		stars = append(stars, st)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return stars, nil
+}
+
+// GetTopRatedPosts gets published posts of public channels ranked by the sum
+// of the stars given to them since the given time. Posts that don't pass the
+// given filter are left out.
+func (repo *postRepository) GetTopRatedPosts(since time.Time, limit, offset int, filter maturity.Filter) ([]*post.Post, error) {
+	allowedRatings, hiddenWarnings := maturityFilterArgs(filter)
+	rows, err := repo.db.Query(`SELECT s.post_id
+								FROM "issue#1".post_stars s
+								         JOIN "issue#1".posts p ON p.id = s.post_id
+								WHERE s.star_time >= $1
+								  AND p.status = 'published'
+								  AND p.channel_from NOT IN (SELECT username FROM "issue#1".channels WHERE private)
+								  AND p.rating = ANY ($4) AND NOT p.warnings && $5
+								GROUP BY s.post_id
+								ORDER BY SUM(s.star_count) DESC, AVG(s.star_count) DESC, s.post_id
+								LIMIT $2 OFFSET $3`, since, limit, offset, allowedRatings, hiddenWarnings)
+	if err != nil {
+		return nil, fmt.Errorf("querying for top rated posts failed because of: %v", err)
+	}
+	defer rows.Close()
+	ids := make([]uint, 0)
+	for rows.Next() {
+		var id uint
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	posts := make([]*post.Post, 0, len(ids))
+	for _, id := range ids {
+		p, err := repo.GetPost(id)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	return posts, nil
+}
+
 //DeletePostStar deletes the star stored under given postid and username
 func (repo *postRepository) DeletePostStar(id uint, username string) error {
 	_, err := repo.db.Exec(`DELETE FROM "issue#1".post_stars
@@ -370,7 +525,7 @@ func (repo *postRepository) AddPostStar(id uint, star *post.Star) (*post.Star, e
 //UpdatePostStar updates a star stored given postid, number of stars and username
 func (repo *postRepository) UpdatePostStar(id uint, star *post.Star) (*post.Star, error) {
 	query := `UPDATE "issue#1".post_stars
-								SET star_count=$2 
+								SET star_count=$2, star_time=CURRENT_TIMESTAMP
 								WHERE post_id = $3 AND username = $1`
 	_, errs := repo.db.Exec(query, star.Username, star.NumOfStars, id)
 	if errs != nil {
@@ -380,6 +535,173 @@ func (repo *postRepository) UpdatePostStar(id uint, star *post.Star) (*post.Star
 	return repo.GetPostStar(id, star.Username)
 }
 
+// UpdatePostStatus moves the post under the given id to the given stage of the editorial pipeline
+func (repo *postRepository) UpdatePostStatus(id uint, status post.Status) (*post.Post, error) {
+	result, err := repo.db.Exec(`UPDATE "issue#1".posts
+								SET status = $1
+								WHERE id = $2`, status, id)
+	if err != nil {
+		return nil, fmt.Errorf("updating of post status failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, post.ErrPostNotFound
+	}
+	return repo.GetPost(id)
+}
+
+// AddDraftReview persists the given review on the draft under the given id
+func (repo *postRepository) AddDraftReview(id uint, review *post.Review) (*post.Review, error) {
+	r := new(post.Review)
+	err := repo.db.QueryRow(`INSERT INTO "issue#1".post_draft_reviews (post_id, reviewer, content, verdict)
+								VALUES ($1, $2, $3, $4)
+								RETURNING id, reviewer, content, verdict, creation_time`,
+		id, review.Reviewer, review.Content, review.Verdict).Scan(&r.ID, &r.Reviewer, &r.Content, &r.Verdict, &r.CreationTime)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			return nil, post.ErrPostNotFound
+		}
+		return nil, fmt.Errorf("insertion of draft review failed because of: %v", err)
+	}
+	return r, nil
+}
+
+// GetDraftReviews gets the reviews left on the draft under the given id ordered by the time they were left
+func (repo *postRepository) GetDraftReviews(id uint) ([]*post.Review, error) {
+	var reviews = make([]*post.Review, 0)
+
+	rows, err := repo.db.Query(`SELECT id, reviewer, content, verdict, creation_time
+								FROM "issue#1".post_draft_reviews
+								WHERE post_id = $1
+								ORDER BY creation_time`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying for draft reviews failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r := new(post.Review)
+		err := rows.Scan(&r.ID, &r.Reviewer, &r.Content, &r.Verdict, &r.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		reviews = append(reviews, r)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return reviews, nil
+}
+
+// GetChannelDrafts gets the unpublished posts of the given channel, of all stages if status is empty
+func (repo *postRepository) GetChannelDrafts(channelUsername string, status post.Status, limit, offset int) ([]*post.Post, error) {
+	var posts = make([]*post.Post, 0)
+	var rows *sql.Rows
+	var err error
+	if status == "" {
+		rows, err = repo.db.Query(`
+		SELECT id, COALESCE(posted_by, ''), COALESCE(channel_from, ''), COALESCE(title, ''), COALESCE(description, ''), status, creation_time
+		FROM "issue#1".posts
+		WHERE channel_from = $1 AND status <> 'published'
+		ORDER BY creation_time DESC NULLS LAST
+		LIMIT $2 OFFSET $3`, channelUsername, limit, offset)
+	} else {
+		rows, err = repo.db.Query(`
+		SELECT id, COALESCE(posted_by, ''), COALESCE(channel_from, ''), COALESCE(title, ''), COALESCE(description, ''), status, creation_time
+		FROM "issue#1".posts
+		WHERE channel_from = $1 AND status = $4
+		ORDER BY creation_time DESC NULLS LAST
+		LIMIT $2 OFFSET $3`, channelUsername, limit, offset, status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying for drafts failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p := new(post.Post)
+		err := rows.Scan(&p.ID, &p.PostedByUsername, &p.OriginChannel, &p.Title, &p.Description, &p.Status, &p.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		if p.ContentsID, err = repo.getContents(p.ID); err != nil {
+			return nil, err
+		}
+		if p.PollsID, err = repo.getPolls(p.ID); err != nil {
+			return nil, err
+		}
+		p.CommentsID = []int{}
+		posts = append(posts, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return posts, nil
+}
+
+// AddRevision persists the given revision of the post under the given id
+func (repo *postRepository) AddRevision(id uint, revision *post.Revision) (*post.Revision, error) {
+	r := new(post.Revision)
+	var creationTime interface{}
+	if !revision.CreationTime.IsZero() {
+		creationTime = revision.CreationTime
+	}
+	err := repo.db.QueryRow(`INSERT INTO "issue#1".post_revisions (post_id, editor, title, description, creation_time)
+								VALUES ($1, NULLIF($2, ''), $3, $4, COALESCE($5, CURRENT_TIMESTAMP))
+								RETURNING id, COALESCE(editor, ''), title, description, creation_time`,
+		id, revision.Editor, revision.Title, revision.Description, creationTime).Scan(&r.ID, &r.Editor, &r.Title, &r.Description, &r.CreationTime)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			return nil, post.ErrPostNotFound
+		}
+		return nil, fmt.Errorf("insertion of post revision failed because of: %v", err)
+	}
+	return r, nil
+}
+
+// GetRevisions gets the revisions of the post under the given id ordered by the time they were made
+func (repo *postRepository) GetRevisions(id uint) ([]*post.Revision, error) {
+	var revisions = make([]*post.Revision, 0)
+
+	rows, err := repo.db.Query(`SELECT id, COALESCE(editor, ''), title, description, creation_time
+								FROM "issue#1".post_revisions
+								WHERE post_id = $1
+								ORDER BY creation_time, id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying for post revisions failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r := new(post.Revision)
+		err := rows.Scan(&r.ID, &r.Editor, &r.Title, &r.Description, &r.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		revisions = append(revisions, r)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return revisions, nil
+}
+
+// GetRevision gets the revision under the given revisionID of the post under the given id
+func (repo *postRepository) GetRevision(id uint, revisionID int) (*post.Revision, error) {
+	r := new(post.Revision)
+	err := repo.db.QueryRow(`SELECT id, COALESCE(editor, ''), title, description, creation_time
+								FROM "issue#1".post_revisions
+								WHERE post_id = $1 AND id = $2`, id, revisionID).Scan(&r.ID, &r.Editor, &r.Title, &r.Description, &r.CreationTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, post.ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("querying for post revision failed because of: %v", err)
+	}
+	return r, nil
+}
+
 // func checkErr(errs error) {
 // 	if pgErr, isPGErr := errs.(pq.Error); !isPGErr {
 // 		fmt.Printf("prin\n%v", pgErr)
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/reaction"
+	"github.com/lib/pq"
+)
+
+// reactionRepository ...
+type reactionRepository repository
+
+// NewReactionRepository returns a struct that implements the reaction.Repository using
+// a postgres database
+func NewReactionRepository(DB *sql.DB, allRepos *map[string]interface{}) reaction.Repository {
+	return &reactionRepository{DB, allRepos}
+}
+
+// targetExists is just a helper function that checks if the entity reactions are left on exists
+func (repo *reactionRepository) targetExists(targetType reaction.TargetType, targetID int) error {
+	var table string
+	switch targetType {
+	case reaction.TargetPost:
+		table = "posts"
+	case reaction.TargetComment:
+		table = "comments"
+	case reaction.TargetRelease:
+		table = "releases"
+	default:
+		return reaction.ErrInvalidTargetType
+	}
+	var found bool
+	err := repo.db.QueryRow(fmt.Sprintf(`SELECT EXISTS(SELECT * FROM "issue#1".%s WHERE id = $1)`, table), targetID).Scan(&found)
+	if err != nil {
+		return fmt.Errorf("unable to check if reaction target exists because of: %v", err)
+	}
+	if !found {
+		return reaction.ErrTargetNotFound
+	}
+	return nil
+}
+
+// GetReaction gets the reaction of the given user with the given emoji on the target
+func (repo *reactionRepository) GetReaction(targetType reaction.TargetType, targetID int, username, emoji string) (*reaction.Reaction, error) {
+	r := reaction.Reaction{TargetType: targetType, TargetID: targetID, Username: username, Emoji: emoji}
+	err := repo.db.QueryRow(`SELECT creation_time
+							FROM "issue#1".reactions
+							WHERE target_type = $1 AND target_id = $2 AND username = $3 AND emoji = $4`,
+		string(targetType), targetID, username, emoji).Scan(&r.CreationTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, reaction.ErrReactionNotFound
+		}
+		return nil, fmt.Errorf("querying for reaction failed because of: %v", err)
+	}
+	return &r, nil
+}
+
+// AddReaction persists the given reaction
+func (repo *reactionRepository) AddReaction(r *reaction.Reaction) (*reaction.Reaction, error) {
+	if err := repo.targetExists(r.TargetType, r.TargetID); err != nil {
+		return nil, err
+	}
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".reactions (target_type, target_id, username, emoji)
+							VALUES ($1, $2, $3, $4)
+							ON CONFLICT DO NOTHING`,
+		string(r.TargetType), r.TargetID, r.Username, r.Emoji)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			return nil, reaction.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("insertion of reaction failed because of: %v", err)
+	}
+	return repo.GetReaction(r.TargetType, r.TargetID, r.Username, r.Emoji)
+}
+
+// DeleteReaction removes the reaction of the given user with the given emoji on the target
+func (repo *reactionRepository) DeleteReaction(targetType reaction.TargetType, targetID int, username, emoji string) error {
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".reactions
+							WHERE target_type = $1 AND target_id = $2 AND username = $3 AND emoji = $4`,
+		string(targetType), targetID, username, emoji)
+	if err != nil {
+		return fmt.Errorf("deletion of reaction failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return reaction.ErrReactionNotFound
+	}
+	return nil
+}
+
+// GetCounts gets the number of reactions per emoji left on the target, most used first
+func (repo *reactionRepository) GetCounts(targetType reaction.TargetType, targetID int, viewer string) ([]*reaction.Count, error) {
+	if err := repo.targetExists(targetType, targetID); err != nil {
+		return nil, err
+	}
+	counts := make([]*reaction.Count, 0)
+	rows, err := repo.db.Query(`SELECT emoji, COUNT(*), BOOL_OR(username = $3)
+								FROM "issue#1".reactions
+								WHERE target_type = $1 AND target_id = $2
+								GROUP BY emoji
+								ORDER BY COUNT(*) DESC, emoji`, string(targetType), targetID, viewer)
+	if err != nil {
+		return nil, fmt.Errorf("querying for reaction counts failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c := new(reaction.Count)
+		err := rows.Scan(&c.Emoji, &c.Count, &c.Reacted)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		counts = append(counts, c)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return counts, nil
+}
+
+// GetReactors gets the reactions left on the target with the given emoji, or any
+// emoji if it's empty, most recent first
+func (repo *reactionRepository) GetReactors(targetType reaction.TargetType, targetID int, emoji string, limit, offset int) ([]*reaction.Reaction, error) {
+	if err := repo.targetExists(targetType, targetID); err != nil {
+		return nil, err
+	}
+	reactions := make([]*reaction.Reaction, 0)
+	rows, err := repo.db.Query(`SELECT username, emoji, creation_time
+								FROM "issue#1".reactions
+								WHERE target_type = $1 AND target_id = $2 AND ($3 = '' OR emoji = $3)
+								ORDER BY creation_time DESC, username
+								LIMIT $4 OFFSET $5`, string(targetType), targetID, emoji, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying for reactions failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r := &reaction.Reaction{TargetType: targetType, TargetID: targetID}
+		err := rows.Scan(&r.Username, &r.Emoji, &r.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		reactions = append(reactions, r)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return reactions, nil
+}
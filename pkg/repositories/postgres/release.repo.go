@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/release"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
+	"github.com/lib/pq"
 )
 
 type releaseRepository repository
@@ -39,6 +41,33 @@ func (repo releaseRepository) GetRelease(id int) (*release.Release, error) {
 	}
 	r.Content = content
 
+	if r.Type == release.Text {
+		err = repo.db.QueryRow(`SELECT format
+								FROM releases_text_based
+								WHERE release_id = $1`, id).Scan(&r.Format)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("unable to get release format because: %v", err)
+		}
+	}
+
+	if r.Type == release.Audio {
+		r.Track, err = repo.getTrack(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.TranslationOf, err = repo.getTranslation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Type == release.Comic {
+		r.Pages, err = repo.getPages(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	metadata, err := repo.getMetadata(id)
 	if err != nil {
 		return nil, err
@@ -50,27 +79,36 @@ func (repo releaseRepository) GetRelease(id int) (*release.Release, error) {
 }
 
 // SearchRelease searches the database for releases that satisfy the given arguments.
-func (repo releaseRepository) SearchRelease(pattern string, by release.SortBy, order release.SortOrder, limit int, offset int) ([]*release.Release, error) {
+// Releases that don't pass the given filter are left out.
+func (repo releaseRepository) SearchRelease(pattern string, by release.SortBy, order release.SortOrder, limit int, offset int, filter maturity.Filter) ([]*release.Release, error) {
+	allowedRatings, hiddenWarnings := maturityFilterArgs(filter)
 	var releases = make([]*release.Release, 0)
 	var err error
 	var rows *sql.Rows
 	var query string
 	if pattern == "" {
 		query = fmt.Sprintf(`
-				SELECT id, owner_channel, content, type, creation_time
+				SELECT id, owner_channel, content, COALESCE(format, ''), type, creation_time
 				FROM (
 				         SELECT *
 				         FROM releases
 				                  LEFT JOIN
 				              (
-				                  SELECT release_id, content
+				                  SELECT release_id, content, format
 				                  FROM (
-				                           SELECT release_id, image_name as content
+				                           SELECT release_id, image_name as content, '' as format
 				                           FROM releases_image_based
 				                       ) AS ric
 				                  UNION
-				                  SELECT *
+				                  SELECT release_id, content, format
 				                  FROM releases_text_based
+				                  UNION
+				                  SELECT release_id, image_name, ''
+				                  FROM release_pages
+				                  WHERE number = 1
+				                  UNION
+				                  SELECT release_id, audio_name, ''
+				                  FROM releases_audio_based
 				              ) AS cs
 				              ON releases.id = cs.release_id
 				     ) AS "r*"
@@ -78,13 +116,15 @@ func (repo releaseRepository) SearchRelease(pattern string, by release.SortBy, o
 				     (
 				         SELECT release_id
 						FROM "issue#1".channel_official_catalog
+						WHERE channel_username NOT IN (SELECT username FROM "issue#1".channels WHERE private)
 				     ) AS "coc*"
+				WHERE %s
 				ORDER BY %s %s NULLS LAST
-				LIMIT $1 OFFSET $2`, by, order)
-		rows, err = repo.db.Query(query, limit, offset)
+				LIMIT $1 OFFSET $2`, fmt.Sprintf(releaseMaturityCondition, 3, 4), by, order)
+		rows, err = repo.db.Query(query, limit, offset, allowedRatings, hiddenWarnings)
 	} else {
 		query = `
-				SELECT id, owner_channel, content, type, creation_time
+				SELECT id, owner_channel, content, COALESCE(format, ''), type, creation_time
 				FROM (
 				         SELECT *
 				         FROM (
@@ -100,14 +140,21 @@ func (repo releaseRepository) SearchRelease(pattern string, by release.SortBy, o
 				              ) AS rc
 				                  LEFT JOIN
 				              (
-				                  SELECT release_id, content
+				                  SELECT release_id, content, format
 				                  FROM (
-				                           SELECT release_id, image_name as content
+				                           SELECT release_id, image_name as content, '' as format
 				                           FROM releases_image_based
 				                       ) AS ric
 				                  UNION
-				                  SELECT *
+				                  SELECT release_id, content, format
 				                  FROM releases_text_based
+				                  UNION
+				                  SELECT release_id, image_name, ''
+				                  FROM release_pages
+				                  WHERE number = 1
+				                  UNION
+				                  SELECT release_id, audio_name, ''
+				                  FROM releases_audio_based
 				              ) AS cs
 				              ON rc.id = cs.release_id
 				     ) AS "rc**"
@@ -115,14 +162,16 @@ func (repo releaseRepository) SearchRelease(pattern string, by release.SortBy, o
 				     (
 				         SELECT release_id
 				         FROM channel_official_catalog
+				         WHERE channel_username NOT IN (SELECT username FROM channels WHERE private)
 				     ) AS "coc*"
+				WHERE ` + fmt.Sprintf(releaseMaturityCondition, 4, 5) + `
 				ORDER BY rank DESC`
 		if by != "" {
 			query = fmt.Sprintf(`%s, %s %s NULLS LAST`, query, by, order)
 		}
 		query = fmt.Sprintf(`%s 
 				LIMIT $2 OFFSET $3`, query)
-		rows, err = repo.db.Query(query, pattern, limit, offset)
+		rows, err = repo.db.Query(query, pattern, limit, offset, allowedRatings, hiddenWarnings)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("querying for releases failed because of: %v", err)
@@ -130,11 +179,22 @@ func (repo releaseRepository) SearchRelease(pattern string, by release.SortBy, o
 	defer rows.Close()
 	for rows.Next() {
 		r := new(release.Release)
-		err := rows.Scan(&r.ID, &r.OwnerChannel, &r.Content, &r.Type, &r.CreationTime)
+		err := rows.Scan(&r.ID, &r.OwnerChannel, &r.Content, &r.Format, &r.Type, &r.CreationTime)
 		if err != nil {
 			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
 		}
 
+		if r.Type == release.Audio {
+			r.Track, err = repo.getTrack(r.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r.TranslationOf, err = repo.getTranslation(r.ID)
+		if err != nil {
+			return nil, err
+		}
+
 		metadata, err := repo.getMetadata(r.ID)
 		if err != nil {
 			return nil, err
@@ -150,6 +210,14 @@ func (repo releaseRepository) SearchRelease(pattern string, by release.SortBy, o
 	return releases, nil
 }
 
+// releaseMaturityCondition filters out releases whose metadata doesn't pass a maturity filter.
+// It's to be formatted with the positions of the allowed ratings and hidden warnings arguments.
+const releaseMaturityCondition = `id NOT IN (
+				         SELECT release_id
+				         FROM release_metadata
+				         WHERE NOT rating = ANY ($%d) OR warnings && $%d
+				     )`
+
 // DeleteRelease removes the release under the given id from the database.
 func (repo releaseRepository) DeleteRelease(id int) error {
 	_, err := repo.db.Exec(`DELETE FROM releases
@@ -169,6 +237,11 @@ func (repo releaseRepository) AddRelease(r *release.Release) (*release.Release,
 	if err != nil {
 		return nil, fmt.Errorf("insertion of release failed because of: %v", err)
 	}
+	if r.Type == release.Comic {
+		if err := repo.AddPages(r.ID, r.Pages); err != nil {
+			return nil, err
+		}
+	}
 	r.OwnerChannel = ""
 	return repo.UpdateRelease(r)
 }
@@ -190,6 +263,26 @@ func (repo releaseRepository) UpdateRelease(rel *release.Release) (*release.Rele
 			errs = append(errs, err)
 		}
 	}
+	if rel.Track != nil && rel.Content != "" {
+		// the file of audio releases is persisted along the track describing it
+		_, err := repo.db.Exec(`INSERT INTO releases_audio_based (release_id, audio_name, content_type, size, duration)
+								VALUES ($1, $2, $3, $4, $5)
+								ON CONFLICT(release_id) DO UPDATE
+								SET audio_name = $2, content_type = $3, size = $4, duration = $5`,
+			rel.ID, rel.Content, rel.Track.ContentType, rel.Track.Size, rel.Track.Duration)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("upserting failed of audio with %s because of: %v", rel.Content, err))
+		}
+	}
+	if rel.Format != "" {
+		// only text releases have a format, the row is made along their content
+		_, err := repo.db.Exec(`UPDATE releases_text_based
+								SET format = $1
+								WHERE release_id = $2`, string(rel.Format), rel.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("updating failed of format with %s because of: %v", rel.Format, err))
+		}
+	}
 	if !rel.ReleaseDate.IsZero() {
 		err := repo.execUpdateStatementOnColumnIntoMetadata("release_date", rel.ReleaseDate, rel.ID)
 		if err != nil {
@@ -214,6 +307,42 @@ func (repo releaseRepository) UpdateRelease(rel *release.Release) (*release.Rele
 			errs = append(errs, err)
 		}
 	}
+	if rel.Rating != "" {
+		err := repo.execUpdateStatementOnColumnIntoMetadata("rating", string(rel.Rating), rel.ID)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if rel.Warnings != nil {
+		err := repo.execUpdateStatementOnColumnIntoMetadata("warnings", pq.Array(rel.Warnings), rel.ID)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if rel.Language != "" {
+		err := repo.execUpdateStatementOnColumnIntoMetadata("language", rel.Language, rel.ID)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if rel.TranslationOf != nil {
+		// permission given for the translation is kept unless it's linked to another release
+		_, err := repo.db.Exec(`INSERT INTO "issue#1".release_translations (release_id, original_id)
+								VALUES ($1, $2)
+								ON CONFLICT(release_id) DO UPDATE
+								SET original_id = $2,
+								    permitted = release_translations.permitted AND release_translations.original_id = $2`,
+			rel.ID, rel.TranslationOf.OriginalID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("upserting failed of translation of %d because of: %v", rel.TranslationOf.OriginalID, err))
+		}
+	}
+	if rel.Credits != nil {
+		err := repo.setCredits(rel.ID, rel.Credits)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 	otherJSONRaw, err := json.Marshal(rel.Other)
 	if err == nil {
 		//jsonbString := fmt.Sprintf("to_jsonb(%s::text)", string(otherJSONRaw))
@@ -224,7 +353,7 @@ func (repo releaseRepository) UpdateRelease(rel *release.Release) (*release.Rele
 	} else {
 		errs = append(errs, err)
 	}
-	const maxNoOfPossibleErr = 6
+	const maxNoOfPossibleErr = 13
 	if len(errs) == maxNoOfPossibleErr {
 		return nil, fmt.Errorf("was unable to update any data because of %v", errs)
 	}
@@ -263,7 +392,13 @@ func (repo releaseRepository) execUpdateStatementOnColumnIntoMetadata(column str
 
 func (repo releaseRepository) execUpdateStatementForContent(t release.Type, value string, id int) error {
 	var query string
-	if t == release.Image {
+	if t == release.Comic {
+		// the content of comics is their first page
+		return nil
+	} else if t == release.Audio {
+		// the content of audio releases is persisted along their track
+		return nil
+	} else if t == release.Image {
 		query = `INSERT INTO releases_image_based (release_id, image_name)
 				VALUES ($1, $2)
 				ON CONFLICT(release_id) DO UPDATE
@@ -283,7 +418,15 @@ func (repo releaseRepository) execUpdateStatementForContent(t release.Type, valu
 
 func (repo releaseRepository) getContent(id int, t release.Type) (string, error) {
 	var content, query string
-	if t == release.Image {
+	if t == release.Comic {
+		query = `SELECT image_name
+				FROM release_pages
+				WHERE release_id = $1 AND number = 1`
+	} else if t == release.Audio {
+		query = `SELECT audio_name
+				FROM releases_audio_based
+				WHERE release_id = $1`
+	} else if t == release.Image {
 		query = `SELECT COALESCE(image_name, '') 
 				FROM releases_image_based 
 				WHERE release_id = $1`
@@ -308,10 +451,10 @@ func (repo releaseRepository) getMetadata(id int) (*release.Metadata, error) {
 
 	var otherJSON string
 
-	query := `SELECT COALESCE(title, ''), COALESCE(description, ''), COALESCE(genre_defining, ''), COALESCE(release_date, to_timestamp(0)), COALESCE(other, jsonb_build_object())
+	query := `SELECT COALESCE(title, ''), COALESCE(description, ''), COALESCE(genre_defining, ''), COALESCE(release_date, to_timestamp(0)), rating, warnings, COALESCE(language, ''), COALESCE(other, jsonb_build_object())
 				FROM release_metadata
 				WHERE release_id = $1`
-	err = repo.db.QueryRow(query, id).Scan(&meta.Title, &meta.Description, &meta.GenreDefining, &meta.ReleaseDate, &otherJSON)
+	err = repo.db.QueryRow(query, id).Scan(&meta.Title, &meta.Description, &meta.GenreDefining, &meta.ReleaseDate, &meta.Rating, pq.Array(&meta.Warnings), &meta.Language, &otherJSON)
 	if err != nil {
 		return nil, fmt.Errorf("metadata for release not found because: %v", err)
 	}
@@ -321,5 +464,367 @@ func (repo releaseRepository) getMetadata(id int) (*release.Metadata, error) {
 		return nil, fmt.Errorf("parsing of 'Other' json blob for metadata failed because: %v", err)
 	}
 
+	meta.Credits, err = repo.getCredits(id)
+	if err != nil {
+		return nil, err
+	}
+
 	return meta, nil
 }
+
+// AddRevision persists the given revision of the release under the given id
+func (repo releaseRepository) AddRevision(id int, revision *release.Revision) (*release.Revision, error) {
+	r := new(release.Revision)
+	var creationTime interface{}
+	if !revision.CreationTime.IsZero() {
+		creationTime = revision.CreationTime
+	}
+	err := repo.db.QueryRow(`INSERT INTO "issue#1".release_revisions (release_id, editor, content, creation_time)
+								VALUES ($1, NULLIF($2, ''), $3, COALESCE($4, CURRENT_TIMESTAMP))
+								RETURNING id, COALESCE(editor, ''), content, creation_time`,
+		id, revision.Editor, revision.Content, creationTime).Scan(&r.ID, &r.Editor, &r.Content, &r.CreationTime)
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			return nil, release.ErrReleaseNotFound
+		}
+		return nil, fmt.Errorf("insertion of release revision failed because of: %v", err)
+	}
+	return r, nil
+}
+
+// GetRevisions gets the revisions of the release under the given id ordered by the time they were made
+func (repo releaseRepository) GetRevisions(id int) ([]*release.Revision, error) {
+	var revisions = make([]*release.Revision, 0)
+
+	rows, err := repo.db.Query(`SELECT id, COALESCE(editor, ''), content, creation_time
+								FROM "issue#1".release_revisions
+								WHERE release_id = $1
+								ORDER BY creation_time, id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying for release revisions failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r := new(release.Revision)
+		err := rows.Scan(&r.ID, &r.Editor, &r.Content, &r.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		revisions = append(revisions, r)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return revisions, nil
+}
+
+// GetRevision gets the revision under the given revisionID of the release under the given id
+func (repo releaseRepository) GetRevision(id int, revisionID int) (*release.Revision, error) {
+	r := new(release.Revision)
+	err := repo.db.QueryRow(`SELECT id, COALESCE(editor, ''), content, creation_time
+								FROM "issue#1".release_revisions
+								WHERE release_id = $1 AND id = $2`, id, revisionID).Scan(&r.ID, &r.Editor, &r.Content, &r.CreationTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, release.ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("querying for release revision failed because of: %v", err)
+	}
+	return r, nil
+}
+
+// getTrack is just a helper function that gets the description of the file of the audio release under the given id
+func (repo releaseRepository) getTrack(id int) (*release.Track, error) {
+	t := new(release.Track)
+	err := repo.db.QueryRow(`SELECT content_type, size, duration
+								FROM "issue#1".releases_audio_based
+								WHERE release_id = $1`, id).Scan(&t.ContentType, &t.Size, &t.Duration)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get release track because: %v", err)
+	}
+	return t, nil
+}
+
+// getPages is just a helper function that gets the pages of the comic release under the given id in order
+func (repo releaseRepository) getPages(id int) ([]*release.Page, error) {
+	var pages = make([]*release.Page, 0)
+	rows, err := repo.db.Query(`SELECT number, image_name, alt_text
+								FROM "issue#1".release_pages
+								WHERE release_id = $1
+								ORDER BY number`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying for release pages failed because of: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p := new(release.Page)
+		err := rows.Scan(&p.Number, &p.Image, &p.AltText)
+		if err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		pages = append(pages, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return pages, nil
+}
+
+// AddPages persists the given pages of the comic release under the given id
+func (repo releaseRepository) AddPages(id int, pages []*release.Page) error {
+	numbers := make(pq.Int64Array, 0, len(pages))
+	images := make([]string, 0, len(pages))
+	altTexts := make([]string, 0, len(pages))
+	for _, p := range pages {
+		numbers = append(numbers, int64(p.Number))
+		images = append(images, p.Image)
+		altTexts = append(altTexts, p.AltText)
+	}
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".release_pages (release_id, number, image_name, alt_text)
+							SELECT $1, number, image_name, alt_text
+							FROM unnest($2::integer[], $3::text[], $4::text[]) AS P (number, image_name, alt_text)`,
+		id, numbers, pq.Array(images), pq.Array(altTexts))
+	if err != nil {
+		const foreignKeyViolationErrorCode = pq.ErrorCode("23503")
+		if pgErr, isPGErr := err.(*pq.Error); isPGErr && pgErr.Code == foreignKeyViolationErrorCode {
+			return release.ErrReleaseNotFound
+		}
+		return fmt.Errorf("insertion of release pages failed because of: %v", err)
+	}
+	return nil
+}
+
+// UpdatePage updates the image and alt text of the page of the comic release under the given id
+// leaving the empty ones as they are
+func (repo releaseRepository) UpdatePage(id int, page *release.Page) error {
+	result, err := repo.db.Exec(`UPDATE "issue#1".release_pages
+								SET image_name = COALESCE(NULLIF($3, ''), image_name),
+								    alt_text = COALESCE(NULLIF($4, ''), alt_text)
+								WHERE release_id = $1 AND number = $2`, id, page.Number, page.Image, page.AltText)
+	if err != nil {
+		return fmt.Errorf("updating of release page failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return release.ErrPageNotFound
+	}
+	return nil
+}
+
+// ReorderPages renumbers the pages of the comic release under the given id so that they
+// follow the order of the given page numbers
+func (repo releaseRepository) ReorderPages(id int, numbers []int) error {
+	order := make(pq.Int64Array, 0, len(numbers))
+	for _, n := range numbers {
+		order = append(order, int64(n))
+	}
+	_, err := repo.db.Exec(`UPDATE "issue#1".release_pages
+							SET number = O.position
+							FROM unnest($2::integer[]) WITH ORDINALITY AS O (number, position)
+							WHERE release_id = $1 AND release_pages.number = O.number`, id, order)
+	if err != nil {
+		return fmt.Errorf("reordering of release pages failed because of: %v", err)
+	}
+	return nil
+}
+
+// DeletePage removes the page of the comic release under the given id and moves up the pages after it
+func (repo releaseRepository) DeletePage(id int, number int) error {
+	_, err := repo.db.Exec(`WITH D AS (
+							    DELETE FROM "issue#1".release_pages
+							    WHERE release_id = $1 AND number = $2
+							)
+							UPDATE "issue#1".release_pages
+							SET number = number - 1
+							WHERE release_id = $1 AND number > $2`, id, number)
+	if err != nil {
+		return fmt.Errorf("deletion of release page failed because of: %v", err)
+	}
+	return nil
+}
+
+// getCredits is just a helper function that gets the credits of the release under the given id in order
+func (repo releaseRepository) getCredits(id int) ([]*release.Credit, error) {
+	rows, err := repo.db.Query(`SELECT role, COALESCE(username, ''), COALESCE(name, '')
+								FROM "issue#1".release_credits
+								WHERE release_id = $1
+								ORDER BY position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying for release credits failed because of: %v", err)
+	}
+	defer rows.Close()
+	var credits []*release.Credit
+	for rows.Next() {
+		c := new(release.Credit)
+		if err := rows.Scan(&c.Role, &c.Username, &c.Name); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		credits = append(credits, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return credits, nil
+}
+
+// setCredits replaces the credits of the release under the given id with the given ones
+func (repo releaseRepository) setCredits(id int, credits []*release.Credit) error {
+	roles := make([]string, 0, len(credits))
+	usernames := make([]string, 0, len(credits))
+	names := make([]string, 0, len(credits))
+	for _, c := range credits {
+		roles = append(roles, string(c.Role))
+		usernames = append(usernames, c.Username)
+		names = append(names, c.Name)
+	}
+	_, err := repo.db.Exec(`WITH D AS (
+							    DELETE FROM "issue#1".release_credits
+							    WHERE release_id = $1
+							)
+							INSERT INTO "issue#1".release_credits (release_id, position, role, username, name)
+							SELECT $1, position, role, NULLIF(username, ''), NULLIF(name, '')
+							FROM unnest($2::text[], $3::text[], $4::text[]) WITH ORDINALITY AS C (role, username, name, position)`,
+		id, pq.Array(roles), pq.Array(usernames), pq.Array(names))
+	if err != nil {
+		return fmt.Errorf("updating of release credits failed because of: %v", err)
+	}
+	return nil
+}
+
+// GetCreditsOf returns the releases the user of the given username is credited in, the
+// latest first, along with their roles in each
+func (repo releaseRepository) GetCreditsOf(username string) ([]*release.Work, error) {
+	rows, err := repo.db.Query(`SELECT release_id, array_agg(role ORDER BY position)
+								FROM "issue#1".release_credits
+								WHERE username = $1
+								GROUP BY release_id
+								ORDER BY release_id DESC`, username)
+	if err != nil {
+		return nil, fmt.Errorf("querying for credits of user failed because of: %v", err)
+	}
+	defer rows.Close()
+	works := make([]*release.Work, 0)
+	for rows.Next() {
+		w := new(release.Work)
+		var roles []string
+		if err := rows.Scan(&w.ReleaseID, pq.Array(&roles)); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		for _, role := range roles {
+			w.Roles = append(w.Roles, release.Role(role))
+		}
+		works = append(works, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return works, nil
+}
+
+// getTranslation is just a helper function that gets the link of the release under the given id
+// to the release it's a translation of, nil if it isn't one
+func (repo releaseRepository) getTranslation(id int) (*release.Translation, error) {
+	t := new(release.Translation)
+	err := repo.db.QueryRow(`SELECT original_id, permitted
+								FROM "issue#1".release_translations
+								WHERE release_id = $1`, id).Scan(&t.OriginalID, &t.Permitted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get release translation because: %v", err)
+	}
+	return t, nil
+}
+
+// GetTranslations returns the releases linked as translations of the release under the given id
+// along with their languages
+func (repo releaseRepository) GetTranslations(originalID int) ([]*release.Variant, error) {
+	rows, err := repo.db.Query(`SELECT T.release_id, COALESCE(M.language, '')
+								FROM "issue#1".release_translations AS T
+								LEFT JOIN "issue#1".release_metadata AS M ON M.release_id = T.release_id
+								WHERE T.original_id = $1
+								ORDER BY T.release_id`, originalID)
+	if err != nil {
+		return nil, fmt.Errorf("querying for release translations failed because of: %v", err)
+	}
+	defer rows.Close()
+	variants := make([]*release.Variant, 0)
+	for rows.Next() {
+		v := new(release.Variant)
+		if err := rows.Scan(&v.ReleaseID, &v.Language); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		variants = append(variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return variants, nil
+}
+
+// SetTranslationPermission marks the translation under the given id as permitted or not by the
+// channel of the original
+func (repo releaseRepository) SetTranslationPermission(id int, permitted bool) error {
+	result, err := repo.db.Exec(`UPDATE "issue#1".release_translations
+								SET permitted = $2
+								WHERE release_id = $1`, id, permitted)
+	if err != nil {
+		return fmt.Errorf("updating of translation permission failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return release.ErrNotTranslation
+	}
+	return nil
+}
+
+// DeleteTranslation unlinks the release under the given id from the release it's a translation of
+func (repo releaseRepository) DeleteTranslation(id int) error {
+	_, err := repo.db.Exec(`DELETE FROM "issue#1".release_translations
+							WHERE release_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deletion of release translation failed because of: %v", err)
+	}
+	return nil
+}
+
+// ReplaceGenres files the releases under any of the given genres under the genre to instead,
+// without repeating it, and returns the ids of the releases that were changed
+func (repo releaseRepository) ReplaceGenres(from []string, to string) ([]int, error) {
+	rows, err := repo.db.Query(`UPDATE "issue#1".release_metadata
+								SET genre_defining = CASE WHEN genre_defining = ANY($1::text[]) THEN $2 ELSE genre_defining END,
+								    other = CASE
+								        WHEN jsonb_typeof(other -> 'genres') = 'array' THEN jsonb_set(other, '{genres}', (
+								            SELECT COALESCE(jsonb_agg(genre ORDER BY position), '[]'::jsonb)
+								            FROM (
+								                     SELECT CASE WHEN G.genre = ANY($1::text[]) THEN $2 ELSE G.genre END AS genre,
+								                            MIN(G.position) AS position
+								                     FROM jsonb_array_elements_text(other -> 'genres') WITH ORDINALITY AS G (genre, position)
+								                     GROUP BY 1
+								                 ) AS R))
+								        ELSE other END
+								WHERE genre_defining = ANY($1::text[])
+								   OR (jsonb_typeof(other -> 'genres') = 'array' AND other -> 'genres' ?| $1::text[])
+								RETURNING release_id`, pq.Array(from), to)
+	if err != nil {
+		return nil, fmt.Errorf("replacing of genres failed because of: %v", err)
+	}
+	defer rows.Close()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return ids, nil
+}
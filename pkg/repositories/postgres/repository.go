@@ -5,6 +5,9 @@ package postgres
 
 import (
 	"database/sql"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
+	"github.com/lib/pq"
 )
 
 /*
@@ -25,3 +28,10 @@ type repository struct {
 	db       *sql.DB
 	allRepos *map[string]interface{}
 }
+
+// maturityFilterArgs is just a helper function that returns the query arguments of the
+// given filter, the ratings it allows and the warnings it hides, as postgres arrays.
+func maturityFilterArgs(filter maturity.Filter) (interface{}, interface{}) {
+	hidden := append([]string{}, filter.HiddenWarnings...)
+	return pq.Array(filter.AllowedRatings()), pq.Array(hidden)
+}
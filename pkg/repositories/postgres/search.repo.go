@@ -33,6 +33,19 @@ func (repo searchRepository) SearchComments(pattern string, by string, order str
 				                  NATURAL JOIN comments
 				             )
 				     ) as "c*"
+				WHERE post_from NOT IN (
+				    SELECT id
+				    FROM posts
+				    WHERE channel_from IN (SELECT username FROM channels WHERE private)
+				)
+				  AND NOT EXISTS (
+				    SELECT 1
+				    FROM channel_blocks b
+				             JOIN posts p ON p.channel_from = b.channel_username
+				    WHERE p.id = "c*".post_from
+				      AND b.username = "c*".commented_by
+				      AND b.mode = 'mute'
+				)
 				ORDER BY rank DESC`
 	if by != "" {
 		query = fmt.Sprintf(`%s, %s %s NULLS LAST`, query, by, order)
@@ -0,0 +1,335 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/tag"
+	"github.com/lib/pq"
+)
+
+// tagRepository ...
+type tagRepository repository
+
+// NewTagRepository returns a struct that implements the tag.Repository using
+// a postgres database
+func NewTagRepository(DB *sql.DB, allRepos *map[string]interface{}) tag.Repository {
+	return &tagRepository{DB, allRepos}
+}
+
+// filedReleases is a common table expression that lists the official releases of public
+// channels along each genre they're filed under, the defining one included
+const filedReleases = `F (release_id, channel_username, genre) AS (
+							    SELECT C.release_id, C.channel_username, G.genre
+							    FROM "issue#1".channel_official_catalog AS C
+							             JOIN "issue#1".channels ON channels.username = C.channel_username AND NOT channels.private
+							             JOIN "issue#1".release_metadata AS M ON M.release_id = C.release_id
+							             CROSS JOIN LATERAL (
+							                 SELECT M.genre_defining
+							                 UNION
+							                 SELECT jsonb_array_elements_text(CASE
+							                     WHEN jsonb_typeof(M.other -> 'genres') = 'array' THEN M.other -> 'genres'
+							                     ELSE '[]'::jsonb END)
+							             ) AS G (genre)
+							    WHERE G.genre IS NOT NULL
+							)`
+
+// descendantsOf is a common table expression that lists the tag named by the first
+// argument of the query along all the tags filed under it, directly or not
+const descendantsOf = `D (name) AS (
+							    SELECT name FROM "issue#1".tags WHERE name = $1
+							    UNION
+							    SELECT T.name FROM "issue#1".tags AS T JOIN D ON T.parent = D.name
+							)`
+
+// GetTag gets the tag with the given key or with an alias of the given key
+func (repo *tagRepository) GetTag(key string) (*tag.Tag, error) {
+	tags, err := repo.queryTags(`T.name = (
+								    SELECT name FROM "issue#1".tags WHERE key = $1
+								    UNION ALL
+								    SELECT tag_name FROM "issue#1".tag_aliases WHERE key = $1
+								    LIMIT 1
+								)`, `T.name`, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, tag.ErrTagNotFound
+	}
+	return tags[0], nil
+}
+
+// GetTags gets all the tags sorted by the given attribute
+func (repo *tagRepository) GetTags(by tag.SortBy, limit, offset int) ([]*tag.Tag, error) {
+	order := `T.name`
+	switch by {
+	case tag.SortByReleaseCount:
+		order = `COUNT(DISTINCT F.release_id) DESC, T.name`
+	case tag.SortByChannelCount:
+		order = `COUNT(DISTINCT F.channel_username) DESC, T.name`
+	}
+	return repo.queryTags(`TRUE`, fmt.Sprintf(`%s
+								LIMIT $1 OFFSET $2`, order), limit, offset)
+}
+
+// queryTags is just a helper function that scans tags, counts and all, matching the given
+// condition in the given order
+func (repo *tagRepository) queryTags(condition, order string, args ...interface{}) ([]*tag.Tag, error) {
+	query := fmt.Sprintf(`WITH RECURSIVE A (ancestor, name) AS (
+							    SELECT name, name FROM "issue#1".tags
+							    UNION
+							    SELECT A.ancestor, T.name FROM "issue#1".tags AS T JOIN A ON T.parent = A.name
+							), %s
+							SELECT T.name, COALESCE(T.description, ''), COALESCE(T.parent, ''),
+							       ARRAY(SELECT name FROM "issue#1".tags WHERE parent = T.name ORDER BY name),
+							       ARRAY(SELECT alias FROM "issue#1".tag_aliases WHERE tag_name = T.name ORDER BY alias),
+							       COUNT(DISTINCT F.release_id), COUNT(DISTINCT F.channel_username)
+							FROM "issue#1".tags AS T
+							         LEFT JOIN A ON A.ancestor = T.name
+							         LEFT JOIN F ON F.genre = A.name
+							WHERE %s
+							GROUP BY T.name
+							ORDER BY %s`, filedReleases, condition, order)
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying for tags failed because of: %v", err)
+	}
+	defer rows.Close()
+	tags := make([]*tag.Tag, 0)
+	for rows.Next() {
+		t := new(tag.Tag)
+		if err := rows.Scan(&t.Name, &t.Description, &t.Parent, pq.Array(&t.Children), pq.Array(&t.Aliases),
+			&t.ReleaseCount, &t.ChannelCount); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return tags, nil
+}
+
+// GetNames maps the given keys to the names of the tags they're the keys of, or aliases of.
+// Keys that match none are left out.
+func (repo *tagRepository) GetNames(keys []string) (map[string]string, error) {
+	rows, err := repo.db.Query(`SELECT key, name FROM "issue#1".tags WHERE key = ANY($1)
+								UNION ALL
+								SELECT key, tag_name FROM "issue#1".tag_aliases WHERE key = ANY($1)`, pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("querying for tag names failed because of: %v", err)
+	}
+	defer rows.Close()
+	names := make(map[string]string)
+	for rows.Next() {
+		var key, name string
+		if err := rows.Scan(&key, &name); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		names[key] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return names, nil
+}
+
+// AddTags persists tags of the given names under the given keys, skipping the ones that
+// already exist
+func (repo *tagRepository) AddTags(names, keys []string) error {
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".tags (name, key)
+							SELECT name, key FROM unnest($1::text[], $2::text[]) AS N (name, key)
+							ON CONFLICT DO NOTHING`, pq.Array(names), pq.Array(keys))
+	if err != nil {
+		return fmt.Errorf("insertion of tags failed because of: %v", err)
+	}
+	return nil
+}
+
+// UpdateTag updates the description and the parent of the tag of the given name leaving
+// the empty ones as they are
+func (repo *tagRepository) UpdateTag(t *tag.Tag) error {
+	_, err := repo.db.Exec(`UPDATE "issue#1".tags
+							SET description = COALESCE(NULLIF($2, ''), description),
+							    parent = COALESCE(NULLIF($3, ''), parent)
+							WHERE name = $1`, t.Name, t.Description, t.Parent)
+	if err != nil {
+		return fmt.Errorf("updating of tag failed because of: %v", err)
+	}
+	return nil
+}
+
+// RemoveParent takes the tag of the given name out of its parent genre
+func (repo *tagRepository) RemoveParent(name string) error {
+	_, err := repo.db.Exec(`UPDATE "issue#1".tags
+							SET parent = NULL
+							WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("removal of parent of tag failed because of: %v", err)
+	}
+	return nil
+}
+
+// AddAlias persists the given alias, under the given key, for the tag of the given name
+func (repo *tagRepository) AddAlias(name, alias, key string) error {
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".tag_aliases (key, alias, tag_name)
+							VALUES ($1, $2, $3)`, key, alias, name)
+	if err != nil {
+		return fmt.Errorf("insertion of tag alias failed because of: %v", err)
+	}
+	return nil
+}
+
+// DeleteAlias removes the alias of the given key of the tag of the given name
+func (repo *tagRepository) DeleteAlias(name, key string) error {
+	result, err := repo.db.Exec(`DELETE FROM "issue#1".tag_aliases
+								WHERE tag_name = $1 AND key = $2`, name, key)
+	if err != nil {
+		return fmt.Errorf("deletion of tag alias failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return tag.ErrAliasNotFound
+	}
+	return nil
+}
+
+// MergeTag makes the tag named from, whose key is given, an alias of the tag named into. The
+// aliases and the children of the former are moved to the latter, which keeps its own
+// description if it has one.
+func (repo *tagRepository) MergeTag(from, into, key string) error {
+	// a tag merged into its own ancestor would otherwise end up filed under itself
+	_, err := repo.db.Exec(`WITH RECURSIVE D (name) AS (
+							    SELECT name FROM "issue#1".tags WHERE parent = $1
+							    UNION
+							    SELECT T.name FROM "issue#1".tags AS T JOIN D ON T.parent = D.name
+							)
+							UPDATE "issue#1".tags AS T
+							SET parent = CASE WHEN T.name IN (SELECT name FROM D) THEN F.parent ELSE T.parent END,
+							    description = COALESCE(NULLIF(T.description, ''), F.description)
+							FROM "issue#1".tags AS F
+							WHERE F.name = $1 AND T.name = $2`, from, into)
+	if err != nil {
+		return fmt.Errorf("merging of tag failed because of: %v", err)
+	}
+	_, err = repo.db.Exec(`UPDATE "issue#1".tags
+							SET parent = $2
+							WHERE parent = $1`, from, into)
+	if err != nil {
+		return fmt.Errorf("moving of children of merged tag failed because of: %v", err)
+	}
+	_, err = repo.db.Exec(`UPDATE "issue#1".tag_aliases
+							SET tag_name = $2
+							WHERE tag_name = $1`, from, into)
+	if err != nil {
+		return fmt.Errorf("moving of aliases of merged tag failed because of: %v", err)
+	}
+	_, err = repo.db.Exec(`WITH D AS (
+							    DELETE FROM "issue#1".tags
+							    WHERE name = $1
+							)
+							INSERT INTO "issue#1".tag_aliases (key, alias, tag_name)
+							VALUES ($3, $1, $2)`, from, into, key)
+	if err != nil {
+		return fmt.Errorf("deletion of merged tag failed because of: %v", err)
+	}
+	return nil
+}
+
+// GetReleases gets the ids of the official releases of public channels filed under the tag
+// of the given name or its descendants, the latest first
+func (repo *tagRepository) GetReleases(name string, limit, offset int) ([]int, error) {
+	rows, err := repo.db.Query(fmt.Sprintf(`WITH RECURSIVE %s, %s
+							SELECT DISTINCT release_id
+							FROM F
+							WHERE genre IN (SELECT name FROM D)
+							ORDER BY release_id DESC
+							LIMIT $2 OFFSET $3`, descendantsOf, filedReleases), name, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying for releases of tag failed because of: %v", err)
+	}
+	defer rows.Close()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return ids, nil
+}
+
+// GetChannels gets the public channels with official releases filed under the tag of the
+// given name or its descendants along the number of those, the ones with the most first
+func (repo *tagRepository) GetChannels(name string, limit, offset int) ([]*tag.Channel, error) {
+	rows, err := repo.db.Query(fmt.Sprintf(`WITH RECURSIVE %s, %s
+							SELECT channel_username, COUNT(DISTINCT release_id) AS release_count
+							FROM F
+							WHERE genre IN (SELECT name FROM D)
+							GROUP BY channel_username
+							ORDER BY release_count DESC, channel_username
+							LIMIT $2 OFFSET $3`, descendantsOf, filedReleases), name, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying for channels of tag failed because of: %v", err)
+	}
+	defer rows.Close()
+	channels := make([]*tag.Channel, 0)
+	for rows.Next() {
+		c := new(tag.Channel)
+		if err := rows.Scan(&c.ChannelUsername, &c.ReleaseCount); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		channels = append(channels, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return channels, nil
+}
+
+// Suggest gets the tags whose keys, or those of their aliases, start with the given key
+// followed by the tags the releases matching the pattern in tsvs_release are filed under
+// the most
+func (repo *tagRepository) Suggest(pattern, key string, limit int) ([]*tag.Suggestion, error) {
+	rows, err := repo.db.Query(fmt.Sprintf(`WITH %s, S AS (
+							    SELECT F.genre, COUNT(DISTINCT F.release_id) AS matches
+							    FROM F
+							             JOIN "issue#1".tsvs_release AS V ON V.release_id = F.release_id,
+							         websearch_to_tsquery('english', $1) AS query
+							    WHERE V.vector @@ query
+							    GROUP BY F.genre
+							)
+							SELECT name, matches
+							FROM (
+							         SELECT T.name, COALESCE(S.matches, 0) AS matches,
+							                $2::text <> '' AND (T.key LIKE $2::text || '%%' OR EXISTS(
+							                    SELECT 1
+							                    FROM "issue#1".tag_aliases AS A
+							                    WHERE A.tag_name = T.name AND A.key LIKE $2::text || '%%'
+							                )) AS prefixed
+							         FROM "issue#1".tags AS T
+							                  LEFT JOIN S ON S.genre = T.name
+							     ) AS R
+							WHERE prefixed OR matches > 0
+							ORDER BY prefixed DESC, matches DESC, name
+							LIMIT $3`, filedReleases), pattern, key, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying for tag suggestions failed because of: %v", err)
+	}
+	defer rows.Close()
+	suggestions := make([]*tag.Suggestion, 0)
+	for rows.Next() {
+		s := new(tag.Suggestion)
+		if err := rows.Scan(&s.Name, &s.Matches); err != nil {
+			return nil, fmt.Errorf("scanning from rows failed because: %v", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning from rows faulty because: %v", err)
+	}
+	return suggestions, nil
+}
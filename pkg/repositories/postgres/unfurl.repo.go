@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/unfurl"
+)
+
+// unfurlRepository ...
+type unfurlRepository repository
+
+// NewUnfurlRepository returns a struct that implements the unfurl.Repository using
+// a postgres database
+func NewUnfurlRepository(DB *sql.DB, allRepos *map[string]interface{}) unfurl.Repository {
+	return &unfurlRepository{DB, allRepos}
+}
+
+// GetPreview gets the cached preview of the given link
+func (repo *unfurlRepository) GetPreview(url string) (*unfurl.Preview, error) {
+	var p = new(unfurl.Preview)
+	var previewType string
+	err := repo.db.QueryRow(`SELECT url, type, title, description, image_url, site_name, fetch_time
+							FROM "issue#1".link_previews
+							WHERE url = $1`, url).Scan(&p.URL, &previewType, &p.Title, &p.Description, &p.ImageURL, &p.SiteName, &p.FetchTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, unfurl.ErrPreviewNotFound
+		}
+		return nil, fmt.Errorf("querying for preview failed because of: %v", err)
+	}
+	p.Type = unfurl.Type(previewType)
+	return p, nil
+}
+
+// AddPreview caches the given preview replacing any previous one of the same link
+func (repo *unfurlRepository) AddPreview(p *unfurl.Preview) error {
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".link_previews (url, type, title, description, image_url, site_name, fetch_time)
+							VALUES ($1, $2, $3, $4, $5, $6, $7)
+							ON CONFLICT (url) DO UPDATE
+							SET type = EXCLUDED.type, title = EXCLUDED.title, description = EXCLUDED.description,
+							    image_url = EXCLUDED.image_url, site_name = EXCLUDED.site_name, fetch_time = EXCLUDED.fetch_time`,
+		p.URL, string(p.Type), p.Title, p.Description, p.ImageURL, p.SiteName, p.FetchTime)
+	if err != nil {
+		return fmt.Errorf("insertion of preview failed because of: %v", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/user"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 	"golang.org/x/crypto/bcrypt"
 	"time"
 
@@ -51,11 +52,12 @@ func (repo *userRepository) AddUser(u *user.User) (*user.User, error) {
 func (repo *userRepository) GetUser(username string) (*user.User, error) {
 	var err error
 	var u = new(user.User)
+	var filter = new(maturity.Filter)
 
 	err = repo.db.QueryRow(`
-								SELECT email, COALESCE(first_name, ''), COALESCE(middle_name, ''), COALESCE(last_name, ''), creation_time, COALESCE(bio, ''), COALESCE(image_name, '')
+								SELECT email, COALESCE(first_name, ''), COALESCE(middle_name, ''), COALESCE(last_name, ''), creation_time, COALESCE(bio, ''), COALESCE(image_name, ''), max_rating, hidden_warnings
 								FROM users LEFT JOIN users_bio ub on users.username = ub.username LEFT JOIN user_avatars ua on users.username = ua.username
-								WHERE users.username = $1`, username).Scan(&u.Email, &u.FirstName, &u.MiddleName, &u.LastName, &u.CreationTime, &u.Bio, &u.PictureURL)
+								WHERE users.username = $1`, username).Scan(&u.Email, &u.FirstName, &u.MiddleName, &u.LastName, &u.CreationTime, &u.Bio, &u.PictureURL, &filter.MaxRating, pq.Array(&filter.HiddenWarnings))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, user.ErrUserNotFound
@@ -68,6 +70,7 @@ func (repo *userRepository) GetUser(username string) (*user.User, error) {
 		return nil, fmt.Errorf("unable to get bookmarked posts because of: %v", err)
 	}
 	u.BookmarkedPosts = bookmarkedPosts
+	u.ContentFilter = filter
 
 	u.Username = username
 	return u, nil
@@ -384,3 +387,17 @@ func (repo *userRepository) RemovePicture(username string) error {
 	}
 	return nil
 }
+
+// SetContentFilter persists the maturity filter of the user under the given username
+func (repo *userRepository) SetContentFilter(username string, filter *maturity.Filter) error {
+	result, err := repo.db.Exec(`UPDATE "issue#1".users
+							SET max_rating = $1, hidden_warnings = $2
+							WHERE username = $3`, string(filter.MaxRating), pq.Array(filter.HiddenWarnings), username)
+	if err != nil {
+		return fmt.Errorf("updating of content filter failed because of: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return user.ErrUserNotFound
+	}
+	return nil
+}
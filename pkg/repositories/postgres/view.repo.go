@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/view"
+	"github.com/lib/pq"
+)
+
+// viewRepository ...
+type viewRepository repository
+
+// NewViewRepository returns a struct that implements the view.Repository using
+// a postgres database
+func NewViewRepository(DB *sql.DB, allRepos *map[string]interface{}) view.Repository {
+	return &viewRepository{DB, allRepos}
+}
+
+// AddCounts adds the given counts onto the persisted hourly view counts in a single statement
+func (repo *viewRepository) AddCounts(counts []*view.Count) error {
+	targetTypes := make([]string, 0, len(counts))
+	targetIDs := make(pq.Int64Array, 0, len(counts))
+	buckets := make([]string, 0, len(counts))
+	values := make(pq.Int64Array, 0, len(counts))
+	for _, c := range counts {
+		targetTypes = append(targetTypes, string(c.TargetType))
+		targetIDs = append(targetIDs, int64(c.TargetID))
+		buckets = append(buckets, c.Bucket.Format(time.RFC3339))
+		values = append(values, int64(c.Count))
+	}
+	_, err := repo.db.Exec(`INSERT INTO "issue#1".views (target_type, target_id, bucket, count)
+							SELECT target_type, target_id, bucket::timestamptz, count
+							FROM unnest($1::text[], $2::integer[], $3::text[], $4::integer[])
+							    AS C (target_type, target_id, bucket, count)
+							ON CONFLICT (target_type, target_id, bucket) DO UPDATE
+							SET count = views.count + EXCLUDED.count`,
+		pq.Array(targetTypes), targetIDs, pq.Array(buckets), values)
+	if err != nil {
+		return fmt.Errorf("insertion of view counts failed because of: %v", err)
+	}
+	return nil
+}
+
+// GetCount gets the total number of views the target received
+func (repo *viewRepository) GetCount(targetType view.TargetType, targetID int) (uint, error) {
+	var count uint
+	err := repo.db.QueryRow(`SELECT COALESCE(SUM(count), 0)
+							FROM "issue#1".views
+							WHERE target_type = $1 AND target_id = $2`, string(targetType), targetID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("querying for view count failed because of: %v", err)
+	}
+	return count, nil
+}
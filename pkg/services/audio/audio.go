@@ -0,0 +1,100 @@
+/*
+Package audio contains a prober that recognizes uploaded audio files by their magic
+bytes and works out how long they play, along with a writer of podcast RSS feeds.*/
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Format signifies the container and codec of an audio file.
+type Format string
+
+const (
+	// FormatMP3 files are MPEG audio frames, optionally after an ID3v2 tag
+	FormatMP3 Format = "mp3"
+	// FormatOgg files are Vorbis streams in an Ogg container
+	FormatOgg Format = "ogg"
+	// FormatOpus files are Opus streams in an Ogg container
+	FormatOpus Format = "opus"
+)
+
+// ContentType returns the media type files of the format are served with
+func (f Format) ContentType() string {
+	switch f {
+	case FormatMP3:
+		return "audio/mpeg"
+	case FormatOgg:
+		return "audio/ogg"
+	case FormatOpus:
+		return "audio/ogg; codecs=opus"
+	}
+	return "application/octet-stream"
+}
+
+// Info describes a probed audio file. Duration is in seconds.
+type Info struct {
+	Format   Format
+	Size     int64
+	Duration float64
+}
+
+// ErrUnsupportedFormat is returned when a file isn't of one of the recognized formats
+var ErrUnsupportedFormat = fmt.Errorf("unsupported audio format")
+
+// ErrMalformedAudio is returned when a file starts like a recognized format but can't be read as one
+var ErrMalformedAudio = fmt.Errorf("malformed audio")
+
+// HeaderSize is the number of bytes from the start of a file Detect needs to recognize its format
+const HeaderSize = 512
+
+// Detect recognizes the format of a file from its first bytes, like http.DetectContentType
+// does for other kinds of files.
+func Detect(header []byte) (Format, error) {
+	switch {
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return FormatMP3, nil
+	case bytes.HasPrefix(header, []byte("OggS")):
+		packet, err := firstPacket(header)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case bytes.HasPrefix(packet, []byte("OpusHead")):
+			return FormatOpus, nil
+		case bytes.HasPrefix(packet, []byte("\x01vorbis")):
+			return FormatOgg, nil
+		}
+	case len(header) >= 4:
+		if _, ok := parseFrameHeader(header); ok {
+			return FormatMP3, nil
+		}
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// Probe recognizes the format of the given file and works out its duration.
+func Probe(r io.ReaderAt, size int64) (*Info, error) {
+	header := make([]byte, HeaderSize)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	format, err := Detect(header[:n])
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{Format: format, Size: size}
+	switch format {
+	case FormatMP3:
+		info.Duration, err = mp3Duration(r, size)
+	default:
+		info.Duration, err = oggDuration(r, size, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,178 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// maxSyncSearch is the number of bytes looked through for the first frame after the ID3 tag
+const maxSyncSearch = 64 << 10
+
+// frameHeader holds the fields of the header of an MPEG audio frame needed to size it
+type frameHeader struct {
+	version    int // 1, 2 or 25 for MPEG 2.5
+	layer      int
+	bitrate    int // bits a second
+	sampleRate int
+	padding    int
+	mono       bool
+}
+
+// bitrates, in kbps, by the version, MPEG 2.5 sharing those of MPEG 2, and layer
+var bitrates = map[int]map[int][]int{
+	1: {
+		1: {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		2: {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		3: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	2: {
+		1: {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		2: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		3: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+var sampleRates = map[int][]int{
+	1:  {44100, 48000, 32000},
+	2:  {22050, 24000, 16000},
+	25: {11025, 12000, 8000},
+}
+
+// parseFrameHeader is just a helper function that reads the four byte header of an MPEG audio
+// frame. It reports false for anything that isn't one, free format frames included.
+func parseFrameHeader(b []byte) (frameHeader, bool) {
+	var h frameHeader
+	if len(b) < 4 || b[0] != 0xff || b[1]&0xe0 != 0xe0 {
+		return h, false
+	}
+	versionBits := (b[1] >> 3) & 3
+	layerBits := (b[1] >> 1) & 3
+	bitrateIndex := int(b[2] >> 4)
+	rateIndex := int((b[2] >> 2) & 3)
+	if versionBits == 1 || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return h, false
+	}
+	switch versionBits {
+	case 0:
+		h.version = 25
+	case 2:
+		h.version = 2
+	case 3:
+		h.version = 1
+	}
+	h.layer = 4 - int(layerBits)
+	class := h.version
+	if class == 25 {
+		class = 2
+	}
+	h.bitrate = bitrates[class][h.layer][bitrateIndex] * 1000
+	h.sampleRate = sampleRates[h.version][rateIndex]
+	h.padding = int((b[2] >> 1) & 1)
+	h.mono = b[3]>>6 == 3
+	return h, true
+}
+
+// samples returns the number of samples the frame holds
+func (h frameHeader) samples() int {
+	switch {
+	case h.layer == 1:
+		return 384
+	case h.layer == 3 && h.version != 1:
+		return 576
+	}
+	return 1152
+}
+
+// length returns the size of the frame, header included, in bytes
+func (h frameHeader) length() int {
+	if h.layer == 1 {
+		return (12*h.bitrate/h.sampleRate + h.padding) * 4
+	}
+	return h.samples()/8*h.bitrate/h.sampleRate + h.padding
+}
+
+// mp3Duration is just a helper function that works out how long MPEG audio plays. The frame
+// count of VBR headers is used when there's one, the frames are counted one by one otherwise.
+func mp3Duration(r io.ReaderAt, size int64) (float64, error) {
+	offset := int64(0)
+	tag := make([]byte, 10)
+	if n, _ := r.ReadAt(tag, 0); n == len(tag) && bytes.HasPrefix(tag, []byte("ID3")) {
+		// the size of ID3v2 tags is synchsafe, only seven bits of each byte are used
+		offset = 10 + (int64(tag[6]&0x7f)<<21 | int64(tag[7]&0x7f)<<14 | int64(tag[8]&0x7f)<<7 | int64(tag[9]&0x7f))
+		if tag[5]&0x10 != 0 {
+			// a footer follows the tag
+			offset += 10
+		}
+	}
+	if offset >= size {
+		return 0, ErrMalformedAudio
+	}
+	reader := bufio.NewReaderSize(io.NewSectionReader(r, offset, size-offset), 64<<10)
+
+	var first frameHeader
+	for skipped := 0; ; skipped++ {
+		b, err := reader.Peek(4)
+		if err != nil || skipped > maxSyncSearch {
+			return 0, ErrMalformedAudio
+		}
+		var ok bool
+		if first, ok = parseFrameHeader(b); ok {
+			break
+		}
+		_, _ = reader.Discard(1)
+	}
+
+	if frame, _ := reader.Peek(first.length()); len(frame) > 0 {
+		sideInfo := 32
+		switch {
+		case first.version == 1 && first.mono:
+			sideInfo = 17
+		case first.version != 1 && first.mono:
+			sideInfo = 9
+		case first.version != 1:
+			sideInfo = 17
+		}
+		xing := frame[minInt(4+sideInfo, len(frame)):]
+		vbri := frame[minInt(4+32, len(frame)):]
+		switch {
+		case len(xing) >= 12 && (bytes.HasPrefix(xing, []byte("Xing")) || bytes.HasPrefix(xing, []byte("Info"))) &&
+			binary.BigEndian.Uint32(xing[4:8])&1 != 0:
+			frames := binary.BigEndian.Uint32(xing[8:12])
+			return float64(frames) * float64(first.samples()) / float64(first.sampleRate), nil
+		case len(vbri) >= 18 && bytes.HasPrefix(vbri, []byte("VBRI")):
+			frames := binary.BigEndian.Uint32(vbri[14:18])
+			return float64(frames) * float64(first.samples()) / float64(first.sampleRate), nil
+		}
+	}
+
+	var samples int64
+	for {
+		b, err := reader.Peek(4)
+		if err != nil {
+			break
+		}
+		h, ok := parseFrameHeader(b)
+		if !ok {
+			// trailing tags and junk
+			break
+		}
+		if _, err := reader.Discard(h.length()); err != nil {
+			// a truncated last frame
+			break
+		}
+		samples += int64(h.samples())
+	}
+	if samples == 0 {
+		return 0, ErrMalformedAudio
+	}
+	return float64(samples) / float64(first.sampleRate), nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// oggPageHeaderSize is the size of the fixed part of the header of Ogg pages
+const oggPageHeaderSize = 27
+
+// oggTailSize is the number of bytes from the end of a file looked through for its last page
+const oggTailSize = 64 << 10
+
+// firstPacket is just a helper function that returns the first packet of an Ogg stream, the
+// identification header, as much of it as the given start of the file holds.
+func firstPacket(header []byte) ([]byte, error) {
+	if len(header) < oggPageHeaderSize || !bytes.HasPrefix(header, []byte("OggS")) {
+		return nil, ErrMalformedAudio
+	}
+	segments := int(header[26])
+	start := oggPageHeaderSize + segments
+	if len(header) < start {
+		return nil, ErrMalformedAudio
+	}
+	length := 0
+	for _, lacing := range header[oggPageHeaderSize:start] {
+		length += int(lacing)
+		if lacing < 255 {
+			break
+		}
+	}
+	end := start + length
+	if end > len(header) {
+		end = len(header)
+	}
+	return header[start:end], nil
+}
+
+// oggDuration is just a helper function that works out how long an Ogg stream plays from
+// the granule position, the number of samples played by its end, of its last page.
+func oggDuration(r io.ReaderAt, size int64, format Format) (float64, error) {
+	header := make([]byte, HeaderSize)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	packet, err := firstPacket(header[:n])
+	if err != nil {
+		return 0, err
+	}
+	serial := binary.LittleEndian.Uint32(header[14:18])
+
+	var rate float64
+	var preSkip int64
+	switch format {
+	case FormatOpus:
+		// Opus always plays at 48kHz, the samples skipped at the start aren't played
+		if len(packet) < 12 {
+			return 0, ErrMalformedAudio
+		}
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+		rate = 48000
+	default:
+		if len(packet) < 16 {
+			return 0, ErrMalformedAudio
+		}
+		rate = float64(binary.LittleEndian.Uint32(packet[12:16]))
+	}
+	if rate == 0 {
+		return 0, ErrMalformedAudio
+	}
+
+	tailSize := int64(oggTailSize)
+	if tailSize > size {
+		tailSize = size
+	}
+	tail := make([]byte, tailSize)
+	n, err = r.ReadAt(tail, size-tailSize)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	tail = tail[:n]
+	for i := len(tail) - oggPageHeaderSize; i >= 0; i-- {
+		if !bytes.HasPrefix(tail[i:], []byte("OggS")) || binary.LittleEndian.Uint32(tail[i+14:i+18]) != serial {
+			continue
+		}
+		// pages where no packet ends have a granule position of -1
+		granule := int64(binary.LittleEndian.Uint64(tail[i+6 : i+14]))
+		if granule < 0 {
+			continue
+		}
+		if granule < preSkip {
+			return 0, nil
+		}
+		return float64(granule-preSkip) / rate, nil
+	}
+	return 0, ErrMalformedAudio
+}
@@ -0,0 +1,138 @@
+package audio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Podcast is a channel of audio releases described the way podcast clients expect.
+type Podcast struct {
+	Title       string
+	Link        string
+	Description string
+	Language    string
+	Author      string
+	ImageURL    string
+	Category    string
+	Explicit    bool
+	Episodes    []*Episode
+}
+
+// Episode is a single audio release of a Podcast. Duration is in seconds and
+// Size, in bytes, is that of the file at URL.
+type Episode struct {
+	GUID        string
+	Title       string
+	Description string
+	Link        string
+	URL         string
+	ContentType string
+	Size        int64
+	Duration    float64
+	Explicit    bool
+	PubDate     time.Time
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	ITunes  string     `xml:"xmlns:itunes,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	Description string       `xml:"description"`
+	Language    string       `xml:"language,omitempty"`
+	Author      string       `xml:"itunes:author,omitempty"`
+	Summary     string       `xml:"itunes:summary,omitempty"`
+	Image       *rssImage    `xml:"itunes:image,omitempty"`
+	Category    *rssCategory `xml:"itunes:category,omitempty"`
+	Explicit    string       `xml:"itunes:explicit"`
+	Items       []rssItem    `xml:"item"`
+}
+
+type rssImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type rssCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Description string       `xml:"description,omitempty"`
+	Link        string       `xml:"link,omitempty"`
+	GUID        rssGUID      `xml:"guid"`
+	PubDate     string       `xml:"pubDate"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+	Duration    string       `xml:"itunes:duration,omitempty"`
+	Explicit    string       `xml:"itunes:explicit"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// formatDuration is just a helper function that formats seconds as HH:MM:SS
+func formatDuration(seconds float64) string {
+	total := int64(seconds + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
+
+// WriteRSS writes the podcast as an RSS 2.0 feed with the iTunes extensions podcast
+// clients and directories look for.
+func WriteRSS(w io.Writer, p *Podcast) error {
+	feed := rssFeed{
+		Version: "2.0",
+		ITunes:  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		Channel: rssChannel{
+			Title:       p.Title,
+			Link:        p.Link,
+			Description: p.Description,
+			Language:    p.Language,
+			Author:      p.Author,
+			Summary:     p.Description,
+			Explicit:    fmt.Sprint(p.Explicit),
+			Items:       make([]rssItem, 0, len(p.Episodes)),
+		},
+	}
+	if p.ImageURL != "" {
+		feed.Channel.Image = &rssImage{Href: p.ImageURL}
+	}
+	if p.Category != "" {
+		feed.Channel.Category = &rssCategory{Text: p.Category}
+	}
+	for _, e := range p.Episodes {
+		item := rssItem{
+			Title:       e.Title,
+			Description: e.Description,
+			Link:        e.Link,
+			GUID:        rssGUID{Value: e.GUID},
+			PubDate:     e.PubDate.Format(time.RFC1123Z),
+			Enclosure:   rssEnclosure{URL: e.URL, Length: e.Size, Type: e.ContentType},
+			Explicit:    fmt.Sprint(e.Explicit),
+		}
+		if e.Duration > 0 {
+			item.Duration = formatDuration(e.Duration)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(feed)
+}
@@ -0,0 +1,13 @@
+package blob
+
+import "time"
+
+// Blob is a stored file named after the hash of its content. RefCount is the number of
+// users, channels and releases that refer to it as of the last count.
+type Blob struct {
+	Name              string     `json:"name"`
+	Size              int64      `json:"size"`
+	RefCount          int        `json:"refCount"`
+	CreationTime      time.Time  `json:"creationTime"`
+	UnreferencedSince *time.Time `json:"unreferencedSince,omitempty"`
+}
@@ -0,0 +1,169 @@
+/*
+Package blob contains definition and implementation of a service that keeps track of
+the content addressed blobs uploaded files are stored as and collects the ones that
+are no longer referred to.*/
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/storage"
+)
+
+// Service specifies a method to service Blob entities.
+type Service interface {
+	AddBlob(name string, data io.Reader, contentType string) (*Blob, bool, error)
+	GetBlob(name string) (*Blob, error)
+	CollectGarbage(gracePeriod time.Duration) ([]string, error)
+}
+
+// Repository specifies a repo interface to serve the Blob Service interface
+type Repository interface {
+	AddBlob(b *Blob) (*Blob, error)
+	GetBlob(name string) (*Blob, error)
+	// CountReferences updates the reference counts of every blob, marking the time
+	// blobs were first found unreferenced and clearing it for referenced ones.
+	CountReferences() error
+	// DeleteUnreferenced removes the blobs unreferenced since before the given time
+	// and returns their names.
+	DeleteUnreferenced(before time.Time) ([]string, error)
+}
+
+// ErrBlobNotFound is returned when the requested blob isn't found
+var ErrBlobNotFound = fmt.Errorf("blob not found")
+
+// ErrInvalidName is returned when a name isn't the content address of a blob
+var ErrInvalidName = fmt.Errorf("invalid blob name")
+
+// Collector specifies a method to remove the files derived from a blob, like the
+// variants of images, when it's collected
+type Collector interface {
+	Remove(name string) error
+}
+
+type service struct {
+	repo       *Repository
+	store      storage.Store
+	collectors []Collector
+
+	// collecting keeps blobs from being added while collected ones are removed so that
+	// a blob found in the store isn't removed right before it's recorded again
+	collecting sync.RWMutex
+}
+
+// NewService returns a struct that implements the blob.Service interface. The blobs
+// are kept in the given store and the given collectors are notified of collected blobs.
+func NewService(repo *Repository, store storage.Store, collectors ...Collector) Service {
+	return &service{repo: repo, store: store, collectors: collectors}
+}
+
+// NewHash returns the hash content addresses are made from
+func NewHash() hash.Hash {
+	return sha256.New()
+}
+
+// Name returns the content address of a blob of the given hash sum with the
+// given extension, e.g. "jpg".
+func Name(sum []byte, extension string) string {
+	return hex.EncodeToString(sum) + "." + extension
+}
+
+// validName is just a helper function that checks the name is a content address
+func validName(name string) bool {
+	const hexLength = sha256.Size * 2
+	if len(name) < hexLength+2 || name[hexLength] != '.' {
+		return false
+	}
+	_, err := hex.DecodeString(name[:hexLength])
+	return err == nil
+}
+
+// AddBlob stores the data under the given content address unless a blob of the same
+// content has already been stored. The second return value reports whether the blob
+// is new. The blob is left unreferenced until an entity refers to it by name.
+func (s *service) AddBlob(name string, data io.Reader, contentType string) (*Blob, bool, error) {
+	if !validName(name) {
+		return nil, false, ErrInvalidName
+	}
+	s.collecting.RLock()
+	defer s.collecting.RUnlock()
+	exists, err := s.store.Exists(name)
+	if err != nil {
+		return nil, false, err
+	}
+	counter := &countingReader{reader: data}
+	if exists {
+		_, err = io.Copy(ioutil.Discard, counter)
+	} else {
+		err = s.store.Put(name, counter, contentType)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	// a blob that's stored again is saved from collection until it's counted again
+	b, err := (*s.repo).AddBlob(&Blob{Name: name, Size: counter.count})
+	if err != nil {
+		return nil, false, err
+	}
+	return b, !exists, nil
+}
+
+// GetBlob returns the blob of the given name.
+func (s *service) GetBlob(name string) (*Blob, error) {
+	return (*s.repo).GetBlob(name)
+}
+
+// CollectGarbage counts the references to every blob and removes, from the store as
+// well, the ones that haven't been referred to for at least the grace period. The grace
+// period keeps blobs that were just uploaded from being collected before they're
+// referred to. It returns the names of the removed blobs.
+func (s *service) CollectGarbage(gracePeriod time.Duration) ([]string, error) {
+	if err := (*s.repo).CountReferences(); err != nil {
+		return nil, err
+	}
+	s.collecting.Lock()
+	defer s.collecting.Unlock()
+	names, err := (*s.repo).DeleteUnreferenced(time.Now().Add(-gracePeriod))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		for _, collector := range s.collectors {
+			if err := collector.Remove(name); err != nil {
+				return nil, fmt.Errorf("removing of files derived from blob %s failed because of: %v", name, err)
+			}
+		}
+		if err := s.store.Delete(name); err != nil && err != storage.ErrBlobNotFound {
+			return nil, fmt.Errorf("removing of blob %s failed because of: %v", name, err)
+		}
+	}
+	return names, nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// Seek lets stores find the size of seekable data without reading it. Stores are only
+// expected to seek back to where they started.
+func (r *countingReader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.reader.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("data can't seek")
+	}
+	return seeker.Seek(offset, whence)
+}
@@ -0,0 +1,190 @@
+package blob
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/storage"
+)
+
+// stubRepository keeps blobs in memory, the references to them are set by the tests
+type stubRepository struct {
+	mutex      sync.Mutex
+	blobs      map[string]*Blob
+	references map[string]int
+	// deleting, if set, is waited on before unreferenced blobs are deleted
+	deleting chan struct{}
+}
+
+func newStubRepository() *stubRepository {
+	return &stubRepository{blobs: make(map[string]*Blob), references: make(map[string]int)}
+}
+
+func (repo *stubRepository) AddBlob(b *Blob) (*Blob, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if existing, ok := repo.blobs[b.Name]; ok {
+		existing.UnreferencedSince = nil
+		return existing, nil
+	}
+	b.CreationTime = time.Now()
+	repo.blobs[b.Name] = b
+	return b, nil
+}
+
+func (repo *stubRepository) GetBlob(name string) (*Blob, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	b, ok := repo.blobs[name]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	return b, nil
+}
+
+func (repo *stubRepository) CountReferences() error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	now := time.Now()
+	for name, b := range repo.blobs {
+		b.RefCount = repo.references[name]
+		switch {
+		case b.RefCount > 0:
+			b.UnreferencedSince = nil
+		case b.UnreferencedSince == nil:
+			b.UnreferencedSince = &now
+		}
+	}
+	return nil
+}
+
+func (repo *stubRepository) DeleteUnreferenced(before time.Time) ([]string, error) {
+	if repo.deleting != nil {
+		<-repo.deleting
+	}
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	names := make([]string, 0)
+	for name, b := range repo.blobs {
+		if b.UnreferencedSince != nil && b.UnreferencedSince.Before(before) {
+			delete(repo.blobs, name)
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// stubCollector records the blobs it's notified of
+type stubCollector struct {
+	removed []string
+}
+
+func (c *stubCollector) Remove(name string) error {
+	c.removed = append(c.removed, name)
+	return nil
+}
+
+func testName(content string) string {
+	hash := NewHash()
+	hash.Write([]byte(content))
+	return Name(hash.Sum(nil), "txt")
+}
+
+func newTestService(t *testing.T) (Service, *stubRepository, storage.Store, *stubCollector) {
+	stub := newStubRepository()
+	var repo Repository = stub
+	store := storage.NewLocalStore(t.TempDir(), "")
+	collector := &stubCollector{}
+	return NewService(&repo, store, collector), stub, store, collector
+}
+
+func TestAddBlob(t *testing.T) {
+	s, _, store, _ := newTestService(t)
+	name := testName("content")
+
+	b, isNew, err := s.AddBlob(name, strings.NewReader("content"), "text/plain")
+	if err != nil {
+		t.Fatalf("AddBlob failed: %v", err)
+	}
+	if !isNew || b.Size != int64(len("content")) {
+		t.Errorf("AddBlob() = %+v, %v, want a new blob of size %d", b, isNew, len("content"))
+	}
+	if exists, _ := store.Exists(name); !exists {
+		t.Error("added blob isn't in the store")
+	}
+
+	if _, isNew, err = s.AddBlob(name, strings.NewReader("content"), "text/plain"); err != nil || isNew {
+		t.Errorf("AddBlob() of stored content = %v, %v, want it not to be new", isNew, err)
+	}
+	if _, _, err = s.AddBlob("../content.txt", strings.NewReader("content"), "text/plain"); err != ErrInvalidName {
+		t.Errorf("AddBlob() of an invalid name = %v, want %v", err, ErrInvalidName)
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	s, stub, store, collector := newTestService(t)
+	referenced, unreferenced := testName("referenced"), testName("unreferenced")
+	s.AddBlob(referenced, strings.NewReader("referenced"), "text/plain")
+	s.AddBlob(unreferenced, strings.NewReader("unreferenced"), "text/plain")
+	stub.references[referenced] = 1
+
+	// blobs are kept through the grace period
+	if names, err := s.CollectGarbage(time.Hour); err != nil || len(names) != 0 {
+		t.Fatalf("CollectGarbage() within the grace period = %v, %v, want nothing removed", names, err)
+	}
+	names, err := s.CollectGarbage(0)
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if len(names) != 1 || names[0] != unreferenced {
+		t.Errorf("CollectGarbage() = %v, want [%s]", names, unreferenced)
+	}
+	if len(collector.removed) != 1 || collector.removed[0] != unreferenced {
+		t.Errorf("collectors were notified of %v, want [%s]", collector.removed, unreferenced)
+	}
+	if exists, _ := store.Exists(unreferenced); exists {
+		t.Error("collected blob is still in the store")
+	}
+	if exists, _ := store.Exists(referenced); !exists {
+		t.Error("referenced blob was removed from the store")
+	}
+}
+
+func TestAddBlobWaitsForCollection(t *testing.T) {
+	s, stub, store, _ := newTestService(t)
+	name := testName("content")
+	s.AddBlob(name, strings.NewReader("content"), "text/plain")
+	stub.deleting = make(chan struct{})
+
+	collected := make(chan []string)
+	go func() {
+		names, _ := s.CollectGarbage(-time.Second)
+		collected <- names
+	}()
+	// the collection is held up before deleting until the blob is added again
+	added := make(chan bool)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, isNew, _ := s.AddBlob(name, strings.NewReader("content"), "text/plain")
+		added <- isNew
+	}()
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-added:
+		t.Fatal("blob was added while being collected")
+	default:
+	}
+	close(stub.deleting)
+
+	if names := <-collected; len(names) != 1 {
+		t.Fatalf("CollectGarbage() = %v, want the blob removed", names)
+	}
+	if isNew := <-added; !isNew {
+		t.Error("blob added after being collected wasn't stored again")
+	}
+	if exists, _ := store.Exists(name); !exists {
+		t.Error("blob added after being collected isn't in the store")
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Package diff contains a line based diffing algorithm used to compare revisions of text */
+package diff
+
+import "strings"
+
+// Op holds enums that describe what happened to a line between two texts
+type Op string
+
+// Operations a Line can carry
+const (
+	OpEqual  Op = "="
+	OpInsert Op = "+"
+	OpDelete Op = "-"
+)
+
+// Line is a single line of a diff along with what happened to it
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns the line by line difference between the texts a and b based
+// on their longest common subsequence of lines. It uses the linear space
+// variation of Myers' algorithm so that long texts can be compared without
+// holding a table of all their line pairs.
+func Lines(a, b string) []Line {
+	x, y := splitLines(a), splitLines(b)
+	lines := make([]Line, 0, len(x)+len(y))
+	return compare(lines, x, y)
+}
+
+// compare is just a helper function that appends the difference between x and y to lines
+func compare(lines []Line, x, y []string) []Line {
+	// the common prefix and suffix are left out of the search
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		lines = append(lines, Line{OpEqual, x[prefix]})
+		prefix++
+	}
+	x, y = x[prefix:], y[prefix:]
+	suffix := 0
+	for suffix < len(x) && suffix < len(y) && x[len(x)-suffix-1] == y[len(y)-suffix-1] {
+		suffix++
+	}
+	common := x[len(x)-suffix:]
+	x, y = x[:len(x)-suffix], y[:len(y)-suffix]
+
+	switch {
+	case len(x) == 0:
+		for _, text := range y {
+			lines = append(lines, Line{OpInsert, text})
+		}
+	case len(y) == 0:
+		for _, text := range x {
+			lines = append(lines, Line{OpDelete, text})
+		}
+	default:
+		if i, j, ok := middleSnake(x, y); ok {
+			lines = compare(lines, x[:i], y[:j])
+			lines = compare(lines, x[i:], y[j:])
+		} else {
+			for _, text := range x {
+				lines = append(lines, Line{OpDelete, text})
+			}
+			for _, text := range y {
+				lines = append(lines, Line{OpInsert, text})
+			}
+		}
+	}
+
+	for _, text := range common {
+		lines = append(lines, Line{OpEqual, text})
+	}
+	return lines
+}
+
+// middleSnake is just a helper function that searches for the shortest edit script from
+// both ends of x and y at once and returns the point where the two searches meet.
+// The texts are split there and each half is compared on its own. ok is false
+// if x and y have no lines in common.
+func middleSnake(x, y []string) (i, j int, ok bool) {
+	n, m := len(x), len(y)
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// forward[offset+k] holds the furthest x reached on diagonal k from the start and
+	// backward[offset+k] the same from the end
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for k := range forward {
+		forward[k] = -1
+		backward[k] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+	delta := n - m
+	// the searches can only meet on the forward pass if delta is odd
+	odd := delta%2 != 0
+	kStart, kEnd, kbStart, kbEnd := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		for k := -d + kStart; k <= d-kEnd; k += 2 {
+			var fx int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				fx = forward[offset+k+1]
+			} else {
+				fx = forward[offset+k-1] + 1
+			}
+			fy := fx - k
+			for fx < n && fy < m && x[fx] == y[fy] {
+				fx++
+				fy++
+			}
+			forward[offset+k] = fx
+			switch {
+			case fx > n:
+				kEnd += 2
+			case fy > m:
+				kStart += 2
+			case odd:
+				if kb := offset + delta - k; kb >= 0 && kb < len(backward) && backward[kb] != -1 {
+					if fx >= n-backward[kb] {
+						return fx, fy, true
+					}
+				}
+			}
+		}
+		for k := -d + kbStart; k <= d-kbEnd; k += 2 {
+			var bx int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				bx = backward[offset+k+1]
+			} else {
+				bx = backward[offset+k-1] + 1
+			}
+			by := bx - k
+			for bx < n && by < m && x[n-bx-1] == y[m-by-1] {
+				bx++
+				by++
+			}
+			backward[offset+k] = bx
+			switch {
+			case bx > n:
+				kbEnd += 2
+			case by > m:
+				kbStart += 2
+			case !odd:
+				if kf := offset + delta - k; kf >= 0 && kf < len(forward) && forward[kf] != -1 {
+					fx := forward[kf]
+					if fx >= n-bx {
+						return fx, offset + fx - kf, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// splitLines is just a helper function that treats the empty text as having no lines
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{"both empty", "", "", []Line{}},
+		{"added", "", "one\ntwo", []Line{{OpInsert, "one"}, {OpInsert, "two"}}},
+		{"removed", "one\ntwo", "", []Line{{OpDelete, "one"}, {OpDelete, "two"}}},
+		{"same", "one\ntwo", "one\ntwo", []Line{{OpEqual, "one"}, {OpEqual, "two"}}},
+		{
+			"changed middle line",
+			"one\ntwo\nthree",
+			"one\n2\nthree",
+			[]Line{{OpEqual, "one"}, {OpDelete, "two"}, {OpInsert, "2"}, {OpEqual, "three"}},
+		},
+		{
+			"nothing in common",
+			"a\nb",
+			"c\nd",
+			[]Line{{OpDelete, "a"}, {OpDelete, "b"}, {OpInsert, "c"}, {OpInsert, "d"}},
+		},
+		{
+			"moved line",
+			"a\nb\nc\nd",
+			"b\nc\nd\na",
+			[]Line{{OpDelete, "a"}, {OpEqual, "b"}, {OpEqual, "c"}, {OpEqual, "d"}, {OpInsert, "a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// lcsLength is the quadratic longest common subsequence the diffs are checked against
+func lcsLength(x, y []string) int {
+	table := make([][]int, len(x)+1)
+	for i := range table {
+		table[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				table[i][j] = table[i+1][j+1] + 1
+			case table[i+1][j] > table[i][j+1]:
+				table[i][j] = table[i+1][j]
+			default:
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+	return table[0][0]
+}
+
+func TestLinesRandom(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	text := func() string {
+		lines := make([]string, random.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+	for i := 0; i < 5000; i++ {
+		a, b := text(), text()
+		lines := Lines(a, b)
+		from, to := make([]string, 0), make([]string, 0)
+		equal := 0
+		for _, line := range lines {
+			switch line.Op {
+			case OpEqual:
+				from = append(from, line.Text)
+				to = append(to, line.Text)
+				equal++
+			case OpDelete:
+				from = append(from, line.Text)
+			case OpInsert:
+				to = append(to, line.Text)
+			}
+		}
+		if strings.Join(from, "\n") != a || strings.Join(to, "\n") != b {
+			t.Fatalf("Lines(%q, %q) = %v doesn't rebuild the texts", a, b, lines)
+		}
+		if want := lcsLength(splitLines(a), splitLines(b)); equal != want {
+			t.Fatalf("Lines(%q, %q) kept %d lines, want %d", a, b, equal, want)
+		}
+	}
+}
+
+func TestLinesLong(t *testing.T) {
+	x := make([]string, 200000)
+	for i := range x {
+		x[i] = strings.Repeat("line", i%7)
+	}
+	y := append([]string(nil), x...)
+	for i := 0; i < len(y); i += len(y) / 10 {
+		y[i] = "changed"
+	}
+	lines := Lines(strings.Join(x, "\n"), strings.Join(y, "\n"))
+	changes := 0
+	for _, line := range lines {
+		if line.Op != OpEqual {
+			changes++
+		}
+	}
+	if changes != 20 {
+		t.Errorf("Lines of texts with 10 changed lines returned %d changes, want 20", changes)
+	}
+}
@@ -1,19 +1,167 @@
 package channel
 
-import "time"
+import (
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/imaging"
+)
 
 // Channel represents a singular stream of posts that a user can subscribe to
 // under adminstration by certain users.
 type Channel struct {
-	ChannelUsername    string    `json:"channelUsername"`
-	Name               string    `json:"name,omitempty"`
-	Description        string    `json:"description,omitempty"`
-	PictureURL         string    `json:"pictureURL,omitempty"`
-	OwnerUsername      string    `json:"ownerUsername,omitempty"`
-	AdminUsernames     []string  `json:"adminUsernames,omitempty"`
-	PostIDs            []uint    `json:"postIDs,omitempty"`
-	StickiedPostIDs    []uint    `json:"stickiedPostIDs,omitempty "`
-	ReleaseIDs         []uint    `json:"releaseIDs,omitempty"`
-	OfficialReleaseIDs []uint    `json:"officialReleaseIDs,omitempty"`
-	CreationTime       time.Time `json:"creationTime,omitempty"`
+	ChannelUsername    string             `json:"channelUsername"`
+	Name               string             `json:"name,omitempty"`
+	Description        string             `json:"description,omitempty"`
+	PictureURL         string             `json:"pictureURL,omitempty"`
+	BannerURL          string             `json:"bannerURL,omitempty"`
+	PictureVariants    []*imaging.Variant `json:"pictureVariants,omitempty"`
+	BannerVariants     []*imaging.Variant `json:"bannerVariants,omitempty"`
+	Theme              Theme              `json:"theme"`
+	OwnerUsername      string             `json:"ownerUsername,omitempty"`
+	AdminUsernames     []string           `json:"adminUsernames,omitempty"`
+	PostIDs            []uint             `json:"postIDs,omitempty"`
+	StickiedPostIDs    []uint             `json:"stickiedPostIDs,omitempty "`
+	Pins               []*Pin             `json:"pins,omitempty"`
+	ReleaseIDs         []uint             `json:"releaseIDs,omitempty"`
+	OfficialReleaseIDs []uint             `json:"officialReleaseIDs,omitempty"`
+	Private            bool               `json:"private"`
+	CreationTime       time.Time          `json:"creationTime,omitempty"`
+}
+
+// Pin describes a post stickied on top of a channel's posts. StickiedPostIDs
+// follow the order of the Positions. Pins without an ExpiresAt stay until removed.
+type Pin struct {
+	PostID       uint       `json:"postID"`
+	Position     int        `json:"position"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	CreationTime time.Time  `json:"creationTime,omitempty"`
+}
+
+// Theme holds the accent colors of a channel as hex color codes.
+type Theme struct {
+	AccentColor    string `json:"accentColor,omitempty"`
+	SecondaryColor string `json:"secondaryColor,omitempty"`
+}
+
+// Page represents a custom markdown page of a channel such as an "About" page.
+// HTML is never persisted, it's the sanitized rendering of Content.
+type Page struct {
+	Slug         string    `json:"slug"`
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	HTML         string    `json:"html,omitempty"`
+	Position     int       `json:"position"`
+	CreationTime time.Time `json:"creationTime,omitempty"`
+	UpdateTime   time.Time `json:"updateTime,omitempty"`
+}
+
+// Member represents a user granted access to a channel along with the tier
+// of the membership.
+type Member struct {
+	Username string    `json:"username"`
+	Tier     Tier      `json:"tier"`
+	JoinTime time.Time `json:"joinTime,omitempty"`
+}
+
+// Tier holds enums used to describe the level of access a Member has.
+type Tier string
+
+// Tiers of membership, admins are above all tiers
+const (
+	// TierMember can view the contents of a private channel
+	TierMember Tier = "member"
+	// TierContributor can also submit drafts to a private channel
+	TierContributor Tier = "contributor"
+)
+
+// JoinRequest represents a request by a user to become a Member of a channel.
+type JoinRequest struct {
+	Username    string    `json:"username"`
+	Message     string    `json:"message,omitempty"`
+	RequestTime time.Time `json:"requestTime,omitempty"`
+}
+
+// Block represents a user restricted from interacting with the posts of a channel.
+type Block struct {
+	Username     string    `json:"username"`
+	Mode         BlockMode `json:"mode"`
+	Reason       string    `json:"reason,omitempty"`
+	BlockedBy    string    `json:"blockedBy,omitempty"`
+	CreationTime time.Time `json:"creationTime,omitempty"`
 }
+
+// BlockMode holds enums used to describe how a Block restricts a user.
+type BlockMode string
+
+// Block modes
+const (
+	// BlockModeBlock refuses the user's comments and stars on the channel's posts
+	BlockModeBlock BlockMode = "block"
+	// BlockModeMute accepts the user's comments but shows them only to the user
+	BlockModeMute BlockMode = "mute"
+)
+
+// Crosspost represents a Post shared into a channel other than the one it was
+// posted in. Crossposts are listed in the receiving channel only after one of its
+// admins accepts them.
+type Crosspost struct {
+	PostID          uint      `json:"postID"`
+	ChannelUsername string    `json:"channelUsername"`
+	OriginChannel   string    `json:"originChannel,omitempty"`
+	RequestedBy     string    `json:"requestedBy,omitempty"`
+	Accepted        bool      `json:"accepted"`
+	CreationTime    time.Time `json:"creationTime,omitempty"`
+}
+
+// Stats holds the analytics of a channel over a period of time.
+// Subscribers holds the running count of current subscribers at the end of each bucket
+// while ActiveReaders holds the count of distinct users that starred or commented on
+// the channel's posts during each bucket. Views holds the number of views the channel's
+// posts and releases received during each bucket.
+type Stats struct {
+	ChannelUsername string          `json:"channelUsername"`
+	From            time.Time       `json:"from"`
+	To              time.Time       `json:"to"`
+	Bucket          Bucket          `json:"bucket"`
+	SubscriberCount uint            `json:"subscriberCount"`
+	Subscribers     []*StatsPoint   `json:"subscribers"`
+	ActiveReaders   []*StatsPoint   `json:"activeReaders"`
+	Views           []*StatsPoint   `json:"views"`
+	Posts           []*PostStats    `json:"posts"`
+	TopPosts        []*PostStats    `json:"topPosts"`
+	Releases        []*ReleaseStats `json:"releases"`
+}
+
+// StatsPoint is a count taken at the start of a bucket.
+type StatsPoint struct {
+	Time  time.Time `json:"time"`
+	Count uint      `json:"count"`
+}
+
+// PostStats holds the stars, comments and views a post received during the period.
+type PostStats struct {
+	PostID       uint   `json:"postID"`
+	Title        string `json:"title"`
+	StarCount    uint   `json:"starCount"`
+	CommentCount uint   `json:"commentCount"`
+	ViewCount    uint   `json:"viewCount"`
+}
+
+// ReleaseStats holds the stars and comments received during the period by the
+// posts a release was attached to along with the views the release itself received.
+type ReleaseStats struct {
+	ReleaseID    uint `json:"releaseID"`
+	StarCount    uint `json:"starCount"`
+	CommentCount uint `json:"commentCount"`
+	ViewCount    uint `json:"viewCount"`
+}
+
+// Bucket holds enums used to specify the granularity of time series in Stats.
+type Bucket string
+
+// Bucket constants
+const (
+	BucketDay   Bucket = "day"
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
@@ -2,7 +2,11 @@
 Package channel contains definition and implemntation of a service that deals with User entities */
 package channel
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
 
 type Service interface {
 	AddChannel(channel *Channel) (*Channel, error)
@@ -17,9 +21,38 @@ type Service interface {
 	DeleteReleaseFromOfficialCatalog(channelUsername string, ReleaseID uint) error
 	AddReleaseToOfficialCatalog(channelUsername string, releaseID uint, postID uint) error
 	DeleteStickiedPost(channelUsername string, stickiedPostID uint) error
-	StickyPost(channelUsername string, postID uint) error
+	StickyPost(channelUsername string, pin *Pin) error
+	ReorderStickiedPosts(channelUsername string, postIDs []uint) error
 	AddPicture(channelUsername string, name string) (string, error)
 	RemovePicture(channelUsername string) error
+	SetPrivacy(channelUsername string, private bool) error
+	CanView(channelUsername string, username string) (bool, error)
+	GetMembers(channelUsername string) ([]*Member, error)
+	GetMember(channelUsername string, username string) (*Member, error)
+	AddMember(channelUsername string, member *Member) error
+	RemoveMember(channelUsername string, username string) error
+	RequestToJoin(channelUsername string, request *JoinRequest) (*JoinRequest, error)
+	GetJoinRequests(channelUsername string) ([]*JoinRequest, error)
+	ApproveJoinRequest(channelUsername string, username string, tier Tier) error
+	RejectJoinRequest(channelUsername string, username string) error
+	GetStats(channelUsername string, from, to time.Time, bucket Bucket, topLimit int) (*Stats, error)
+	AddBanner(channelUsername string, name string) (string, error)
+	RemoveBanner(channelUsername string) error
+	SetTheme(channelUsername string, theme *Theme) error
+	GetPages(channelUsername string) ([]*Page, error)
+	GetPage(channelUsername string, slug string) (*Page, error)
+	AddPage(channelUsername string, page *Page) (*Page, error)
+	UpdatePage(channelUsername string, slug string, page *Page) (*Page, error)
+	DeletePage(channelUsername string, slug string) error
+	GetBlockedUsers(channelUsername string) ([]*Block, error)
+	GetBlockedUser(channelUsername string, username string) (*Block, error)
+	BlockUser(channelUsername string, block *Block) (*Block, error)
+	UnblockUser(channelUsername string, username string) error
+	GetCrossposts(channelUsername string, accepted bool) ([]*Crosspost, error)
+	GetPostCrossposts(postID uint) ([]*Crosspost, error)
+	RequestCrosspost(channelUsername string, crosspost *Crosspost) (*Crosspost, error)
+	AcceptCrosspost(channelUsername string, postID uint) error
+	RemoveCrosspost(channelUsername string, postID uint) error
 }
 type Repository interface {
 	AddChannel(channel *Channel) (*Channel, error)
@@ -34,9 +67,37 @@ type Repository interface {
 	DeleteReleaseFromOfficialCatalog(channelUsername string, ReleaseID uint) error
 	AddReleaseToOfficialCatalog(channelUsername string, releaseID uint, postID uint) error
 	DeleteStickiedPost(channelUsername string, stickiedPostID uint) error
-	StickyPost(channelUsername string, postID uint) error
+	StickyPost(channelUsername string, pin *Pin) error
+	ReorderStickiedPosts(channelUsername string, postIDs []uint) error
 	AddPicture(channelUsername string, name string) (string, error)
 	RemovePicture(channelUsername string) error
+	SetPrivacy(channelUsername string, private bool) error
+	GetMembers(channelUsername string) ([]*Member, error)
+	GetMember(channelUsername string, username string) (*Member, error)
+	AddMember(channelUsername string, member *Member) error
+	RemoveMember(channelUsername string, username string) error
+	GetJoinRequests(channelUsername string) ([]*JoinRequest, error)
+	AddJoinRequest(channelUsername string, request *JoinRequest) (*JoinRequest, error)
+	DeleteJoinRequest(channelUsername string, username string) error
+	GetStats(channelUsername string, from, to time.Time, bucket Bucket, topLimit int) (*Stats, error)
+	AddBanner(channelUsername string, name string) (string, error)
+	RemoveBanner(channelUsername string) error
+	SetTheme(channelUsername string, theme *Theme) error
+	GetPages(channelUsername string) ([]*Page, error)
+	GetPage(channelUsername string, slug string) (*Page, error)
+	AddPage(channelUsername string, page *Page) (*Page, error)
+	UpdatePage(channelUsername string, slug string, page *Page) (*Page, error)
+	DeletePage(channelUsername string, slug string) error
+	GetBlockedUsers(channelUsername string) ([]*Block, error)
+	GetBlockedUser(channelUsername string, username string) (*Block, error)
+	BlockUser(channelUsername string, block *Block) (*Block, error)
+	UnblockUser(channelUsername string, username string) error
+	GetCrossposts(channelUsername string, accepted bool) ([]*Crosspost, error)
+	GetPostCrossposts(postID uint) ([]*Crosspost, error)
+	GetCrosspost(channelUsername string, postID uint) (*Crosspost, error)
+	AddCrosspost(channelUsername string, crosspost *Crosspost) (*Crosspost, error)
+	AcceptCrosspost(channelUsername string, postID uint) error
+	DeleteCrosspost(channelUsername string, postID uint) error
 }
 type SortOrder string
 type SortBy string
@@ -87,7 +148,82 @@ var ErrStickiedPostNotFound = fmt.Errorf("stickied post not found")
 var ErrPostNotFound = fmt.Errorf("post not found")
 
 // ErrStickiedPostFull is returned when the channel has filled it's stickied post quota
-var ErrStickiedPostFull = fmt.Errorf("two posts already stickied")
+var ErrStickiedPostFull = fmt.Errorf("maximum number of posts already stickied")
+
+// ErrInvalidPinExpiry is returned when a post is stickied with an expiry that has already passed
+var ErrInvalidPinExpiry = fmt.Errorf("pin expiry has already passed")
+
+// ErrInvalidPinOrder is returned when a new order of stickied posts doesn't list each of them exactly once
+var ErrInvalidPinOrder = fmt.Errorf("invalid order of stickied posts")
+
+// MaxStickiedPosts is the most posts a channel can have stickied at once
+const MaxStickiedPosts = 5
+
+// ErrMemberNotFound is returned when the specified user isn't a member of the channel
+var ErrMemberNotFound = fmt.Errorf("member not found")
+
+// ErrAlreadyMember is returned when the specified user already has access to the channel
+var ErrAlreadyMember = fmt.Errorf("user is already a member")
+
+// ErrInvalidTier is returned when the specified membership tier isn't recognized
+var ErrInvalidTier = fmt.Errorf("invalid membership tier")
+
+// ErrJoinRequestNotFound is returned when the specified user hasn't requested to join the channel
+var ErrJoinRequestNotFound = fmt.Errorf("join request not found")
+
+// ErrJoinRequestAlreadyExists is returned when the specified user already has a pending join request
+var ErrJoinRequestAlreadyExists = fmt.Errorf("join request already exists")
+
+// ErrUserNotFound is returned when the specified user isn't recognized
+var ErrUserNotFound = fmt.Errorf("user not found")
+
+// ErrInvalidStatsRange is returned when the requested period of the stats is invalid
+var ErrInvalidStatsRange = fmt.Errorf("invalid stats range")
+
+// ErrInvalidBucket is returned when the requested bucketing of the stats isn't recognized
+var ErrInvalidBucket = fmt.Errorf("invalid stats bucket")
+
+// ErrInvalidTheme is returned when the given colors aren't hex color codes
+var ErrInvalidTheme = fmt.Errorf("invalid theme colors")
+
+// ErrPageNotFound is returned when the requested page doesn't exist
+var ErrPageNotFound = fmt.Errorf("page not found")
+
+// ErrPageAlreadyExists is returned when the channel already has a page under the given slug
+var ErrPageAlreadyExists = fmt.Errorf("page already exists")
+
+// ErrInvalidPage is returned when the given page has an invalid slug or is missing a title
+var ErrInvalidPage = fmt.Errorf("invalid page data")
+
+// ErrTooManyPages is returned when the channel has reached the maximum number of pages
+var ErrTooManyPages = fmt.Errorf("too many pages")
+
+// ErrBlockNotFound is returned when the specified user isn't blocked on the channel
+var ErrBlockNotFound = fmt.Errorf("block not found")
+
+// ErrInvalidBlockMode is returned when the specified block mode isn't recognized
+var ErrInvalidBlockMode = fmt.Errorf("invalid block mode")
+
+// ErrCantBlockAdmin is returned when an attempt is made to block an admin of the channel
+var ErrCantBlockAdmin = fmt.Errorf("admins can't be blocked")
+
+// ErrCrosspostNotFound is returned when the specified post isn't crossposted to the channel
+var ErrCrosspostNotFound = fmt.Errorf("crosspost not found")
+
+// ErrCrosspostAlreadyExists is returned when the specified post is already crossposted or requested to be crossposted to the channel
+var ErrCrosspostAlreadyExists = fmt.Errorf("crosspost already exists")
+
+// ErrCrosspostToOrigin is returned when an attempt is made to crosspost a post to the channel it was posted in
+var ErrCrosspostToOrigin = fmt.Errorf("post can't be crossposted to its own channel")
+
+// maxPages limits the number of custom pages a channel can have
+const maxPages = 10
+
+var colorRegexp = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+var slugRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// maxStatsBuckets limits the number of buckets a single stats query can span
+const maxStatsBuckets = 400
 
 type service struct {
 	allServices *map[string]interface{}
@@ -126,7 +262,26 @@ func (service *service) AddChannel(channel *Channel) (*Channel, error) {
 
 // GetChannel gets a channel according to the given username
 func (service *service) GetChannel(username string) (*Channel, error) {
-	return (*service.repo).GetChannel(username)
+	c, err := (*service.repo).GetChannel(username)
+	if err != nil {
+		return nil, err
+	}
+	dropExpiredPins(c, time.Now())
+	return c, nil
+}
+
+// dropExpiredPins is just a helper function that leaves out the pins that might have
+// expired since the channel was fetched
+func dropExpiredPins(c *Channel, now time.Time) {
+	pins := make([]*Pin, 0, len(c.Pins))
+	ids := make([]uint, 0, len(c.Pins))
+	for _, pin := range c.Pins {
+		if pin.ExpiresAt == nil || pin.ExpiresAt.After(now) {
+			pins = append(pins, pin)
+			ids = append(ids, pin.PostID)
+		}
+	}
+	c.Pins, c.StickiedPostIDs = pins, ids
 }
 
 // UpdateChannel updates a channel according to the given username and channel
@@ -148,7 +303,15 @@ func (service *service) SearchChannels(pattern string, sortBy SortBy, sortOrder
 	if limit < 0 || offset < 0 {
 		return nil, fmt.Errorf("invalid pagination")
 	}
-	return (*service.repo).SearchChannels(pattern, sortBy, sortOrder, limit, offset)
+	channels, err := (*service.repo).SearchChannels(pattern, sortBy, sortOrder, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, c := range channels {
+		dropExpiredPins(c, now)
+	}
+	return channels, nil
 }
 
 // DeleteChannel removes the channel of the given username
@@ -294,28 +457,55 @@ func (service *service) ChangeOwner(channelUsername string, ownerUsername string
 	return (*service.repo).ChangeOwner(channelUsername, ownerUsername)
 }
 
-// StickyPost sticks the given postID for the channel of the given username on top of the post view of channel.
-func (service *service) StickyPost(channelUsername string, postID uint) error {
+// StickyPost sticks the given post on top of the post view of the channel of the given username.
+// Pins with a Position out of the range of the current pins are placed after them.
+func (service *service) StickyPost(channelUsername string, pin *Pin) error {
 	c, err := service.GetChannel(channelUsername)
 	if err != nil {
 		//fmt.Errorf("channel can't sticky post because %s", err.Error())
 		return err
 	}
-	i := 0
-	oia := false
-
-	for ; i < len(c.StickiedPostIDs); i++ {
-		if c.StickiedPostIDs[i] == postID {
-			oia = true
-			break
-		} else {
-			oia = false
+	for _, id := range c.StickiedPostIDs {
+		if id == pin.PostID {
+			return ErrPostAlreadyStickied
 		}
 	}
-	if oia == true {
-		return ErrPostAlreadyStickied
+	if !service.IsPostFromChannel(channelUsername, pin.PostID) {
+		return ErrPostNotFound
+	}
+	if len(c.StickiedPostIDs) >= MaxStickiedPosts {
+		return ErrStickiedPostFull
+	}
+	if pin.ExpiresAt != nil && !pin.ExpiresAt.After(time.Now()) {
+		return ErrInvalidPinExpiry
 	}
-	return (*service.repo).StickyPost(channelUsername, postID)
+	if pin.Position < 0 || pin.Position > len(c.StickiedPostIDs) {
+		pin.Position = len(c.StickiedPostIDs)
+	}
+	return (*service.repo).StickyPost(channelUsername, pin)
+}
+
+// ReorderStickiedPosts orders the stickied posts of the channel of the given username
+// according to the given list which must hold each of them exactly once.
+func (service *service) ReorderStickiedPosts(channelUsername string, postIDs []uint) error {
+	c, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	if len(postIDs) != len(c.StickiedPostIDs) {
+		return ErrInvalidPinOrder
+	}
+	stickied := make(map[uint]bool, len(c.StickiedPostIDs))
+	for _, id := range c.StickiedPostIDs {
+		stickied[id] = true
+	}
+	for _, id := range postIDs {
+		if !stickied[id] {
+			return ErrInvalidPinOrder
+		}
+		delete(stickied, id)
+	}
+	return (*service.repo).ReorderStickiedPosts(channelUsername, postIDs)
 }
 
 // AddPicture adds the given image name as the picture for the given username.
@@ -337,3 +527,374 @@ func (service *service) RemovePicture(channelUsername string) error {
 	}
 	return (*service.repo).RemovePicture(channelUsername)
 }
+
+// SetPrivacy makes the channel of the given username private or public.
+func (service *service) SetPrivacy(channelUsername string, private bool) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).SetPrivacy(channelUsername, private)
+}
+
+// CanView checks if the given user can view the contents of the channel.
+// Public channels are visible to all while private channels are visible only to
+// their admins and members.
+func (service *service) CanView(channelUsername string, username string) (bool, error) {
+	c, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return false, err
+	}
+	if !c.Private {
+		return true, nil
+	}
+	if username == "" {
+		return false, nil
+	}
+	for _, admin := range c.AdminUsernames {
+		if admin == username {
+			return true, nil
+		}
+	}
+	_, err = (*service.repo).GetMember(channelUsername, username)
+	switch err {
+	case nil:
+		return true, nil
+	case ErrMemberNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// GetMembers returns the members of the channel of the given username.
+func (service *service) GetMembers(channelUsername string) ([]*Member, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	return (*service.repo).GetMembers(channelUsername)
+}
+
+// GetMember returns the membership of the given user in the channel of the given username.
+func (service *service) GetMember(channelUsername string, username string) (*Member, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	return (*service.repo).GetMember(channelUsername, username)
+}
+
+// AddMember adds the given member to the channel or updates the tier if already a member.
+func (service *service) AddMember(channelUsername string, member *Member) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	switch member.Tier {
+	case "":
+		member.Tier = TierMember
+	case TierMember, TierContributor:
+	default:
+		return ErrInvalidTier
+	}
+	return (*service.repo).AddMember(channelUsername, member)
+}
+
+// RemoveMember removes the given user from the members of the channel.
+func (service *service) RemoveMember(channelUsername string, username string) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).RemoveMember(channelUsername, username)
+}
+
+// RequestToJoin files a request by the given user to become a member of the channel.
+func (service *service) RequestToJoin(channelUsername string, request *JoinRequest) (*JoinRequest, error) {
+	c, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	for _, admin := range c.AdminUsernames {
+		if admin == request.Username {
+			return nil, ErrAlreadyMember
+		}
+	}
+	_, err = (*service.repo).GetMember(channelUsername, request.Username)
+	switch err {
+	case nil:
+		return nil, ErrAlreadyMember
+	case ErrMemberNotFound:
+	default:
+		return nil, err
+	}
+	return (*service.repo).AddJoinRequest(channelUsername, request)
+}
+
+// GetJoinRequests returns the pending join requests of the channel of the given username.
+func (service *service) GetJoinRequests(channelUsername string) ([]*JoinRequest, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	return (*service.repo).GetJoinRequests(channelUsername)
+}
+
+// ApproveJoinRequest grants the requesting user membership of the given tier.
+func (service *service) ApproveJoinRequest(channelUsername string, username string, tier Tier) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	switch tier {
+	case "":
+		tier = TierMember
+	case TierMember, TierContributor:
+	default:
+		return ErrInvalidTier
+	}
+	err = (*service.repo).DeleteJoinRequest(channelUsername, username)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).AddMember(channelUsername, &Member{Username: username, Tier: tier})
+}
+
+// RejectJoinRequest discards the join request of the given user.
+func (service *service) RejectJoinRequest(channelUsername string, username string) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).DeleteJoinRequest(channelUsername, username)
+}
+
+// GetStats returns the analytics of the channel between the given times bucketed by the given Bucket.
+// A zero to defaults to now and a zero from defaults to thirty days before to.
+func (service *service) GetStats(channelUsername string, from, to time.Time, bucket Bucket, topLimit int) (*Stats, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	var bucketLength time.Duration
+	switch bucket {
+	case "":
+		bucket = BucketDay
+		fallthrough
+	case BucketDay:
+		bucketLength = 24 * time.Hour
+	case BucketWeek:
+		bucketLength = 7 * 24 * time.Hour
+	case BucketMonth:
+		bucketLength = 28 * 24 * time.Hour
+	default:
+		return nil, ErrInvalidBucket
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if from.After(to) || to.Sub(from)/bucketLength > maxStatsBuckets {
+		return nil, ErrInvalidStatsRange
+	}
+	if topLimit <= 0 {
+		topLimit = 10
+	}
+	return (*service.repo).GetStats(channelUsername, from, to, bucket, topLimit)
+}
+
+// AddBanner adds the given image name as the banner for the given username.
+func (service *service) AddBanner(channelUsername string, name string) (string, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return "", err
+	}
+	return (*service.repo).AddBanner(channelUsername, name)
+}
+
+// RemoveBanner removes the banner for the given username.
+func (service *service) RemoveBanner(channelUsername string) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).RemoveBanner(channelUsername)
+}
+
+// SetTheme sets the accent colors of the channel. Empty colors reset to the default.
+func (service *service) SetTheme(channelUsername string, theme *Theme) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	for _, color := range []string{theme.AccentColor, theme.SecondaryColor} {
+		if color != "" && !colorRegexp.MatchString(color) {
+			return ErrInvalidTheme
+		}
+	}
+	return (*service.repo).SetTheme(channelUsername, theme)
+}
+
+// GetPages returns the custom pages of the channel ordered by their position.
+func (service *service) GetPages(channelUsername string) ([]*Page, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	return (*service.repo).GetPages(channelUsername)
+}
+
+// GetPage returns the custom page of the channel under the given slug.
+func (service *service) GetPage(channelUsername string, slug string) (*Page, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	return (*service.repo).GetPage(channelUsername, slug)
+}
+
+// AddPage adds a custom page to the channel.
+func (service *service) AddPage(channelUsername string, page *Page) (*Page, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	if !slugRegexp.MatchString(page.Slug) || len(page.Slug) > 32 || page.Title == "" {
+		return nil, ErrInvalidPage
+	}
+	pages, err := (*service.repo).GetPages(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) >= maxPages {
+		return nil, ErrTooManyPages
+	}
+	return (*service.repo).AddPage(channelUsername, page)
+}
+
+// UpdatePage replaces the custom page under the given slug with the given page.
+func (service *service) UpdatePage(channelUsername string, slug string, page *Page) (*Page, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	if page.Slug == "" {
+		page.Slug = slug
+	}
+	if !slugRegexp.MatchString(page.Slug) || len(page.Slug) > 32 || page.Title == "" {
+		return nil, ErrInvalidPage
+	}
+	return (*service.repo).UpdatePage(channelUsername, slug, page)
+}
+
+// DeletePage removes the custom page under the given slug.
+func (service *service) DeletePage(channelUsername string, slug string) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).DeletePage(channelUsername, slug)
+}
+
+// GetBlockedUsers returns the users blocked or muted on the channel.
+func (service *service) GetBlockedUsers(channelUsername string) ([]*Block, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	return (*service.repo).GetBlockedUsers(channelUsername)
+}
+
+// GetBlockedUser returns the block of the given user on the channel.
+// ErrBlockNotFound is returned if the user isn't blocked nor muted.
+func (service *service) GetBlockedUser(channelUsername string, username string) (*Block, error) {
+	return (*service.repo).GetBlockedUser(channelUsername, username)
+}
+
+// BlockUser blocks or mutes the given user on the channel, replacing any previous block.
+func (service *service) BlockUser(channelUsername string, block *Block) (*Block, error) {
+	c, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Mode {
+	case "":
+		block.Mode = BlockModeBlock
+	case BlockModeBlock, BlockModeMute:
+	default:
+		return nil, ErrInvalidBlockMode
+	}
+	for _, admin := range c.AdminUsernames {
+		if admin == block.Username {
+			return nil, ErrCantBlockAdmin
+		}
+	}
+	return (*service.repo).BlockUser(channelUsername, block)
+}
+
+// UnblockUser lifts the block of the given user on the channel.
+func (service *service) UnblockUser(channelUsername string, username string) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).UnblockUser(channelUsername, username)
+}
+
+// GetCrossposts returns the posts crossposted to the channel, the accepted ones
+// or the ones still waiting for the consent of an admin.
+func (service *service) GetCrossposts(channelUsername string, accepted bool) ([]*Crosspost, error) {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	return (*service.repo).GetCrossposts(channelUsername, accepted)
+}
+
+// GetPostCrossposts returns all the channels the given post is crossposted, or requested
+// to be crossposted, to.
+func (service *service) GetPostCrossposts(postID uint) ([]*Crosspost, error) {
+	return (*service.repo).GetPostCrossposts(postID)
+}
+
+// RequestCrosspost requests for the post of the given crosspost to be shared into the channel.
+// The crosspost is accepted right away if the requesting user is an admin of the channel.
+func (service *service) RequestCrosspost(channelUsername string, crosspost *Crosspost) (*Crosspost, error) {
+	c, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return nil, err
+	}
+	if crosspost.OriginChannel == channelUsername {
+		return nil, ErrCrosspostToOrigin
+	}
+	crosspost.Accepted = false
+	for _, admin := range c.AdminUsernames {
+		if admin == crosspost.RequestedBy {
+			crosspost.Accepted = true
+			break
+		}
+	}
+	return (*service.repo).AddCrosspost(channelUsername, crosspost)
+}
+
+// AcceptCrosspost lists the requested crosspost of the given post in the channel.
+func (service *service) AcceptCrosspost(channelUsername string, postID uint) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).AcceptCrosspost(channelUsername, postID)
+}
+
+// RemoveCrosspost removes the given post from the channel it's crossposted to.
+// It's also used to reject or withdraw pending crossposts.
+func (service *service) RemoveCrosspost(channelUsername string, postID uint) error {
+	_, err := service.GetChannel(channelUsername)
+	if err != nil {
+		return err
+	}
+	return (*service.repo).DeleteCrosspost(channelUsername, postID)
+}
@@ -1,16 +1,21 @@
 package comment
 
-import "time"
+import (
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/mention"
+)
 
 // Comment represents standard comments users can attach
 // to a post or another comment.
 // replyTo is either and id of another comment or -1 if
 // it's only a reply to original post.
 type Comment struct {
-	ID           int       `json:"id"`
-	OriginPost   int       `json:"originPost,omitempty"`
-	Commenter    string    `json:"commenter"`
-	Content      string    `json:"content"`
-	ReplyTo      int       `json:"replyTo,omitempty"`
-	CreationTime time.Time `json:"creationTime,omitempty"`
+	ID           int                `json:"id"`
+	OriginPost   int                `json:"originPost,omitempty"`
+	Commenter    string             `json:"commenter"`
+	Content      string             `json:"content"`
+	ReplyTo      int                `json:"replyTo,omitempty"`
+	Mentions     []*mention.Mention `json:"mentions,omitempty"`
+	CreationTime time.Time          `json:"creationTime,omitempty"`
 }
@@ -8,8 +8,8 @@ import "fmt"
 type Service interface {
 	AddComment(c *Comment) (*Comment, error)
 	GetComment(id int) (*Comment, error)
-	GetComments(postID int, by SortBy, order SortOrder, limit, offset int) ([]*Comment, error)
-	GetReplies(commentID int, by SortBy, order SortOrder, limit, offset int) ([]*Comment, error)
+	GetComments(postID int, by SortBy, order SortOrder, limit, offset int, viewer string) ([]*Comment, error)
+	GetReplies(commentID int, by SortBy, order SortOrder, limit, offset int, viewer string) ([]*Comment, error)
 	UpdateComment(c *Comment) (*Comment, error)
 	DeleteComment(id int) error
 }
@@ -18,8 +18,8 @@ type Service interface {
 type Repository interface {
 	AddComment(c *Comment) (*Comment, error)
 	GetComment(id int) (*Comment, error)
-	GetComments(postID int, sortBy string, sortOrder string, limit, offset int) ([]*Comment, error)
-	GetReplies(commentID int, by string, order string, limit, offset int) ([]*Comment, error)
+	GetComments(postID int, sortBy string, sortOrder string, limit, offset int, viewer string) ([]*Comment, error)
+	GetReplies(commentID int, by string, order string, limit, offset int, viewer string) ([]*Comment, error)
 	UpdateComment(c *Comment) (*Comment, error)
 	DeleteComment(id int) error
 }
@@ -78,15 +78,16 @@ func (s service) GetComment(id int) (*Comment, error) {
 }
 
 // GetComment get's all the comments found under a single post.
-// This includes replies to comments.
-func (s service) GetComments(postID int, by SortBy, order SortOrder, limit, offset int) ([]*Comment, error) {
-	return (*s.repo).GetComments(postID, string(by), string(order), limit, offset)
+// This includes replies to comments. Comments of users muted on the post's
+// channel are only included if viewer is the commenter.
+func (s service) GetComments(postID int, by SortBy, order SortOrder, limit, offset int, viewer string) ([]*Comment, error) {
+	return (*s.repo).GetComments(postID, string(by), string(order), limit, offset, viewer)
 }
 
 // GetReplies returns all the comments that are replies to the comment
-// under the given id.
-func (s service) GetReplies(commentID int, by SortBy, order SortOrder, limit, offset int) ([]*Comment, error) {
-	return (*s.repo).GetReplies(commentID, string(by), string(order), limit, offset)
+// under the given id. Replies of muted users are only included if viewer is the commenter.
+func (s service) GetReplies(commentID int, by SortBy, order SortOrder, limit, offset int, viewer string) ([]*Comment, error) {
+	return (*s.repo).GetReplies(commentID, string(by), string(order), limit, offset, viewer)
 }
 
 // UpdateComment updates a comment entity based on the given struct.
@@ -22,7 +22,8 @@ type (
 	// Post is an aggregate entity of Releases along with socially interactive
 	// components such as stars, posting user and comments attached to Releases
 	Post struct {
-		ID int `json:"id"`
+		ID     int  `json:"id"`
+		Pinned bool `json:"pinned,omitempty"`
 		//OwnerChannel     string    `json:"ownerChannel"`
 		//PosterUsername   string    `json:"posterUsername"`
 		//Title            string    `json:"title"`
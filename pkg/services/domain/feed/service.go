@@ -4,13 +4,15 @@ package feed
 
 import (
 	"fmt"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 // Service specifies a method to service Feeds .
 type Service interface {
 	AddFeed(f *Feed) error
 	GetFeed(username string) (*Feed, error)
-	GetPosts(f *Feed, sort Sorting, limit, offset int) ([]*Post, error)
+	GetPosts(f *Feed, sort Sorting, limit, offset int, filter maturity.Filter) ([]*Post, error)
 	GetChannels(f *Feed, sortBy SortBy, sortOrder SortOrder) ([]*Channel, error)
 	UpdateFeed(username string, f *Feed) error
 	Subscribe(f *Feed, channelname string) error
@@ -21,7 +23,8 @@ type Service interface {
 type Repository interface {
 	AddFeed(f *Feed) error
 	GetFeed(username string) (*Feed, error)
-	GetPosts(f *Feed, sort Sorting, limit, offset int) ([]*Post, error)
+	GetPosts(f *Feed, sort Sorting, limit, offset int, filter maturity.Filter, exclude []int) ([]*Post, error)
+	GetPinnedPosts(f *Feed, filter maturity.Filter) ([]*Post, error)
 	GetChannels(f *Feed, sortBy string, sortOrder string) ([]*Channel, error)
 	UpdateFeed(id uint, f *Feed) error
 	Subscribe(f *Feed, channelname string) error
@@ -102,9 +105,10 @@ func (s service) GetFeed(username string) (*Feed, error) {
 
 // GetPosts returns a list of posts collected from the channels
 // the given feed has subscribed to sorted according to the given
-// method.
-// Pagination can be specified.
-func (s service) GetPosts(f *Feed, sort Sorting, limit, offset int) ([]*Post, error) {
+// method leaving out the ones that don't pass the given filter.
+// Pagination can be specified. The posts pinned in the subscribed
+// channels top the list and count towards the limit like the rest.
+func (s service) GetPosts(f *Feed, sort Sorting, limit, offset int, filter maturity.Filter) ([]*Post, error) {
 	if limit < 0 || offset < 0 {
 		return nil, fmt.Errorf("invalid pagination")
 	}
@@ -114,7 +118,35 @@ func (s service) GetPosts(f *Feed, sort Sorting, limit, offset int) ([]*Post, er
 		if sort == NotSet {
 			sort = f.Sorting
 		}
-		return (*s.repo).GetPosts(f, sort, limit, offset)
+		pinned, err := (*s.repo).GetPinnedPosts(f, filter)
+		if err != nil {
+			return nil, err
+		}
+		// pinned posts are left out of the pages so that they're never listed twice
+		pinnedIDs := make([]int, 0, len(pinned))
+		for _, p := range pinned {
+			p.Pinned = true
+			pinnedIDs = append(pinnedIDs, p.ID)
+		}
+		// the page is cut from the pinned posts followed by the rest
+		from, to := offset, offset+limit
+		if from > len(pinned) {
+			from = len(pinned)
+		}
+		if to > len(pinned) {
+			to = len(pinned)
+		}
+		pinned = pinned[from:to]
+		offset -= from
+		limit -= len(pinned)
+		if limit == 0 {
+			return pinned, nil
+		}
+		posts, err := (*s.repo).GetPosts(f, sort, limit, offset, filter, pinnedIDs)
+		if err != nil {
+			return nil, err
+		}
+		return append(pinned, posts...), nil
 	}
 }
 
@@ -0,0 +1,33 @@
+package mention
+
+import "time"
+
+// Mention is a reference to a user or a channel, written as @username or
+// #channelUsername, found in the content of a post or a comment.
+// Text holds the mention as written so that clients can turn it into a link.
+type Mention struct {
+	SourceType   SourceType `json:"sourceType,omitempty"`
+	SourceID     int        `json:"sourceID,omitempty"`
+	Kind         Kind       `json:"kind"`
+	Target       string     `json:"target"`
+	Text         string     `json:"text"`
+	CreationTime time.Time  `json:"creationTime,omitempty"`
+}
+
+// SourceType holds enums that specify what kind of entity a mention was written in
+type SourceType string
+
+// SourceType constants
+const (
+	SourcePost    SourceType = "post"
+	SourceComment SourceType = "comment"
+)
+
+// Kind holds enums that specify what kind of entity is mentioned
+type Kind string
+
+// Kind constants
+const (
+	KindUser    Kind = "user"
+	KindChannel Kind = "channel"
+)
@@ -0,0 +1,139 @@
+/*
+Package mention contains definition and implementation of a service that deals with the
+mentions of users and channels in posts and comments */
+package mention
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/channel"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/user"
+)
+
+// Service specifies a method to service Mention entities.
+type Service interface {
+	SetMentions(sourceType SourceType, sourceID int, text string) ([]*Mention, error)
+	GetMentions(sourceType SourceType, sourceID int) ([]*Mention, error)
+	GetMentionsOf(kind Kind, target, viewer string, limit, offset int) ([]*Mention, error)
+	DeleteMentions(sourceType SourceType, sourceID int) error
+}
+
+// Repository specifies a repo interface to serve the Mention Service interface
+type Repository interface {
+	SetMentions(sourceType SourceType, sourceID int, mentions []*Mention) error
+	GetMentions(sourceType SourceType, sourceID int) ([]*Mention, error)
+	GetMentionsOf(kind Kind, target, viewer string, limit, offset int) ([]*Mention, error)
+	DeleteMentions(sourceType SourceType, sourceID int) error
+}
+
+// ErrInvalidSourceType is returned when mentions are looked for in an unrecognized kind of entity
+var ErrInvalidSourceType = fmt.Errorf("invalid mention source type")
+
+// ErrInvalidKind is returned when mentions of an unrecognized kind of entity are requested
+var ErrInvalidKind = fmt.Errorf("invalid mention kind")
+
+// MaxMentions is the number of mentions recorded for a single post or comment
+const MaxMentions = 20
+
+// mentionPattern matches @username and #channelUsername when they aren't part of a word,
+// an html entity or a link
+var mentionPattern = regexp.MustCompile(`(?:^|[\s>(\[,;])([@#])([a-zA-Z](?:_?[a-zA-Z0-9])*)`)
+
+type service struct {
+	repo        *Repository
+	allServices *map[string]interface{}
+}
+
+// NewService returns a struct that implements the mention.Service interface.
+// A map of all the services must be passed as the second argument as mentions
+// are resolved against the User and Channel services.
+func NewService(repo *Repository, allServices *map[string]interface{}) Service {
+	return &service{repo: repo, allServices: allServices}
+}
+
+// ParseMentions returns the distinct user and channel mentions written in the given
+// text, in the order they appear, without checking whether they exist.
+func ParseMentions(text string) []*Mention {
+	mentions := make([]*Mention, 0)
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(html.UnescapeString(text), -1) {
+		m := &Mention{Kind: KindUser, Target: match[2], Text: match[1] + match[2]}
+		if match[1] == "#" {
+			m.Kind = KindChannel
+		}
+		if !seen[m.Text] {
+			seen[m.Text] = true
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions
+}
+
+// SetMentions replaces the mentions recorded for the given source with the ones written
+// in text that refer to existing users and channels.
+func (s *service) SetMentions(sourceType SourceType, sourceID int, text string) ([]*Mention, error) {
+	switch sourceType {
+	case SourcePost, SourceComment:
+	default:
+		return nil, ErrInvalidSourceType
+	}
+	mentions := make([]*Mention, 0)
+	for _, m := range ParseMentions(text) {
+		if len(mentions) == MaxMentions {
+			break
+		}
+		m.SourceType = sourceType
+		m.SourceID = sourceID
+		switch m.Kind {
+		case KindUser:
+			if userService, ok := (*s.allServices)["User"].(*user.Service); ok {
+				if u, err := (*userService).GetUser(m.Target); err == nil {
+					m.Target = u.Username
+					mentions = append(mentions, m)
+				}
+			}
+		case KindChannel:
+			if channelService, ok := (*s.allServices)["Channel"].(*channel.Service); ok {
+				if c, err := (*channelService).GetChannel(m.Target); err == nil {
+					m.Target = c.ChannelUsername
+					mentions = append(mentions, m)
+				}
+			}
+		}
+	}
+	if err := (*s.repo).SetMentions(sourceType, sourceID, mentions); err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
+
+// GetMentions returns the mentions recorded for the given source.
+func (s *service) GetMentions(sourceType SourceType, sourceID int) ([]*Mention, error) {
+	switch sourceType {
+	case SourcePost, SourceComment:
+	default:
+		return nil, ErrInvalidSourceType
+	}
+	return (*s.repo).GetMentions(sourceType, sourceID)
+}
+
+// GetMentionsOf returns the mentions of the given user or channel, newest first. Only the
+// mentions in the posts and comments the viewer can see are returned.
+func (s *service) GetMentionsOf(kind Kind, target, viewer string, limit, offset int) ([]*Mention, error) {
+	switch kind {
+	case KindUser, KindChannel:
+	default:
+		return nil, ErrInvalidKind
+	}
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	return (*s.repo).GetMentionsOf(kind, target, viewer, limit, offset)
+}
+
+// DeleteMentions removes the mentions recorded for the given source.
+func (s *service) DeleteMentions(sourceType SourceType, sourceID int) error {
+	return (*s.repo).DeleteMentions(sourceType, sourceID)
+}
@@ -0,0 +1,55 @@
+package poll
+
+import "time"
+
+// Poll is a question attached to a Post that readers can vote on.
+type Poll struct {
+	ID             uint       `json:"id"`
+	PostID         uint       `json:"postID"`
+	CreatedBy      string     `json:"createdBy,omitempty"`
+	Question       string     `json:"question"`
+	Options        []*Option  `json:"options"`
+	MultipleChoice bool       `json:"multipleChoice"`
+	HideResults    bool       `json:"hideResults"`
+	ClosesAt       *time.Time `json:"closesAt,omitempty"`
+	CreationTime   time.Time  `json:"creationTime"`
+}
+
+// Option is one of the choices of a Poll.
+type Option struct {
+	ID   uint   `json:"id"`
+	Text string `json:"text"`
+}
+
+// Closed reports whether voting on the poll has closed by the given time.
+func (p *Poll) Closed(at time.Time) bool {
+	return p.ClosesAt != nil && !at.Before(*p.ClosesAt)
+}
+
+// Vote is the set of options a user picked on a Poll. Single choice
+// polls only accept votes with a single option.
+type Vote struct {
+	PollID       uint      `json:"pollID"`
+	Username     string    `json:"username"`
+	OptionIDs    []uint    `json:"optionIDs"`
+	CreationTime time.Time `json:"creationTime,omitempty"`
+}
+
+// Results is the tally of the votes cast on a Poll.
+// Hidden reports whether the tally has been withheld since the poll's author chose
+// to hide results until voting closes. Voted holds the options the viewing user picked.
+type Results struct {
+	PollID      uint           `json:"pollID"`
+	Closed      bool           `json:"closed"`
+	Hidden      bool           `json:"hidden"`
+	TotalVoters uint           `json:"totalVoters"`
+	Options     []*OptionTally `json:"options"`
+	Voted       []uint         `json:"voted,omitempty"`
+}
+
+// OptionTally is the number of votes an Option received.
+type OptionTally struct {
+	OptionID uint   `json:"optionID"`
+	Text     string `json:"text"`
+	Votes    uint   `json:"votes"`
+}
@@ -0,0 +1,169 @@
+/*
+Package poll contains definition and implementation of a service that deals with Poll entities */
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Service specifies a method to service Poll entities.
+type Service interface {
+	GetPoll(id uint) (*Poll, error)
+	GetPostPolls(postID uint) ([]*Poll, error)
+	AddPoll(p *Poll) (*Poll, error)
+	DeletePoll(id uint) error
+	CastVote(v *Vote) (*Vote, error)
+	GetResults(id uint, viewer string) (*Results, error)
+}
+
+// Repository specifies a repo interface to serve the Poll Service interface
+type Repository interface {
+	GetPoll(id uint) (*Poll, error)
+	GetPostPolls(postID uint) ([]*Poll, error)
+	AddPoll(p *Poll) (*Poll, error)
+	DeletePoll(id uint) error
+	GetVote(pollID uint, username string) (*Vote, error)
+	AddVote(v *Vote) (*Vote, error)
+	GetTally(pollID uint) (*Results, error)
+}
+
+// MaxOptions is the most options a single poll can have
+const MaxOptions = 20
+
+// ErrPollNotFound is returned when the requested poll is not found
+var ErrPollNotFound = fmt.Errorf("poll not found")
+
+// ErrPostNotFound is returned when the post the poll is attached to is not found
+var ErrPostNotFound = fmt.Errorf("post not found")
+
+// ErrUserNotFound is returned when the the username specified isn't recognized
+var ErrUserNotFound = fmt.Errorf("user not found")
+
+// ErrInvalidPoll is returned when a poll is missing its question or doesn't have
+// between 2 and MaxOptions distinct options
+var ErrInvalidPoll = fmt.Errorf("invalid poll")
+
+// ErrInvalidCloseTime is returned when a poll is created with a close time that has already passed
+var ErrInvalidCloseTime = fmt.Errorf("poll close time has already passed")
+
+// ErrPollClosed is returned when a vote is cast on a poll after voting has closed
+var ErrPollClosed = fmt.Errorf("poll is closed")
+
+// ErrInvalidVote is returned when a vote picks options not found on the poll, repeats
+// an option or picks more than one option on a single choice poll
+var ErrInvalidVote = fmt.Errorf("invalid vote")
+
+// ErrAlreadyVoted is returned when a user votes on a poll more than once
+var ErrAlreadyVoted = fmt.Errorf("user has already voted on poll")
+
+// ErrVoteNotFound is returned when the requested vote is not found
+var ErrVoteNotFound = fmt.Errorf("vote not found")
+
+type service struct {
+	repo *Repository
+}
+
+// NewService returns a struct that implements the poll.Service interface
+func NewService(repo *Repository) Service {
+	return &service{repo: repo}
+}
+
+// GetPoll gets the Poll stored under the given id.
+func (s service) GetPoll(id uint) (*Poll, error) {
+	return (*s.repo).GetPoll(id)
+}
+
+// GetPostPolls gets the polls attached to the post under the given id.
+func (s service) GetPostPolls(postID uint) ([]*Poll, error) {
+	return (*s.repo).GetPostPolls(postID)
+}
+
+// AddPoll validates and persists the given poll. Option texts are trimmed and
+// their IDs are assigned by the repository.
+func (s service) AddPoll(p *Poll) (*Poll, error) {
+	p.Question = strings.TrimSpace(p.Question)
+	if p.Question == "" || len(p.Options) < 2 || len(p.Options) > MaxOptions {
+		return nil, ErrInvalidPoll
+	}
+	seen := make(map[string]bool, len(p.Options))
+	for _, o := range p.Options {
+		if o == nil {
+			return nil, ErrInvalidPoll
+		}
+		o.Text = strings.TrimSpace(o.Text)
+		if o.Text == "" || seen[o.Text] {
+			return nil, ErrInvalidPoll
+		}
+		seen[o.Text] = true
+	}
+	if p.ClosesAt != nil && !p.ClosesAt.After(time.Now()) {
+		return nil, ErrInvalidCloseTime
+	}
+	return (*s.repo).AddPoll(p)
+}
+
+// DeletePoll removes the poll under the given id along with its votes.
+func (s service) DeletePoll(id uint) error {
+	return (*s.repo).DeletePoll(id)
+}
+
+// CastVote records the given vote. A user can only vote once on a poll and only
+// while it's open.
+func (s service) CastVote(v *Vote) (*Vote, error) {
+	p, err := (*s.repo).GetPoll(v.PollID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Closed(time.Now()) {
+		return nil, ErrPollClosed
+	}
+	if len(v.OptionIDs) == 0 || (!p.MultipleChoice && len(v.OptionIDs) > 1) {
+		return nil, ErrInvalidVote
+	}
+	options := make(map[uint]bool, len(p.Options))
+	for _, o := range p.Options {
+		options[o.ID] = true
+	}
+	picked := make(map[uint]bool, len(v.OptionIDs))
+	for _, id := range v.OptionIDs {
+		if !options[id] || picked[id] {
+			return nil, ErrInvalidVote
+		}
+		picked[id] = true
+	}
+	return (*s.repo).AddVote(v)
+}
+
+// GetResults gets the tally of the votes cast on the poll under the given id. If the poll's
+// author chose to hide results, the tally is withheld from everyone but them until voting closes.
+func (s service) GetResults(id uint, viewer string) (*Results, error) {
+	p, err := (*s.repo).GetPoll(id)
+	if err != nil {
+		return nil, err
+	}
+	results, err := (*s.repo).GetTally(id)
+	if err != nil {
+		return nil, err
+	}
+	results.Closed = p.Closed(time.Now())
+	if p.HideResults && !results.Closed && (viewer == "" || viewer != p.CreatedBy) {
+		results.Hidden = true
+		results.TotalVoters = 0
+		for _, t := range results.Options {
+			t.Votes = 0
+		}
+	}
+	if viewer != "" {
+		v, err := (*s.repo).GetVote(id, viewer)
+		switch err {
+		case nil:
+			results.Voted = v.OptionIDs
+		case ErrVoteNotFound:
+		default:
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -1,23 +1,108 @@
 package post
 
-import "time"
+import (
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/diff"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/mention"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/unfurl"
+)
 
 // Post is an aggregate entity of Releases along with socially interactive
 // components such as stars, posting user and comments attached to the post
 type Post struct {
-	ID               uint            `json:"id"`
-	PostedByUsername string         `json:"postedByUsername,omitempty"`
-	OriginChannel    string         `json:"originChannel,omitempty"`
-	Title            string         `json:"title"`
-	Description      string         `json:"description"`
-	ContentsID       []uint          `json:"contentsID"`
-	Stars            map[string]uint `json:"stars"`
-	CommentsID       []int          `json:"commentsID"`
-	CreationTime     time.Time      `json:"creationTime"`
+	ID               uint               `json:"id"`
+	PostedByUsername string             `json:"postedByUsername,omitempty"`
+	OriginChannel    string             `json:"originChannel,omitempty"`
+	Title            string             `json:"title"`
+	Description      string             `json:"description"`
+	ContentsID       []uint             `json:"contentsID"`
+	PollsID          []uint             `json:"pollsID"`
+	Stars            StarSummary        `json:"stars"`
+	CommentsID       []int              `json:"commentsID"`
+	Views            uint               `json:"views"`
+	Previews         []*unfurl.Preview  `json:"previews,omitempty"`
+	Mentions         []*mention.Mention `json:"mentions,omitempty"`
+	Status           Status             `json:"status,omitempty"`
+	Rating           maturity.Rating    `json:"rating,omitempty"`
+	Warnings         []string           `json:"warnings,omitempty"`
+	CreationTime     time.Time          `json:"creationTime"`
+}
+
+// StarSummary is an aggregate of the stars given to a Post. Histogram[i]
+// holds the number of users that gave the post i+1 stars.
+type StarSummary struct {
+	Total     uint    `json:"total"`
+	Count     uint    `json:"count"`
+	Average   float64 `json:"average"`
+	Histogram [5]uint `json:"histogram"`
+	Own       uint    `json:"own,omitempty"`
 }
 
+// LeaderboardWindow holds enums used to describe the time window
+// the stars considered by a leaderboard are given in
+type LeaderboardWindow string
+
+// Leaderboard windows
+const (
+	WindowWeek    LeaderboardWindow = "week"
+	WindowMonth   LeaderboardWindow = "month"
+	WindowAllTime LeaderboardWindow = "all"
+)
+
 //Star is a key value pair of username and number of stars
 type Star struct {
 	Username   string `json:"username,omitempty"`
-	NumOfStars uint    `json:"stars,omitempty"`
+	NumOfStars uint   `json:"stars,omitempty"`
 }
+
+// Status holds enums used to mark at which stage of the editorial
+// pipeline a Post is at
+type Status string
+
+// Statuses a Post goes through from draft to publication
+const (
+	StatusDraft     Status = "draft"
+	StatusInReview  Status = "in-review"
+	StatusApproved  Status = "approved"
+	StatusPublished Status = "published"
+)
+
+// Revision is a snapshot of the title and description of a Post
+// taken every time either of them is updated
+type Revision struct {
+	ID           int       `json:"id"`
+	Editor       string    `json:"editor"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+// RevisionDiff holds the line by line differences between two Revisions of a Post
+type RevisionDiff struct {
+	From        int         `json:"from"`
+	To          int         `json:"to"`
+	Title       []diff.Line `json:"title"`
+	Description []diff.Line `json:"description"`
+}
+
+// Review is a remark left on a draft Post by one of the admins of
+// the channel it's to be published in
+type Review struct {
+	ID           int       `json:"id"`
+	Reviewer     string    `json:"reviewer"`
+	Content      string    `json:"content"`
+	Verdict      Verdict   `json:"verdict,omitempty"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+// Verdict holds enums used to mark the decision a Review carries if any
+type Verdict string
+
+// Verdicts a Review can carry, an empty verdict is just a remark
+const (
+	VerdictNone    Verdict = ""
+	VerdictApprove Verdict = "approve"
+	VerdictReject  Verdict = "reject"
+)
@@ -4,6 +4,10 @@ package post
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/diff"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 // Service specifies a method to service Release entities.
@@ -11,12 +15,24 @@ type Service interface {
 	GetPost(id uint) (*Post, error)
 	DeletePost(id uint) error
 	AddPost(p *Post) (*Post, error)
-	UpdatePost(pos *Post, id uint) (*Post, error)
-	SearchPost(pattern string, by SortBy, order SortOrder, limit int, offset int) ([]*Post, error)
+	UpdatePost(pos *Post, id uint, editor string, needsReview bool) (*Post, error)
+	SearchPost(pattern string, by SortBy, order SortOrder, limit int, offset int, filter maturity.Filter) ([]*Post, error)
 	GetPostStar(id uint, username string) (*Star, error)
+	GetPostStars(id uint, limit, offset int) ([]*Star, error)
+	GetPostStarSummary(id uint, username string) (*StarSummary, error)
+	GetTopRatedPosts(window LeaderboardWindow, limit, offset int, filter maturity.Filter) ([]*Post, error)
 	DeletePostStar(id uint, username string) error
 	AddPostStar(id uint, star *Star) (*Star, error)
 	UpdatePostStar(id uint, star *Star) (*Star, error)
+	SubmitDraft(id uint) (*Post, error)
+	ReviewDraft(id uint, review *Review) (*Review, error)
+	PublishPost(id uint) (*Post, error)
+	GetDraftReviews(id uint) ([]*Review, error)
+	GetChannelDrafts(channelUsername string, status Status, limit, offset int) ([]*Post, error)
+	GetRevisions(id uint) ([]*Revision, error)
+	GetRevision(id uint, revisionID int) (*Revision, error)
+	DiffRevisions(id uint, from, to int) (*RevisionDiff, error)
+	RestoreRevision(id uint, revisionID int, editor string, needsReview bool) (*Post, error)
 }
 
 // Repository specifies a repo interface to serve the Post Service interface
@@ -25,11 +41,20 @@ type Repository interface {
 	DeletePost(id uint) error
 	AddPost(p *Post) (*Post, error)
 	UpdatePost(pos *Post, id uint) (*Post, error)
-	SearchPost(pattern string, by SortBy, order SortOrder, limit int, offset int) ([]*Post, error)
+	SearchPost(pattern string, by SortBy, order SortOrder, limit int, offset int, filter maturity.Filter) ([]*Post, error)
 	GetPostStar(id uint, username string) (*Star, error)
+	GetPostStars(id uint, limit, offset int) ([]*Star, error)
+	GetTopRatedPosts(since time.Time, limit, offset int, filter maturity.Filter) ([]*Post, error)
 	DeletePostStar(id uint, username string) error
 	AddPostStar(id uint, star *Star) (*Star, error)
 	UpdatePostStar(id uint, star *Star) (*Star, error)
+	UpdatePostStatus(id uint, status Status) (*Post, error)
+	AddDraftReview(id uint, review *Review) (*Review, error)
+	GetDraftReviews(id uint) ([]*Review, error)
+	GetChannelDrafts(channelUsername string, status Status, limit, offset int) ([]*Post, error)
+	AddRevision(id uint, revision *Revision) (*Revision, error)
+	GetRevisions(id uint) ([]*Revision, error)
+	GetRevision(id uint, revisionID int) (*Revision, error)
 }
 
 // SortOrder holds enums used by SearchPost methods the order of Users are sorted with
@@ -64,6 +89,24 @@ var ErrStarNotFound = fmt.Errorf("Star not found")
 //ErrSomePostDataNotPersisted is returned when data aren't properly added to post database
 var ErrSomePostDataNotPersisted = fmt.Errorf("Data not properly added")
 
+//ErrInvalidStatus is returned when a post is given a status it can't be created with
+var ErrInvalidStatus = fmt.Errorf("invalid post status")
+
+//ErrInvalidStatusTransition is returned when a post can't be moved to the requested stage from its current one
+var ErrInvalidStatusTransition = fmt.Errorf("post can't be moved to the requested status")
+
+//ErrInvalidLeaderboardWindow is returned when the requested leaderboard window isn't recognized
+var ErrInvalidLeaderboardWindow = fmt.Errorf("invalid leaderboard window")
+
+//ErrRevisionNotFound is returned when the requested revision of a post is not found
+var ErrRevisionNotFound = fmt.Errorf("revision not found")
+
+//ErrInvalidRating is returned when a post is given a maturity rating that isn't recognized
+var ErrInvalidRating = fmt.Errorf("invalid maturity rating")
+
+//ErrPostNotDraft is returned when a draft only action is attempted on a published post
+var ErrPostNotDraft = fmt.Errorf("post is not a draft")
+
 type service struct {
 	repo *Repository
 }
@@ -94,26 +137,206 @@ func (s service) DeletePost(id uint) error {
 }
 
 // AddPost Adds the Post stored under the given id.
+// Posts are published right away unless they're marked as drafts.
 func (s service) AddPost(p *Post) (*Post, error) {
-
+	switch p.Status {
+	case "":
+		p.Status = StatusPublished
+	case StatusDraft, StatusPublished:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	if p.Rating == "" {
+		p.Rating = maturity.General
+	}
+	if !p.Rating.Valid() {
+		return nil, ErrInvalidRating
+	}
+	p.Warnings = maturity.NormalizeWarnings(p.Warnings)
 	return (*s.repo).AddPost(p)
 }
 
-//UpdatePost updates the post with given id and post struct
-func (s service) UpdatePost(pos *Post, id uint) (*Post, error) {
-	return (*s.repo).UpdatePost(pos, id)
+//UpdatePost updates the post with given id and post struct.
+// A revision attributed to editor is kept if the title or description change.
+// Drafts in review or approved are sent back to be drafted if their contents change
+// so that no text gets published without the admins seeing it. Published posts are sent
+// back the same way when their contents are changed by edits that need review.
+func (s service) UpdatePost(pos *Post, id uint, editor string, needsReview bool) (*Post, error) {
+	old, err := s.GetPost(id)
+	if err != nil {
+		return nil, err
+	}
+	if !pos.Rating.Valid() {
+		return nil, ErrInvalidRating
+	}
+	if pos.Warnings != nil {
+		pos.Warnings = maturity.NormalizeWarnings(pos.Warnings)
+	}
+	p, err := (*s.repo).UpdatePost(pos, id)
+	if p == nil {
+		return nil, err
+	}
+	if p.Title != old.Title || p.Description != old.Description {
+		if errs := s.addRevision(old, p, editor); errs != nil {
+			return nil, errs
+		}
+	}
+	reviewed := old.Status == StatusInReview || old.Status == StatusApproved ||
+		(old.Status == StatusPublished && needsReview)
+	if reviewed && contentsChanged(old, p) {
+		reverted, errs := (*s.repo).UpdatePostStatus(id, StatusDraft)
+		if errs != nil {
+			return nil, errs
+		}
+		p = reverted
+	}
+	return p, err
+}
+
+// contentsChanged is just a helper function that checks if any of what the admins
+// review a draft for differs between the two posts.
+func contentsChanged(old, updated *Post) bool {
+	if old.Title != updated.Title || old.Description != updated.Description ||
+		old.Rating != updated.Rating ||
+		len(old.ContentsID) != len(updated.ContentsID) || len(old.Warnings) != len(updated.Warnings) {
+		return true
+	}
+	for i := range old.ContentsID {
+		if old.ContentsID[i] != updated.ContentsID[i] {
+			return true
+		}
+	}
+	for i := range old.Warnings {
+		if old.Warnings[i] != updated.Warnings[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// addRevision is just a helper function that keeps a revision of the updated post.
+// The state the post was created with is kept first if it has no revisions yet.
+func (s service) addRevision(old, updated *Post, editor string) error {
+	revisions, err := (*s.repo).GetRevisions(old.ID)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		_, err = (*s.repo).AddRevision(old.ID, &Revision{
+			Editor:       old.PostedByUsername,
+			Title:        old.Title,
+			Description:  old.Description,
+			CreationTime: old.CreationTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = (*s.repo).AddRevision(old.ID, &Revision{
+		Editor:      editor,
+		Title:       updated.Title,
+		Description: updated.Description,
+	})
+	return err
+}
+
+// GetRevisions returns the revisions of the post under the given id, oldest first.
+func (s service) GetRevisions(id uint) ([]*Revision, error) {
+	if _, err := s.GetPost(id); err != nil {
+		return nil, err
+	}
+	return (*s.repo).GetRevisions(id)
+}
+
+// GetRevision returns the revision of the post under the given ids.
+func (s service) GetRevision(id uint, revisionID int) (*Revision, error) {
+	return (*s.repo).GetRevision(id, revisionID)
+}
+
+// DiffRevisions returns the differences between the revisions from and to of the post.
+func (s service) DiffRevisions(id uint, from, to int) (*RevisionDiff, error) {
+	a, err := (*s.repo).GetRevision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := (*s.repo).GetRevision(id, to)
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionDiff{
+		From:        from,
+		To:          to,
+		Title:       diff.Lines(a.Title, b.Title),
+		Description: diff.Lines(a.Description, b.Description),
+	}, nil
+}
+
+// RestoreRevision sets the title and description of the post back to the ones of
+// the given revision. The restoration is itself kept as a new revision.
+func (s service) RestoreRevision(id uint, revisionID int, editor string, needsReview bool) (*Post, error) {
+	r, err := (*s.repo).GetRevision(id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.UpdatePost(&Post{Title: r.Title, Description: r.Description}, id, editor, needsReview)
 }
 
-func (s service) SearchPost(pattern string, by SortBy, order SortOrder, limit int, offset int) ([]*Post, error) {
+// SearchPost returns published posts of public channels matching the given pattern
+// leaving out the ones that don't pass the given filter.
+func (s service) SearchPost(pattern string, by SortBy, order SortOrder, limit int, offset int, filter maturity.Filter) ([]*Post, error) {
 	if limit < 0 || offset < 0 {
 		return nil, fmt.Errorf("invalid pagination")
 	}
-	return (*s.repo).SearchPost(pattern, by, order, limit, offset)
+	return (*s.repo).SearchPost(pattern, by, order, limit, offset, filter)
 
 }
 func (s service) GetPostStar(id uint, username string) (*Star, error) {
 	return (*s.repo).GetPostStar(id, username)
 }
+
+// GetPostStars returns a page of the stars given to the post under the given id,
+// most recent first.
+func (s service) GetPostStars(id uint, limit, offset int) ([]*Star, error) {
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	return (*s.repo).GetPostStars(id, limit, offset)
+}
+
+// GetPostStarSummary returns the aggregate of the stars given to the post under the
+// given id along with the stars given by the user of the passed in username if any.
+func (s service) GetPostStarSummary(id uint, username string) (*StarSummary, error) {
+	p, err := s.GetPost(id)
+	if err != nil {
+		return nil, err
+	}
+	summary := p.Stars
+	if username != "" {
+		if st, err := (*s.repo).GetPostStar(id, username); err == nil {
+			summary.Own = st.NumOfStars
+		}
+	}
+	return &summary, nil
+}
+
+// GetTopRatedPosts returns published posts ranked by the stars they received within
+// the given window leaving out the ones that don't pass the given filter.
+func (s service) GetTopRatedPosts(window LeaderboardWindow, limit, offset int, filter maturity.Filter) ([]*Post, error) {
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	var since time.Time
+	switch window {
+	case WindowWeek:
+		since = time.Now().AddDate(0, 0, -7)
+	case WindowMonth:
+		since = time.Now().AddDate(0, -1, 0)
+	case "", WindowAllTime:
+	default:
+		return nil, ErrInvalidLeaderboardWindow
+	}
+	return (*s.repo).GetTopRatedPosts(since, limit, offset, filter)
+}
 func (s service) DeletePostStar(id uint, username string) error {
 
 	return (*s.repo).DeletePostStar(id, username)
@@ -124,3 +347,90 @@ func (s service) AddPostStar(id uint, star *Star) (*Star, error) {
 func (s service) UpdatePostStar(id uint, star *Star) (*Star, error) {
 	return (*s.repo).UpdatePostStar(id, star)
 }
+
+// SubmitDraft moves a draft to the in-review stage so that the admins of
+// its channel can review it.
+func (s service) SubmitDraft(id uint) (*Post, error) {
+	p, err := s.GetPost(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != StatusDraft {
+		return nil, ErrInvalidStatusTransition
+	}
+	return (*s.repo).UpdatePostStatus(id, StatusInReview)
+}
+
+// ReviewDraft attaches the given review to a draft. Reviews that carry a verdict
+// approve or send back to draft stage posts that are in review.
+func (s service) ReviewDraft(id uint, review *Review) (*Review, error) {
+	p, err := s.GetPost(id)
+	if err != nil {
+		return nil, err
+	}
+	switch p.Status {
+	case StatusDraft, StatusInReview, StatusApproved:
+	default:
+		return nil, ErrPostNotDraft
+	}
+	var status Status
+	switch review.Verdict {
+	case VerdictNone:
+	case VerdictApprove:
+		if p.Status != StatusInReview {
+			return nil, ErrInvalidStatusTransition
+		}
+		status = StatusApproved
+	case VerdictReject:
+		if p.Status != StatusInReview && p.Status != StatusApproved {
+			return nil, ErrInvalidStatusTransition
+		}
+		status = StatusDraft
+	default:
+		return nil, ErrInvalidStatusTransition
+	}
+	r, err := (*s.repo).AddDraftReview(id, review)
+	if err != nil {
+		return nil, err
+	}
+	if status != "" {
+		if _, err = (*s.repo).UpdatePostStatus(id, status); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// PublishPost publishes an approved draft making it visible on its channel and feeds.
+func (s service) PublishPost(id uint) (*Post, error) {
+	p, err := s.GetPost(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != StatusApproved {
+		return nil, ErrInvalidStatusTransition
+	}
+	return (*s.repo).UpdatePostStatus(id, StatusPublished)
+}
+
+// GetDraftReviews returns the reviews left on the draft under the given id.
+func (s service) GetDraftReviews(id uint) ([]*Review, error) {
+	if _, err := s.GetPost(id); err != nil {
+		return nil, err
+	}
+	return (*s.repo).GetDraftReviews(id)
+}
+
+// GetChannelDrafts returns the unpublished posts of the given channel.
+// If status is empty, drafts from all stages are returned.
+func (s service) GetChannelDrafts(channelUsername string, status Status, limit, offset int) ([]*Post, error) {
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	switch status {
+	case "", StatusDraft, StatusInReview, StatusApproved:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	return (*s.repo).GetChannelDrafts(channelUsername, status, limit, offset)
+}
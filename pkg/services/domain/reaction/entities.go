@@ -0,0 +1,31 @@
+package reaction
+
+import "time"
+
+// Reaction represents an emoji reaction a user left on a post,
+// a comment or a release.
+type Reaction struct {
+	TargetType   TargetType `json:"targetType"`
+	TargetID     int        `json:"targetID"`
+	Username     string     `json:"username"`
+	Emoji        string     `json:"emoji"`
+	CreationTime time.Time  `json:"creationTime,omitempty"`
+}
+
+// TargetType holds enums used to describe what kind of entity a Reaction is left on.
+type TargetType string
+
+// Entities reactions can be left on
+const (
+	TargetPost    TargetType = "post"
+	TargetComment TargetType = "comment"
+	TargetRelease TargetType = "release"
+)
+
+// Count is the number of reactions of a single emoji left on a target.
+// Reacted reports whether the viewing user is one of the reactors.
+type Count struct {
+	Emoji   string `json:"emoji"`
+	Count   uint   `json:"count"`
+	Reacted bool   `json:"reacted,omitempty"`
+}
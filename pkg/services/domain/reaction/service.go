@@ -0,0 +1,113 @@
+/*
+Package reaction contains definition and implementation of a service that deals with Reaction entities */
+package reaction
+
+import "fmt"
+
+// Service specifies a method to service Reaction entities.
+type Service interface {
+	GetEmojis() []string
+	ToggleReaction(r *Reaction) (bool, error)
+	GetCounts(targetType TargetType, targetID int, viewer string) ([]*Count, error)
+	GetReactors(targetType TargetType, targetID int, emoji string, limit, offset int) ([]*Reaction, error)
+}
+
+// Repository specifies a repo interface to serve the Reaction Service interface
+type Repository interface {
+	GetReaction(targetType TargetType, targetID int, username, emoji string) (*Reaction, error)
+	AddReaction(r *Reaction) (*Reaction, error)
+	DeleteReaction(targetType TargetType, targetID int, username, emoji string) error
+	GetCounts(targetType TargetType, targetID int, viewer string) ([]*Count, error)
+	GetReactors(targetType TargetType, targetID int, emoji string, limit, offset int) ([]*Reaction, error)
+}
+
+// DefaultEmojis is the set of emojis users can react with unless configured otherwise
+var DefaultEmojis = []string{"👍", "❤️", "😂", "😮", "😢", "🔥"}
+
+// ErrTargetNotFound is returned when the entity being reacted on doesn't exist
+var ErrTargetNotFound = fmt.Errorf("reaction target not found")
+
+// ErrInvalidTargetType is returned when the reactions are requested on an unrecognized kind of entity
+var ErrInvalidTargetType = fmt.Errorf("invalid reaction target type")
+
+// ErrInvalidEmoji is returned when the emoji isn't one of the configured set
+var ErrInvalidEmoji = fmt.Errorf("emoji not accepted")
+
+// ErrUserNotFound is returned when the the username specified isn't recognized
+var ErrUserNotFound = fmt.Errorf("user not found")
+
+// ErrReactionNotFound is returned when the requested reaction is not found
+var ErrReactionNotFound = fmt.Errorf("reaction not found")
+
+type service struct {
+	repo   *Repository
+	emojis []string
+}
+
+// NewService returns a struct that implements the reaction.Service interface.
+// Users will only be able to react with the passed in emojis.
+func NewService(repo *Repository, emojis []string) Service {
+	return &service{repo: repo, emojis: emojis}
+}
+
+// GetEmojis returns the set of emojis users can react with.
+func (s service) GetEmojis() []string {
+	return s.emojis
+}
+
+// ToggleReaction adds the given reaction if the user hasn't reacted with the emoji
+// on the target yet or removes it otherwise. It reports whether the reaction is present
+// after the toggle.
+func (s service) ToggleReaction(r *Reaction) (bool, error) {
+	if err := s.validate(r.TargetType, r.Emoji); err != nil {
+		return false, err
+	}
+	_, err := (*s.repo).GetReaction(r.TargetType, r.TargetID, r.Username, r.Emoji)
+	switch err {
+	case nil:
+		return false, (*s.repo).DeleteReaction(r.TargetType, r.TargetID, r.Username, r.Emoji)
+	case ErrReactionNotFound:
+		_, err = (*s.repo).AddReaction(r)
+		return err == nil, err
+	default:
+		return false, err
+	}
+}
+
+// GetCounts returns the number of reactions per emoji left on the target.
+func (s service) GetCounts(targetType TargetType, targetID int, viewer string) ([]*Count, error) {
+	if err := s.validate(targetType, ""); err != nil {
+		return nil, err
+	}
+	return (*s.repo).GetCounts(targetType, targetID, viewer)
+}
+
+// GetReactors returns the reactions left on the target with the given emoji, or with any
+// emoji if none is given, most recent first.
+func (s service) GetReactors(targetType TargetType, targetID int, emoji string, limit, offset int) ([]*Reaction, error) {
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	if err := s.validate(targetType, emoji); err != nil {
+		return nil, err
+	}
+	return (*s.repo).GetReactors(targetType, targetID, emoji, limit, offset)
+}
+
+// validate is just a helper function that checks the target type and, if given, the emoji
+func (s service) validate(targetType TargetType, emoji string) error {
+	switch targetType {
+	case TargetPost, TargetComment, TargetRelease:
+	default:
+		return ErrInvalidTargetType
+	}
+	if emoji == "" {
+		return nil
+	}
+	for _, e := range s.emojis {
+		if e == emoji {
+			return nil
+		}
+	}
+	return ErrInvalidEmoji
+}
@@ -1,8 +1,14 @@
 package release
 
-import "time"
+import (
+	"time"
 
-// Type signifies the content type of the release. Either Image or Text.
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/diff"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/imaging"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
+)
+
+// Type signifies the content type of the release. Either Image, Comic, Text or Audio.
 type Type string
 
 const (
@@ -10,34 +16,161 @@ const (
 	Image Type = "image"
 	// Text type releases include web-series, essays, blogs, anecdote...etc
 	Text Type = "text"
+	// Comic type releases are made up of ordered pages of images like webcomic chapters
+	Comic Type = "comic"
+	// Audio type releases include podcasts, audio dramas, music...etc
+	Audio Type = "audio"
 )
 
 // Release represents an atomic work of creativity.
+// Comic releases keep their images in Pages with the first page
+// doubling as the Content. Variants lists the scaled copies of the
+// image of Image releases. The Content of Text releases is written
+// in their Format and HTML holds it rendered for display. Audio releases
+// describe the file that's their Content in Track. Releases translated
+// from another link to it in TranslationOf while Languages lists the
+// releases of the same work in other languages when served.
 type Release struct {
-	ID           int    `json:"id"`
-	OwnerChannel string `json:"ownerChannel"`
-	Type         Type   `json:"type"`
-	Content      string `json:"content"`
-	Metadata     `json:"metadata,omitempty"`
-	CreationTime time.Time `json:"creationTime,omitempty"`
+	ID            int                `json:"id"`
+	OwnerChannel  string             `json:"ownerChannel"`
+	Type          Type               `json:"type"`
+	Content       string             `json:"content"`
+	Format        Format             `json:"format,omitempty"`
+	HTML          string             `json:"html,omitempty"`
+	Pages         []*Page            `json:"pages,omitempty"`
+	Variants      []*imaging.Variant `json:"variants,omitempty"`
+	Track         *Track             `json:"track,omitempty"`
+	TranslationOf *Translation       `json:"translationOf,omitempty"`
+	Languages     []*Variant         `json:"languages,omitempty"`
+	Metadata      `json:"metadata,omitempty"`
+	Views         uint      `json:"views"`
+	CreationTime  time.Time `json:"creationTime,omitempty"`
+}
+
+// Format signifies the markup the content of a Text release is written in.
+type Format string
+
+const (
+	// FormatHTML is the default format of Text releases
+	FormatHTML Format = "html"
+	// FormatMarkdown releases are written in CommonMark flavored Markdown
+	FormatMarkdown Format = "markdown"
+)
+
+// Valid checks whether the format is recognized
+func (f Format) Valid() bool {
+	return f == FormatHTML || f == FormatMarkdown
+}
+
+// Track describes the file of an Audio release. Duration is in seconds and Size in bytes.
+type Track struct {
+	ContentType string  `json:"contentType"`
+	Size        int64   `json:"size"`
+	Duration    float64 `json:"duration"`
+}
+
+// Page is a single image of a Comic release. Pages are numbered from 1.
+type Page struct {
+	Number   int                `json:"number"`
+	Image    string             `json:"image"`
+	AltText  string             `json:"altText,omitempty"`
+	Variants []*imaging.Variant `json:"variants,omitempty"`
 }
 
 // Metadata is a value object holds all the metadata of releases.
 // genreDefining is the genre classification that defines the release most.
-// authors contains username in string form if author is an issue#1 user
-// or plain names otherwise.
+// credits attribute the work that went into the release, in order.
+// language is the BCP 47 tag of the language the release is in, like en or pt-BR.
 // description is for data like blurb.
+// rating and warnings flag mature content, see the maturity package.
 type Metadata struct {
-	Title         string    `json:"title,omitempty"`
-	ReleaseDate   time.Time `json:"releaseDate,omitempty"`
-	GenreDefining string    `json:"genreDefining,omitempty"`
-	Description   string    `json:"description,omitempty"`
+	Title         string          `json:"title,omitempty"`
+	ReleaseDate   time.Time       `json:"releaseDate,omitempty"`
+	GenreDefining string          `json:"genreDefining,omitempty"`
+	Description   string          `json:"description,omitempty"`
+	Rating        maturity.Rating `json:"rating,omitempty"`
+	Warnings      []string        `json:"warnings,omitempty"`
+	Language      string          `json:"language,omitempty"`
+	Credits       []*Credit       `json:"credits,omitempty"`
 	Other         `json:"other,omitempty"`
 	//Cover         string   `json:"cover"`
 }
 
 // Other is a struct used to contain metadata not necessarily present in all releases
+// Releases made from the chapters of a book hold its title and their position in it.
 type Other struct {
-	Authors []string `json:"authors,omitempty"`
 	Genres  []string `json:"genres,omitempty"`
+	Book    string   `json:"book,omitempty"`
+	Chapter int      `json:"chapter,omitempty"`
+}
+
+// Role signifies the part a contributor played in the making of a release.
+type Role string
+
+const (
+	// RoleWriter is credited for the story or the text
+	RoleWriter Role = "writer"
+	// RoleArtist is credited for the art, line work included
+	RoleArtist Role = "artist"
+	// RoleColorist is credited for coloring the art
+	RoleColorist Role = "colorist"
+	// RoleTranslator is credited for translating the release from its original language
+	RoleTranslator Role = "translator"
+	// RoleEditor is credited for editing the release
+	RoleEditor Role = "editor"
+)
+
+// Valid checks whether the role is one of the recognized ones
+func (r Role) Valid() bool {
+	switch r {
+	case RoleWriter, RoleArtist, RoleColorist, RoleTranslator, RoleEditor:
+		return true
+	}
+	return false
+}
+
+// Credit attributes a role in the making of a release either to an issue#1
+// user, by their Username, or to someone off the platform by Name. Only one
+// of the two is set.
+type Credit struct {
+	Role     Role   `json:"role"`
+	Username string `json:"username,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// Translation links a release to the release it's a translation of. Permitted is
+// set once an admin of the channel of the original permits the translation.
+type Translation struct {
+	OriginalID int  `json:"originalID"`
+	Permitted  bool `json:"permitted"`
+}
+
+// Variant is a release of a work in one of the languages it's available in.
+// Original marks the release the rest are translated from.
+type Variant struct {
+	ReleaseID int    `json:"releaseID"`
+	Language  string `json:"language"`
+	Original  bool   `json:"original,omitempty"`
+}
+
+// Work is a release along the roles a user is credited with in it.
+type Work struct {
+	ReleaseID int    `json:"releaseID"`
+	Roles     []Role `json:"roles"`
+}
+
+// Revision is a snapshot of the content of a text Release taken
+// every time it's updated
+type Revision struct {
+	ID           int       `json:"id"`
+	Editor       string    `json:"editor,omitempty"`
+	Content      string    `json:"content"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+// RevisionDiff holds the line by line differences between two Revisions of a Release
+type RevisionDiff struct {
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Content []diff.Line `json:"content"`
 }
@@ -4,24 +4,55 @@ package release
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/diff"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/tag"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/domain/user"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 // Service specifies a method to service Release entities.
 type Service interface {
 	GetRelease(id int) (*Release, error)
-	SearchRelease(pattern string, by SortBy, order SortOrder, limit int, offset int) ([]*Release, error)
+	SearchRelease(pattern string, by SortBy, order SortOrder, limit int, offset int, filter maturity.Filter) ([]*Release, error)
 	DeleteRelease(id int) error
 	AddRelease(r *Release) (*Release, error)
-	UpdateRelease(rel *Release) (*Release, error)
+	UpdateRelease(rel *Release, editor string) (*Release, error)
+	GetRevisions(id int) ([]*Revision, error)
+	GetRevision(id int, revisionID int) (*Revision, error)
+	DiffRevisions(id int, from, to int) (*RevisionDiff, error)
+	RestoreRevision(id int, revisionID int, editor string) (*Release, error)
+	AddPages(id int, pages []*Page) (*Release, error)
+	UpdatePage(id int, page *Page) (*Release, error)
+	ReorderPages(id int, numbers []int) (*Release, error)
+	DeletePage(id int, number int) (*Release, error)
+	GetCreditsOf(username string) ([]*Work, error)
+	GetLanguages(id int) ([]*Variant, error)
+	SetTranslationPermission(id int, permitted bool) (*Release, error)
+	RemoveTranslation(id int) (*Release, error)
+	ReplaceGenres(from []string, to string) error
 }
 
 // Repository specifies a repo interface to serve the release Service interface
 type Repository interface {
 	GetRelease(id int) (*Release, error)
-	SearchRelease(pattern string, by SortBy, order SortOrder, limit int, offset int) ([]*Release, error)
+	SearchRelease(pattern string, by SortBy, order SortOrder, limit int, offset int, filter maturity.Filter) ([]*Release, error)
 	DeleteRelease(id int) error
 	AddRelease(r *Release) (*Release, error)
 	UpdateRelease(rel *Release) (*Release, error)
+	AddRevision(id int, revision *Revision) (*Revision, error)
+	GetRevisions(id int) ([]*Revision, error)
+	GetRevision(id int, revisionID int) (*Revision, error)
+	AddPages(id int, pages []*Page) error
+	UpdatePage(id int, page *Page) error
+	ReorderPages(id int, numbers []int) error
+	DeletePage(id int, number int) error
+	GetCreditsOf(username string) ([]*Work, error)
+	GetTranslations(originalID int) ([]*Variant, error)
+	SetTranslationPermission(id int, permitted bool) error
+	DeleteTranslation(id int) error
+	ReplaceGenres(from []string, to string) ([]int, error)
 }
 
 // SortOrder holds enums used by SearchRelease methods the order of Users are sorted with
@@ -52,20 +83,132 @@ var ErrSomeReleaseDataNotPersisted = fmt.Errorf("was unable to persist some rele
 // ErrAttemptToChangeReleaseType is returned when the requested passed release has invalid dat
 var ErrAttemptToChangeReleaseType = fmt.Errorf("attempt to change release type")
 
+// ErrRevisionNotFound is returned when the requested revision of a release is not found
+var ErrRevisionNotFound = fmt.Errorf("revision not found")
+
+// ErrInvalidRating is returned when a release is given a maturity rating that isn't recognized
+var ErrInvalidRating = fmt.Errorf("invalid maturity rating")
+
+// ErrPageNotFound is returned when the requested page of a comic release is not found
+var ErrPageNotFound = fmt.Errorf("page not found")
+
+// ErrNotComic is returned when pages are requested on releases that aren't comics
+var ErrNotComic = fmt.Errorf("release isn't a comic")
+
+// ErrTooManyPages is returned when a comic release would exceed MaxPages
+var ErrTooManyPages = fmt.Errorf("too many pages")
+
+// ErrInvalidPageOrder is returned when a new order doesn't list every page of the release exactly once
+var ErrInvalidPageOrder = fmt.Errorf("invalid page order")
+
+// ErrInvalidFormat is returned when a text release is given a format that isn't recognized
+// or a format is given to releases that aren't text
+var ErrInvalidFormat = fmt.Errorf("invalid release format")
+
+// ErrInvalidCredit is returned when a credit has a role that isn't recognized or
+// doesn't name exactly one of a user or someone off the platform
+var ErrInvalidCredit = fmt.Errorf("invalid credit")
+
+// ErrCreditedUserNotFound is returned when a credit names a user that doesn't exist
+var ErrCreditedUserNotFound = fmt.Errorf("credited user not found")
+
+// ErrInvalidLanguage is returned when a release is given a language tag that isn't well formed
+// or a translation is in the same language as the original, or in none
+var ErrInvalidLanguage = fmt.Errorf("invalid language")
+
+// ErrInvalidTranslation is returned when a release is linked as a translation of a release
+// that doesn't exist, of itself or while it has translations of its own
+var ErrInvalidTranslation = fmt.Errorf("invalid translation")
+
+// ErrTranslatorNotCredited is returned when a translation doesn't credit a translator
+var ErrTranslatorNotCredited = fmt.Errorf("translator not credited")
+
+// ErrNotTranslation is returned when translation permissions are given to releases that aren't translations
+var ErrNotTranslation = fmt.Errorf("release isn't a translation")
+
+// ErrInvalidGenre is returned when a genre of a release is too long to be a tag
+var ErrInvalidGenre = fmt.Errorf("invalid genre")
+
+// MaxPages is the number of pages a comic release can have
+const MaxPages = 300
+
+// MaxCredits is the number of credits a release can have
+const MaxCredits = 64
+
 type service struct {
-	repo *Repository
+	repo        *Repository
+	allServices *map[string]interface{}
 }
 
 // NewService returns a struct that implements the release.Service interface
-func NewService(repo *Repository) Service {
-	return &service{repo: repo}
+func NewService(repo *Repository, allServices *map[string]interface{}) Service {
+	return &service{repo: repo, allServices: allServices}
 }
 
 // AddRelease adds an new release based on the passed in struct
 func (s service) AddRelease(r *Release) (*Release, error) {
+	if r.Type == Comic {
+		if len(r.Pages) == 0 {
+			return nil, ErrInvalidReleaseData
+		}
+		if len(r.Pages) > MaxPages {
+			return nil, ErrTooManyPages
+		}
+		for i, p := range r.Pages {
+			if p.Image == "" {
+				return nil, ErrInvalidReleaseData
+			}
+			p.Number = i + 1
+		}
+		r.Content = r.Pages[0].Image
+	} else {
+		r.Pages = nil
+	}
+	if r.Type == Text {
+		if r.Format == "" {
+			r.Format = FormatHTML
+		}
+		if !r.Format.Valid() {
+			return nil, ErrInvalidFormat
+		}
+	} else if r.Format != "" {
+		return nil, ErrInvalidFormat
+	}
+	if r.Type == Audio {
+		if r.Track == nil || r.Track.ContentType == "" {
+			return nil, ErrInvalidReleaseData
+		}
+	} else {
+		r.Track = nil
+	}
 	if r.Content == "" || r.OwnerChannel == "" {
 		return nil, ErrInvalidReleaseData
 	}
+	if !r.Rating.Valid() {
+		return nil, ErrInvalidRating
+	}
+	r.Warnings = maturity.NormalizeWarnings(r.Warnings)
+	credits, err := s.checkCredits(r.Credits)
+	if err != nil {
+		return nil, err
+	}
+	r.Credits = credits
+	if r.Language != "" {
+		language, ok := NormalizeLanguage(r.Language)
+		if !ok {
+			return nil, ErrInvalidLanguage
+		}
+		r.Language = language
+	}
+	if r.TranslationOf != nil {
+		if err := s.checkTranslation(r, r.Language, r.Credits); err != nil {
+			return nil, err
+		}
+	}
+	// genres are filed last as unknown ones become tags
+	if err := s.checkGenres(r); err != nil {
+		return nil, err
+	}
 	return (*s.repo).AddRelease(r)
 }
 
@@ -77,12 +220,12 @@ func (s service) GetRelease(id int) (*Release, error) {
 // SearchRelease returns a list of official releases that match against the pattern.
 // Note: this won't return releases that aren't in a channel's official catalog.
 // If pattern is empty, it returns all releases.
-// Sorting and pagination can be specified.
-func (s service) SearchRelease(pattern string, by SortBy, order SortOrder, limit int, offset int) ([]*Release, error) {
+// Sorting and pagination can be specified. Releases that don't pass the filter are left out.
+func (s service) SearchRelease(pattern string, by SortBy, order SortOrder, limit int, offset int, filter maturity.Filter) ([]*Release, error) {
 	if limit < 0 || offset < 0 {
 		return nil, fmt.Errorf("invalid pagination")
 	}
-	return (*s.repo).SearchRelease(pattern, by, order, limit, offset)
+	return (*s.repo).SearchRelease(pattern, by, order, limit, offset, filter)
 }
 
 // DeleteRelease removes the release stored under the given id.
@@ -94,21 +237,436 @@ func (s service) DeleteRelease(id int) error {
 }
 
 // UpdateRelease updates the release stored under the given id
-// based on the passed in struct.
-func (s service) UpdateRelease(r *Release) (*Release, error) {
-	if rel, err := s.GetRelease(r.ID); err != nil {
+// based on the passed in struct. A revision attributed to editor is
+// kept if the content of a text release changes.
+func (s service) UpdateRelease(r *Release, editor string) (*Release, error) {
+	rel, err := s.GetRelease(r.ID)
+	if err != nil {
 		return nil, err
-	} else {
-		if r.Type != "" && r.Type != rel.Type {
-			return nil, ErrAttemptToChangeReleaseType
+	}
+	if r.Type != "" && r.Type != rel.Type {
+		return nil, ErrAttemptToChangeReleaseType
+	}
+	if rel.Type == Comic {
+		// the pages of comics are managed on their own
+		r.Content = ""
+		r.Pages = nil
+	}
+	if r.Format != "" && (rel.Type != Text || !r.Format.Valid()) {
+		return nil, ErrInvalidFormat
+	}
+	if rel.Type != Audio || r.Content == "" || r.Track == nil {
+		// the track only changes along the file of audio releases
+		r.Track = nil
+		if rel.Type == Audio {
+			r.Content = ""
+		}
+	}
+	if !r.Rating.Valid() {
+		return nil, ErrInvalidRating
+	}
+	if r.Warnings != nil {
+		r.Warnings = maturity.NormalizeWarnings(r.Warnings)
+	}
+	if r.Credits != nil {
+		// credits are replaced as a whole so that they can be taken back
+		r.Credits, err = s.checkCredits(r.Credits)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.Genres = mergeStringSlicesRemovingDuplicates(r.Genres, rel.Genres)
+	language := rel.Language
+	if r.Language != "" {
+		var ok bool
+		if r.Language, ok = NormalizeLanguage(r.Language); !ok {
+			return nil, ErrInvalidLanguage
+		}
+		language = r.Language
+	}
+	if r.TranslationOf != nil {
+		credits := r.Credits
+		if credits == nil {
+			credits = rel.Credits
+		}
+		if err := s.checkTranslation(r, language, credits); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkGenres(r); err != nil {
+		return nil, err
+	}
+	if r.Book == "" {
+		r.Book = rel.Book
+	}
+	if r.Chapter == 0 {
+		r.Chapter = rel.Chapter
+	}
+	if r.OwnerChannel == rel.OwnerChannel {
+		r.OwnerChannel = ""
+	}
+	updated, err := (*s.repo).UpdateRelease(r)
+	if updated == nil {
+		return nil, err
+	}
+	if updated.Type == Text && updated.Content != rel.Content {
+		if errs := s.addRevision(rel, updated, editor); errs != nil {
+			return nil, errs
+		}
+	}
+	return updated, err
+}
+
+// GetCreditsOf returns the releases the user of the given username is credited in,
+// along with their roles in them
+func (s service) GetCreditsOf(username string) ([]*Work, error) {
+	return (*s.repo).GetCreditsOf(username)
+}
+
+// GetLanguages returns the languages the work of the release under the given id is available
+// in, the original release first followed by its translations, itself included
+func (s service) GetLanguages(id int) ([]*Variant, error) {
+	rel, err := s.GetRelease(id)
+	if err != nil {
+		return nil, err
+	}
+	original := rel
+	if rel.TranslationOf != nil {
+		original, err = s.GetRelease(rel.TranslationOf.OriginalID)
+		if err == ErrReleaseNotFound {
+			// the original was removed since
+			return []*Variant{{ReleaseID: rel.ID, Language: rel.Language}}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	translations, err := (*s.repo).GetTranslations(original.ID)
+	if err != nil {
+		return nil, err
+	}
+	variants := []*Variant{{ReleaseID: original.ID, Language: original.Language, Original: true}}
+	return append(variants, translations...), nil
+}
+
+// SetTranslationPermission marks the translation under the given id as permitted, or not,
+// by the channel of the release it's translated from
+func (s service) SetTranslationPermission(id int, permitted bool) (*Release, error) {
+	rel, err := s.GetRelease(id)
+	if err != nil {
+		return nil, err
+	}
+	if rel.TranslationOf == nil {
+		return nil, ErrNotTranslation
+	}
+	if err := (*s.repo).SetTranslationPermission(id, permitted); err != nil {
+		return nil, err
+	}
+	return s.GetRelease(id)
+}
+
+// RemoveTranslation unlinks the release under the given id from the release it's a translation of
+func (s service) RemoveTranslation(id int) (*Release, error) {
+	rel, err := s.GetRelease(id)
+	if err != nil {
+		return nil, err
+	}
+	if rel.TranslationOf == nil {
+		return nil, ErrNotTranslation
+	}
+	if err := (*s.repo).DeleteTranslation(id); err != nil {
+		return nil, err
+	}
+	return s.GetRelease(id)
+}
+
+// ReplaceGenres files the releases under any of the given genres under the genre
+// to instead, used when the former become aliases of the latter
+func (s service) ReplaceGenres(from []string, to string) error {
+	if len(from) == 0 || to == "" {
+		return nil
+	}
+	_, err := (*s.repo).ReplaceGenres(from, to)
+	return err
+}
+
+// checkGenres is just a helper function that files the genres of the given release,
+// the defining one included, under their tags in the taxonomy
+func (s service) checkGenres(r *Release) error {
+	if r.GenreDefining == "" && len(r.Genres) == 0 {
+		return nil
+	}
+	tagService, ok := (*s.allServices)["Tag"].(*tag.Service)
+	if !ok {
+		return fmt.Errorf("tag service not found")
+	}
+	genres, err := (*tagService).Resolve(r.Genres)
+	var defining []string
+	if err == nil && r.GenreDefining != "" {
+		defining, err = (*tagService).Resolve([]string{r.GenreDefining})
+	}
+	switch err {
+	case nil:
+	case tag.ErrInvalidTag:
+		return ErrInvalidGenre
+	default:
+		return err
+	}
+	r.Genres = genres
+	r.GenreDefining = ""
+	if len(defining) > 0 {
+		r.GenreDefining = defining[0]
+	}
+	return nil
+}
+
+// checkTranslation is just a helper function that validates linking the given release, in the
+// given language and with the given credits, as a translation. Translations of translations
+// are linked to the release they all come from.
+func (s service) checkTranslation(r *Release, language string, credits []*Credit) error {
+	original, err := s.GetRelease(r.TranslationOf.OriginalID)
+	if err == nil && original.TranslationOf != nil {
+		original, err = s.GetRelease(original.TranslationOf.OriginalID)
+	}
+	switch err {
+	case nil:
+	case ErrReleaseNotFound:
+		return ErrInvalidTranslation
+	default:
+		return err
+	}
+	if original.ID == r.ID {
+		return ErrInvalidTranslation
+	}
+	if r.ID != 0 {
+		// a release other releases are translated from can't be a translation itself
+		translations, err := (*s.repo).GetTranslations(r.ID)
+		if err != nil {
+			return err
+		}
+		if len(translations) > 0 {
+			return ErrInvalidTranslation
 		}
-		r.Authors = mergeStringSlicesRemovingDuplicates(r.Authors, rel.Authors)
-		r.Genres = mergeStringSlicesRemovingDuplicates(r.Genres, rel.Genres)
-		if r.OwnerChannel == rel.OwnerChannel {
-			r.OwnerChannel = ""
+	}
+	if language == "" || language == original.Language {
+		return ErrInvalidLanguage
+	}
+	credited := false
+	for _, c := range credits {
+		if c.Role == RoleTranslator {
+			credited = true
+			break
 		}
 	}
-	return (*s.repo).UpdateRelease(r)
+	if !credited {
+		return ErrTranslatorNotCredited
+	}
+	r.TranslationOf = &Translation{OriginalID: original.ID}
+	return nil
+}
+
+// checkCredits is just a helper function that validates the given credits, trimming
+// names and resolving usernames to how they're stored, and drops repeated ones.
+// The returned slice is never nil.
+func (s service) checkCredits(credits []*Credit) ([]*Credit, error) {
+	if len(credits) > MaxCredits {
+		return nil, ErrInvalidCredit
+	}
+	checked := make([]*Credit, 0, len(credits))
+	seen := make(map[Credit]bool)
+	for _, c := range credits {
+		if c == nil {
+			continue
+		}
+		credit := Credit{
+			Role:     Role(strings.ToLower(strings.TrimSpace(string(c.Role)))),
+			Username: strings.TrimPrefix(strings.TrimSpace(c.Username), "@"),
+			Name:     strings.TrimSpace(c.Name),
+		}
+		if !credit.Role.Valid() || (credit.Username == "") == (credit.Name == "") {
+			return nil, ErrInvalidCredit
+		}
+		if credit.Username != "" {
+			userService, ok := (*s.allServices)["User"].(*user.Service)
+			if !ok {
+				return nil, fmt.Errorf("user service not found")
+			}
+			u, err := (*userService).GetUser(credit.Username)
+			switch err {
+			case nil:
+				credit.Username = u.Username
+			case user.ErrUserNotFound:
+				return nil, ErrCreditedUserNotFound
+			default:
+				return nil, err
+			}
+		}
+		if !seen[credit] {
+			seen[credit] = true
+			checked = append(checked, &credit)
+		}
+	}
+	return checked, nil
+}
+
+// getComic is just a helper function that gets the release stored under the given id
+// making sure it's a comic.
+func (s service) getComic(id int) (*Release, error) {
+	rel, err := s.GetRelease(id)
+	if err != nil {
+		return nil, err
+	}
+	if rel.Type != Comic {
+		return nil, ErrNotComic
+	}
+	return rel, nil
+}
+
+// AddPages appends the given pages to the end of the comic release stored under the given id.
+func (s service) AddPages(id int, pages []*Page) (*Release, error) {
+	rel, err := s.getComic(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, ErrInvalidReleaseData
+	}
+	if len(rel.Pages)+len(pages) > MaxPages {
+		return nil, ErrTooManyPages
+	}
+	for i, p := range pages {
+		if p.Image == "" {
+			return nil, ErrInvalidReleaseData
+		}
+		p.Number = len(rel.Pages) + i + 1
+	}
+	if err := (*s.repo).AddPages(id, pages); err != nil {
+		return nil, err
+	}
+	return s.GetRelease(id)
+}
+
+// UpdatePage replaces the image and, or, the alt text of the page of the comic
+// release stored under the given id. Empty fields are left as they are.
+func (s service) UpdatePage(id int, page *Page) (*Release, error) {
+	rel, err := s.getComic(id)
+	if err != nil {
+		return nil, err
+	}
+	if page.Number < 1 || page.Number > len(rel.Pages) {
+		return nil, ErrPageNotFound
+	}
+	if err := (*s.repo).UpdatePage(id, page); err != nil {
+		return nil, err
+	}
+	return s.GetRelease(id)
+}
+
+// ReorderPages rearranges the pages of the comic release stored under the given id.
+// numbers lists the current page numbers in their new order.
+func (s service) ReorderPages(id int, numbers []int) (*Release, error) {
+	rel, err := s.getComic(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(numbers) != len(rel.Pages) {
+		return nil, ErrInvalidPageOrder
+	}
+	seen := make(map[int]bool)
+	for _, n := range numbers {
+		if n < 1 || n > len(rel.Pages) || seen[n] {
+			return nil, ErrInvalidPageOrder
+		}
+		seen[n] = true
+	}
+	if err := (*s.repo).ReorderPages(id, numbers); err != nil {
+		return nil, err
+	}
+	return s.GetRelease(id)
+}
+
+// DeletePage removes the page of the comic release stored under the given id
+// renumbering the ones that follow it. The last page of a release can't be removed.
+func (s service) DeletePage(id int, number int) (*Release, error) {
+	rel, err := s.getComic(id)
+	if err != nil {
+		return nil, err
+	}
+	if number < 1 || number > len(rel.Pages) {
+		return nil, ErrPageNotFound
+	}
+	if len(rel.Pages) == 1 {
+		return nil, ErrInvalidReleaseData
+	}
+	if err := (*s.repo).DeletePage(id, number); err != nil {
+		return nil, err
+	}
+	return s.GetRelease(id)
+}
+
+// addRevision is just a helper function that keeps a revision of the updated release.
+// The content the release was created with is kept first if it has no revisions yet.
+func (s service) addRevision(old, updated *Release, editor string) error {
+	revisions, err := (*s.repo).GetRevisions(old.ID)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		_, err = (*s.repo).AddRevision(old.ID, &Revision{
+			Content:      old.Content,
+			CreationTime: old.CreationTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = (*s.repo).AddRevision(old.ID, &Revision{
+		Editor:  editor,
+		Content: updated.Content,
+	})
+	return err
+}
+
+// GetRevisions returns the revisions of the release under the given id, oldest first.
+func (s service) GetRevisions(id int) ([]*Revision, error) {
+	if _, err := s.GetRelease(id); err != nil {
+		return nil, err
+	}
+	return (*s.repo).GetRevisions(id)
+}
+
+// GetRevision returns the revision of the release under the given ids.
+func (s service) GetRevision(id int, revisionID int) (*Revision, error) {
+	return (*s.repo).GetRevision(id, revisionID)
+}
+
+// DiffRevisions returns the differences between the revisions from and to of the release.
+func (s service) DiffRevisions(id int, from, to int) (*RevisionDiff, error) {
+	a, err := (*s.repo).GetRevision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := (*s.repo).GetRevision(id, to)
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionDiff{From: from, To: to, Content: diff.Lines(a.Content, b.Content)}, nil
+}
+
+// RestoreRevision sets the content of the release back to the one of the given
+// revision. The restoration is itself kept as a new revision.
+func (s service) RestoreRevision(id int, revisionID int, editor string) (*Release, error) {
+	r, err := (*s.repo).GetRevision(id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.GetRelease(id)
+	if err != nil {
+		return nil, err
+	}
+	restored := &Release{ID: id, Type: current.Type, Content: r.Content}
+	restored.Other = current.Other
+	return s.UpdateRelease(restored, editor)
 }
 
 // removeDuplicates is a helper function
@@ -126,3 +684,43 @@ func mergeStringSlicesRemovingDuplicates(slice1, slice2 []string) []string {
 	}
 	return slice3
 }
+
+// NormalizeLanguage checks that the given tag is a well formed BCP 47 language tag, like en,
+// pt-BR or zh-Hant-TW, and returns it in its conventional casing
+func NormalizeLanguage(tag string) (string, bool) {
+	subtags := strings.Split(strings.Replace(strings.TrimSpace(tag), "_", "-", -1), "-")
+	if len(subtags[0]) < 2 || len(subtags[0]) > 3 || !isAlphanumeric(subtags[0], false) {
+		return "", false
+	}
+	subtags[0] = strings.ToLower(subtags[0])
+	for i, subtag := range subtags[1:] {
+		if len(subtag) < 2 || len(subtag) > 8 || !isAlphanumeric(subtag, true) {
+			return "", false
+		}
+		switch {
+		case len(subtag) == 2:
+			// regions
+			subtags[i+1] = strings.ToUpper(subtag)
+		case len(subtag) == 4 && isAlphanumeric(subtag, false):
+			// scripts
+			subtags[i+1] = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		default:
+			subtags[i+1] = strings.ToLower(subtag)
+		}
+	}
+	return strings.Join(subtags, "-"), true
+}
+
+// isAlphanumeric is just a helper function that checks whether the string is made up of ASCII
+// letters, and digits if allowed, only
+func isAlphanumeric(str string, digits bool) bool {
+	for _, c := range str {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case digits && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
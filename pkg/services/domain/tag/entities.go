@@ -0,0 +1,30 @@
+package tag
+
+// Tag is a canonical genre releases are filed under. Genres given as any of
+// its Aliases, or as a spelling that only differs in case, spacing or
+// punctuation, are filed under its Name instead. Tags can be filed under a
+// Parent genre and Children lists those filed under them. ReleaseCount and
+// ChannelCount count the official releases of public channels, and those
+// channels, filed under the tag or any of its descendants.
+type Tag struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Parent       string   `json:"parent,omitempty"`
+	Children     []string `json:"children,omitempty"`
+	Aliases      []string `json:"aliases,omitempty"`
+	ReleaseCount uint     `json:"releaseCount"`
+	ChannelCount uint     `json:"channelCount"`
+}
+
+// Channel is a channel along the number of its official releases filed under a tag.
+type Channel struct {
+	ChannelUsername string `json:"channelUsername"`
+	ReleaseCount    uint   `json:"releaseCount"`
+}
+
+// Suggestion is a tag suggested for a pattern. Matches is the number of releases
+// matching the pattern that are filed under the tag.
+type Suggestion struct {
+	Name    string `json:"name"`
+	Matches uint   `json:"matches"`
+}
@@ -0,0 +1,318 @@
+/*
+Package tag contains definition and implementation of a service that manages the taxonomy of genres releases are filed under */
+package tag
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Service specifies a method to service Tag entities.
+type Service interface {
+	GetTag(name string) (*Tag, error)
+	GetTags(by SortBy, limit, offset int) ([]*Tag, error)
+	Resolve(names []string) ([]string, error)
+	UpdateTag(t *Tag) (*Tag, error)
+	RemoveParent(name string) (*Tag, error)
+	AddAlias(name, alias string) (*Tag, error)
+	RemoveAlias(name, alias string) (*Tag, error)
+	GetReleases(name string, limit, offset int) ([]int, error)
+	GetChannels(name string, limit, offset int) ([]*Channel, error)
+	Suggest(pattern string, limit int) ([]*Suggestion, error)
+}
+
+// Repository specifies a repo interface to serve the tag Service interface.
+// Tags and aliases are looked up by their keys, see the Service for those.
+type Repository interface {
+	GetTag(key string) (*Tag, error)
+	GetTags(by SortBy, limit, offset int) ([]*Tag, error)
+	GetNames(keys []string) (map[string]string, error)
+	AddTags(names, keys []string) error
+	UpdateTag(t *Tag) error
+	RemoveParent(name string) error
+	AddAlias(name, alias, key string) error
+	DeleteAlias(name, key string) error
+	MergeTag(from, into, key string) error
+	GetReleases(name string, limit, offset int) ([]int, error)
+	GetChannels(name string, limit, offset int) ([]*Channel, error)
+	Suggest(pattern, key string, limit int) ([]*Suggestion, error)
+}
+
+// SortBy holds enums used by GetTags methods the attribute of Tags are sorted with
+type SortBy string
+
+// Sorting constants used by GetTags methods. Tags are sorted by name in ascending order
+// and by their counts in descending order.
+const (
+	SortByName         SortBy = "name"
+	SortByReleaseCount SortBy = "release_count"
+	SortByChannelCount SortBy = "channel_count"
+)
+
+// ErrTagNotFound is returned when the requested tag is not found
+var ErrTagNotFound = fmt.Errorf("tag not found")
+
+// ErrInvalidTag is returned when a tag name, an alias or a description is too long or a
+// name or an alias is made up of nothing but spaces and punctuation
+var ErrInvalidTag = fmt.Errorf("invalid tag")
+
+// ErrInvalidParent is returned when a tag is filed under a tag that doesn't exist, itself
+// or one of its own descendants
+var ErrInvalidParent = fmt.Errorf("invalid parent tag")
+
+// ErrAliasTaken is returned when an alias is already in use by another tag
+var ErrAliasTaken = fmt.Errorf("alias taken")
+
+// ErrAliasNotFound is returned when the requested alias isn't one of the tag
+var ErrAliasNotFound = fmt.Errorf("alias not found")
+
+// MaxNameLength is the number of characters the names and aliases of tags can have
+const MaxNameLength = 64
+
+// MaxDescriptionLength is the number of characters the descriptions of tags can have
+const MaxDescriptionLength = 1024
+
+type service struct {
+	repo *Repository
+}
+
+// NewService returns a struct that implements the tag.Service interface
+func NewService(repo *Repository) Service {
+	return &service{repo: repo}
+}
+
+// GetTag gets the tag the given name or alias is filed under.
+func (s service) GetTag(name string) (*Tag, error) {
+	key := keyOf(name)
+	if key == "" {
+		return nil, ErrTagNotFound
+	}
+	return (*s.repo).GetTag(key)
+}
+
+// GetTags returns a list of all the tags sorted by the given attribute. Pagination can be specified.
+func (s service) GetTags(by SortBy, limit, offset int) ([]*Tag, error) {
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	switch by {
+	case SortByName, SortByReleaseCount, SortByChannelCount:
+	default:
+		by = SortByName
+	}
+	return (*s.repo).GetTags(by, limit, offset)
+}
+
+// Resolve files the given genres under their tags and returns the names of those, in the
+// order the genres were given and without repeats. Genres that match no tag become new
+// ones while those made up of nothing but spaces and punctuation are dropped.
+// The returned slice is never nil.
+func (s service) Resolve(names []string) ([]string, error) {
+	keys := make([]string, 0, len(names))
+	cleaned := make(map[string]string)
+	for _, name := range names {
+		name = cleanName(name)
+		key := keyOf(name)
+		if key == "" {
+			continue
+		}
+		if utf8.RuneCountInString(name) > MaxNameLength {
+			return nil, ErrInvalidTag
+		}
+		if _, ok := cleaned[key]; !ok {
+			cleaned[key] = name
+			keys = append(keys, key)
+		}
+	}
+	resolved := make([]string, 0, len(keys))
+	if len(keys) == 0 {
+		return resolved, nil
+	}
+	found, err := (*s.repo).GetNames(keys)
+	if err != nil {
+		return nil, err
+	}
+	var newNames, newKeys []string
+	for _, key := range keys {
+		if _, ok := found[key]; !ok {
+			newNames = append(newNames, cleaned[key])
+			newKeys = append(newKeys, key)
+			found[key] = cleaned[key]
+		}
+	}
+	if len(newNames) > 0 {
+		if err := (*s.repo).AddTags(newNames, newKeys); err != nil {
+			return nil, err
+		}
+	}
+	// different genres could be aliases of the same tag
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if name := found[key]; !seen[name] {
+			seen[name] = true
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved, nil
+}
+
+// UpdateTag updates the description and the parent of the tag the given name is filed
+// under based on the passed in struct. Empty fields are left as they are.
+func (s service) UpdateTag(t *Tag) (*Tag, error) {
+	current, err := s.GetTag(t.Name)
+	if err != nil {
+		return nil, err
+	}
+	update := &Tag{Name: current.Name, Description: strings.TrimSpace(t.Description)}
+	if utf8.RuneCountInString(update.Description) > MaxDescriptionLength {
+		return nil, ErrInvalidTag
+	}
+	if t.Parent != "" {
+		parent, err := s.GetTag(t.Parent)
+		switch err {
+		case nil:
+		case ErrTagNotFound:
+			return nil, ErrInvalidParent
+		default:
+			return nil, err
+		}
+		// the tag can't be filed under itself, even by way of its descendants
+		for ancestor := parent; ; {
+			if ancestor.Name == current.Name {
+				return nil, ErrInvalidParent
+			}
+			if ancestor.Parent == "" {
+				break
+			}
+			if ancestor, err = s.GetTag(ancestor.Parent); err != nil {
+				return nil, err
+			}
+		}
+		update.Parent = parent.Name
+	}
+	if err := (*s.repo).UpdateTag(update); err != nil {
+		return nil, err
+	}
+	return s.GetTag(current.Name)
+}
+
+// RemoveParent takes the tag the given name is filed under out of its parent genre.
+func (s service) RemoveParent(name string) (*Tag, error) {
+	t, err := s.GetTag(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := (*s.repo).RemoveParent(t.Name); err != nil {
+		return nil, err
+	}
+	return s.GetTag(t.Name)
+}
+
+// AddAlias files the given alias under the tag the given name is filed under. Aliases that
+// are tags of their own are merged into it, their aliases and children along.
+func (s service) AddAlias(name, alias string) (*Tag, error) {
+	t, err := s.GetTag(name)
+	if err != nil {
+		return nil, err
+	}
+	alias = cleanName(alias)
+	key := keyOf(alias)
+	if key == "" || utf8.RuneCountInString(alias) > MaxNameLength {
+		return nil, ErrInvalidTag
+	}
+	existing, err := (*s.repo).GetTag(key)
+	switch err {
+	case nil:
+		switch {
+		case existing.Name == t.Name:
+			// the alias is already filed under the tag
+			return t, nil
+		case keyOf(existing.Name) == key:
+			err = (*s.repo).MergeTag(existing.Name, t.Name, key)
+		default:
+			return nil, ErrAliasTaken
+		}
+	case ErrTagNotFound:
+		err = (*s.repo).AddAlias(t.Name, alias, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTag(t.Name)
+}
+
+// RemoveAlias removes the given alias from the tag the given name is filed under.
+func (s service) RemoveAlias(name, alias string) (*Tag, error) {
+	t, err := s.GetTag(name)
+	if err != nil {
+		return nil, err
+	}
+	key := keyOf(alias)
+	if key == "" || key == keyOf(t.Name) {
+		return nil, ErrAliasNotFound
+	}
+	if err := (*s.repo).DeleteAlias(t.Name, key); err != nil {
+		return nil, err
+	}
+	return s.GetTag(t.Name)
+}
+
+// GetReleases returns the ids of the official releases of public channels filed under the
+// tag the given name is filed under, or any of its descendants, the latest first.
+func (s service) GetReleases(name string, limit, offset int) ([]int, error) {
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	t, err := s.GetTag(name)
+	if err != nil {
+		return nil, err
+	}
+	return (*s.repo).GetReleases(t.Name, limit, offset)
+}
+
+// GetChannels returns the public channels with official releases filed under the tag the
+// given name is filed under, or any of its descendants, the ones with the most first.
+func (s service) GetChannels(name string, limit, offset int) ([]*Channel, error) {
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	t, err := s.GetTag(name)
+	if err != nil {
+		return nil, err
+	}
+	return (*s.repo).GetChannels(t.Name, limit, offset)
+}
+
+// Suggest returns the tags whose names or aliases start like the given pattern followed by
+// the ones the releases matching the pattern in a full text search are filed under the most.
+func (s service) Suggest(pattern string, limit int) ([]*Suggestion, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid pagination")
+	}
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return make([]*Suggestion, 0), nil
+	}
+	return (*s.repo).Suggest(pattern, keyOf(pattern), limit)
+}
+
+// cleanName is just a helper function that trims the given name and collapses the
+// spaces in it.
+func cleanName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// keyOf is just a helper function that returns what tags and aliases are matched by,
+// their name in lower case without spaces and punctuation. "Sci-fi" and "SciFi" have
+// the same key, "scifi".
+func keyOf(name string) string {
+	var key strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			key.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return key.String()
+}
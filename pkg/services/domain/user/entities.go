@@ -2,19 +2,25 @@ package user
 
 import (
 	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/imaging"
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 // User represents standard user entity of issue#1.
 // bookmarkedPosts map contains the postId mapped to the time it was bookmarked.
+// contentFilter holds the maturity filter the user has set for listings.
 type User struct {
-	Username        string            `json:"username"`
-	Email           string            `json:"email"`
-	FirstName       string            `json:"firstName"`
-	MiddleName      string            `json:"middleName"`
-	LastName        string            `json:"lastName"`
-	CreationTime    time.Time         `json:"creationTime"`
-	Bio             string            `json:"bio"`
-	BookmarkedPosts map[time.Time]int `json:"-"`
-	Password        string            `json:"password,omitempty"`
-	PictureURL      string            `json:"pictureURL"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	FirstName       string             `json:"firstName"`
+	MiddleName      string             `json:"middleName"`
+	LastName        string             `json:"lastName"`
+	CreationTime    time.Time          `json:"creationTime"`
+	Bio             string             `json:"bio"`
+	BookmarkedPosts map[time.Time]int  `json:"-"`
+	Password        string             `json:"password,omitempty"`
+	PictureURL      string             `json:"pictureURL"`
+	PictureVariants []*imaging.Variant `json:"pictureVariants,omitempty"`
+	ContentFilter   *maturity.Filter   `json:"contentFilter,omitempty"`
 }
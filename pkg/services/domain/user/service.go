@@ -4,6 +4,8 @@ package user
 
 import (
 	"fmt"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/maturity"
 )
 
 // Service specifies a method to service User entities.
@@ -18,6 +20,7 @@ type Service interface {
 	DeleteBookmark(username string, postID int) error
 	AddPicture(username, name string) error
 	RemovePicture(username string) error
+	SetContentFilter(username string, filter *maturity.Filter) (*maturity.Filter, error)
 }
 
 // Repository specifies a repo interface to serve the Service interface
@@ -34,6 +37,7 @@ type Repository interface {
 	EmailOccupied(email string) (bool, error)
 	AddPicture(username, name string) error
 	RemovePicture(username string) error
+	SetContentFilter(username string, filter *maturity.Filter) error
 }
 
 // SortOrder holds enums used by SearchUser methods the order of Users are sorted with
@@ -68,6 +72,9 @@ var ErrPostNotFound = fmt.Errorf("post not found")
 // ErrInvalidUserData is returned when the the username specified isn't recognized
 var ErrInvalidUserData = fmt.Errorf("passed user data is invalid")
 
+// ErrInvalidContentFilter is returned when the given content filter has a maturity rating that isn't recognized
+var ErrInvalidContentFilter = fmt.Errorf("invalid content filter")
+
 // ErrSomeUserDataNotPersisted is returned when the the username specified isn't recognized
 var ErrSomeUserDataNotPersisted = fmt.Errorf("was not able to persist some user data")
 
@@ -181,3 +188,16 @@ func (service *service) AddPicture(username, name string) error {
 func (service *service) RemovePicture(username string) error {
 	return (*service.repo).RemovePicture(username)
 }
+
+// SetContentFilter sets the maturity filter the user of the given username has for listings.
+func (service *service) SetContentFilter(username string, filter *maturity.Filter) (*maturity.Filter, error) {
+	if !filter.Valid() {
+		return nil, ErrInvalidContentFilter
+	}
+	filter.HiddenWarnings = maturity.NormalizeWarnings(filter.HiddenWarnings)
+	err := (*service.repo).SetContentFilter(username, filter)
+	if err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
@@ -0,0 +1,29 @@
+package view
+
+import "time"
+
+// View represents a single read of a post or a release. Viewer is either
+// the username of the reader or a fingerprint of an anonymous one.
+type View struct {
+	TargetType TargetType `json:"targetType"`
+	TargetID   int        `json:"targetID"`
+	Viewer     string     `json:"viewer"`
+	Time       time.Time  `json:"time"`
+}
+
+// TargetType holds enums used to describe what kind of entity a View is of.
+type TargetType string
+
+// Entities views are counted on
+const (
+	TargetPost    TargetType = "post"
+	TargetRelease TargetType = "release"
+)
+
+// Count is the number of views a target received during the hour starting at Bucket.
+type Count struct {
+	TargetType TargetType `json:"targetType"`
+	TargetID   int        `json:"targetID"`
+	Bucket     time.Time  `json:"bucket"`
+	Count      uint       `json:"count"`
+}
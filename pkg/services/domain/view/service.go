@@ -0,0 +1,188 @@
+/*
+Package view contains definition and implementation of a service that counts the views of posts and releases */
+package view
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Service specifies a method to service View entities.
+type Service interface {
+	RecordView(v *View) (bool, error)
+	GetCount(targetType TargetType, targetID int) (uint, error)
+	Flush() error
+}
+
+// Repository specifies a repo interface to serve the View Service interface
+type Repository interface {
+	AddCounts(counts []*Count) error
+	GetCount(targetType TargetType, targetID int) (uint, error)
+}
+
+// ErrInvalidTargetType is returned when views are recorded on an unrecognized kind of entity
+var ErrInvalidTargetType = fmt.Errorf("invalid view target type")
+
+// countKey identifies the buffered count of a target during a single hour
+type countKey struct {
+	targetType TargetType
+	targetID   int
+	bucket     time.Time
+}
+
+// targetKey identifies a target across the hours it's viewed in
+type targetKey struct {
+	targetType TargetType
+	targetID   int
+}
+
+// Delays between the attempts at writing batches that pile up after writes fail
+const (
+	minFlushBackoff = time.Second
+	maxFlushBackoff = 5 * time.Minute
+)
+
+type service struct {
+	repo      *Repository
+	window    time.Duration
+	batchSize int
+
+	mutex    sync.Mutex
+	lastSeen map[string]time.Time
+	pending  map[countKey]uint
+	buffered int
+	// unflushed holds the number of views of each target that are yet to be written
+	unflushed map[targetKey]uint
+
+	// flushMutex keeps batches from being written concurrently
+	flushMutex sync.Mutex
+	// full is signaled once a batch piles up so that it's written off of the viewers' requests
+	full chan struct{}
+}
+
+// NewService returns a struct that implements the view.Service interface.
+// Repeated views of a target by the same viewer within the given window are only
+// counted once. Views are buffered in memory and written in batches once batchSize
+// of them pile up or whenever Flush is called.
+func NewService(repo *Repository, window time.Duration, batchSize int) Service {
+	s := &service{
+		repo:      repo,
+		window:    window,
+		batchSize: batchSize,
+		lastSeen:  make(map[string]time.Time),
+		pending:   make(map[countKey]uint),
+		unflushed: make(map[targetKey]uint),
+		full:      make(chan struct{}, 1),
+	}
+	go s.flushFullBatches()
+	return s
+}
+
+// flushFullBatches writes the batches that pile up between calls to Flush. Writing is
+// retried with growing delays after failures so that a failing repository isn't hammered.
+func (s *service) flushFullBatches() {
+	var backoff time.Duration
+	for range s.full {
+		if err := s.Flush(); err == nil {
+			backoff = 0
+			continue
+		}
+		switch {
+		case backoff == 0:
+			backoff = minFlushBackoff
+		case backoff < maxFlushBackoff:
+			backoff *= 2
+		}
+		time.Sleep(backoff)
+	}
+}
+
+// RecordView counts the given view unless the viewer has already viewed the target
+// within the deduplication window. It reports whether the view was counted.
+func (s *service) RecordView(v *View) (bool, error) {
+	switch v.TargetType {
+	case TargetPost, TargetRelease:
+	default:
+		return false, ErrInvalidTargetType
+	}
+	if v.Time.IsZero() {
+		v.Time = time.Now()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	seenKey := fmt.Sprintf("%s:%d:%s", v.TargetType, v.TargetID, v.Viewer)
+	if last, ok := s.lastSeen[seenKey]; ok && v.Time.Sub(last) < s.window {
+		return false, nil
+	}
+	s.lastSeen[seenKey] = v.Time
+	s.pending[countKey{v.TargetType, v.TargetID, v.Time.UTC().Truncate(time.Hour)}]++
+	s.unflushed[targetKey{v.TargetType, v.TargetID}]++
+	s.buffered++
+	if s.buffered >= s.batchSize {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+	return true, nil
+}
+
+// GetCount returns the number of views the target received including the ones yet to be flushed.
+// Views of a batch that's being written might be counted twice until the write finishes.
+func (s *service) GetCount(targetType TargetType, targetID int) (uint, error) {
+	count, err := (*s.repo).GetCount(targetType, targetID)
+	if err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return count + s.unflushed[targetKey{targetType, targetID}], nil
+}
+
+// Flush writes the buffered views to the repository in a single batch. Views that
+// fail to be written are kept for the next flush.
+func (s *service) Flush() error {
+	s.flushMutex.Lock()
+	defer s.flushMutex.Unlock()
+
+	s.mutex.Lock()
+	batch := s.pending
+	s.pending = make(map[countKey]uint)
+	s.buffered = 0
+	// viewers that haven't been seen within the window won't be deduplicated anymore
+	now := time.Now()
+	for key, last := range s.lastSeen {
+		if now.Sub(last) >= s.window {
+			delete(s.lastSeen, key)
+		}
+	}
+	s.mutex.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	counts := make([]*Count, 0, len(batch))
+	for key, n := range batch {
+		counts = append(counts, &Count{TargetType: key.targetType, TargetID: key.targetID, Bucket: key.bucket, Count: n})
+	}
+	err := (*s.repo).AddCounts(counts)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err != nil {
+		for key, n := range batch {
+			s.pending[key] += n
+			s.buffered += int(n)
+		}
+		return err
+	}
+	for key, n := range batch {
+		target := targetKey{key.targetType, key.targetID}
+		if s.unflushed[target] -= n; s.unflushed[target] == 0 {
+			delete(s.unflushed, target)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package view
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubRepository keeps counts in memory and fails writes while failing is set
+type stubRepository struct {
+	mutex   sync.Mutex
+	counts  map[targetKey]uint
+	writes  int
+	failing bool
+}
+
+func newStubRepository() *stubRepository {
+	return &stubRepository{counts: make(map[targetKey]uint)}
+}
+
+func (repo *stubRepository) AddCounts(counts []*Count) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	repo.writes++
+	if repo.failing {
+		return fmt.Errorf("database unavailable")
+	}
+	for _, c := range counts {
+		repo.counts[targetKey{c.TargetType, c.TargetID}] += c.Count
+	}
+	return nil
+}
+
+func (repo *stubRepository) GetCount(targetType TargetType, targetID int) (uint, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	return repo.counts[targetKey{targetType, targetID}], nil
+}
+
+func (repo *stubRepository) setFailing(failing bool) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	repo.failing = failing
+}
+
+func newTestService(stub *stubRepository, batchSize int) Service {
+	var repo Repository = stub
+	return NewService(&repo, time.Hour, batchSize)
+}
+
+func assertCount(t *testing.T, s Service, targetID int, want uint) {
+	t.Helper()
+	got, err := s.GetCount(TargetPost, targetID)
+	if err != nil {
+		t.Fatalf("GetCount(%d) failed: %v", targetID, err)
+	}
+	if got != want {
+		t.Errorf("GetCount(%d) = %d, want %d", targetID, got, want)
+	}
+}
+
+func TestRecordViewDeduplicates(t *testing.T) {
+	s := newTestService(newStubRepository(), 100)
+	start := time.Now()
+	views := []struct {
+		viewer string
+		after  time.Duration
+		want   bool
+	}{
+		{"alice", 0, true},
+		{"alice", time.Minute, false},
+		{"bob", time.Minute, true},
+		{"alice", 2 * time.Hour, true},
+	}
+	for _, v := range views {
+		counted, err := s.RecordView(&View{TargetType: TargetPost, TargetID: 1, Viewer: v.viewer, Time: start.Add(v.after)})
+		if err != nil {
+			t.Fatalf("RecordView failed: %v", err)
+		}
+		if counted != v.want {
+			t.Errorf("RecordView of %s after %v = %v, want %v", v.viewer, v.after, counted, v.want)
+		}
+	}
+	assertCount(t, s, 1, 3)
+}
+
+func TestRecordViewInvalidTarget(t *testing.T) {
+	s := newTestService(newStubRepository(), 100)
+	if _, err := s.RecordView(&View{TargetType: "channel", TargetID: 1, Viewer: "alice"}); err != ErrInvalidTargetType {
+		t.Errorf("RecordView of an unknown target = %v, want %v", err, ErrInvalidTargetType)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	stub := newStubRepository()
+	s := newTestService(stub, 100)
+	for _, viewer := range []string{"alice", "bob"} {
+		s.RecordView(&View{TargetType: TargetPost, TargetID: 1, Viewer: viewer})
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if n, _ := stub.GetCount(TargetPost, 1); n != 2 {
+		t.Errorf("repository holds %d views after flushing, want 2", n)
+	}
+	assertCount(t, s, 1, 2)
+}
+
+func TestFlushFailureKeepsViews(t *testing.T) {
+	stub := newStubRepository()
+	stub.setFailing(true)
+	s := newTestService(stub, 100)
+	s.RecordView(&View{TargetType: TargetPost, TargetID: 1, Viewer: "alice"})
+	if err := s.Flush(); err == nil {
+		t.Fatal("Flush succeeded on a failing repository")
+	}
+	assertCount(t, s, 1, 1)
+
+	stub.setFailing(false)
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if n, _ := stub.GetCount(TargetPost, 1); n != 1 {
+		t.Errorf("repository holds %d views after flushing, want 1", n)
+	}
+	assertCount(t, s, 1, 1)
+}
+
+func TestFullBatchIsFlushedInBackground(t *testing.T) {
+	stub := newStubRepository()
+	s := newTestService(stub, 2)
+	for _, viewer := range []string{"alice", "bob"} {
+		s.RecordView(&View{TargetType: TargetPost, TargetID: 1, Viewer: viewer})
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if n, _ := stub.GetCount(TargetPost, 1); n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("full batch wasn't flushed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertCount(t, s, 1, 2)
+}
+
+func TestFailingBatchesBackOff(t *testing.T) {
+	stub := newStubRepository()
+	stub.setFailing(true)
+	s := newTestService(stub, 1)
+	for i := 0; i < 100; i++ {
+		s.RecordView(&View{TargetType: TargetPost, TargetID: 1, Viewer: fmt.Sprint(i)})
+	}
+	time.Sleep(100 * time.Millisecond)
+	stub.mutex.Lock()
+	writes := stub.writes
+	stub.mutex.Unlock()
+	if writes > 2 {
+		t.Errorf("failing repository was written to %d times, want the writes to back off", writes)
+	}
+	assertCount(t, s, 1, 100)
+}
@@ -0,0 +1,323 @@
+/*
+Package epub contains a reader that splits EPUB books into their chapters along with
+the metadata of the book taken from its OPF package document.*/
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Book is the content and metadata of an EPUB file.
+type Book struct {
+	Title       string
+	Authors     []string
+	Description string
+	Subjects    []string
+	Language    string
+	Date        time.Time
+	Chapters    []*Chapter
+}
+
+// Chapter is a single document of the reading order of a Book. HTML holds the
+// unsanitized inner HTML of the body of the document.
+type Chapter struct {
+	Title string
+	HTML  string
+}
+
+// ErrInvalidBook is returned when a file isn't a readable EPUB
+var ErrInvalidBook = fmt.Errorf("invalid epub")
+
+// ErrTooLarge is returned when a book has more or larger chapters than are allowed
+var ErrTooLarge = fmt.Errorf("epub too large")
+
+const (
+	// MaxChapters is the number of chapters read from a single book
+	MaxChapters = 500
+	// MaxDocumentSize is the largest size, uncompressed, of a document read from a book.
+	// It keeps small archives from expanding into huge ones.
+	MaxDocumentSize = 8 << 20
+	// MaxBookSize is the largest size, uncompressed, of all the documents read from a book
+	MaxBookSize = 32 << 20
+)
+
+// archive is the files of a book along the number of bytes that can still be read from them
+type archive struct {
+	files     map[string]*zip.File
+	remaining int64
+}
+
+type container struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// opfPackage is the package document of a book. The tags leave out namespaces
+// so that the Dublin Core elements match whatever prefix they're given.
+type opfPackage struct {
+	Metadata struct {
+		Titles       []string `xml:"title"`
+		Creators     []string `xml:"creator"`
+		Descriptions []string `xml:"description"`
+		Subjects     []string `xml:"subject"`
+		Languages    []string `xml:"language"`
+		Dates        []string `xml:"date"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		Toc      string `xml:"toc,attr"`
+		ItemRefs []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+type ncx struct {
+	NavPoints []ncxNavPoint `xml:"navMap>navPoint"`
+}
+
+type ncxNavPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	NavPoints []ncxNavPoint `xml:"navPoint"`
+}
+
+var (
+	bodyPattern    = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+	titlePattern   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	headingPattern = regexp.MustCompile(`(?is)<h[1-3][^>]*>(.*?)</h[1-3]>`)
+	tagPattern     = regexp.MustCompile(`(?s)<[^>]*>`)
+	// images point inside the archive and aren't imported
+	imagePattern = regexp.MustCompile(`(?is)<(img|image|svg)\b[^>]*?(/>|>.*?</(img|image|svg)>)`)
+)
+
+// Parse reads the book from the EPUB archive of the given size.
+// Documents of the reading order without any text, like covers, are skipped.
+func Parse(r io.ReaderAt, size int64) (*Book, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidBook
+	}
+	files := &archive{files: make(map[string]*zip.File), remaining: MaxBookSize}
+	for _, f := range zipReader.File {
+		files.files[f.Name] = f
+	}
+
+	var c container
+	if err = files.readXML("META-INF/container.xml", &c); err != nil {
+		return nil, err
+	}
+	if len(c.Rootfiles) == 0 {
+		return nil, ErrInvalidBook
+	}
+	opfPath := c.Rootfiles[0].FullPath
+	var pkg opfPackage
+	if err = files.readXML(opfPath, &pkg); err != nil {
+		return nil, err
+	}
+	base := path.Dir(opfPath)
+
+	book := &Book{
+		Title:       first(pkg.Metadata.Titles),
+		Authors:     trimAll(pkg.Metadata.Creators),
+		Description: first(pkg.Metadata.Descriptions),
+		Subjects:    trimAll(pkg.Metadata.Subjects),
+		Language:    first(pkg.Metadata.Languages),
+		Date:        parseDate(first(pkg.Metadata.Dates)),
+		Chapters:    make([]*Chapter, 0),
+	}
+
+	hrefs := make(map[string]string)
+	mediaTypes := make(map[string]string)
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = resolve(base, item.Href)
+		mediaTypes[item.ID] = item.MediaType
+	}
+	labels := make(map[string]string)
+	if tocPath, ok := hrefs[pkg.Spine.Toc]; ok {
+		var toc ncx
+		// books without a readable table of contents fall back to headings
+		if files.readXML(tocPath, &toc) == nil {
+			collectLabels(path.Dir(tocPath), toc.NavPoints, labels)
+		}
+	}
+
+	// documents are only read once however many times they're in the reading order
+	read := make(map[string]bool)
+	for _, ref := range pkg.Spine.ItemRefs {
+		if ref.Linear == "no" || mediaTypes[ref.IDRef] != "application/xhtml+xml" {
+			continue
+		}
+		docPath, ok := hrefs[ref.IDRef]
+		if !ok || read[docPath] {
+			continue
+		}
+		read[docPath] = true
+		raw, err := files.readFile(docPath)
+		if err != nil {
+			return nil, err
+		}
+		chapter := parseChapter(raw, labels[docPath])
+		if chapter == nil {
+			continue
+		}
+		if len(book.Chapters) == MaxChapters {
+			return nil, ErrTooLarge
+		}
+		if chapter.Title == "" {
+			chapter.Title = fmt.Sprintf("Chapter %d", len(book.Chapters)+1)
+		}
+		book.Chapters = append(book.Chapters, chapter)
+	}
+	if len(book.Chapters) == 0 {
+		return nil, ErrInvalidBook
+	}
+	return book, nil
+}
+
+// parseChapter is just a helper function that extracts the body and title of a document.
+// It returns nil for documents without any text.
+func parseChapter(raw []byte, label string) *Chapter {
+	match := bodyPattern.FindSubmatch(raw)
+	if match == nil {
+		return nil
+	}
+	body := imagePattern.ReplaceAllString(string(match[1]), "")
+	if plainText(body) == "" {
+		return nil
+	}
+	chapter := &Chapter{Title: label, HTML: strings.TrimSpace(body)}
+	if chapter.Title == "" {
+		if heading := headingPattern.FindStringSubmatch(body); heading != nil {
+			chapter.Title = plainText(heading[1])
+		}
+	}
+	if chapter.Title == "" {
+		if title := titlePattern.FindSubmatch(raw); title != nil {
+			chapter.Title = plainText(string(title[1]))
+		}
+	}
+	return chapter
+}
+
+// collectLabels is just a helper function that maps the documents of the table of contents
+// to their labels, the first label of a document wins
+func collectLabels(base string, points []ncxNavPoint, labels map[string]string) {
+	for _, point := range points {
+		src := point.Content.Src
+		if i := strings.IndexByte(src, '#'); i != -1 {
+			src = src[:i]
+		}
+		src = resolve(base, src)
+		if _, ok := labels[src]; !ok {
+			labels[src] = strings.TrimSpace(point.Label)
+		}
+		collectLabels(base, point.NavPoints, labels)
+	}
+}
+
+// readFile is just a helper function that reads a file of the archive up to MaxDocumentSize
+// and what's left of MaxBookSize
+func (a *archive) readFile(name string) ([]byte, error) {
+	f, ok := a.files[name]
+	if !ok {
+		return nil, ErrInvalidBook
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, ErrInvalidBook
+	}
+	defer rc.Close()
+	limit := int64(MaxDocumentSize)
+	if a.remaining < limit {
+		limit = a.remaining
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, ErrInvalidBook
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrTooLarge
+	}
+	a.remaining -= int64(len(data))
+	return data, nil
+}
+
+// readXML is just a helper function that decodes an XML file of the archive
+func (a *archive) readXML(name string, v interface{}) error {
+	data, err := a.readFile(name)
+	if err != nil {
+		return err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// books are often declared with encodings other than UTF-8 while actually being UTF-8
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	decoder.Strict = false
+	if err := decoder.Decode(v); err != nil {
+		return ErrInvalidBook
+	}
+	return nil
+}
+
+// resolve is just a helper function that turns a link relative to a document into a path in the archive
+func resolve(base, href string) string {
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	return strings.TrimPrefix(path.Join(base, href), "./")
+}
+
+// plainText is just a helper function that strips the tags of an HTML fragment
+func plainText(fragment string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(fragment, " "))), " ")
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
+
+func trimAll(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
+
+// parseDate is just a helper function that reads the partial ISO 8601 dates used by books
+func parseDate(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,90 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// comicInfo is the ComicInfo.xml metadata most comic book readers look for in archives
+type comicInfo struct {
+	XMLName   xml.Name    `xml:"ComicInfo"`
+	Title     string      `xml:"Title,omitempty"`
+	Summary   string      `xml:"Summary,omitempty"`
+	Writer    string      `xml:"Writer,omitempty"`
+	Genre     string      `xml:"Genre,omitempty"`
+	Language  string      `xml:"LanguageISO,omitempty"`
+	PageCount int         `xml:"PageCount"`
+	Pages     []comicPage `xml:"Pages>Page"`
+}
+
+// comicPage describes a single image of a comic book archive
+type comicPage struct {
+	Image int    `xml:"Image,attr"`
+	Type  string `xml:"Type,attr,omitempty"`
+}
+
+// writeCBZ writes the images of the book, cover first, into a comic book archive
+func writeCBZ(w io.Writer, book *Book, read imageReader) error {
+	info := comicInfo{
+		Title:    book.Title,
+		Summary:  book.Description,
+		Writer:   strings.Join(book.Authors, ", "),
+		Genre:    strings.Join(book.Genres, ", "),
+		Language: book.Language,
+	}
+	z := zip.NewWriter(w)
+	add := func(name, pageType string) error {
+		p, err := readPicture(read, name)
+		if err != nil || p == nil {
+			return err
+		}
+		// images are stored as they are, they're already compressed
+		f, err := z.CreateHeader(&zip.FileHeader{
+			Name:   fmt.Sprintf("%05d.%s", info.PageCount, p.ext()),
+			Method: zip.Store,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = f.Write(p.data); err != nil {
+			return err
+		}
+		info.Pages = append(info.Pages, comicPage{Image: info.PageCount, Type: pageType})
+		info.PageCount++
+		return nil
+	}
+
+	if book.Cover != "" {
+		if err := add(book.Cover, "FrontCover"); err != nil {
+			return err
+		}
+	}
+	covered := info.PageCount
+	for _, chapter := range book.Chapters {
+		for _, image := range chapter.Images {
+			if err := add(image, ""); err != nil {
+				return err
+			}
+		}
+	}
+	if info.PageCount == covered {
+		return ErrEmptyBook
+	}
+
+	f, err := z.Create("ComicInfo.xml")
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(f, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(f)
+	encoder.Indent("", "  ")
+	if err = encoder.Encode(info); err != nil {
+		return err
+	}
+	return z.Close()
+}
@@ -0,0 +1,85 @@
+package export
+
+import "time"
+
+// Format signifies the kind of file a Book is exported to.
+type Format string
+
+const (
+	// FormatEPUB exports the text chapters of a book into an EPUB 3 file
+	FormatEPUB Format = "epub"
+	// FormatCBZ exports the images of a book into a comic book archive
+	FormatCBZ Format = "cbz"
+	// FormatPDF exports the images of a book into a PDF, one image a page
+	FormatPDF Format = "pdf"
+)
+
+// Valid checks whether the format is recognized
+func (f Format) Valid() bool {
+	return f == FormatEPUB || f == FormatCBZ || f == FormatPDF
+}
+
+// ContentType returns the media type of the files of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatEPUB:
+		return "application/epub+zip"
+	case FormatCBZ:
+		return "application/vnd.comicbook+zip"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+// Book is the content exported together. Cover and Images hold the names
+// the images are kept under in the store.
+type Book struct {
+	Title       string     `json:"title"`
+	Authors     []string   `json:"authors,omitempty"`
+	Genres      []string   `json:"genres,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Cover       string     `json:"cover,omitempty"`
+	Chapters    []*Chapter `json:"chapters"`
+}
+
+// Chapter is a single release of a Book. Text releases have their content,
+// already sanitized, in HTML while image and comic releases list their Images.
+type Chapter struct {
+	Title  string   `json:"title"`
+	HTML   string   `json:"html,omitempty"`
+	Images []string `json:"images,omitempty"`
+}
+
+// Status signifies the progress of a Job.
+type Status string
+
+const (
+	// StatusQueued jobs wait for a worker
+	StatusQueued Status = "queued"
+	// StatusRunning jobs are being written
+	StatusRunning Status = "running"
+	// StatusDone jobs have their file ready to be downloaded
+	StatusDone Status = "done"
+	// StatusFailed jobs couldn't be written, see Error
+	StatusFailed Status = "failed"
+)
+
+// Job is the export of a Book in a certain Format. The ID is derived from the
+// content of the book so the same content is only ever exported once. Variant
+// tells apart the books of the same Owner that are exported side by side, like
+// the ones gathered under different content filters.
+type Job struct {
+	ID             string     `json:"id"`
+	Owner          string     `json:"owner"`
+	Variant        string     `json:"-"`
+	Format         Format     `json:"format"`
+	Status         Status     `json:"status"`
+	Size           int64      `json:"size,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	File           string     `json:"-"`
+	URL            string     `json:"url,omitempty"`
+	CreationTime   time.Time  `json:"creationTime"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+}
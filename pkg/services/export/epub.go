@@ -0,0 +1,238 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// voidElementPattern matches the html elements that have no closing tag, which XHTML requires be self closed
+var voidElementPattern = regexp.MustCompile(`(?i)<(area|br|col|hr|wbr)(\s[^>]*?)?\s*/?>`)
+
+// imgPattern matches images, they're left out as they'd point outside the book
+var imgPattern = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+
+// entityPattern matches named character references, only five of which are known to XML
+var entityPattern = regexp.MustCompile(`&([a-zA-Z][a-zA-Z0-9]*);`)
+
+// tagPattern matches any tag, used to fall back to the text of malformed content
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// paragraphBreakPattern matches the blank lines between paragraphs
+var paragraphBreakPattern = regexp.MustCompile(`\n\s*\n`)
+
+// escapeXML is just a helper function that escapes text for XML documents
+func escapeXML(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// toXHTML is a helper function that turns sanitized html into well formed XHTML. Markup
+// that's still malformed is dropped and only the text of the content kept, in paragraphs.
+func toXHTML(content string) string {
+	content = imgPattern.ReplaceAllString(content, "")
+	content = voidElementPattern.ReplaceAllString(content, "<$1$2/>")
+	content = entityPattern.ReplaceAllStringFunc(content, func(entity string) string {
+		switch entity {
+		case "&amp;", "&lt;", "&gt;", "&quot;", "&apos;":
+			return entity
+		}
+		if unescaped := html.UnescapeString(entity); unescaped != entity {
+			return escapeXML(unescaped)
+		}
+		return "&amp;" + entity[1:]
+	})
+
+	decoder := xml.NewDecoder(strings.NewReader("<div>" + content + "</div>"))
+	var err error
+	for err == nil {
+		_, err = decoder.Token()
+	}
+	if err == io.EOF {
+		return content
+	}
+
+	var b strings.Builder
+	text := html.UnescapeString(tagPattern.ReplaceAllString(content, "\n"))
+	for _, paragraph := range paragraphBreakPattern.Split(text, -1) {
+		if paragraph = strings.Join(strings.Fields(paragraph), " "); paragraph != "" {
+			b.WriteString("<p>" + escapeXML(paragraph) + "</p>\n")
+		}
+	}
+	return b.String()
+}
+
+// xhtmlDocument is just a helper function that wraps the given body in an XHTML document
+func xhtmlDocument(title, language, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` +
+		escapeXML(language) + `" lang="` + escapeXML(language) + `">
+<head><title>` + escapeXML(title) + `</title></head>
+<body>
+` + body + `
+</body>
+</html>
+`
+}
+
+// writeEPUB writes the text chapters of the book into an EPUB 3 file along with
+// a table of contents readers of EPUB 2 understand as well.
+func writeEPUB(w io.Writer, id string, book *Book, read imageReader) error {
+	language := book.Language
+	if language == "" {
+		language = "und"
+	}
+	z := zip.NewWriter(w)
+	// the mimetype has to come first and uncompressed
+	f, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(f, "application/epub+zip"); err != nil {
+		return err
+	}
+	write := func(name string, content []byte) error {
+		f, err := z.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(content)
+		return err
+	}
+
+	err = write("META-INF/container.xml", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`))
+	if err != nil {
+		return err
+	}
+
+	var manifest, spine, nav, navMap strings.Builder
+	hasCover := false
+	if book.Cover != "" {
+		cover, err := readPicture(read, book.Cover)
+		if err != nil {
+			return err
+		}
+		if cover != nil {
+			href := "cover." + cover.ext()
+			if err = write("OEBPS/"+href, cover.data); err != nil {
+				return err
+			}
+			body := `<section epub:type="cover"><img src="` + href + `" alt="` + escapeXML(book.Title) + `"/></section>`
+			if err = write("OEBPS/cover.xhtml", []byte(xhtmlDocument(book.Title, language, body))); err != nil {
+				return err
+			}
+			fmt.Fprintf(&manifest, `    <item id="cover-image" href="%s" media-type="%s" properties="cover-image"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+`, href, cover.mediaType())
+			spine.WriteString(`    <itemref idref="cover"/>
+`)
+			hasCover = true
+		}
+	}
+
+	n := 0
+	for _, chapter := range book.Chapters {
+		if chapter.HTML == "" {
+			continue
+		}
+		n++
+		name := fmt.Sprintf("chapter-%03d", n)
+		title := chapter.Title
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", n)
+		}
+		body := `<section epub:type="chapter">
+<h1>` + escapeXML(title) + `</h1>
+` + toXHTML(chapter.HTML) + `
+</section>`
+		if err = write("OEBPS/"+name+".xhtml", []byte(xhtmlDocument(title, language, body))); err != nil {
+			return err
+		}
+		fmt.Fprintf(&manifest, `    <item id="%s" href="%s.xhtml" media-type="application/xhtml+xml"/>
+`, name, name)
+		fmt.Fprintf(&spine, `    <itemref idref="%s"/>
+`, name)
+		fmt.Fprintf(&nav, `      <li><a href="%s.xhtml">%s</a></li>
+`, name, escapeXML(title))
+		fmt.Fprintf(&navMap, `    <navPoint id="%s" playOrder="%d"><navLabel><text>%s</text></navLabel><content src="%s.xhtml"/></navPoint>
+`, name, n, escapeXML(title), name)
+	}
+	if n == 0 {
+		return ErrEmptyBook
+	}
+
+	identifier := "urn:issue1:export:" + id
+	body := `<nav epub:type="toc" id="toc">
+  <h1>Contents</h1>
+  <ol>
+` + nav.String() + `  </ol>
+</nav>`
+	if err = write("OEBPS/nav.xhtml", []byte(xhtmlDocument(book.Title, language, body))); err != nil {
+		return err
+	}
+
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head><meta name="dtb:uid" content="` + identifier + `"/></head>
+  <docTitle><text>` + escapeXML(book.Title) + `</text></docTitle>
+  <navMap>
+` + navMap.String() + `  </navMap>
+</ncx>
+`
+	if err = write("OEBPS/toc.ncx", []byte(ncx)); err != nil {
+		return err
+	}
+
+	var metadata strings.Builder
+	fmt.Fprintf(&metadata, `    <dc:identifier id="book-id">%s</dc:identifier>
+    <dc:title>%s</dc:title>
+    <dc:language>%s</dc:language>
+`, identifier, escapeXML(book.Title), escapeXML(language))
+	for _, author := range book.Authors {
+		fmt.Fprintf(&metadata, "    <dc:creator>%s</dc:creator>\n", escapeXML(author))
+	}
+	for _, genre := range book.Genres {
+		fmt.Fprintf(&metadata, "    <dc:subject>%s</dc:subject>\n", escapeXML(genre))
+	}
+	if book.Description != "" {
+		fmt.Fprintf(&metadata, "    <dc:description>%s</dc:description>\n", escapeXML(book.Description))
+	}
+	fmt.Fprintf(&metadata, `    <meta property="dcterms:modified">%s</meta>
+`, time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	if hasCover {
+		// lets readers of EPUB 2 find the cover
+		metadata.WriteString(`    <meta name="cover" content="cover-image"/>
+`)
+	}
+
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+` + metadata.String() + `  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+` + manifest.String() + `  </manifest>
+  <spine toc="ncx">
+` + spine.String() + `  </spine>
+</package>
+`
+	if err = write("OEBPS/content.opf", []byte(opf)); err != nil {
+		return err
+	}
+	return z.Close()
+}
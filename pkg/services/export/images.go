@@ -0,0 +1,51 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg" // registers the jpeg decoder
+	_ "image/png"  // registers the png decoder
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/storage"
+)
+
+// imageReader reads the image stored under the given name
+type imageReader func(name string) ([]byte, error)
+
+// picture is an image read to be put in an export
+type picture struct {
+	data   []byte
+	format string
+	config image.Config
+}
+
+// readPicture is just a helper function that reads an image and its dimensions. A nil
+// picture is returned for images that have since been removed from the store.
+func readPicture(read imageReader, name string) (*picture, error) {
+	data, err := read(name)
+	if err == storage.ErrBlobNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, fmt.Errorf("image %s is of an unsupported format", name)
+	}
+	return &picture{data: data, format: format, config: config}, nil
+}
+
+// ext returns the file extension of the picture
+func (p *picture) ext() string {
+	if p.format == "jpeg" {
+		return "jpg"
+	}
+	return p.format
+}
+
+// mediaType returns the media type of the picture
+func (p *picture) mediaType() string {
+	return "image/" + p.format
+}
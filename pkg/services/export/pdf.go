@@ -0,0 +1,201 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/imaging"
+)
+
+// pageWidth is the width, in points, every page of exported PDFs is given, that of A4
+const pageWidth = 595.0
+
+// pdfWriter keeps track of the offsets of the objects written to a PDF for its cross reference table
+type pdfWriter struct {
+	w       io.Writer
+	offset  int64
+	objects []int64
+	err     error
+}
+
+// write writes formatted output to the PDF unless an earlier write failed
+func (p *pdfWriter) write(format string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.w, format, a...)
+	p.offset += int64(n)
+	p.err = err
+}
+
+// object starts the object of the given number, objects are numbered from 1
+func (p *pdfWriter) object(number int) {
+	for len(p.objects) < number {
+		p.objects = append(p.objects, 0)
+	}
+	p.objects[number-1] = p.offset
+	p.write("%d 0 obj\n", number)
+}
+
+// stream writes a stream object of the given dictionary entries and data
+func (p *pdfWriter) stream(number int, dictionary string, data []byte) {
+	p.object(number)
+	p.write("<< %s /Length %d >>\nstream\n", dictionary, len(data))
+	if p.err == nil {
+		var n int
+		n, p.err = p.w.Write(data)
+		p.offset += int64(n)
+	}
+	p.write("\nendstream\nendobj\n")
+}
+
+// pdfString is just a helper function that encodes text as a PDF string, in UTF-16 if it isn't ASCII
+func pdfString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s) + ")"
+	}
+	units := utf16.Encode([]rune(s))
+	raw := make([]byte, 2, 2+2*len(units))
+	raw[0], raw[1] = 0xfe, 0xff
+	for _, u := range units {
+		raw = append(raw, byte(u>>8), byte(u))
+	}
+	return "<" + hex.EncodeToString(raw) + ">"
+}
+
+// pdfImage is just a helper function that returns the image dictionary entries and data
+// of the picture. Baseline JPEGs are embedded as they are while other images are
+// decoded and compressed losslessly, on a white background if they're transparent.
+func pdfImage(p *picture) (string, []byte, error) {
+	dictionary := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8",
+		p.config.Width, p.config.Height)
+	if p.format == "jpeg" {
+		switch p.config.ColorModel {
+		case color.YCbCrModel:
+			return dictionary + " /ColorSpace /DeviceRGB /Filter /DCTDecode", p.data, nil
+		case color.GrayModel:
+			return dictionary + " /ColorSpace /DeviceGray /Filter /DCTDecode", p.data, nil
+		}
+	}
+	if p.config.Width*p.config.Height > imaging.MaxPixels {
+		return "", nil, imaging.ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(p.data))
+	if err != nil {
+		return "", nil, err
+	}
+	var buffer bytes.Buffer
+	z := zlib.NewWriter(&buffer)
+	bounds := img.Bounds()
+	row := make([]byte, 0, 3*bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// composites the premultiplied colors over white
+			white := 0xffff - a
+			row = append(row, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+		if _, err = z.Write(row); err != nil {
+			return "", nil, err
+		}
+	}
+	if err = z.Close(); err != nil {
+		return "", nil, err
+	}
+	return dictionary + " /ColorSpace /DeviceRGB /Filter /FlateDecode", buffer.Bytes(), nil
+}
+
+// writePDF writes the images of the book, cover first, into a PDF with an image a page
+func writePDF(w io.Writer, book *Book, read imageReader) error {
+	const (
+		catalogObject = 1
+		pagesObject   = 2
+		infoObject    = 3
+	)
+	p := &pdfWriter{w: w}
+	p.write("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	names := make([]string, 0)
+	first := 0
+	if book.Cover != "" {
+		names = append(names, book.Cover)
+		first = 1
+	}
+	for _, chapter := range book.Chapters {
+		names = append(names, chapter.Images...)
+	}
+	kids := make([]string, 0, len(names))
+	next := infoObject + 1
+	embedded := 0
+	for i, name := range names {
+		picture, err := readPicture(read, name)
+		if err != nil {
+			return err
+		}
+		if picture == nil {
+			continue
+		}
+		dictionary, data, err := pdfImage(picture)
+		if err != nil {
+			return fmt.Errorf("image %s couldn't be embedded because: %v", name, err)
+		}
+		page, contents, xObject := next, next+1, next+2
+		next += 3
+		height := pageWidth * float64(picture.config.Height) / float64(picture.config.Width)
+
+		p.object(page)
+		p.write("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Contents %d 0 R /Resources << /XObject << /Im0 %d 0 R >> >> >>\nendobj\n",
+			pagesObject, pageWidth, height, contents, xObject)
+		p.stream(contents, "", []byte(fmt.Sprintf("q %.2f 0 0 %.2f 0 0 cm /Im0 Do Q", pageWidth, height)))
+		p.stream(xObject, dictionary, data)
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+		if i >= first {
+			embedded++
+		}
+	}
+	if embedded == 0 {
+		return ErrEmptyBook
+	}
+
+	p.object(pagesObject)
+	p.write("<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(kids))
+	p.object(catalogObject)
+	p.write("<< /Type /Catalog /Pages %d 0 R >>\nendobj\n", pagesObject)
+	p.object(infoObject)
+	p.write("<< /Title %s", pdfString(book.Title))
+	if len(book.Authors) > 0 {
+		p.write(" /Author %s", pdfString(strings.Join(book.Authors, ", ")))
+	}
+	if book.Description != "" {
+		p.write(" /Subject %s", pdfString(book.Description))
+	}
+	if len(book.Genres) > 0 {
+		p.write(" /Keywords %s", pdfString(strings.Join(book.Genres, ", ")))
+	}
+	p.write(" /CreationDate %s >>\nendobj\n", pdfString(time.Now().UTC().Format("D:20060102150405Z")))
+
+	xref := p.offset
+	p.write("xref\n0 %d\n0000000000 65535 f \n", len(p.objects)+1)
+	for _, offset := range p.objects {
+		p.write("%010d 00000 n \n", offset)
+	}
+	p.write("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(p.objects)+1, catalogObject, infoObject, xref)
+	return p.err
+}
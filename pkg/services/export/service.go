@@ -0,0 +1,342 @@
+/*
+Package export contains definition and implementation of a service that bundles the
+releases of a channel into files to be read offline: EPUBs of text releases and comic
+book archives or PDFs of image releases. Exports are written in the background.*/
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/storage"
+)
+
+// Service specifies a method to export Books and follow the Jobs doing so.
+type Service interface {
+	Export(owner, variant string, format Format, book *Book) (*Job, error)
+	GetJob(id string) (*Job, error)
+	Open(id string) (io.ReadCloser, error)
+}
+
+// ErrJobNotFound is returned when the requested export job is not found
+var ErrJobNotFound = fmt.Errorf("export job not found")
+
+// ErrJobNotDone is returned when the file of an export job that isn't done is requested
+var ErrJobNotDone = fmt.Errorf("export job not done")
+
+// ErrInvalidFormat is returned when an export to an unrecognized format is requested
+var ErrInvalidFormat = fmt.Errorf("invalid export format")
+
+// ErrEmptyBook is returned when a book has nothing that can be exported to the requested format
+var ErrEmptyBook = fmt.Errorf("nothing to export")
+
+// ErrQueueFull is returned when an export can't be queued as all the workers are busy
+var ErrQueueFull = fmt.Errorf("export queue is full")
+
+// Logger specifies a method the service reports the failures of background jobs through
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type task struct {
+	job  *Job
+	book *Book
+}
+
+type service struct {
+	images storage.Store
+	store  storage.Store
+	logger Logger
+	tasks  chan task
+
+	mutex sync.Mutex
+	jobs  map[string]*Job
+	// reading counts the open files of every export, stale holds the files of the
+	// exports replaced while being read that are to be removed once they're closed
+	reading map[string]int
+	stale   map[string]string
+}
+
+// NewService returns a struct that implements the export.Service interface. Images are read
+// from the images store and the exports written to the exports store, which shouldn't be
+// served as is since exports are only to be sent to those that can view their channels.
+// Exports are written in the background by the given number of workers and up to queueSize
+// exports can wait for a worker.
+func NewService(images, exports storage.Store, workers, queueSize int, logger Logger) Service {
+	s := &service{
+		images:  images,
+		store:   exports,
+		logger:  logger,
+		tasks:   make(chan task, queueSize),
+		jobs:    make(map[string]*Job),
+		reading: make(map[string]int),
+		stale:   make(map[string]string),
+	}
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+// jobID is just a helper function that derives the id of the export of the given content
+func jobID(owner, variant string, format Format, book *Book) (string, error) {
+	raw, err := json.Marshal(book)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", owner, variant, format)
+	hash.Write(raw)
+	return hex.EncodeToString(hash.Sum(nil))[:32], nil
+}
+
+// hasContent is just a helper function that checks whether the book has any chapter the format can hold
+func hasContent(format Format, book *Book) bool {
+	for _, chapter := range book.Chapters {
+		if format == FormatEPUB && chapter.HTML != "" {
+			return true
+		}
+		if format != FormatEPUB && len(chapter.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Export queues the export of the book to the given format and returns the job doing so.
+// Books of the same content are only exported once, the job of the earlier export
+// is returned instead until its content changes. Only the exports of the same owner and
+// variant replace each other.
+func (s *service) Export(owner, variant string, format Format, book *Book) (*Job, error) {
+	if !format.Valid() {
+		return nil, ErrInvalidFormat
+	}
+	if !hasContent(format, book) {
+		return nil, ErrEmptyBook
+	}
+	id, err := jobID(owner, variant, format, book)
+	if err != nil {
+		return nil, err
+	}
+	if job, err := s.GetJob(id); err == nil && job.Status != StatusFailed {
+		return job, nil
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:           id,
+		Owner:        owner,
+		Variant:      variant,
+		Format:       format,
+		Status:       StatusQueued,
+		File:         "export-" + id + "." + string(format),
+		CreationTime: now,
+	}
+	// the jobs are forgotten on restarts but not their files
+	exists, err := s.store.Exists(job.File)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// the file of an export that was replaced is wanted again
+	delete(s.stale, id)
+	if exists {
+		job.Status = StatusDone
+		job.CompletionTime = &now
+		s.jobs[id] = job
+		j := *job
+		return &j, nil
+	}
+	if existing, ok := s.jobs[id]; ok && existing.Status != StatusFailed {
+		// queued by another request in the meantime
+		j := *existing
+		return &j, nil
+	}
+	select {
+	case s.tasks <- task{job: job, book: book}:
+	default:
+		return nil, ErrQueueFull
+	}
+	s.jobs[id] = job
+	j := *job
+	return &j, nil
+}
+
+// GetJob returns the export job of the given id.
+func (s *service) GetJob(id string) (*Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	j := *job
+	return &j, nil
+}
+
+// Open returns the file written by the export job of the given id. The file isn't
+// removed, even if the export is replaced, until it's closed.
+func (s *service) Open(id string) (io.ReadCloser, error) {
+	s.mutex.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, ErrJobNotFound
+	}
+	if job.Status != StatusDone {
+		s.mutex.Unlock()
+		return nil, ErrJobNotDone
+	}
+	s.reading[id]++
+	fileName := job.File
+	s.mutex.Unlock()
+
+	file, err := s.store.Get(fileName)
+	if err != nil {
+		s.release(id)
+		if err == storage.ErrBlobNotFound {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &exportFile{ReadCloser: file, service: s, id: id}, nil
+}
+
+// exportFile is an open file of an export that's released once closed
+type exportFile struct {
+	io.ReadCloser
+	service *service
+	id      string
+	closed  bool
+}
+
+func (f *exportFile) Close() error {
+	err := f.ReadCloser.Close()
+	if !f.closed {
+		f.closed = true
+		f.service.release(f.id)
+	}
+	return err
+}
+
+// release is just a helper function that marks a file of the export of the given id as
+// closed, removing it if the export was replaced and no one else is reading it
+func (s *service) release(id string) {
+	s.mutex.Lock()
+	s.reading[id]--
+	if s.reading[id] > 0 {
+		s.mutex.Unlock()
+		return
+	}
+	delete(s.reading, id)
+	fileName, isStale := s.stale[id]
+	delete(s.stale, id)
+	s.mutex.Unlock()
+	if isStale {
+		s.remove(id, fileName)
+	}
+}
+
+// remove is just a helper function that removes the file of the export of the given id
+func (s *service) remove(id, fileName string) {
+	if err := s.store.Delete(fileName); err != nil && err != storage.ErrBlobNotFound {
+		s.logger.Printf("removal of stale export %s failed because: %v", id, err)
+	}
+}
+
+// work is just a helper function that writes queued exports until the service is dropped
+func (s *service) work() {
+	for t := range s.tasks {
+		s.mutex.Lock()
+		t.job.Status = StatusRunning
+		s.mutex.Unlock()
+
+		size, err := s.write(t.job, t.book)
+
+		now := time.Now()
+		stale := make([]*Job, 0)
+		s.mutex.Lock()
+		t.job.CompletionTime = &now
+		if err != nil {
+			s.logger.Printf("export %s of %s failed because: %v", t.job.ID, t.job.Owner, err)
+			t.job.Status = StatusFailed
+			t.job.Error = "export couldn't be written"
+		} else {
+			t.job.Status = StatusDone
+			t.job.Size = size
+			// the earlier exports of the owner were of content that has since changed,
+			// the ones being read are removed once they're closed
+			for id, job := range s.jobs {
+				if id != t.job.ID && job.Owner == t.job.Owner && job.Variant == t.job.Variant &&
+					job.Format == t.job.Format && (job.Status == StatusDone || job.Status == StatusFailed) {
+					delete(s.jobs, id)
+					if job.Status != StatusDone {
+						continue
+					}
+					if s.reading[id] > 0 {
+						s.stale[id] = job.File
+					} else {
+						stale = append(stale, job)
+					}
+				}
+			}
+		}
+		s.mutex.Unlock()
+
+		for _, job := range stale {
+			s.remove(job.ID, job.File)
+		}
+	}
+}
+
+// write is just a helper function that writes the export to a temporary file
+// before putting it in the store
+func (s *service) write(job *Job, book *Book) (int64, error) {
+	tmpFile, err := ioutil.TempFile("", "export*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	switch job.Format {
+	case FormatEPUB:
+		err = writeEPUB(tmpFile, job.ID, book, s.readImage)
+	case FormatCBZ:
+		err = writeCBZ(tmpFile, book, s.readImage)
+	case FormatPDF:
+		err = writePDF(tmpFile, book, s.readImage)
+	default:
+		err = ErrInvalidFormat
+	}
+	if err != nil {
+		return 0, err
+	}
+	size, err := tmpFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = tmpFile.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return size, s.store.Put(job.File, tmpFile, job.Format.ContentType())
+}
+
+// readImage is just a helper function that reads the image stored under the given name
+func (s *service) readImage(name string) ([]byte, error) {
+	file, err := s.images.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
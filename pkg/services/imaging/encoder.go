@@ -0,0 +1,99 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os/exec"
+)
+
+// Encoder specifies a method to write images in a certain format
+type Encoder interface {
+	// Format returns the name of the format, also used as the file extension
+	Format() string
+	ContentType() string
+	Encode(w io.Writer, img image.Image) error
+}
+
+type jpegEncoder struct {
+	quality int
+}
+
+// NewJPEGEncoder returns an Encoder that writes JPEG images of the given quality
+func NewJPEGEncoder(quality int) Encoder {
+	return &jpegEncoder{quality: quality}
+}
+
+func (e *jpegEncoder) Format() string {
+	return "jpg"
+}
+
+func (e *jpegEncoder) ContentType() string {
+	return "image/jpeg"
+}
+
+func (e *jpegEncoder) Encode(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: e.quality})
+}
+
+type pngEncoder struct{}
+
+// NewPNGEncoder returns an Encoder that writes PNG images
+func NewPNGEncoder() Encoder {
+	return &pngEncoder{}
+}
+
+func (e *pngEncoder) Format() string {
+	return "png"
+}
+
+func (e *pngEncoder) ContentType() string {
+	return "image/png"
+}
+
+func (e *pngEncoder) Encode(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+type webpEncoder struct {
+	binary  string
+	quality int
+}
+
+// NewWebPEncoder returns an Encoder that writes WebP images through the cwebp
+// tool as the standard library can't encode them. It returns nil if cwebp
+// can't be found in the PATH.
+func NewWebPEncoder(quality int) Encoder {
+	binary, err := exec.LookPath("cwebp")
+	if err != nil {
+		return nil
+	}
+	return &webpEncoder{binary: binary, quality: quality}
+}
+
+func (e *webpEncoder) Format() string {
+	return "webp"
+}
+
+func (e *webpEncoder) ContentType() string {
+	return "image/webp"
+}
+
+func (e *webpEncoder) Encode(w io.Writer, img image.Image) error {
+	var in bytes.Buffer
+	if err := png.Encode(&in, img); err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(e.binary, "-quiet", "-metadata", "none", "-q", fmt.Sprint(e.quality), "-o", "-", "--", "-")
+	cmd.Stdin = &in
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cwebp failed because of: %v %s", err, stderr.String())
+	}
+	return nil
+}
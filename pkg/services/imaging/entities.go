@@ -0,0 +1,28 @@
+package imaging
+
+// Size is a width images are scaled down to
+type Size struct {
+	Name  string
+	Width int
+}
+
+// DefaultSizes are the responsive widths generated for every stored image
+var DefaultSizes = []Size{
+	{Name: "thumbnail", Width: 160},
+	{Name: "small", Width: 480},
+	{Name: "medium", Width: 960},
+	{Name: "large", Width: 1600},
+}
+
+// SizeOriginal is the name of the variants that keep the width of the uploaded image
+const SizeOriginal = "original"
+
+// Variant is a scaled or re-encoded copy of a stored image.
+type Variant struct {
+	Size   string `json:"size"`
+	Width  int    `json:"width"`
+	Format string `json:"format"`
+	// File is the name the variant is stored under, relative to the storage path
+	File string `json:"-"`
+	URL  string `json:"url"`
+}
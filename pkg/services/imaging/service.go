@@ -0,0 +1,307 @@
+/*
+Package imaging contains definition and implementation of a service that prepares
+uploaded images for serving: scaled down copies for responsive sizes and thumbnails
+along with WebP versions when an encoder is available.*/
+package imaging
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"image"
+	_ "image/jpeg" // registers the jpeg decoder
+	_ "image/png"  // registers the png decoder
+	"io/ioutil"
+	"sync"
+
+	"github.com/Yohe-Am/issue-1-REST/pkg/services/storage"
+)
+
+// Service specifies a method to process stored images.
+type Service interface {
+	Process(name string) error
+	Variants(name string) []*Variant
+	Remove(name string) error
+}
+
+// ErrQueueFull is returned when an image can't be queued as all the workers are busy
+var ErrQueueFull = fmt.Errorf("image processing queue is full")
+
+// ErrImageTooLarge is returned when an image has more pixels than are allowed to be decoded
+var ErrImageTooLarge = fmt.Errorf("image too large")
+
+// ErrUnsupportedFormat is returned when an image of an unknown format is processed
+var ErrUnsupportedFormat = fmt.Errorf("unsupported image format")
+
+// MaxPixels is the largest image, in pixels, that's decoded. It keeps small files of huge
+// dimensions from exhausting memory.
+const MaxPixels = 50 * 1000 * 1000
+
+// MaxCachedImages is the number of images the variants of which are kept in memory.
+// The least recently served ones are looked up in the store again once dropped.
+const MaxCachedImages = 4096
+
+// Logger specifies a method the service reports the failures of background jobs through
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type service struct {
+	store    storage.Store
+	sizes    []Size
+	encoders map[string]Encoder
+	webp     Encoder
+	logger   Logger
+	jobs     chan string
+
+	mutex   sync.Mutex
+	recent  *list.List
+	entries map[string]*list.Element
+}
+
+// cacheEntry is an image along its variants as kept in the cache, the most recently
+// served ones at the front of the list
+type cacheEntry struct {
+	name     string
+	variants []*Variant
+}
+
+// NewService returns a struct that implements the imaging.Service interface. The images
+// are read from and the variants written to the given store. They're processed in the background by the given number of workers and up to queueSize
+// images can wait for a worker. WebP variants are only generated if cwebp is installed.
+func NewService(store storage.Store, sizes []Size, workers, queueSize int, logger Logger) Service {
+	s := &service{
+		store: store,
+		sizes: sizes,
+		encoders: map[string]Encoder{
+			"jpeg": NewJPEGEncoder(85),
+			"png":  NewPNGEncoder(),
+		},
+		logger:  logger,
+		jobs:    make(chan string, queueSize),
+		recent:  list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	// avoids a non nil interface holding a nil pointer
+	if webp := NewWebPEncoder(80); webp != nil {
+		s.webp = webp
+	}
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+// work is just a helper function that processes queued images until the service is dropped
+func (s *service) work() {
+	for name := range s.jobs {
+		if err := s.process(name); err != nil {
+			s.logger.Printf("processing of image %s failed because: %v", name, err)
+		}
+	}
+}
+
+// Process queues the generation of the variants of the image stored under the given name.
+// It doesn't wait for the variants to be generated.
+func (s *service) Process(name string) error {
+	select {
+	case s.jobs <- name:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// variantFile is just a helper function that returns the name a variant is stored under
+func variantFile(name, size, format string) string {
+	return name + "." + size + "." + format
+}
+
+// process is just a helper function that generates and saves every variant of an image
+func (s *service) process(name string) error {
+	file, err := s.store.Get(name)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ErrUnsupportedFormat
+	}
+	if config.Width*config.Height > MaxPixels {
+		return ErrImageTooLarge
+	}
+	encoder, ok := s.encoders[format]
+	if !ok {
+		return ErrUnsupportedFormat
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ErrUnsupportedFormat
+	}
+
+	variants := make([]*Variant, 0)
+	save := func(img image.Image, size string, enc Encoder) error {
+		v := &Variant{
+			Size:   size,
+			Width:  img.Bounds().Dx(),
+			Format: enc.Format(),
+			File:   variantFile(name, size, enc.Format()),
+		}
+		if err := s.write(v.File, img, enc); err != nil {
+			return err
+		}
+		variants = append(variants, v)
+		return nil
+	}
+	for _, size := range s.sizes {
+		if size.Width >= config.Width {
+			continue
+		}
+		scaled := Resize(src, size.Width)
+		if err = save(scaled, size.Name, encoder); err != nil {
+			return err
+		}
+		if s.webp != nil {
+			if err = save(scaled, size.Name, s.webp); err != nil {
+				return err
+			}
+		}
+	}
+	if s.webp != nil {
+		if err = save(src, SizeOriginal, s.webp); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	s.remember(name, variants)
+	s.mutex.Unlock()
+	return nil
+}
+
+// remember is just a helper function that caches the variants of the image dropping
+// the least recently served image if the cache is full. The mutex must be held.
+func (s *service) remember(name string, variants []*Variant) {
+	if element, ok := s.entries[name]; ok {
+		element.Value.(*cacheEntry).variants = variants
+		s.recent.MoveToFront(element)
+		return
+	}
+	s.entries[name] = s.recent.PushFront(&cacheEntry{name: name, variants: variants})
+	if s.recent.Len() > MaxCachedImages {
+		oldest := s.recent.Back()
+		s.recent.Remove(oldest)
+		delete(s.entries, oldest.Value.(*cacheEntry).name)
+	}
+}
+
+// write is just a helper function that encodes the image and saves it to the store
+func (s *service) write(fileName string, img image.Image, enc Encoder) error {
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, img); err != nil {
+		return err
+	}
+	return s.store.Put(fileName, &buf, enc.ContentType())
+}
+
+// Variants returns the variants generated so far for the image stored under the given name.
+// The returned Variants are copies and can be modified freely.
+func (s *service) Variants(name string) []*Variant {
+	var variants []*Variant
+	s.mutex.Lock()
+	element, ok := s.entries[name]
+	if ok {
+		variants = element.Value.(*cacheEntry).variants
+		s.recent.MoveToFront(element)
+	}
+	s.mutex.Unlock()
+	if !ok {
+		// the variants might've been generated before a restart. Images without
+		// any are remembered too as looking them up costs a request per candidate
+		// with remote stores, processing replaces the entry once done. Failed
+		// lookups aren't as the store might do better on the next request.
+		var err error
+		variants, err = s.lookupVariants(name)
+		if err != nil {
+			s.logger.Printf("looking up of variants of image %s failed because: %v", name, err)
+			return make([]*Variant, 0)
+		}
+		s.mutex.Lock()
+		if _, ok := s.entries[name]; !ok {
+			s.remember(name, variants)
+		}
+		s.mutex.Unlock()
+	}
+	copies := make([]*Variant, 0, len(variants))
+	for _, v := range variants {
+		vCopy := *v
+		copies = append(copies, &vCopy)
+	}
+	return copies
+}
+
+// lookupVariants is just a helper function that finds the variants of an image in the store
+func (s *service) lookupVariants(name string) ([]*Variant, error) {
+	variants := make([]*Variant, 0)
+	candidates := make([]Size, 0, len(s.sizes)+1)
+	candidates = append(candidates, s.sizes...)
+	candidates = append(candidates, Size{Name: SizeOriginal})
+	for _, size := range candidates {
+		for _, format := range []string{"jpg", "png", "webp"} {
+			fileName := variantFile(name, size.Name, format)
+			ok, err := s.store.Exists(fileName)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			v := &Variant{Size: size.Name, Width: size.Width, Format: format, File: fileName}
+			if size.Name == SizeOriginal {
+				v.Width = s.originalWidth(name)
+			}
+			variants = append(variants, v)
+		}
+	}
+	return variants, nil
+}
+
+// originalWidth is just a helper function that reads the width of the stored image
+func (s *service) originalWidth(name string) int {
+	file, err := s.store.Get(name)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0
+	}
+	return config.Width
+}
+
+// Remove deletes the variants of the image stored under the given name.
+// The image itself is left alone.
+func (s *service) Remove(name string) error {
+	s.mutex.Lock()
+	if element, ok := s.entries[name]; ok {
+		s.recent.Remove(element)
+		delete(s.entries, name)
+	}
+	s.mutex.Unlock()
+	variants, err := s.lookupVariants(name)
+	if err != nil {
+		return err
+	}
+	for _, v := range variants {
+		if removeErr := s.store.Delete(v.File); removeErr != nil && removeErr != storage.ErrBlobNotFound {
+			err = removeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+var (
+	jpegMagic = []byte{0xFF, 0xD8}
+	pngMagic  = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// strippedJPEGMarkers are the JPEG segments that carry metadata: APP1 holds EXIF, GPS
+// and XMP data, APP13 holds IPTC data and COM holds free form comments.
+var strippedJPEGMarkers = map[byte]bool{0xE1: true, 0xED: true, 0xFE: true}
+
+// strippedPNGChunks are the PNG chunks that carry metadata.
+var strippedPNGChunks = map[string]bool{"eXIf": true, "tEXt": true, "iTXt": true, "zTXt": true, "tIME": true}
+
+// ErrMalformedImage is returned when an image can't be parsed
+var ErrMalformedImage = fmt.Errorf("malformed image")
+
+// StripMetadata copies the image read from r into w leaving out the segments that
+// carry EXIF, GPS and other metadata. The pixels aren't decoded so it's cheap enough
+// to run on every upload. Data of formats other than JPEG and PNG is copied as is.
+func StripMetadata(w io.Writer, r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(pngMagic))
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+	switch {
+	case bytes.HasPrefix(magic, jpegMagic):
+		return stripJPEG(w, br)
+	case bytes.HasPrefix(magic, pngMagic):
+		return stripPNG(w, br)
+	}
+	_, err = io.Copy(w, br)
+	return err
+}
+
+// stripJPEG is just a helper function that copies the segments of a JPEG up to
+// the start of the scan, from which the entropy coded data follows, skipping
+// the ones that carry metadata.
+func stripJPEG(w io.Writer, r *bufio.Reader) error {
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(r, soi); err != nil {
+		return ErrMalformedImage
+	}
+	if _, err := w.Write(soi); err != nil {
+		return err
+	}
+	for {
+		marker := make([]byte, 2)
+		if _, err := io.ReadFull(r, marker); err != nil || marker[0] != 0xFF {
+			return ErrMalformedImage
+		}
+		// fill bytes may precede markers
+		for marker[1] == 0xFF {
+			b, err := r.ReadByte()
+			if err != nil {
+				return ErrMalformedImage
+			}
+			marker[1] = b
+		}
+		// markers without a payload
+		if marker[1] == 0x01 || (marker[1] >= 0xD0 && marker[1] <= 0xD7) {
+			if _, err := w.Write(marker); err != nil {
+				return err
+			}
+			continue
+		}
+		if marker[1] == 0xD9 {
+			_, err := w.Write(marker)
+			return err
+		}
+		lengthRaw := make([]byte, 2)
+		if _, err := io.ReadFull(r, lengthRaw); err != nil {
+			return ErrMalformedImage
+		}
+		length := int(binary.BigEndian.Uint16(lengthRaw))
+		if length < 2 {
+			return ErrMalformedImage
+		}
+		if strippedJPEGMarkers[marker[1]] {
+			if _, err := r.Discard(length - 2); err != nil {
+				return ErrMalformedImage
+			}
+			continue
+		}
+		if _, err := w.Write(marker); err != nil {
+			return err
+		}
+		if _, err := w.Write(lengthRaw); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, r, int64(length-2)); err != nil {
+			return ErrMalformedImage
+		}
+		if marker[1] == 0xDA {
+			// start of scan, the rest is image data
+			_, err := io.Copy(w, r)
+			return err
+		}
+	}
+}
+
+// stripPNG is just a helper function that copies the chunks of a PNG skipping
+// the ones that carry metadata.
+func stripPNG(w io.Writer, r *bufio.Reader) error {
+	signature := make([]byte, len(pngMagic))
+	if _, err := io.ReadFull(r, signature); err != nil {
+		return ErrMalformedImage
+	}
+	if _, err := w.Write(signature); err != nil {
+		return err
+	}
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return ErrMalformedImage
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		chunkType := string(header[4:])
+		// data followed by the crc
+		if strippedPNGChunks[chunkType] {
+			if _, err := io.CopyN(ioutil.Discard, r, length+4); err != nil {
+				return ErrMalformedImage
+			}
+			continue
+		}
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, r, length+4); err != nil {
+			return ErrMalformedImage
+		}
+		if chunkType == "IEND" {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Package maturity contains the maturity ratings and content warnings used to flag
+mature posts and releases along with the filters users hide them with */
+package maturity
+
+import "strings"
+
+// Rating holds enums that describe how mature a piece of content is
+type Rating string
+
+// Ratings from the least to the most mature
+const (
+	General  Rating = "general"
+	Teen     Rating = "teen"
+	Mature   Rating = "mature"
+	Explicit Rating = "explicit"
+)
+
+// ratings holds all the ratings ordered from the least to the most mature
+var ratings = []Rating{General, Teen, Mature, Explicit}
+
+// level returns the position of the rating in the ratings order, -1 if it isn't recognized.
+// Content without a rating is considered to be of general rating.
+func (r Rating) level() int {
+	if r == "" {
+		return 0
+	}
+	for i, rating := range ratings {
+		if rating == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Valid reports whether the rating is one of the recognized ratings
+func (r Rating) Valid() bool {
+	return r.level() != -1
+}
+
+// Filter describes the content a user wants to see. Content rated above
+// MaxRating or carrying any of the HiddenWarnings is filtered out.
+type Filter struct {
+	MaxRating      Rating   `json:"maxRating"`
+	HiddenWarnings []string `json:"hiddenWarnings"`
+}
+
+// DefaultFilter is the filter used for unauthenticated requests and users
+// that haven't set up their own.
+var DefaultFilter = Filter{MaxRating: Teen, HiddenWarnings: []string{}}
+
+// Valid reports whether the filter's max rating is recognized
+func (f Filter) Valid() bool {
+	return f.MaxRating != "" && f.MaxRating.Valid()
+}
+
+// AllowedRatings returns the ratings that pass the filter
+func (f Filter) AllowedRatings() []string {
+	allowed := make([]string, 0, len(ratings))
+	for _, rating := range ratings[:f.MaxRating.level()+1] {
+		allowed = append(allowed, string(rating))
+	}
+	return allowed
+}
+
+// Allows reports whether content of the given rating and warnings passes the filter
+func (f Filter) Allows(rating Rating, warnings []string) bool {
+	if rating.level() > f.MaxRating.level() {
+		return false
+	}
+	for _, warning := range NormalizeWarnings(warnings) {
+		for _, hidden := range f.HiddenWarnings {
+			if warning == hidden {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NormalizeWarnings lower cases and trims the given warnings dropping empty and repeated ones
+func NormalizeWarnings(warnings []string) []string {
+	normalized := make([]string, 0, len(warnings))
+	seen := make(map[string]bool, len(warnings))
+	for _, warning := range warnings {
+		warning = strings.ToLower(strings.TrimSpace(warning))
+		if warning == "" || seen[warning] {
+			continue
+		}
+		seen[warning] = true
+		normalized = append(normalized, warning)
+	}
+	return normalized
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+type localStore struct {
+	path    string
+	baseURL string
+}
+
+// NewLocalStore returns a Store that keeps blobs as files in the given directory.
+// The blobs are expected to be served under baseURL, by an http.FileServer for example.
+func NewLocalStore(path, baseURL string) Store {
+	return &localStore{path: path, baseURL: baseURL}
+}
+
+// Put writes the blob to a temporary file which is then renamed so that a half
+// written blob is never served.
+func (s *localStore) Put(name string, data io.Reader, contentType string) error {
+	if !validName(name) {
+		return ErrInvalidName
+	}
+	tmpFile, err := ioutil.TempFile(s.path, ".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = io.Copy(tmpFile, data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), filepath.Join(s.path, name))
+}
+
+// Get opens the blob of the given name.
+func (s *localStore) Get(name string) (io.ReadCloser, error) {
+	if !validName(name) {
+		return nil, ErrInvalidName
+	}
+	file, err := os.Open(filepath.Join(s.path, name))
+	if os.IsNotExist(err) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+// Exists checks whether a blob of the given name is stored.
+func (s *localStore) Exists(name string) (bool, error) {
+	if !validName(name) {
+		return false, ErrInvalidName
+	}
+	_, err := os.Stat(filepath.Join(s.path, name))
+	switch {
+	case err == nil:
+		return true, nil
+	case os.IsNotExist(err):
+		return false, nil
+	}
+	return false, err
+}
+
+// Delete removes the blob of the given name.
+func (s *localStore) Delete(name string) error {
+	if !validName(name) {
+		return ErrInvalidName
+	}
+	err := os.Remove(filepath.Join(s.path, name))
+	if os.IsNotExist(err) {
+		return ErrBlobNotFound
+	}
+	return err
+}
+
+// URL returns the address the blob is served under.
+func (s *localStore) URL(name string) (string, error) {
+	if !validName(name) {
+		return "", ErrInvalidName
+	}
+	return s.baseURL + url.PathEscape(name), nil
+}
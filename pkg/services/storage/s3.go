@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config holds the settings of an S3 compatible object store.
+// Endpoint is the scheme and host of the service, e.g. https://s3.eu-west-1.amazonaws.com
+// or http://localhost:9000 for a local MinIO. PathStyle addresses the bucket in the path
+// instead of the host, which is what most self hosted implementations expect.
+// If URLExpiry is set, clients are handed signed URLs valid for that long
+// instead of plain ones which require a publicly readable bucket.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+	URLExpiry time.Duration
+	Client    *http.Client
+}
+
+type s3Store struct {
+	S3Config
+	endpoint *url.URL
+}
+
+// NewS3Store returns a Store that keeps blobs as objects of an S3 compatible bucket.
+// Requests are signed using AWS Signature Version 4.
+func NewS3Store(config S3Config) (Store, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &s3Store{S3Config: config, endpoint: endpoint}, nil
+}
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3AmzDateFormat   = "20060102T150405Z"
+	s3ShortDateFormat = "20060102"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// objectURL is just a helper function that returns the url of the object of the given name
+func (s *s3Store) objectURL(name string) *url.URL {
+	u := *s.endpoint
+	if s.PathStyle {
+		u.Path = "/" + s.Bucket + "/" + name
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = "/" + name
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+// do is just a helper function that signs and sends a request for the object of the given name.
+// Bodies, of the given size, are left unsigned so that they're streamed instead of being
+// read into memory to be hashed.
+func (s *s3Store) do(method, name string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	if !validName(name) {
+		return nil, ErrInvalidName
+	}
+	u := s.objectURL(name)
+	emptyHash := sha256.Sum256(nil)
+	payloadHash := hex.EncodeToString(emptyHash[:])
+	if body != nil {
+		// the client isn't to close the body, it's owned by the caller
+		body = ioutil.NopCloser(body)
+		payloadHash = s3UnsignedPayload
+	}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	now := time.Now().UTC()
+	req.Header.Set("x-amz-date", now.Format(s3AmzDateFormat))
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signedHeaders = []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	}
+	canonicalHeaders := ""
+	for _, h := range signedHeaders {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = u.Host
+		}
+		canonicalHeaders += h + ":" + strings.TrimSpace(value) + "\n"
+	}
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.RawPath,
+		"",
+		canonicalHeaders,
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+	scope := s.scope(now)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.AccessKey, scope, strings.Join(signedHeaders, ";"), s.sign(now, scope, canonicalRequest)))
+	return s.Client.Do(req)
+}
+
+// scope is just a helper function that returns the credential scope of requests signed at the given time
+func (s *s3Store) scope(t time.Time) string {
+	return t.Format(s3ShortDateFormat) + "/" + s.Region + "/s3/aws4_request"
+}
+
+// sign is just a helper function that returns the signature of a canonical request
+func (s *s3Store) sign(t time.Time, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format(s3AmzDateFormat),
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), t.Format(s3ShortDateFormat))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode is just a helper function that percent encodes everything but the unreserved
+// characters as required by the signature, slashes are kept unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// errorFromResponse is just a helper function that turns unsuccessful responses into errors
+func errorFromResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrBlobNotFound
+	}
+	message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 request failed with status %d: %s", resp.StatusCode, message)
+}
+
+// Put uploads the blob streaming it from data. S3 needs to know the size of uploads ahead
+// so blobs are only read into memory first if data can't tell its size nor seek.
+func (s *s3Store) Put(name string, data io.Reader, contentType string) error {
+	size, ok := readerSize(data)
+	if !ok {
+		body, err := ioutil.ReadAll(data)
+		if err != nil {
+			return err
+		}
+		data, size = bytes.NewReader(body), int64(len(body))
+	}
+	header := make(http.Header)
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(http.MethodPut, name, data, size, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errorFromResponse(resp)
+	}
+	return nil
+}
+
+// readerSize is just a helper function that tells how much is left to be read from r
+// without reading it, if r can tell its length or seek
+func readerSize(r io.Reader) (int64, bool) {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len()), true
+	case io.Seeker:
+		current, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err = r.Seek(current, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - current, true
+	}
+	return 0, false
+}
+
+// Get downloads the blob of the given name. The returned body must be closed.
+func (s *s3Store) Get(name string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, name, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, errorFromResponse(resp)
+	}
+	return resp.Body, nil
+}
+
+// Exists checks whether an object of the given name is in the bucket.
+func (s *s3Store) Exists(name string) (bool, error) {
+	resp, err := s.do(http.MethodHead, name, nil, 0, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, errorFromResponse(resp)
+}
+
+// Delete removes the object of the given name. S3 doesn't report whether
+// the object existed so ErrBlobNotFound is never returned.
+func (s *s3Store) Delete(name string) error {
+	resp, err := s.do(http.MethodDelete, name, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return errorFromResponse(resp)
+	}
+	return nil
+}
+
+// URL returns the address of the object, presigned if URLExpiry is set.
+func (s *s3Store) URL(name string) (string, error) {
+	if !validName(name) {
+		return "", ErrInvalidName
+	}
+	u := s.objectURL(name)
+	if s.URLExpiry <= 0 {
+		return u.String(), nil
+	}
+	// signatures are made for the start of the minute so that the urls of a
+	// blob stay the same for a while and can be cached by clients
+	now := time.Now().UTC().Truncate(time.Minute)
+	scope := s.scope(now)
+	query := map[string]string{
+		"X-Amz-Algorithm":     s3Algorithm,
+		"X-Amz-Credential":    s.AccessKey + "/" + scope,
+		"X-Amz-Date":          now.Format(s3AmzDateFormat),
+		"X-Amz-Expires":       fmt.Sprint(int64(s.URLExpiry / time.Second)),
+		"X-Amz-SignedHeaders": "host",
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(query[key], true))
+	}
+	canonicalQuery := strings.Join(pairs, "&")
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.RawPath,
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.sign(now, scope, canonicalRequest)
+	return u.String(), nil
+}
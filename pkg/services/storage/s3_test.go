@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testBucket    = "images"
+	testRegion    = "eu-west-1"
+	testAccessKey = "access"
+	testSecretKey = "secret"
+)
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+// fakeS3 is a stand-in for an S3 compatible service that keeps objects in memory
+// and rejects requests that aren't signed with the test credentials.
+type fakeS3 struct {
+	pathStyle bool
+
+	mutex   sync.Mutex
+	objects map[string]fakeObject
+	// uploads holds the headers the objects were uploaded with
+	uploads []http.Header
+}
+
+func newFakeS3(t *testing.T, pathStyle bool) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{pathStyle: pathStyle, objects: make(map[string]fakeObject)}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func hmacOf(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// signature is just a helper function that signs the canonical request the way the service does
+func signature(date, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + date + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := hmacOf([]byte("AWS4"+testSecretKey), date[:8])
+	key = hmacOf(key, testRegion)
+	key = hmacOf(key, "s3")
+	key = hmacOf(key, "aws4_request")
+	return hex.EncodeToString(hmacOf(key, stringToSign))
+}
+
+// authorized is just a helper function that checks the signature of header or query signed requests
+func (f *fakeS3) authorized(r *http.Request, path string) bool {
+	query := r.URL.Query()
+	if presigned := query.Get("X-Amz-Signature"); presigned != "" {
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			if key != "X-Amz-Signature" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(query.Get(key)))
+		}
+		credential := strings.SplitN(query.Get("X-Amz-Credential"), "/", 2)
+		if len(credential) != 2 || credential[0] != testAccessKey {
+			return false
+		}
+		canonicalRequest := strings.Join([]string{
+			r.Method, path, strings.Join(pairs, "&"), "host:" + r.Host + "\n", "host", "UNSIGNED-PAYLOAD",
+		}, "\n")
+		return presigned == signature(query.Get("X-Amz-Date"), credential[1], canonicalRequest)
+	}
+
+	fields := make(map[string]string)
+	auth := strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	for _, field := range strings.Split(auth, ", ") {
+		if parts := strings.SplitN(field, "=", 2); len(parts) == 2 {
+			fields[parts[0]] = parts[1]
+		}
+	}
+	credential := strings.SplitN(fields["Credential"], "/", 2)
+	if len(credential) != 2 || credential[0] != testAccessKey {
+		return false
+	}
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	canonicalHeaders := ""
+	for _, h := range signedHeaders {
+		value := r.Header.Get(h)
+		if h == "host" {
+			value = r.Host
+		}
+		canonicalHeaders += h + ":" + value + "\n"
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method, path, "", canonicalHeaders, fields["SignedHeaders"], r.Header.Get("x-amz-content-sha256"),
+	}, "\n")
+	return fields["Signature"] == signature(r.Header.Get("x-amz-date"), credential[1], canonicalRequest)
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.SplitN(r.RequestURI, "?", 2)[0]
+	key, err := url.PathUnescape(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if f.pathStyle {
+		if !strings.HasPrefix(key, testBucket+"/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		key = strings.TrimPrefix(key, testBucket+"/")
+	} else if !strings.HasPrefix(r.Host, testBucket+".") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !f.authorized(r, path) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "SignatureDoesNotMatch")
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		header := r.Header.Clone()
+		if len(r.TransferEncoding) > 0 {
+			header.Set("Transfer-Encoding", strings.Join(r.TransferEncoding, ","))
+		}
+		f.uploads = append(f.uploads, header)
+		f.objects[key] = fakeObject{data, r.Header.Get("Content-Type")}
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Write(object.data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestS3Store(t *testing.T, endpoint string, pathStyle bool, secretKey string) Store {
+	store, err := NewS3Store(S3Config{
+		Endpoint:  endpoint,
+		Region:    testRegion,
+		Bucket:    testBucket,
+		AccessKey: testAccessKey,
+		SecretKey: secretKey,
+		PathStyle: pathStyle,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store failed: %v", err)
+	}
+	return store
+}
+
+func TestS3Store(t *testing.T) {
+	fake, server := newFakeS3(t, true)
+	store := newTestS3Store(t, server.URL, true, testSecretKey)
+	name := "a b+c.png"
+
+	if err := store.Put(name, bytes.NewReader([]byte("image")), "image/png"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if object := fake.objects[name]; string(object.data) != "image" || object.contentType != "image/png" {
+		t.Errorf("bucket holds %q of type %q, want %q of type %q", object.data, object.contentType, "image", "image/png")
+	}
+	if exists, err := store.Exists(name); err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+	body, err := store.Get(name)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	data, _ := ioutil.ReadAll(body)
+	body.Close()
+	if string(data) != "image" {
+		t.Errorf("Get() = %q, want %q", data, "image")
+	}
+
+	if err := store.Delete(name); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if exists, err := store.Exists(name); err != nil || exists {
+		t.Errorf("Exists() after Delete = %v, %v, want false", exists, err)
+	}
+	if _, err := store.Get(name); err != ErrBlobNotFound {
+		t.Errorf("Get() after Delete = %v, want %v", err, ErrBlobNotFound)
+	}
+}
+
+// seekOnly hides everything but reading and seeking of the wrapped reader
+type seekOnly struct {
+	io.ReadSeeker
+}
+
+func TestS3StorePutStreams(t *testing.T) {
+	fake, server := newFakeS3(t, true)
+	store := newTestS3Store(t, server.URL, true, testSecretKey)
+
+	// readers that can seek are sent from where they are without being buffered
+	reader := seekOnly{bytes.NewReader([]byte("skipped content"))}
+	reader.Seek(int64(len("skipped ")), io.SeekStart)
+	if err := store.Put("seeker", reader, ""); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	// and the rest are read whole first
+	if err := store.Put("plain", io.MultiReader(strings.NewReader("plain "), strings.NewReader("content")), ""); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Put("empty", bytes.NewReader(nil), ""); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	for name, want := range map[string]string{"seeker": "content", "plain": "plain content", "empty": ""} {
+		if got := string(fake.objects[name].data); got != want {
+			t.Errorf("bucket holds %q under %s, want %q", got, name, want)
+		}
+	}
+	for _, header := range fake.uploads {
+		if header.Get("x-amz-content-sha256") != "UNSIGNED-PAYLOAD" {
+			t.Errorf("upload was sent with payload hash %q, want it unsigned", header.Get("x-amz-content-sha256"))
+		}
+		if header.Get("Transfer-Encoding") != "" {
+			t.Errorf("upload was sent with %s transfer encoding, want its length", header.Get("Transfer-Encoding"))
+		}
+	}
+}
+
+func TestS3StoreVirtualHosted(t *testing.T) {
+	fake, server := newFakeS3(t, false)
+	store, err := NewS3Store(S3Config{
+		Endpoint:  strings.Replace(server.URL, "127.0.0.1", "s3.test", 1),
+		Region:    testRegion,
+		Bucket:    testBucket,
+		AccessKey: testAccessKey,
+		SecretKey: testSecretKey,
+		Client: &http.Client{Transport: &http.Transport{
+			// every host is served by the stand-in
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return new(net.Dialer).DialContext(ctx, network, server.Listener.Addr().String())
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store failed: %v", err)
+	}
+	if err := store.Put("blob", strings.NewReader("data"), ""); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if string(fake.objects["blob"].data) != "data" {
+		t.Errorf("bucket holds %q, want %q", fake.objects["blob"].data, "data")
+	}
+}
+
+func TestS3StoreRejectedSignature(t *testing.T) {
+	_, server := newFakeS3(t, true)
+	store := newTestS3Store(t, server.URL, true, "wrong secret")
+	err := store.Put("blob", strings.NewReader("data"), "")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put() with a wrong secret = %v, want a 403 error", err)
+	}
+}
+
+func TestS3StoreInvalidName(t *testing.T) {
+	_, server := newFakeS3(t, true)
+	store := newTestS3Store(t, server.URL, true, testSecretKey)
+	for _, name := range []string{"", "..", "../escape", "a/b"} {
+		if err := store.Put(name, strings.NewReader("data"), ""); err != ErrInvalidName {
+			t.Errorf("Put(%q) = %v, want %v", name, err, ErrInvalidName)
+		}
+	}
+}
+
+func TestS3StorePresignedURL(t *testing.T) {
+	fake, server := newFakeS3(t, true)
+	fake.objects["blob"] = fakeObject{data: []byte("data")}
+	store, err := NewS3Store(S3Config{
+		Endpoint:  server.URL,
+		Region:    testRegion,
+		Bucket:    testBucket,
+		AccessKey: testAccessKey,
+		SecretKey: testSecretKey,
+		PathStyle: true,
+		URLExpiry: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store failed: %v", err)
+	}
+	link, err := store.URL("blob")
+	if err != nil {
+		t.Fatalf("URL failed: %v", err)
+	}
+	resp, err := http.Get(link)
+	if err != nil {
+		t.Fatalf("fetching of presigned url failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("fetching of presigned url %s returned status %d, want %d", link, resp.StatusCode, http.StatusOK)
+	}
+
+	plain := newTestS3Store(t, server.URL, true, testSecretKey)
+	if link, _ := plain.URL("blob"); link != server.URL+"/"+testBucket+"/blob" {
+		t.Errorf("URL() without expiry = %s, want %s", link, server.URL+"/"+testBucket+"/blob")
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Package storage contains definition and implementations of the blob stores uploaded
+files are kept in: the local disk and S3 compatible object stores.*/
+package storage
+
+import (
+	"fmt"
+	"io"
+)
+
+// Store specifies a method to keep and serve named blobs.
+type Store interface {
+	Put(name string, data io.Reader, contentType string) error
+	Get(name string) (io.ReadCloser, error)
+	Exists(name string) (bool, error)
+	Delete(name string) error
+	// URL returns the address clients can fetch the blob from
+	URL(name string) (string, error)
+}
+
+// ErrBlobNotFound is returned when the requested blob isn't found
+var ErrBlobNotFound = fmt.Errorf("blob not found")
+
+// ErrInvalidName is returned when a blob name would escape the store
+var ErrInvalidName = fmt.Errorf("invalid blob name")
+
+// validName is just a helper function that checks whether a name is usable as is
+// both as a file name and an object key
+func validName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	for _, r := range name {
+		if r == '/' || r == '\\' || r < 0x20 {
+			return false
+		}
+	}
+	return true
+}
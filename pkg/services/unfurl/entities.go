@@ -0,0 +1,24 @@
+package unfurl
+
+import "time"
+
+// Preview is the card rendered for a link found in a post.
+type Preview struct {
+	URL         string    `json:"url"`
+	Type        Type      `json:"type"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	ImageURL    string    `json:"imageURL,omitempty"`
+	SiteName    string    `json:"siteName,omitempty"`
+	FetchTime   time.Time `json:"fetchTime"`
+}
+
+// Type holds enums that specify where the metadata of a Preview was taken from
+type Type string
+
+// Type constants
+const (
+	TypeOpenGraph Type = "opengraph"
+	TypeOEmbed    Type = "oembed"
+	TypeHTML      Type = "html"
+)
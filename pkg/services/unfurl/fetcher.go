@@ -0,0 +1,293 @@
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// blockedNetworks holds the address ranges links aren't allowed to resolve to so that
+// previews can't be used to probe the network the server runs in.
+var blockedNetworks = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",      // current network
+		"10.0.0.0/8",     // private
+		"100.64.0.0/10",  // carrier grade nat
+		"127.0.0.0/8",    // loopback
+		"169.254.0.0/16", // link local, cloud metadata services
+		"172.16.0.0/12",  // private
+		"192.0.0.0/24",   // ietf protocol assignments
+		"192.168.0.0/16", // private
+		"198.18.0.0/15",  // benchmarking
+		"224.0.0.0/4",    // multicast
+		"240.0.0.0/4",    // reserved
+		"::/128",         // unspecified
+		"::1/128",        // loopback
+		"64:ff9b::/96",   // nat64
+		"fc00::/7",       // unique local
+		"fe80::/10",      // link local
+		"ff00::/8",       // multicast
+	}
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// IsBlockedIP reports whether links resolving to the given address shouldn't be fetched.
+func IsBlockedIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxRedirects is the number of redirects followed when fetching a link
+const maxRedirects = 3
+
+var (
+	metaPattern  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	linkPattern  = regexp.MustCompile(`(?is)<link\s[^>]*>`)
+	titlePattern = regexp.MustCompile(`(?is)<title[^>]*>([^<]*)</title>`)
+	attrPattern  = regexp.MustCompile(`(?s)([\w:-]+)\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+)
+
+type httpFetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// NewHTTPFetcher returns a Fetcher that reads the OpenGraph or oEmbed metadata of links over http.
+// Only links on the standard ports that resolve to public addresses are fetched, the address
+// being checked on every connection including ones made for redirects. Requests are cut off after
+// the given timeout and no more than maxBytes of any response are read.
+func NewHTTPFetcher(timeout time.Duration, maxBytes int64) Fetcher {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if port != "80" && port != "443" {
+				return ErrBlockedAddress
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || IsBlockedIP(ip) {
+				return ErrBlockedAddress
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		// proxies are ignored as they'd be the ones resolving the links
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       time.Minute,
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return checkURL(req.URL)
+		},
+	}
+	return &httpFetcher{client, maxBytes}
+}
+
+// checkURL is just a helper function that makes sure only web links are fetched
+func checkURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// get is just a helper function that reads at most maxBytes of the response to a GET
+// request on the given url along with the url the response came from.
+func (f *httpFetcher) get(rawURL, accept string) ([]byte, *url.URL, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, "", ErrInvalidURL
+	}
+	if err := checkURL(u); err != nil {
+		return nil, nil, "", err
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, "", ErrInvalidURL
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "issue#1 link preview")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("fetching of %s failed because of: %v", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, "", fmt.Errorf("fetching of %s failed with status %d", u, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes))
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("reading of %s failed because of: %v", u, err)
+	}
+	return body, resp.Request.URL, resp.Header.Get("Content-Type"), nil
+}
+
+// Fetch builds a preview of the link from its OpenGraph tags, falling back to its
+// oEmbed endpoint and finally to its title and description.
+func (f *httpFetcher) Fetch(rawURL string) (*Preview, error) {
+	body, base, contentType, err := f.get(rawURL, "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(contentType, "html") {
+		return nil, ErrUnsupportedContent
+	}
+	page := string(body)
+
+	meta := make(map[string]string)
+	for _, tag := range metaPattern.FindAllString(page, -1) {
+		attrs := parseAttributes(tag)
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		if key = strings.ToLower(key); key != "" && meta[key] == "" {
+			meta[key] = attrs["content"]
+		}
+	}
+
+	if meta["og:title"] != "" {
+		return &Preview{
+			Type:        TypeOpenGraph,
+			Title:       meta["og:title"],
+			Description: meta["og:description"],
+			ImageURL:    resolveURL(base, meta["og:image"]),
+			SiteName:    meta["og:site_name"],
+		}, nil
+	}
+
+	for _, tag := range linkPattern.FindAllString(page, -1) {
+		attrs := parseAttributes(tag)
+		if strings.EqualFold(attrs["type"], "application/json+oembed") {
+			if p, err := f.fetchOEmbed(resolveURL(base, attrs["href"])); err == nil {
+				return p, nil
+			}
+			break
+		}
+	}
+
+	p := &Preview{
+		Type:        TypeHTML,
+		Description: meta["description"],
+		SiteName:    base.Hostname(),
+	}
+	if match := titlePattern.FindStringSubmatch(page); match != nil {
+		p.Title = strings.TrimSpace(html.UnescapeString(match[1]))
+	}
+	if p.Title == "" {
+		return nil, ErrPreviewNotFound
+	}
+	return p, nil
+}
+
+// fetchOEmbed is just a helper function that builds a preview from an oEmbed endpoint
+func (f *httpFetcher) fetchOEmbed(endpoint string) (*Preview, error) {
+	if endpoint == "" {
+		return nil, ErrInvalidURL
+	}
+	body, base, _, err := f.get(endpoint, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	oembed := struct {
+		Title        string `json:"title"`
+		AuthorName   string `json:"author_name"`
+		ProviderName string `json:"provider_name"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}{}
+	if err := json.Unmarshal(body, &oembed); err != nil {
+		return nil, fmt.Errorf("parsing of oembed response failed because of: %v", err)
+	}
+	if oembed.Title == "" {
+		return nil, ErrPreviewNotFound
+	}
+	return &Preview{
+		Type:        TypeOEmbed,
+		Title:       oembed.Title,
+		Description: oembed.AuthorName,
+		ImageURL:    resolveURL(base, oembed.ThumbnailURL),
+		SiteName:    oembed.ProviderName,
+	}, nil
+}
+
+// parseAttributes is just a helper function that reads the attributes of an html tag
+func parseAttributes(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range attrPattern.FindAllStringSubmatch(tag, -1) {
+		value := strings.Trim(match[2], `"'`)
+		attrs[strings.ToLower(match[1])] = strings.TrimSpace(html.UnescapeString(value))
+	}
+	return attrs
+}
+
+// resolveURL is just a helper function that resolves ref against base,
+// returning an empty string for anything that isn't a web link
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || checkURL(u) != nil {
+		return ""
+	}
+	return u.String()
+}
+
+type stubFetcher struct {
+	previews map[string]*Preview
+}
+
+// NewStubFetcher returns a Fetcher that serves the given previews without touching
+// the network, for use in tests and local setups. Unknown links aren't found.
+func NewStubFetcher(previews map[string]*Preview) Fetcher {
+	return &stubFetcher{previews}
+}
+
+// Fetch returns a copy of the preview registered under the link.
+func (f *stubFetcher) Fetch(rawURL string) (*Preview, error) {
+	p, ok := f.previews[rawURL]
+	if !ok {
+		return nil, ErrPreviewNotFound
+	}
+	preview := *p
+	return &preview, nil
+}
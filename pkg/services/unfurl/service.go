@@ -0,0 +1,188 @@
+/*
+Package unfurl contains definition and implementation of a service that builds preview
+cards for the links found in posts.*/
+package unfurl
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Service specifies a method to provide link previews.
+type Service interface {
+	GetPreviews(text string) ([]*Preview, error)
+}
+
+// Repository specifies a repo interface to serve the unfurl.Service interface
+type Repository interface {
+	GetPreview(url string) (*Preview, error)
+	AddPreview(p *Preview) error
+}
+
+// Fetcher specifies a method to fetch the metadata of a link.
+type Fetcher interface {
+	Fetch(url string) (*Preview, error)
+}
+
+// ErrPreviewNotFound is returned when the requested preview isn't found
+var ErrPreviewNotFound = fmt.Errorf("preview not found")
+
+// ErrInvalidURL is returned when a link can't be unfurled
+var ErrInvalidURL = fmt.Errorf("invalid url")
+
+// ErrBlockedAddress is returned when a link resolves to a private, loopback or otherwise internal address
+var ErrBlockedAddress = fmt.Errorf("blocked address")
+
+// ErrUnsupportedContent is returned when a link doesn't point to an html page
+var ErrUnsupportedContent = fmt.Errorf("unsupported content")
+
+// MaxLinks is the number of links of a single text that are unfurled
+const MaxLinks = 3
+
+// maxFailedLinks is the number of failed links that are remembered so as not to be retried
+const maxFailedLinks = 10000
+
+// urlPattern matches the links, either in anchors or in plain text, of a sanitized post description
+var urlPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+type service struct {
+	repo    *Repository
+	fetcher Fetcher
+	ttl     time.Duration
+
+	links chan string
+
+	mutex    sync.Mutex
+	inflight map[string]bool
+	failed   map[string]time.Time
+}
+
+// NewService returns a struct that implements the unfurl.Service interface.
+// Previews are refetched once they're older than ttl and links that fail to unfurl
+// aren't retried for the same duration. Links are fetched in the background by the
+// given number of workers and up to queueSize links can wait for a worker.
+func NewService(repo *Repository, fetcher Fetcher, ttl time.Duration, workers, queueSize int) Service {
+	s := &service{
+		repo:     repo,
+		fetcher:  fetcher,
+		ttl:      ttl,
+		links:    make(chan string, queueSize),
+		inflight: make(map[string]bool),
+		failed:   make(map[string]time.Time),
+	}
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+// ExtractURLs returns the distinct http links found in the given text in the order they appear.
+func ExtractURLs(text string) []string {
+	urls := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range urlPattern.FindAllString(html.UnescapeString(text), -1) {
+		match = strings.TrimRight(match, ".,;:!?)")
+		u, err := url.Parse(match)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		u.Fragment = ""
+		link := u.String()
+		if !seen[link] {
+			seen[link] = true
+			urls = append(urls, link)
+		}
+	}
+	return urls
+}
+
+// GetPreviews returns the cached previews of the links found in the given text.
+// Links without a fresh preview are unfurled in the background so as not to hold up
+// the caller and will be included in subsequent calls.
+func (s *service) GetPreviews(text string) ([]*Preview, error) {
+	previews := make([]*Preview, 0)
+	urls := ExtractURLs(text)
+	if len(urls) > MaxLinks {
+		urls = urls[:MaxLinks]
+	}
+	for _, link := range urls {
+		p, err := (*s.repo).GetPreview(link)
+		switch err {
+		case nil:
+			previews = append(previews, p)
+			if time.Since(p.FetchTime) > s.ttl {
+				s.unfurl(link)
+			}
+		case ErrPreviewNotFound:
+			s.unfurl(link)
+		default:
+			return nil, err
+		}
+	}
+	return previews, nil
+}
+
+// unfurl queues the given link to be fetched and cached in the background unless
+// it's already being fetched or has recently failed. Links are dropped if the queue
+// is full, they're queued again by later calls.
+func (s *service) unfurl(link string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.inflight[link] {
+		return
+	}
+	if failedAt, ok := s.failed[link]; ok {
+		if time.Since(failedAt) < s.ttl {
+			return
+		}
+		delete(s.failed, link)
+	}
+	select {
+	case s.links <- link:
+		s.inflight[link] = true
+	default:
+	}
+}
+
+// work is just a helper function that fetches queued links until the service is dropped
+func (s *service) work() {
+	for link := range s.links {
+		p, err := s.fetcher.Fetch(link)
+		if err == nil {
+			p.URL = link
+			p.FetchTime = time.Now()
+			err = (*s.repo).AddPreview(p)
+		}
+		s.mutex.Lock()
+		delete(s.inflight, link)
+		if err != nil {
+			if len(s.failed) >= maxFailedLinks {
+				s.forgetFailures()
+			}
+			s.failed[link] = time.Now()
+		}
+		s.mutex.Unlock()
+	}
+}
+
+// forgetFailures is just a helper function that makes room for more failed links. Expired
+// failures are forgotten first and arbitrary ones after that if they aren't enough.
+// It's called with the mutex held.
+func (s *service) forgetFailures() {
+	for link, failedAt := range s.failed {
+		if time.Since(failedAt) >= s.ttl {
+			delete(s.failed, link)
+		}
+	}
+	for link := range s.failed {
+		if len(s.failed) < maxFailedLinks/2 {
+			break
+		}
+		delete(s.failed, link)
+	}
+}
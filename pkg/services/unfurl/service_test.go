@@ -0,0 +1,159 @@
+package unfurl
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubRepository keeps previews in memory
+type stubRepository struct {
+	mutex    sync.Mutex
+	previews map[string]*Preview
+}
+
+func (repo *stubRepository) GetPreview(url string) (*Preview, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	p, ok := repo.previews[url]
+	if !ok {
+		return nil, ErrPreviewNotFound
+	}
+	preview := *p
+	return &preview, nil
+}
+
+func (repo *stubRepository) AddPreview(p *Preview) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	repo.previews[p.URL] = p
+	return nil
+}
+
+// countingFetcher counts the fetches made through the wrapped Fetcher
+type countingFetcher struct {
+	Fetcher
+	mutex   sync.Mutex
+	fetches map[string]int
+}
+
+func (f *countingFetcher) Fetch(url string) (*Preview, error) {
+	f.mutex.Lock()
+	f.fetches[url]++
+	f.mutex.Unlock()
+	return f.Fetcher.Fetch(url)
+}
+
+func (f *countingFetcher) count(url string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.fetches[url]
+}
+
+func newTestService(previews map[string]*Preview) (Service, *countingFetcher) {
+	var repo Repository = &stubRepository{previews: make(map[string]*Preview)}
+	fetcher := &countingFetcher{Fetcher: NewStubFetcher(previews), fetches: make(map[string]int)}
+	return NewService(&repo, fetcher, time.Hour, 2, 16), fetcher
+}
+
+// waitFor is just a helper function that waits for the background fetches to catch up
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition wasn't met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestExtractURLs(t *testing.T) {
+	text := `<p>see <a href="https://example.com/a">this</a>, https://example.com/a and
+	http://example.org/b?c=d&amp;e=f#top. not ftp://example.net</p>`
+	want := []string{"https://example.com/a", "http://example.org/b?c=d&e=f"}
+	if got := ExtractURLs(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractURLs() = %v, want %v", got, want)
+	}
+}
+
+func TestGetPreviews(t *testing.T) {
+	link := "https://example.com/post"
+	s, fetcher := newTestService(map[string]*Preview{
+		link: {Type: TypeOpenGraph, Title: "A post"},
+	})
+
+	previews, err := s.GetPreviews("read " + link)
+	if err != nil {
+		t.Fatalf("GetPreviews failed: %v", err)
+	}
+	if len(previews) != 0 {
+		t.Errorf("GetPreviews returned %d previews before fetching, want 0", len(previews))
+	}
+	waitFor(t, func() bool {
+		previews, err = s.GetPreviews("read " + link)
+		return err == nil && len(previews) == 1
+	})
+	if p := previews[0]; p.URL != link || p.Title != "A post" || p.FetchTime.IsZero() {
+		t.Errorf("GetPreviews returned %+v", p)
+	}
+	if n := fetcher.count(link); n != 1 {
+		t.Errorf("fresh preview was fetched %d times, want 1", n)
+	}
+}
+
+func TestGetPreviewsLimitsLinks(t *testing.T) {
+	s, fetcher := newTestService(map[string]*Preview{})
+	text := ""
+	links := []string{"https://a.example", "https://b.example", "https://c.example", "https://d.example"}
+	for _, link := range links {
+		text += link + " "
+	}
+	s.GetPreviews(text)
+	waitFor(t, func() bool { return fetcher.count(links[MaxLinks-1]) == 1 })
+	if n := fetcher.count(links[MaxLinks]); n != 0 {
+		t.Errorf("link past the limit was fetched %d times, want 0", n)
+	}
+}
+
+func TestFailedLinksArentRetried(t *testing.T) {
+	link := "https://example.com/missing"
+	s, fetcher := newTestService(map[string]*Preview{})
+	s.GetPreviews(link)
+	waitFor(t, func() bool {
+		impl := s.(*service)
+		impl.mutex.Lock()
+		defer impl.mutex.Unlock()
+		_, failed := impl.failed[link]
+		return failed
+	})
+	s.GetPreviews(link)
+	time.Sleep(50 * time.Millisecond)
+	if n := fetcher.count(link); n != 1 {
+		t.Errorf("failed link was fetched %d times within the ttl, want 1", n)
+	}
+}
+
+func TestForgetFailures(t *testing.T) {
+	var repo Repository = &stubRepository{previews: make(map[string]*Preview)}
+	s := &service{repo: &repo, ttl: time.Hour, failed: make(map[string]time.Time)}
+	for i := 0; i < maxFailedLinks; i++ {
+		s.failed[string(rune(i))] = time.Now()
+	}
+	s.forgetFailures()
+	if n := len(s.failed); n >= maxFailedLinks/2 {
+		t.Errorf("%d failures are remembered after making room, want fewer than %d", n, maxFailedLinks/2)
+	}
+}
+
+func TestFullQueueDropsLinks(t *testing.T) {
+	var repo Repository = &stubRepository{previews: make(map[string]*Preview)}
+	// no workers so that the queue fills up
+	s := NewService(&repo, NewStubFetcher(nil), time.Hour, 0, 1).(*service)
+	s.unfurl("https://a.example")
+	s.unfurl("https://b.example")
+	if !s.inflight["https://a.example"] || s.inflight["https://b.example"] {
+		t.Errorf("inflight = %v, want only the first link queued", s.inflight)
+	}
+}
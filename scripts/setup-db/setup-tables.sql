@@ -239,7 +239,11 @@ ALTER TABLE "issue#1".channel_pictures OWNER TO "issue#1_dev";
 --
 
 CREATE TABLE "issue#1".channel_stickies (
-    post_id integer
+    channel_username character varying(24) NOT NULL,
+    post_id integer NOT NULL,
+    "position" integer DEFAULT 0 NOT NULL,
+    expires_at timestamp with time zone,
+    creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
 );
 
 
@@ -253,7 +257,10 @@ CREATE TABLE "issue#1".channels (
                                     username character varying(24) NOT NULL,
                                     creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
                                     name character varying(80) NOT NULL,
-                                    description text
+                                    description text,
+                                    private boolean DEFAULT false NOT NULL,
+                                    accent_color character varying(7),
+                                    secondary_color character varying(7)
 );
 
 
@@ -359,7 +366,12 @@ CREATE TABLE "issue#1".posts (
                                  title character varying(256) NOT NULL,
                                  posted_by character varying(22) NOT NULL,
                                  channel_from character varying(22) NOT NULL,
-                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+                                 status text DEFAULT 'published'::text NOT NULL,
+                                 rating text DEFAULT 'general'::text NOT NULL,
+                                 warnings text[] DEFAULT '{}'::text[] NOT NULL,
+                                 CONSTRAINT posts_rating_check CHECK ((rating = ANY (ARRAY['general'::text, 'teen'::text, 'mature'::text, 'explicit'::text]))),
+                                 CONSTRAINT posts_status_check CHECK ((status = ANY (ARRAY['draft'::text, 'in-review'::text, 'approved'::text, 'published'::text])))
 );
 
 
@@ -394,7 +406,8 @@ ALTER SEQUENCE "issue#1".post_id_seq OWNED BY "issue#1".posts.id;
 CREATE TABLE "issue#1".post_stars (
                                       star_count integer NOT NULL,
                                       post_id integer NOT NULL,
-                                      username character varying(22) NOT NULL
+                                      username character varying(22) NOT NULL,
+                                      star_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
 );
 
 
@@ -410,7 +423,11 @@ CREATE TABLE "issue#1".release_metadata (
                                             other jsonb,
                                             genre_defining text,
                                             release_date timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
-                                            title text
+                                            title text,
+                                            rating text DEFAULT 'general'::text NOT NULL,
+                                            warnings text[] DEFAULT '{}'::text[] NOT NULL,
+                                            language text,
+                                            CONSTRAINT release_metadata_rating_check CHECK ((rating = ANY (ARRAY['general'::text, 'teen'::text, 'mature'::text, 'explicit'::text])))
 );
 
 
@@ -448,7 +465,8 @@ ALTER TABLE "issue#1".releases_image_based OWNER TO "issue#1_dev";
 
 CREATE TABLE "issue#1".releases_text_based (
                                                release_id integer NOT NULL,
-                                               content text NOT NULL
+                                               content text NOT NULL,
+                                               format text DEFAULT 'html'::text NOT NULL
 );
 
 
@@ -548,7 +566,10 @@ CREATE TABLE "issue#1".users (
                                  pass_hash text NOT NULL,
                                  first_name character varying(30),
                                  middle_name character varying(30),
-                                 last_name character varying(30)
+                                 last_name character varying(30),
+                                 max_rating text DEFAULT 'teen'::text NOT NULL,
+                                 hidden_warnings text[] DEFAULT '{}'::text[] NOT NULL,
+                                 CONSTRAINT users_max_rating_check CHECK ((max_rating = ANY (ARRAY['general'::text, 'teen'::text, 'mature'::text, 'explicit'::text])))
 );
 
 
@@ -566,6 +587,358 @@ CREATE TABLE "issue#1".users_bio (
 
 ALTER TABLE "issue#1".users_bio OWNER TO "issue#1_dev";
 
+--
+-- Name: post_draft_reviews; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".post_draft_reviews (
+                                 id integer GENERATED ALWAYS AS IDENTITY,
+                                 post_id integer NOT NULL,
+                                 reviewer character varying(24) NOT NULL,
+                                 content text NOT NULL,
+                                 verdict text DEFAULT ''::text NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+
+ALTER TABLE "issue#1".post_draft_reviews OWNER TO "issue#1_dev";
+
+--
+-- Name: channel_members; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".channel_members (
+                                 channel_username character varying(24) NOT NULL,
+                                 username character varying(24) NOT NULL,
+                                 tier text DEFAULT 'member'::text NOT NULL,
+                                 join_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".channel_members OWNER TO "issue#1_dev";
+
+--
+-- Name: channel_join_requests; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".channel_join_requests (
+                                 channel_username character varying(24) NOT NULL,
+                                 username character varying(24) NOT NULL,
+                                 message text,
+                                 request_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".channel_join_requests OWNER TO "issue#1_dev";
+
+--
+-- Name: channel_banners; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".channel_banners (
+                                 channelname character varying(24) NOT NULL,
+                                 image_name text NOT NULL
+);
+
+
+ALTER TABLE "issue#1".channel_banners OWNER TO "issue#1_dev";
+
+--
+-- Name: channel_pages; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".channel_pages (
+                                 channel_username character varying(24) NOT NULL,
+                                 slug character varying(32) NOT NULL,
+                                 title text NOT NULL,
+                                 content text DEFAULT ''::text NOT NULL,
+                                 position integer DEFAULT 0 NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+                                 update_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".channel_pages OWNER TO "issue#1_dev";
+
+--
+-- Name: channel_blocks; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".channel_blocks (
+                                 channel_username character varying(24) NOT NULL,
+                                 username character varying(24) NOT NULL,
+                                 mode text DEFAULT 'block'::text NOT NULL,
+                                 reason text,
+                                 blocked_by character varying(24),
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".channel_blocks OWNER TO "issue#1_dev";
+
+--
+-- Name: reactions; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".reactions (
+                                 target_type text NOT NULL,
+                                 target_id integer NOT NULL,
+                                 username character varying(24) NOT NULL,
+                                 emoji text NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+                                 CONSTRAINT reactions_target_type_check CHECK ((target_type = ANY (ARRAY['post'::text, 'comment'::text, 'release'::text])))
+);
+
+
+ALTER TABLE "issue#1".reactions OWNER TO "issue#1_dev";
+
+--
+-- Name: post_revisions; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".post_revisions (
+                                 id integer GENERATED ALWAYS AS IDENTITY,
+                                 post_id integer NOT NULL,
+                                 editor character varying(24),
+                                 title text DEFAULT ''::text NOT NULL,
+                                 description text DEFAULT ''::text NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".post_revisions OWNER TO "issue#1_dev";
+
+--
+-- Name: release_revisions; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".release_revisions (
+                                 id integer GENERATED ALWAYS AS IDENTITY,
+                                 release_id integer NOT NULL,
+                                 editor character varying(24),
+                                 content text NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".release_revisions OWNER TO "issue#1_dev";
+
+--
+-- Name: post_crossposts; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".post_crossposts (
+                                 post_id integer NOT NULL,
+                                 channel_username character varying(24) NOT NULL,
+                                 requested_by character varying(24),
+                                 accepted boolean DEFAULT false NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".post_crossposts OWNER TO "issue#1_dev";
+
+--
+-- Name: polls; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".polls (
+                                 id integer GENERATED ALWAYS AS IDENTITY,
+                                 post_id integer NOT NULL,
+                                 created_by character varying(24),
+                                 question text NOT NULL,
+                                 multiple_choice boolean DEFAULT false NOT NULL,
+                                 hide_results boolean DEFAULT false NOT NULL,
+                                 closes_at timestamp with time zone,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".polls OWNER TO "issue#1_dev";
+
+--
+-- Name: poll_options; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".poll_options (
+                                 id integer GENERATED ALWAYS AS IDENTITY,
+                                 poll_id integer NOT NULL,
+                                 "position" integer NOT NULL,
+                                 text text NOT NULL
+);
+
+
+ALTER TABLE "issue#1".poll_options OWNER TO "issue#1_dev";
+
+--
+-- Name: poll_votes; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".poll_votes (
+                                 poll_id integer NOT NULL,
+                                 username character varying(24) NOT NULL,
+                                 option_ids integer[] NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".poll_votes OWNER TO "issue#1_dev";
+
+--
+-- Name: views; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".views (
+                                 target_type text NOT NULL,
+                                 target_id integer NOT NULL,
+                                 bucket timestamp with time zone NOT NULL,
+                                 count integer DEFAULT 0 NOT NULL,
+                                 CONSTRAINT views_target_type_check CHECK ((target_type = ANY (ARRAY['post'::text, 'release'::text])))
+);
+
+
+ALTER TABLE "issue#1".views OWNER TO "issue#1_dev";
+
+--
+-- Name: link_previews; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".link_previews (
+                                 url text NOT NULL,
+                                 type text NOT NULL,
+                                 title text DEFAULT ''::text NOT NULL,
+                                 description text DEFAULT ''::text NOT NULL,
+                                 image_url text DEFAULT ''::text NOT NULL,
+                                 site_name text DEFAULT ''::text NOT NULL,
+                                 fetch_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+                                 CONSTRAINT link_previews_type_check CHECK ((type = ANY (ARRAY['opengraph'::text, 'oembed'::text, 'html'::text])))
+);
+
+
+ALTER TABLE "issue#1".link_previews OWNER TO "issue#1_dev";
+
+--
+-- Name: mentions; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".mentions (
+                                 source_type text NOT NULL,
+                                 source_id integer NOT NULL,
+                                 kind text NOT NULL,
+                                 target text NOT NULL,
+                                 text text NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+                                 CONSTRAINT mentions_source_type_check CHECK ((source_type = ANY (ARRAY['post'::text, 'comment'::text]))),
+                                 CONSTRAINT mentions_kind_check CHECK ((kind = ANY (ARRAY['user'::text, 'channel'::text])))
+);
+
+
+ALTER TABLE "issue#1".mentions OWNER TO "issue#1_dev";
+
+--
+-- Name: release_pages; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".release_pages (
+                                 release_id integer NOT NULL,
+                                 number integer NOT NULL,
+                                 image_name text NOT NULL,
+                                 alt_text text DEFAULT ''::text NOT NULL,
+                                 CONSTRAINT release_pages_number_check CHECK ((number > 0))
+);
+
+
+ALTER TABLE "issue#1".release_pages OWNER TO "issue#1_dev";
+
+--
+-- Name: blobs; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".blobs (
+                                 name text NOT NULL,
+                                 size bigint DEFAULT 0 NOT NULL,
+                                 ref_count integer DEFAULT 0 NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+                                 unreferenced_since timestamp with time zone
+);
+
+
+ALTER TABLE "issue#1".blobs OWNER TO "issue#1_dev";
+
+--
+-- Name: releases_audio_based; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".releases_audio_based (
+                                 release_id integer NOT NULL,
+                                 audio_name text NOT NULL,
+                                 content_type text NOT NULL,
+                                 size bigint NOT NULL,
+                                 duration double precision DEFAULT 0 NOT NULL
+);
+
+
+ALTER TABLE "issue#1".releases_audio_based OWNER TO "issue#1_dev";
+
+--
+-- Name: release_credits; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".release_credits (
+                                 release_id integer NOT NULL,
+                                 "position" integer NOT NULL,
+                                 role text NOT NULL,
+                                 username character varying(24),
+                                 name text
+);
+
+
+ALTER TABLE "issue#1".release_credits OWNER TO "issue#1_dev";
+
+--
+-- Name: release_translations; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".release_translations (
+                                 release_id integer NOT NULL,
+                                 original_id integer NOT NULL,
+                                 permitted boolean DEFAULT false NOT NULL,
+                                 CONSTRAINT release_translations_check CHECK ((release_id <> original_id))
+);
+
+
+ALTER TABLE "issue#1".release_translations OWNER TO "issue#1_dev";
+
+--
+-- Name: tags; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".tags (
+                                 name text NOT NULL,
+                                 key text NOT NULL,
+                                 description text,
+                                 parent text,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+                                 CONSTRAINT tags_check CHECK ((name <> parent))
+);
+
+
+ALTER TABLE "issue#1".tags OWNER TO "issue#1_dev";
+
+--
+-- Name: tag_aliases; Type: TABLE; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TABLE "issue#1".tag_aliases (
+                                 key text NOT NULL,
+                                 alias text NOT NULL,
+                                 tag_name text NOT NULL,
+                                 creation_time timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE "issue#1".tag_aliases OWNER TO "issue#1_dev";
+
 --
 -- Name: feeds id; Type: DEFAULT; Schema: issue#1; Owner: issue#1_dev
 --
@@ -692,16 +1065,55 @@ INSERT INTO "issue#1".posts VALUES (3, 'B7 Chord, G6 Chord.', 'its so strangeeee
 INSERT INTO "issue#1".posts VALUES (6, 'Welcome!', 'Issue #1 v0.1', 'rembrandt', 'chromagnum', '2020-01-12 18:54:48.460537+03');
 
 
+--
+-- Data for Name: tags; Type: TABLE DATA; Schema: issue#1; Owner: issue#1_dev
+--
+
+INSERT INTO "issue#1".tags VALUES ('Atomic', 'atomic', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Cartoon', 'cartoon', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Catastrophe', 'catastrophe', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Coding', 'coding', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Design', 'design', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Guide', 'guide', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('K-Pop', 'kpop', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Literature', 'literature', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Message', 'message', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Omnious Message', 'omniousmessage', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Programming', 'programming', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('Prophecy', 'prophecy', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tags VALUES ('School', 'school', NULL, NULL, '2020-01-28 13:14:16.017584+03');
+
+
+--
+-- Data for Name: tag_aliases; Type: TABLE DATA; Schema: issue#1; Owner: issue#1_dev
+--
+
+INSERT INTO "issue#1".tag_aliases VALUES ('koreanpop', 'Korean Pop', 'K-Pop', '2020-01-28 13:14:16.017584+03');
+INSERT INTO "issue#1".tag_aliases VALUES ('ominousmessage', 'Ominous Message', 'Omnious Message', '2020-01-28 13:14:16.017584+03');
+
+
+--
+-- Data for Name: release_credits; Type: TABLE DATA; Schema: issue#1; Owner: issue#1_dev
+--
+
+INSERT INTO "issue#1".release_credits VALUES (73, 1, 'writer', NULL, 'Yohe-Am');
+INSERT INTO "issue#1".release_credits VALUES (6, 1, 'writer', 'rembrandt', NULL);
+INSERT INTO "issue#1".release_credits VALUES (53, 1, 'artist', NULL, 'Rebbecca Sugar');
+INSERT INTO "issue#1".release_credits VALUES (52, 1, 'writer', NULL, 'Hooded Messenger');
+INSERT INTO "issue#1".release_credits VALUES (54, 1, 'writer', NULL, 'Hooded Messenger');
+INSERT INTO "issue#1".release_credits VALUES (68, 1, 'writer', NULL, 'Man');
+
+
 --
 -- Data for Name: release_metadata; Type: TABLE DATA; Schema: issue#1; Owner: issue#1_dev
 --
 
-INSERT INTO "issue#1".release_metadata VALUES (73, 'Here it is folks.', '{"genres": ["Programming", "Design"], "authors": ["Yohe-Am"]}', 'Coding', '2020-01-28 13:14:16.017584+03', 'ISSUE #1 DESIGN DOC');
-INSERT INTO "issue#1".release_metadata VALUES (6, 'tips baby, tips', '{"genres": ["School"], "authors": ["@rembrandt"]}', 'Guide', '2020-01-04 11:25:15.055178+03', 'Tips1.md');
-INSERT INTO "issue#1".release_metadata VALUES (53, 'a broken moon', '{"genres": ["Cartoon"], "authors": ["Rebbecca Sugar"]}', 'Cartoon', '2016-01-04 23:06:16.017584+03', 'Please don''t leave Pink...don''t leave please.');
-INSERT INTO "issue#1".release_metadata VALUES (52, 'Forget-this-not.', '{"genres": ["Omnious Message", "Prophecy"], "authors": ["Hooded Messenger"]}', 'Message', '2020-01-05 13:14:16.017584+03', 'The Journey Ends!');
-INSERT INTO "issue#1".release_metadata VALUES (54, 'Guidelines to the future and to the deep, honest, archaic path.', '{"genres": ["K-Pop"], "authors": ["Hooded Messenger"]}', 'Literature', '2020-12-05 13:14:16.017584+03', 'Above & Not There Yet.');
-INSERT INTO "issue#1".release_metadata VALUES (68, 'Full stop.', '{"genres": ["Catastrophe"], "authors": ["Man"]}', 'Atomic', '2020-01-24 23:16:09.273085+03', 'This is Not A Test');
+INSERT INTO "issue#1".release_metadata VALUES (73, 'Here it is folks.', '{"genres": ["Programming", "Design"]}', 'Coding', '2020-01-28 13:14:16.017584+03', 'ISSUE #1 DESIGN DOC');
+INSERT INTO "issue#1".release_metadata VALUES (6, 'tips baby, tips', '{"genres": ["School"]}', 'Guide', '2020-01-04 11:25:15.055178+03', 'Tips1.md');
+INSERT INTO "issue#1".release_metadata VALUES (53, 'a broken moon', '{"genres": ["Cartoon"]}', 'Cartoon', '2016-01-04 23:06:16.017584+03', 'Please don''t leave Pink...don''t leave please.');
+INSERT INTO "issue#1".release_metadata VALUES (52, 'Forget-this-not.', '{"genres": ["Omnious Message", "Prophecy"]}', 'Message', '2020-01-05 13:14:16.017584+03', 'The Journey Ends!');
+INSERT INTO "issue#1".release_metadata VALUES (54, 'Guidelines to the future and to the deep, honest, archaic path.', '{"genres": ["K-Pop"]}', 'Literature', '2020-12-05 13:14:16.017584+03', 'Above & Not There Yet.');
+INSERT INTO "issue#1".release_metadata VALUES (68, 'Full stop.', '{"genres": ["Catastrophe"]}', 'Atomic', '2020-01-24 23:16:09.273085+03', 'This is Not A Test');
 
 
 --
@@ -1390,14 +1802,6 @@ ALTER TABLE ONLY "issue#1".feeds
     ADD CONSTRAINT feeds_pkey PRIMARY KEY (id);
 
 
---
--- Name: releases_image_based image based_image_name_key; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
---
-
-ALTER TABLE ONLY "issue#1".releases_image_based
-    ADD CONSTRAINT "image based_image_name_key" UNIQUE (image_name);
-
-
 --
 -- Name: releases_image_based image based_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
@@ -1511,195 +1915,543 @@ ALTER TABLE ONLY "issue#1".users
 
 
 --
--- Name: comment_ts_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+-- Name: post_draft_reviews post_draft_reviews_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE INDEX comment_ts_index ON "issue#1".tsvs_comment USING gin (vector);
+ALTER TABLE ONLY "issue#1".post_draft_reviews
+    ADD CONSTRAINT post_draft_reviews_pkey PRIMARY KEY (id);
 
 
 --
--- Name: post_ts_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_members channel_members_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE INDEX post_ts_index ON "issue#1".tsvs_posts USING gin (vector);
+ALTER TABLE ONLY "issue#1".channel_members
+    ADD CONSTRAINT channel_members_pkey PRIMARY KEY (channel_username, username);
 
 
 --
--- Name: release_ts_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_join_requests channel_join_requests_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE INDEX release_ts_index ON "issue#1".tsvs_release USING gin (vector);
+ALTER TABLE ONLY "issue#1".channel_join_requests
+    ADD CONSTRAINT channel_join_requests_pkey PRIMARY KEY (channel_username, username);
 
 
 --
--- Name: release_tsvs_release_id_uindex; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_banners channel_banners_pk; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE UNIQUE INDEX release_tsvs_release_id_uindex ON "issue#1".tsvs_release USING btree (release_id);
+ALTER TABLE ONLY "issue#1".channel_banners
+    ADD CONSTRAINT channel_banners_pk PRIMARY KEY (channelname);
 
 
 --
--- Name: comments comment_insert_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_pages channel_pages_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE TRIGGER comment_insert_trigger AFTER INSERT ON "issue#1".comments FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_comment_trigger();
+ALTER TABLE ONLY "issue#1".channel_pages
+    ADD CONSTRAINT channel_pages_pkey PRIMARY KEY (channel_username, slug);
 
 
 --
--- Name: comments comment_update_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_blocks channel_blocks_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE TRIGGER comment_update_trigger AFTER UPDATE ON "issue#1".comments FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_comment_trigger();
+ALTER TABLE ONLY "issue#1".channel_blocks
+    ADD CONSTRAINT channel_blocks_pkey PRIMARY KEY (channel_username, username);
 
 
 --
--- Name: release_metadata metadata_insert_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+-- Name: reactions reactions_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE TRIGGER metadata_insert_trigger AFTER INSERT ON "issue#1".release_metadata FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_on_metadata_update_trigger();
+ALTER TABLE ONLY "issue#1".reactions
+    ADD CONSTRAINT reactions_pkey PRIMARY KEY (target_type, target_id, username, emoji);
 
 
 --
--- Name: release_metadata metadata_update_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+-- Name: post_revisions post_revisions_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE TRIGGER metadata_update_trigger AFTER UPDATE ON "issue#1".release_metadata FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_on_metadata_update_trigger();
+ALTER TABLE ONLY "issue#1".post_revisions
+    ADD CONSTRAINT post_revisions_pkey PRIMARY KEY (id);
 
 
 --
--- Name: posts post_insert_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_revisions release_revisions_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE TRIGGER post_insert_trigger AFTER INSERT ON "issue#1".posts FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_post_trigger();
+ALTER TABLE ONLY "issue#1".release_revisions
+    ADD CONSTRAINT release_revisions_pkey PRIMARY KEY (id);
 
 
 --
--- Name: posts post_update_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+-- Name: post_crossposts post_crossposts_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE TRIGGER post_update_trigger AFTER UPDATE ON "issue#1".posts FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_post_trigger();
+ALTER TABLE ONLY "issue#1".post_crossposts
+    ADD CONSTRAINT post_crossposts_pkey PRIMARY KEY (post_id, channel_username);
 
 
 --
--- Name: users setup_user; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+-- Name: polls polls_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE TRIGGER setup_user AFTER INSERT ON "issue#1".users FOR EACH ROW EXECUTE FUNCTION "issue#1".setup_user();
+ALTER TABLE ONLY "issue#1".polls
+    ADD CONSTRAINT polls_pkey PRIMARY KEY (id);
 
 
 --
--- Name: releases_text_based text_based_update_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+-- Name: poll_options poll_options_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-CREATE TRIGGER text_based_update_trigger AFTER UPDATE ON "issue#1".releases_text_based FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_text_based_update_trigger();
+ALTER TABLE ONLY "issue#1".poll_options
+    ADD CONSTRAINT poll_options_pkey PRIMARY KEY (id);
 
 
 --
--- Name: channel_admins channel_admins_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: poll_votes poll_votes_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".channel_admins
-    ADD CONSTRAINT channel_admins_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".poll_votes
+    ADD CONSTRAINT poll_votes_pkey PRIMARY KEY (poll_id, username);
 
 
 --
--- Name: channel_admins channel_admins_user_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_stickies channel_stickies_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".channel_admins
-    ADD CONSTRAINT channel_admins_user_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".channel_stickies
+    ADD CONSTRAINT channel_stickies_pkey PRIMARY KEY (channel_username, post_id);
 
 
 --
--- Name: channel_official_catalog channel_catalog_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: views views_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".channel_official_catalog
-    ADD CONSTRAINT channel_catalog_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".views
+    ADD CONSTRAINT views_pkey PRIMARY KEY (target_type, target_id, bucket);
 
 
 --
--- Name: channel_official_catalog channel_catalog_post_from_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: link_previews link_previews_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".channel_official_catalog
-    ADD CONSTRAINT channel_catalog_post_from_id_fkey FOREIGN KEY (post_from_id) REFERENCES "issue#1".posts(id);
+ALTER TABLE ONLY "issue#1".link_previews
+    ADD CONSTRAINT link_previews_pkey PRIMARY KEY (url);
 
 
 --
--- Name: channel_official_catalog channel_catalog_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: mentions mentions_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".channel_official_catalog
-    ADD CONSTRAINT channel_catalog_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".mentions
+    ADD CONSTRAINT mentions_pkey PRIMARY KEY (source_type, source_id, kind, target);
 
 
 --
--- Name: channel_pictures channel_pictures_channels_username_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_pages release_pages_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".channel_pictures
-    ADD CONSTRAINT channel_pictures_channels_username_fk FOREIGN KEY (channelname) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".release_pages
+    ADD CONSTRAINT release_pages_pkey PRIMARY KEY (release_id, number) DEFERRABLE INITIALLY DEFERRED;
 
 
 --
--- Name: tsvs_comment comment_tsvs_comments_id_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: blobs blobs_pk; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".tsvs_comment
-    ADD CONSTRAINT comment_tsvs_comments_id_fk FOREIGN KEY (comment_id) REFERENCES "issue#1".comments(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".blobs
+    ADD CONSTRAINT blobs_pk PRIMARY KEY (name);
 
 
 --
--- Name: comments comments_commented_by_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: releases_audio_based releases_audio_based_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".comments
-    ADD CONSTRAINT comments_commented_by_fkey FOREIGN KEY (commented_by) REFERENCES "issue#1".users(username) ON UPDATE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".releases_audio_based
+    ADD CONSTRAINT releases_audio_based_pkey PRIMARY KEY (release_id);
 
 
 --
--- Name: comments comments_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_credits release_credits_credited_check; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".comments
-    ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_from) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".release_credits
+    ADD CONSTRAINT release_credits_credited_check CHECK (((username IS NULL) <> (name IS NULL)));
 
 
 --
--- Name: feed_subscriptions feed_channels_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_credits release_credits_role_check; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".feed_subscriptions
-    ADD CONSTRAINT feed_channels_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".release_credits
+    ADD CONSTRAINT release_credits_role_check CHECK ((role = ANY (ARRAY['writer'::text, 'artist'::text, 'colorist'::text, 'translator'::text, 'editor'::text])));
 
 
 --
--- Name: feed_subscriptions feed_channels_feed_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_translations release_translations_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".feed_subscriptions
-    ADD CONSTRAINT feed_channels_feed_id_fkey FOREIGN KEY (feed_id) REFERENCES "issue#1".feeds(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".release_translations
+    ADD CONSTRAINT release_translations_pkey PRIMARY KEY (release_id);
 
 
 --
--- Name: feeds feeds_owner_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: tags tags_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".feeds
-    ADD CONSTRAINT feeds_owner_username_fkey FOREIGN KEY (owner_username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".tags
+    ADD CONSTRAINT tags_pkey PRIMARY KEY (name);
 
 
 --
--- Name: release_metadata metadata_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: tags tags_key_key; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".release_metadata
-    ADD CONSTRAINT metadata_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".tags
+    ADD CONSTRAINT tags_key_key UNIQUE (key);
 
 
 --
--- Name: post_contents post_contents_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: tag_aliases tag_aliases_pkey; Type: CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".tag_aliases
+    ADD CONSTRAINT tag_aliases_pkey PRIMARY KEY (key);
+
+
+--
+-- Name: comment_ts_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX comment_ts_index ON "issue#1".tsvs_comment USING gin (vector);
+
+
+--
+-- Name: post_ts_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX post_ts_index ON "issue#1".tsvs_posts USING gin (vector);
+
+
+--
+-- Name: release_ts_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX release_ts_index ON "issue#1".tsvs_release USING gin (vector);
+
+
+--
+-- Name: release_tsvs_release_id_uindex; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE UNIQUE INDEX release_tsvs_release_id_uindex ON "issue#1".tsvs_release USING btree (release_id);
+
+
+--
+-- Name: posts_channel_from_status_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX posts_channel_from_status_index ON "issue#1".posts USING btree (channel_from, status);
+
+
+--
+-- Name: feed_subscriptions_channel_time_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX feed_subscriptions_channel_time_index ON "issue#1".feed_subscriptions USING btree (channel_username, subscription_time);
+
+
+--
+-- Name: comments_post_from_time_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX comments_post_from_time_index ON "issue#1".comments USING btree (post_from, creation_time);
+
+
+--
+-- Name: post_stars_star_time_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX post_stars_star_time_index ON "issue#1".post_stars USING btree (star_time);
+
+
+--
+-- Name: reactions_target_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX reactions_target_index ON "issue#1".reactions USING btree (target_type, target_id, creation_time);
+
+
+--
+-- Name: post_revisions_post_id_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX post_revisions_post_id_index ON "issue#1".post_revisions USING btree (post_id, creation_time);
+
+
+--
+-- Name: release_revisions_release_id_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX release_revisions_release_id_index ON "issue#1".release_revisions USING btree (release_id, creation_time);
+
+
+--
+-- Name: post_crossposts_channel_username_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX post_crossposts_channel_username_index ON "issue#1".post_crossposts USING btree (channel_username);
+
+
+--
+-- Name: polls_post_id_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX polls_post_id_index ON "issue#1".polls USING btree (post_id);
+
+
+--
+-- Name: poll_options_poll_id_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX poll_options_poll_id_index ON "issue#1".poll_options USING btree (poll_id, "position");
+
+
+--
+-- Name: views_bucket_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX views_bucket_index ON "issue#1".views USING btree (bucket);
+
+
+--
+-- Name: mentions_target_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX mentions_target_index ON "issue#1".mentions USING btree (kind, target, creation_time);
+
+
+--
+-- Name: blobs_unreferenced_since_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX blobs_unreferenced_since_index ON "issue#1".blobs USING btree (unreferenced_since) WHERE (ref_count = 0);
+
+
+--
+-- Name: release_credits_release_id_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX release_credits_release_id_index ON "issue#1".release_credits USING btree (release_id, "position");
+
+
+--
+-- Name: release_credits_username_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX release_credits_username_index ON "issue#1".release_credits USING btree (username);
+
+
+--
+-- Name: release_translations_original_id_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX release_translations_original_id_index ON "issue#1".release_translations USING btree (original_id);
+
+
+--
+-- Name: release_metadata_language_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX release_metadata_language_index ON "issue#1".release_metadata USING btree (language);
+
+
+--
+-- Name: tags_parent_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX tags_parent_index ON "issue#1".tags USING btree (parent);
+
+
+--
+-- Name: tag_aliases_tag_name_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX tag_aliases_tag_name_index ON "issue#1".tag_aliases USING btree (tag_name);
+
+
+--
+-- Name: release_metadata_genre_defining_index; Type: INDEX; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE INDEX release_metadata_genre_defining_index ON "issue#1".release_metadata USING btree (genre_defining);
+
+
+--
+-- Name: comments comment_insert_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TRIGGER comment_insert_trigger AFTER INSERT ON "issue#1".comments FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_comment_trigger();
+
+
+--
+-- Name: comments comment_update_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TRIGGER comment_update_trigger AFTER UPDATE ON "issue#1".comments FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_comment_trigger();
+
+
+--
+-- Name: release_metadata metadata_insert_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TRIGGER metadata_insert_trigger AFTER INSERT ON "issue#1".release_metadata FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_on_metadata_update_trigger();
+
+
+--
+-- Name: release_metadata metadata_update_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TRIGGER metadata_update_trigger AFTER UPDATE ON "issue#1".release_metadata FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_on_metadata_update_trigger();
+
+
+--
+-- Name: posts post_insert_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TRIGGER post_insert_trigger AFTER INSERT ON "issue#1".posts FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_post_trigger();
+
+
+--
+-- Name: posts post_update_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TRIGGER post_update_trigger AFTER UPDATE ON "issue#1".posts FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_post_trigger();
+
+
+--
+-- Name: users setup_user; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TRIGGER setup_user AFTER INSERT ON "issue#1".users FOR EACH ROW EXECUTE FUNCTION "issue#1".setup_user();
+
+
+--
+-- Name: releases_text_based text_based_update_trigger; Type: TRIGGER; Schema: issue#1; Owner: issue#1_dev
+--
+
+CREATE TRIGGER text_based_update_trigger AFTER UPDATE ON "issue#1".releases_text_based FOR EACH ROW EXECUTE FUNCTION "issue#1".tsv_text_based_update_trigger();
+
+
+--
+-- Name: channel_admins channel_admins_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_admins
+    ADD CONSTRAINT channel_admins_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: channel_admins channel_admins_user_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_admins
+    ADD CONSTRAINT channel_admins_user_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: channel_official_catalog channel_catalog_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_official_catalog
+    ADD CONSTRAINT channel_catalog_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: channel_official_catalog channel_catalog_post_from_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_official_catalog
+    ADD CONSTRAINT channel_catalog_post_from_id_fkey FOREIGN KEY (post_from_id) REFERENCES "issue#1".posts(id);
+
+
+--
+-- Name: channel_official_catalog channel_catalog_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_official_catalog
+    ADD CONSTRAINT channel_catalog_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: channel_pictures channel_pictures_channels_username_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_pictures
+    ADD CONSTRAINT channel_pictures_channels_username_fk FOREIGN KEY (channelname) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: tsvs_comment comment_tsvs_comments_id_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".tsvs_comment
+    ADD CONSTRAINT comment_tsvs_comments_id_fk FOREIGN KEY (comment_id) REFERENCES "issue#1".comments(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: comments comments_commented_by_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".comments
+    ADD CONSTRAINT comments_commented_by_fkey FOREIGN KEY (commented_by) REFERENCES "issue#1".users(username) ON UPDATE CASCADE NOT VALID;
+
+
+--
+-- Name: comments comments_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".comments
+    ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_from) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: feed_subscriptions feed_channels_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".feed_subscriptions
+    ADD CONSTRAINT feed_channels_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: feed_subscriptions feed_channels_feed_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".feed_subscriptions
+    ADD CONSTRAINT feed_channels_feed_id_fkey FOREIGN KEY (feed_id) REFERENCES "issue#1".feeds(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: feeds feeds_owner_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".feeds
+    ADD CONSTRAINT feeds_owner_username_fkey FOREIGN KEY (owner_username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: release_metadata metadata_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".release_metadata
+    ADD CONSTRAINT metadata_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON DELETE CASCADE;
+
+
+--
+-- Name: post_contents post_contents_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
 ALTER TABLE ONLY "issue#1".post_contents
@@ -1707,123 +2459,379 @@ ALTER TABLE ONLY "issue#1".post_contents
 
 
 --
--- Name: post_contents post_contents_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: post_contents post_contents_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".post_contents
+    ADD CONSTRAINT post_contents_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: post_stars post_stars_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".post_stars
+    ADD CONSTRAINT post_stars_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: post_stars post_stars_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".post_stars
+    ADD CONSTRAINT post_stars_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: posts posts_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".posts
+    ADD CONSTRAINT posts_channel_username_fkey FOREIGN KEY (channel_from) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: posts posts_poster_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".posts
+    ADD CONSTRAINT posts_poster_username_fkey FOREIGN KEY (posted_by) REFERENCES "issue#1".users(username) ON UPDATE CASCADE NOT VALID;
+
+
+--
+-- Name: tsvs_posts posts_tsvs_posts_id_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".tsvs_posts
+    ADD CONSTRAINT posts_tsvs_posts_id_fk FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: tsvs_release release_tsvs_releases_id_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".tsvs_release
+    ADD CONSTRAINT release_tsvs_releases_id_fk FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: releases_image_based releases_image_based_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".releases_image_based
+    ADD CONSTRAINT releases_image_based_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: releases releases_owner_channel_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".releases
+    ADD CONSTRAINT releases_owner_channel_fkey FOREIGN KEY (owner_channel) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: channel_stickies stickied_posts_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_stickies
+    ADD CONSTRAINT stickied_posts_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: releases_text_based text based_title_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".releases_text_based
+    ADD CONSTRAINT "text based_title_id_fkey" FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON DELETE CASCADE NOT VALID;
+
+
+--
+-- Name: user_avatars user_avatars_users_username_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".user_avatars
+    ADD CONSTRAINT user_avatars_users_username_fk FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: user_bookmarks user_bookmarks_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".user_bookmarks
+    ADD CONSTRAINT user_bookmarks_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: user_bookmarks user_bookmarks_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".user_bookmarks
+    ADD CONSTRAINT user_bookmarks_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: users_bio users_bio_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".post_contents
-    ADD CONSTRAINT post_contents_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".users_bio
+    ADD CONSTRAINT users_bio_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
 
 
 --
--- Name: post_stars post_stars_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: post_draft_reviews post_draft_reviews_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".post_stars
-    ADD CONSTRAINT post_stars_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".post_draft_reviews
+    ADD CONSTRAINT post_draft_reviews_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: post_stars post_stars_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: post_draft_reviews post_draft_reviews_reviewer_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".post_stars
-    ADD CONSTRAINT post_stars_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".post_draft_reviews
+    ADD CONSTRAINT post_draft_reviews_reviewer_fkey FOREIGN KEY (reviewer) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: posts posts_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_members channel_members_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".posts
-    ADD CONSTRAINT posts_channel_username_fkey FOREIGN KEY (channel_from) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".channel_members
+    ADD CONSTRAINT channel_members_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: posts posts_poster_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_members channel_members_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".posts
-    ADD CONSTRAINT posts_poster_username_fkey FOREIGN KEY (posted_by) REFERENCES "issue#1".users(username) ON UPDATE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".channel_members
+    ADD CONSTRAINT channel_members_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: tsvs_posts posts_tsvs_posts_id_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_join_requests channel_join_requests_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".tsvs_posts
-    ADD CONSTRAINT posts_tsvs_posts_id_fk FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".channel_join_requests
+    ADD CONSTRAINT channel_join_requests_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: tsvs_release release_tsvs_releases_id_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_join_requests channel_join_requests_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".tsvs_release
-    ADD CONSTRAINT release_tsvs_releases_id_fk FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".channel_join_requests
+    ADD CONSTRAINT channel_join_requests_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: releases_image_based releases_image_based_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_banners channel_banners_channels_username_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".releases_image_based
-    ADD CONSTRAINT releases_image_based_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".channel_banners
+    ADD CONSTRAINT channel_banners_channels_username_fk FOREIGN KEY (channelname) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: releases releases_owner_channel_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_pages channel_pages_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".releases
-    ADD CONSTRAINT releases_owner_channel_fkey FOREIGN KEY (owner_channel) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".channel_pages
+    ADD CONSTRAINT channel_pages_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: channel_stickies stickied_posts_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: channel_blocks channel_blocks_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_blocks
+    ADD CONSTRAINT channel_blocks_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: channel_blocks channel_blocks_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".channel_blocks
+    ADD CONSTRAINT channel_blocks_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: reactions reactions_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".reactions
+    ADD CONSTRAINT reactions_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: post_revisions post_revisions_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".post_revisions
+    ADD CONSTRAINT post_revisions_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: post_revisions post_revisions_editor_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".post_revisions
+    ADD CONSTRAINT post_revisions_editor_fkey FOREIGN KEY (editor) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
+--
+-- Name: release_revisions release_revisions_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".release_revisions
+    ADD CONSTRAINT release_revisions_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: release_revisions release_revisions_editor_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".release_revisions
+    ADD CONSTRAINT release_revisions_editor_fkey FOREIGN KEY (editor) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
+--
+-- Name: post_crossposts post_crossposts_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".post_crossposts
+    ADD CONSTRAINT post_crossposts_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: post_crossposts post_crossposts_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".post_crossposts
+    ADD CONSTRAINT post_crossposts_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: post_crossposts post_crossposts_requested_by_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".post_crossposts
+    ADD CONSTRAINT post_crossposts_requested_by_fkey FOREIGN KEY (requested_by) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
+--
+-- Name: polls polls_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".polls
+    ADD CONSTRAINT polls_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: polls polls_created_by_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".polls
+    ADD CONSTRAINT polls_created_by_fkey FOREIGN KEY (created_by) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
+--
+-- Name: poll_options poll_options_poll_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".poll_options
+    ADD CONSTRAINT poll_options_poll_id_fkey FOREIGN KEY (poll_id) REFERENCES "issue#1".polls(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: poll_votes poll_votes_poll_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".poll_votes
+    ADD CONSTRAINT poll_votes_poll_id_fkey FOREIGN KEY (poll_id) REFERENCES "issue#1".polls(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: poll_votes poll_votes_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".poll_votes
+    ADD CONSTRAINT poll_votes_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: channel_stickies channel_stickies_channel_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
 ALTER TABLE ONLY "issue#1".channel_stickies
-    ADD CONSTRAINT stickied_posts_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+    ADD CONSTRAINT channel_stickies_channel_username_fkey FOREIGN KEY (channel_username) REFERENCES "issue#1".channels(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: releases_text_based text based_title_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_pages release_pages_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".releases_text_based
-    ADD CONSTRAINT "text based_title_id_fkey" FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".release_pages
+    ADD CONSTRAINT release_pages_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: user_avatars user_avatars_users_username_fk; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: releases_audio_based releases_audio_based_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".user_avatars
-    ADD CONSTRAINT user_avatars_users_username_fk FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".releases_audio_based
+    ADD CONSTRAINT releases_audio_based_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: user_bookmarks user_bookmarks_post_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_credits release_credits_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".user_bookmarks
-    ADD CONSTRAINT user_bookmarks_post_id_fkey FOREIGN KEY (post_id) REFERENCES "issue#1".posts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".release_credits
+    ADD CONSTRAINT release_credits_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: user_bookmarks user_bookmarks_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_credits release_credits_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".user_bookmarks
-    ADD CONSTRAINT user_bookmarks_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY "issue#1".release_credits
+    ADD CONSTRAINT release_credits_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
--- Name: users_bio users_bio_username_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+-- Name: release_translations release_translations_release_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
 --
 
-ALTER TABLE ONLY "issue#1".users_bio
-    ADD CONSTRAINT users_bio_username_fkey FOREIGN KEY (username) REFERENCES "issue#1".users(username) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE ONLY "issue#1".release_translations
+    ADD CONSTRAINT release_translations_release_id_fkey FOREIGN KEY (release_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: release_translations release_translations_original_id_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".release_translations
+    ADD CONSTRAINT release_translations_original_id_fkey FOREIGN KEY (original_id) REFERENCES "issue#1".releases(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: tags tags_parent_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".tags
+    ADD CONSTRAINT tags_parent_fkey FOREIGN KEY (parent) REFERENCES "issue#1".tags(name) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
+--
+-- Name: tag_aliases tag_aliases_tag_name_fkey; Type: FK CONSTRAINT; Schema: issue#1; Owner: issue#1_dev
+--
+
+ALTER TABLE ONLY "issue#1".tag_aliases
+    ADD CONSTRAINT tag_aliases_tag_name_fkey FOREIGN KEY (tag_name) REFERENCES "issue#1".tags(name) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
 --
@@ -2281,6 +3289,167 @@ GRANT ALL ON TABLE "issue#1".users TO "issue#1_REST";
 GRANT ALL ON TABLE "issue#1".users_bio TO "issue#1_REST";
 
 
+--
+-- Name: TABLE post_draft_reviews; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".post_draft_reviews TO "issue#1_REST";
+
+
+--
+-- Name: TABLE channel_members; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".channel_members TO "issue#1_REST";
+
+
+--
+-- Name: TABLE channel_join_requests; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".channel_join_requests TO "issue#1_REST";
+
+
+--
+-- Name: TABLE channel_banners; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".channel_banners TO "issue#1_REST";
+
+
+--
+-- Name: TABLE channel_pages; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".channel_pages TO "issue#1_REST";
+
+
+--
+-- Name: TABLE channel_blocks; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".channel_blocks TO "issue#1_REST";
+
+
+--
+-- Name: TABLE reactions; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".reactions TO "issue#1_REST";
+
+
+--
+-- Name: TABLE post_revisions; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".post_revisions TO "issue#1_REST";
+
+
+--
+-- Name: TABLE release_revisions; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".release_revisions TO "issue#1_REST";
+
+
+--
+-- Name: TABLE post_crossposts; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".post_crossposts TO "issue#1_REST";
+
+
+--
+-- Name: TABLE polls; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".polls TO "issue#1_REST";
+
+
+--
+-- Name: TABLE poll_options; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".poll_options TO "issue#1_REST";
+
+
+--
+-- Name: TABLE poll_votes; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".poll_votes TO "issue#1_REST";
+
+
+--
+-- Name: TABLE views; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".views TO "issue#1_REST";
+
+
+--
+-- Name: TABLE link_previews; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".link_previews TO "issue#1_REST";
+
+
+--
+-- Name: TABLE mentions; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".mentions TO "issue#1_REST";
+
+
+--
+-- Name: TABLE release_pages; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".release_pages TO "issue#1_REST";
+
+
+--
+-- Name: TABLE blobs; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".blobs TO "issue#1_REST";
+
+
+--
+-- Name: TABLE releases_audio_based; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".releases_audio_based TO "issue#1_REST";
+
+
+--
+-- Name: TABLE release_credits; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".release_credits TO "issue#1_REST";
+
+
+--
+-- Name: TABLE release_translations; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".release_translations TO "issue#1_REST";
+
+
+--
+-- Name: TABLE tags; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".tags TO "issue#1_REST";
+
+
+--
+-- Name: TABLE tag_aliases; Type: ACL; Schema: issue#1; Owner: issue#1_dev
+--
+
+GRANT ALL ON TABLE "issue#1".tag_aliases TO "issue#1_REST";
+
+
 --
 -- PostgreSQL database dump complete
 --